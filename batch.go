@@ -0,0 +1,78 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BatchWriter определяет интерфейс провайдеров, умеющих записывать
+// несколько записей за один вызов (например, одним запросом к удаленному сервису).
+// Обертки, накапливающие записи, определяют его через приведение типа
+// и используют WriteEntries, который при отсутствии интерфейса
+// передает записи по одной через Write.
+//
+// Записи передаются в порядке их создания и должны сохраняться в том же порядке.
+// При ошибке WriteBatch возвращает:
+//   - *BatchError, если первые BatchError.Written записей сохранены,
+//     а остальные нет;
+//   - любую другую ошибку, если не сохранена ни одна запись.
+//
+// Несохраненные записи считаются неудачными и могут быть переданы повторно,
+// поэтому частично сохраненный пакет не должен приводить к дублированию
+// уже сохраненных записей.
+type BatchWriter interface {
+	// WriteBatch записывает пакет записей
+	WriteBatch(ctx context.Context, entries []Entry) error
+}
+
+// BatchError описывает частично записанный пакет: первые Written записей
+// сохранены, запись остальных завершилась ошибкой Err.
+type BatchError struct {
+	Written int
+	Err     error
+}
+
+// Error возвращает описание ошибки.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("sglogger: batch written partially (%d entries): %v", e.Written, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// WriteEntries записывает пакет записей в провайдер: через WriteBatch,
+// если провайдер реализует BatchWriter, иначе по одной через Write до первой
+// ошибки с временем записи в контексте (см. ContextWithEntryTime).
+// Возвращает количество сохраненных записей (всегда префикс entries)
+// и ошибку записи остальных.
+func WriteEntries(ctx context.Context, provider LoggerProvider, entries []Entry) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	if bw, ok := provider.(BatchWriter); ok {
+		err := bw.WriteBatch(ctx, entries)
+		if err == nil {
+			return len(entries), nil
+		}
+		var batchErr *BatchError
+		if errors.As(err, &batchErr) && batchErr.Written >= 0 && batchErr.Written <= len(entries) {
+			return batchErr.Written, batchErr.Err
+		}
+		return 0, err
+	}
+
+	for i, e := range entries {
+		entryCtx := ctx
+		if !e.Time.IsZero() {
+			entryCtx = ContextWithEntryTime(ctx, e.Time)
+		}
+		if err := provider.Write(entryCtx, e.Level, e.Message, e.Fields); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
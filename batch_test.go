@@ -0,0 +1,162 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// batchProvider реализует BatchWriter и возвращает заданную ошибку из WriteBatch.
+type batchProvider struct {
+	recordingProvider
+
+	err     error
+	batches [][]Entry
+}
+
+func (p *batchProvider) WriteBatch(ctx context.Context, entries []Entry) error {
+	p.batches = append(p.batches, entries)
+	return p.err
+}
+
+// errWriteFailed возвращается failAtProvider.
+var errWriteFailed = errors.New("write failed")
+
+// failAtProvider запоминает записи и отклоняет запись с номером failAt.
+type failAtProvider struct {
+	failAt int
+	times  []time.Time
+}
+
+func (p *failAtProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if len(p.times) == p.failAt {
+		return errWriteFailed
+	}
+	p.times = append(p.times, entryTime(ctx))
+	return nil
+}
+
+func (p *failAtProvider) ShouldLog(ctx context.Context, level Level) bool { return true }
+
+func (p *failAtProvider) Close(ctx context.Context) error { return nil }
+
+func TestWriteEntries(t *testing.T) {
+	errSend := errors.New("send failed")
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := make([]Entry, 4)
+	for i := range entries {
+		entries[i] = Entry{Time: base.Add(time.Duration(i) * time.Second), Level: LevelInfo, Message: "entry"}
+	}
+
+	tests := []struct {
+		name        string
+		provider    LoggerProvider
+		wantWritten int
+		wantErr     error
+	}{
+		{name: "batch success", provider: &batchProvider{}, wantWritten: 4},
+		{name: "batch partial", provider: &batchProvider{err: &BatchError{Written: 2, Err: errSend}}, wantWritten: 2, wantErr: errSend},
+		{name: "batch failed", provider: &batchProvider{err: errSend}, wantWritten: 0, wantErr: errSend},
+		{name: "batch invalid written", provider: &batchProvider{err: &BatchError{Written: 10, Err: errSend}}, wantWritten: 0, wantErr: errSend},
+		{name: "per entry success", provider: &failAtProvider{failAt: -1}, wantWritten: 4},
+		{name: "per entry stops at first error", provider: &failAtProvider{failAt: 1}, wantWritten: 1, wantErr: errWriteFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written, err := WriteEntries(context.Background(), tt.provider, entries)
+			if written != tt.wantWritten {
+				t.Errorf("written = %d, want %d", written, tt.wantWritten)
+			}
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("err = %v, want nil", err)
+			case tt.wantErr != nil && err == nil:
+				t.Errorf("err = nil, want %v", tt.wantErr)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			switch p := tt.provider.(type) {
+			case *batchProvider:
+				if len(p.batches) != 1 || len(p.batches[0]) != len(entries) {
+					t.Errorf("WriteBatch calls = %d, want one call with all entries", len(p.batches))
+				}
+				if len(p.Entries()) != 0 {
+					t.Errorf("Write called %d times for a BatchWriter", len(p.Entries()))
+				}
+			case *failAtProvider:
+				// Каждая запись передается со своим временем, а не временем доставки
+				for i, got := range p.times {
+					if !got.Equal(entries[i].Time) {
+						t.Errorf("entry %d time = %v, want %v", i, got, entries[i].Time)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPBatcherWriteBatch(t *testing.T) {
+	errSend := errors.New("send failed")
+
+	tests := []struct {
+		name        string
+		failOnCall  int // номер отправки, завершающейся ошибкой; 0 - без ошибок
+		wantSent    []string
+		wantWritten int // BatchError.Written или -1, если ошибка не *BatchError
+		wantErr     bool
+	}{
+		{name: "all batches sent", wantSent: []string{"a", "b", "c", "d", "e"}},
+		{name: "first batch fails", failOnCall: 1, wantWritten: -1, wantErr: true},
+		{name: "third batch fails", failOnCall: 3, wantSent: []string{"a", "b", "c", "d"}, wantWritten: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			calls := 0
+			send := func(ctx context.Context, tenant string, entries []Entry) error {
+				calls++
+				if calls == tt.failOnCall {
+					return errSend
+				}
+				for _, e := range entries {
+					sent = append(sent, e.Message)
+				}
+				return nil
+			}
+			b := newHTTPBatcher(HTTPBatchConfig{BatchSize: 2, FlushInterval: time.Hour}, send, nil, nil)
+			defer b.close(context.Background())
+
+			var entries []Entry
+			for _, message := range []string{"a", "b", "c", "d", "e"} {
+				entries = append(entries, Entry{Time: time.Now(), Level: LevelInfo, Message: message})
+			}
+			err := b.writeBatch(context.Background(), entries)
+
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("sent %q, want %q", sent, tt.wantSent)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("writeBatch = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, errSend) {
+				t.Fatalf("writeBatch = %v, want %v", err, errSend)
+			}
+			var batchErr *BatchError
+			written := -1
+			if errors.As(err, &batchErr) {
+				written = batchErr.Written
+			}
+			if written != tt.wantWritten {
+				t.Errorf("BatchError.Written = %d, want %d", written, tt.wantWritten)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/pprof"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newBenchLogger создает логгер уровня Info с текстовым провайдером,
+// пишущим в io.Discard.
+func newBenchLogger() Logger {
+	return newBenchLoggerWithConfig(LoggerConfig{})
+}
+
+// newBenchLoggerWithConfig создает такой же логгер с настройками config.
+func newBenchLoggerWithConfig(config LoggerConfig) Logger {
+	provider := NewFmtProviderWithWriter(ProviderConfig{Level: LevelInfo}, io.Discard)
+	return NewLogger(config, NewFieldsHandler(), provider)
+}
+
+// benchFields - пять полей типичной записи о запросе.
+var benchFields = Fields{
+	"method":      "GET",
+	"path":        "/api/orders",
+	"status":      200,
+	"duration_ms": 12.5,
+	"error":       errors.New("upstream timeout"),
+}
+
+func BenchmarkInfoDisabled(b *testing.B) {
+	logger := newBenchLogger()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug(ctx, "cache miss")
+	}
+}
+
+func BenchmarkInfoNoFields(b *testing.B) {
+	logger := newBenchLogger()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info(ctx, "request handled")
+	}
+}
+
+func BenchmarkInfo5Fields(b *testing.B) {
+	logger := newBenchLogger()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.InfoWithFields(ctx, benchFields, "request handled")
+	}
+}
+
+func BenchmarkInfoWithContext(b *testing.B) {
+	logger := newBenchLogger()
+	ctx := ContextWithFields(context.Background(), Fields{"trace_id": "4bf92f3577b34da6", "tenant": "acme"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info(ctx, "request handled")
+	}
+}
+
+// BenchmarkGoroutineInfo сравнивает стоимость записи с полями
+// LoggerConfig.GoroutineInfo и без них; для отключенного уровня
+// идентификатор горутины не вычисляется.
+func BenchmarkGoroutineInfo(b *testing.B) {
+	labeled := pprof.WithLabels(context.Background(), pprof.Labels("handler", "orders"))
+
+	benchmarks := []struct {
+		name   string
+		config LoggerConfig
+		ctx    context.Context
+		debug  bool // запись отключенного уровня
+	}{
+		{name: "off", ctx: context.Background()},
+		{name: "on", config: LoggerConfig{GoroutineInfo: true}, ctx: context.Background()},
+		{name: "on with labels", config: LoggerConfig{GoroutineInfo: true}, ctx: labeled},
+		{name: "on disabled level", config: LoggerConfig{GoroutineInfo: true}, ctx: context.Background(), debug: true},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			logger := newBenchLoggerWithConfig(bm.config)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if bm.debug {
+					logger.Debug(bm.ctx, "request handled")
+				} else {
+					logger.Info(bm.ctx, "request handled")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFormatters измеряет форматтеры с 0, 3 и 10 полями; буфер
+// переиспользуется, как в провайдерах с пулом буферов.
+func BenchmarkFormatters(b *testing.B) {
+	text, err := NewTextFormatter(ProviderConfig{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	formatters := []struct {
+		name      string
+		formatter Formatter
+	}{
+		{"text", text},
+		{"json", NewJSONFormatter(ProviderConfig{})},
+		{"json deterministic", NewJSONFormatter(ProviderConfig{JSON: JSONFormat{Deterministic: true}})},
+	}
+
+	for _, f := range formatters {
+		for _, n := range []int{0, 3, 10} {
+			fields := make(Fields, n)
+			for i := 0; i < n; i++ {
+				fields["field_"+strconv.Itoa(i)] = i
+			}
+			entry := Entry{Time: time.Now(), Level: LevelInfo, Message: "request handled", Fields: fields}
+
+			b.Run(f.name+"/"+strconv.Itoa(n)+" fields", func(b *testing.B) {
+				var buf []byte
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					buf = f.formatter.AppendFormat(buf[:0], entry)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkDeltaBatch сравнивает размер пакета из 100 записей с шестью
+// статическими полями в формате AppendDeltaBatch и в виде строк JSON
+// (метрика bytes/batch); saved_% - экономия delta-формата.
+func BenchmarkDeltaBatch(b *testing.B) {
+	entries := make([]Entry, 100)
+	for i := range entries {
+		entries[i] = Entry{
+			Time:    time.Date(2024, 5, 1, 12, 0, i, 0, time.UTC),
+			Level:   LevelInfo,
+			Message: "request handled",
+			Fields: Fields{
+				"service": "orders-api", "env": "production", "host": "orders-api-7f9c4-x2k8p",
+				"version": "1.42.0", "region": "eu-central-1", "msg": "static",
+				"request_id": "req-" + strconv.Itoa(i), "status": 200 + i%3, "duration_ms": float64(i) / 4,
+			},
+		}
+	}
+	formatter := NewJSONFormatter(ProviderConfig{})
+	ndjson := func(buf []byte) []byte {
+		for _, e := range entries {
+			buf = formatter.AppendFormat(buf, e)
+		}
+		return buf
+	}
+	ndjsonSize := len(ndjson(nil))
+
+	b.Run("ndjson", func(b *testing.B) {
+		var buf []byte
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf = ndjson(buf[:0])
+		}
+		b.ReportMetric(float64(len(buf)), "bytes/batch")
+	})
+	b.Run("delta", func(b *testing.B) {
+		var buf []byte
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf = AppendDeltaBatch(buf[:0], entries, FloatFormat{})
+		}
+		b.ReportMetric(float64(len(buf)), "bytes/batch")
+		b.ReportMetric(100*(1-float64(len(buf))/float64(ndjsonSize)), "saved_%")
+	})
+}
+
+// BenchmarkOrdered сравнивает запись из параллельных горутин без
+// упорядочивания и через NewOrderedProvider; reordered/op - доля записей,
+// которые пришли раньше предшествующих и были задержаны.
+func BenchmarkOrdered(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		ordered bool
+	}{
+		{name: "best effort"},
+		{name: "ordered", ordered: true},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var provider LoggerProvider = NewFmtProviderWithWriter(ProviderConfig{Level: LevelInfo}, io.Discard)
+			if bm.ordered {
+				var err error
+				if provider, err = NewOrderedProvider(provider, OrderedConfig{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			logger := NewLogger(LoggerConfig{}, NewFieldsHandler(), provider)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				ctx := context.Background()
+				for pb.Next() {
+					logger.InfoWithFields(ctx, benchFields, "request handled")
+				}
+			})
+			b.StopTimer()
+			if recorder, ok := provider.(OrderRecorder); ok {
+				b.ReportMetric(float64(recorder.OrderStats().Reordered)/float64(b.N), "reordered/op")
+			}
+		})
+	}
+}
+
+// TestInfoAllocs закрепляет результаты бенчмарков: запись отключенного
+// уровня не выделяет память, запись без полей - не больше двух раз.
+func TestInfoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector adds allocations")
+	}
+	logger := newBenchLogger()
+	ctx := context.Background()
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		logger.Debug(ctx, "cache miss")
+	}); allocs != 0 {
+		t.Errorf("disabled level: %v allocs/op, want 0", allocs)
+	}
+	// Аргументы не форматируются; остается только срез аргументов,
+	// который вызывающий размещает в куче при вызове метода интерфейса
+	if allocs := testing.AllocsPerRun(100, func() {
+		logger.Debug(ctx, "cache miss for %s", "order:42")
+	}); allocs > 1 {
+		t.Errorf("disabled level with an argument: %v allocs/op, want at most 1", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() {
+		logger.Info(ctx, "request handled")
+	}); allocs > 2 {
+		t.Errorf("no fields: %v allocs/op, want at most 2", allocs)
+	}
+}
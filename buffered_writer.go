@@ -0,0 +1,98 @@
+package sglogger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// defaultFlushInterval задает период фонового сброса буфера по умолчанию.
+const defaultFlushInterval = time.Second
+
+// bufferedWriter буферизует запись в нижележащий io.Writer и периодически
+// сбрасывает буфер из фонового горутина.
+//
+// Окно потери данных: при аварийном завершении процесса (panic без recover,
+// SIGKILL, отключение питания) теряются записи, накопленные с момента последнего
+// сброса, то есть не более чем за flushInterval и не более size байт.
+// Провайдеры сбрасывают буфер немедленно после записи сообщений уровня Error
+// и выше, поэтому ошибки и критические сообщения в буфере не задерживаются.
+type bufferedWriter struct {
+	mu   sync.Mutex
+	buf  *bufio.Writer
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// newBufferedWriter создает буферизованный writer с заданным размером буфера
+// и запускает фоновый сброс с периодом interval (по умолчанию 1 секунда).
+func newBufferedWriter(w io.Writer, size int, interval time.Duration) *bufferedWriter {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	bw := &bufferedWriter{
+		buf:  bufio.NewWriterSize(w, size),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go bw.flushLoop(interval)
+	return bw
+}
+
+// Write записывает данные в буфер.
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+// Flush сбрасывает накопленные данные в нижележащий writer.
+func (w *bufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Flush()
+}
+
+// reset направляет буфер в w после замены файла. bufio.Writer запоминает
+// первую ошибку записи и возвращает ее при каждом следующем вызове, поэтому
+// без сброса одна неудачная запись в прежний файл навсегда останавливала бы
+// вывод. Данные, которые не удалось сбросить в прежний файл, отбрасываются:
+// ошибка о них уже возвращена вызывающему.
+func (w *bufferedWriter) reset(sink io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Reset(sink)
+}
+
+// Close останавливает фоновый сброс и сбрасывает оставшиеся данные.
+// Нижележащий writer не закрывается.
+func (w *bufferedWriter) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+	return w.Flush()
+}
+
+// flushLoop периодически сбрасывает буфер до вызова Close.
+func (w *bufferedWriter) flushLoop(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.Flush()
+		case <-w.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingWriter возвращает ошибку, пока установлен fail.
+type failingWriter struct {
+	fail int32
+	buf  bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.fail) != 0 {
+		return 0, errors.New("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func TestFmtProviderFlushesOnError(t *testing.T) {
+	var out bytes.Buffer
+	provider := NewFmtProviderWithWriter(ProviderConfig{BufferSize: 4096, FlushInterval: time.Hour}, &out)
+	defer provider.Close(context.Background())
+
+	ctx := context.Background()
+	if err := provider.Write(ctx, LevelWarn, "retrying", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("warning was written before a flush: %q", out.String())
+	}
+	if err := provider.Write(ctx, LevelError, "failed", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "retrying") || !strings.Contains(got, "failed") {
+		t.Errorf("output after an error entry = %q, want both entries", got)
+	}
+}
+
+func TestFileProviderFlushesOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	provider, err := NewFileProvider(FileProviderConfig{
+		ProviderConfig: ProviderConfig{BufferSize: 4096, FlushInterval: time.Hour},
+		Path:           path,
+	})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	ctx := context.Background()
+	if err := provider.Write(ctx, LevelInfo, "started", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Fatalf("info entry was written before a flush: %q", data)
+	}
+	if err := provider.Write(ctx, LevelFatal, "crashed", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "started") || !strings.Contains(string(data), "crashed") {
+		t.Errorf("file after a fatal entry = %q, want both entries", data)
+	}
+}
+
+func TestBufferedWriterReset(t *testing.T) {
+	broken := &failingWriter{fail: 1}
+	w := newBufferedWriter(broken, 64, time.Hour)
+	defer w.Close()
+
+	w.Write([]byte("lost\n"))
+	if err := w.Flush(); err == nil {
+		t.Fatal("Flush to a failing writer succeeded")
+	}
+	// Ошибка bufio.Writer сохраняется и после восстановления writer
+	atomic.StoreInt32(&broken.fail, 0)
+	if err := w.Flush(); err == nil {
+		t.Fatal("Flush succeeded without reset")
+	}
+
+	var sink bytes.Buffer
+	w.reset(&sink)
+	w.Write([]byte("kept\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush after reset: %v", err)
+	}
+	if got := sink.String(); got != "kept\n" {
+		t.Errorf("sink = %q, want only the entry written after reset", got)
+	}
+}
+
+// failingFile - файл журнала, запись в который не проходит, пока
+// установлен fail.
+type failingFile struct {
+	*os.File
+	fail *int32
+}
+
+func (f failingFile) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(f.fail) != 0 {
+		return 0, errors.New("disk full")
+	}
+	return f.File.Write(p)
+}
+
+func TestFileProviderRecoversAfterSinkError(t *testing.T) {
+	fail := new(int32)
+	path := filepath.Join(t.TempDir(), "app.log")
+	p, err := newFileProvider(FileProviderConfig{
+		ProviderConfig: ProviderConfig{
+			LoggerConfig: LoggerConfig{Diagnostics: &DiagnosticsConfig{Disabled: true}},
+			BufferSize:   4096,
+		},
+		Path: path,
+	}, func(name string, flag int, perm os.FileMode) (logFile, error) {
+		file, err := os.OpenFile(name, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return failingFile{File: file, fail: fail}, nil
+	})
+	if err != nil {
+		t.Fatalf("newFileProvider: %v", err)
+	}
+	defer p.Close(context.Background())
+
+	ctx := context.Background()
+	atomic.StoreInt32(fail, 1)
+	if err := p.Write(ctx, LevelError, "lost", nil); err == nil {
+		t.Fatal("Write to a failing file succeeded")
+	}
+
+	// После переоткрытия файла буфер снова принимает записи
+	atomic.StoreInt32(fail, 0)
+	if err := p.Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	if err := p.Write(ctx, LevelError, "kept", nil); err != nil {
+		t.Fatalf("Write after Reopen: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "kept") {
+		t.Errorf("file = %q, want the entry written after Reopen", data)
+	}
+}
@@ -0,0 +1,205 @@
+package sglogger
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// CanonicalLineMaxFields ограничивает количество полей канонической строки,
+	// включая длительности и счетчики. Поля сверх предела отбрасываются.
+	CanonicalLineMaxFields = 256
+
+	// CanonicalDroppedField содержит количество полей, отброшенных из-за CanonicalLineMaxFields.
+	CanonicalDroppedField = "canonical.dropped_fields"
+
+	// canonicalTimingPrefix и canonicalCountPrefix - префиксы полей длительностей и счетчиков.
+	canonicalTimingPrefix = "timing."
+	canonicalCountPrefix  = "count."
+)
+
+// CanonicalLine накапливает сведения о запросе для одной итоговой записи
+// ("каноническая строка"): поля, суммарные длительности подопераций, счетчики
+// и ошибку. Методы безопасны для одновременного вызова из горутин запроса
+// и для нулевого указателя, поэтому код может вызывать
+// CanonicalLineFromContext(ctx).AddField(...) без проверок.
+// После Finish новые значения не принимаются.
+type CanonicalLine struct {
+	fields   Fields
+	timings  map[string]time.Duration
+	counts   map[string]int64
+	err      error
+	dropped  int
+	finished bool
+	mu       sync.Mutex
+}
+
+// StartCanonicalLine создает накопитель канонической строки и возвращает
+// контекст, в котором он доступен через CanonicalLineFromContext.
+// Если в ctx уже есть накопитель, возвращается он, чтобы вложенные
+// middleware не создавали вторую строку для одного запроса.
+func StartCanonicalLine(ctx context.Context) (context.Context, *CanonicalLine) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if canon := CanonicalLineFromContext(ctx); canon != nil {
+		return ctx, canon
+	}
+	canon := &CanonicalLine{fields: make(Fields)}
+	return context.WithValue(ctx, canonicalLineKey, canon), canon
+}
+
+// CanonicalLineFromContext возвращает накопитель из контекста или nil.
+// Контекст DetachFields накопитель не содержит: фоновая работа,
+// продолжающаяся после ответа, не может дополнить уже записанную строку.
+func CanonicalLineFromContext(ctx context.Context) *CanonicalLine {
+	if ctx == nil {
+		return nil
+	}
+	canon, _ := ctx.Value(canonicalLineKey).(*CanonicalLine)
+	return canon
+}
+
+// AddField задает поле строки; повторный вызов заменяет значение.
+func (c *CanonicalLine) AddField(key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.fields[key]; !ok && !c.reserveLocked() {
+		return
+	}
+	if !c.finished {
+		c.fields[key] = value
+	}
+}
+
+// AddTiming добавляет длительность подоперации name; длительности
+// с одним именем суммируются. В строке записывается поле timing.<name>_ms.
+func (c *CanonicalLine) AddTiming(name string, d time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.timings[name]; !ok {
+		if !c.reserveLocked() {
+			return
+		}
+		if c.timings == nil {
+			c.timings = make(map[string]time.Duration)
+		}
+	}
+	if !c.finished {
+		c.timings[name] += d
+	}
+}
+
+// AddCount увеличивает счетчик name на delta. В строке записывается поле count.<name>.
+func (c *CanonicalLine) AddCount(name string, delta int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.counts[name]; !ok {
+		if !c.reserveLocked() {
+			return
+		}
+		if c.counts == nil {
+			c.counts = make(map[string]int64)
+		}
+	}
+	if !c.finished {
+		c.counts[name] += delta
+	}
+}
+
+// SetError задает ошибку запроса; сохраняется первая ненулевая ошибка.
+func (c *CanonicalLine) SetError(err error) {
+	if c == nil || err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil && !c.finished {
+		c.err = err
+	}
+}
+
+// Finish завершает накопление и возвращает поля строки и ошибку.
+// Повторные вызовы возвращают те же значения.
+func (c *CanonicalLine) Finish() (Fields, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.finished = true
+	fields := make(Fields, len(c.fields)+len(c.timings)+len(c.counts)+1)
+	for k, v := range c.fields {
+		fields[k] = v
+	}
+	for name, d := range c.timings {
+		fields[canonicalTimingPrefix+name+"_ms"] = float64(d) / float64(time.Millisecond)
+	}
+	for name, n := range c.counts {
+		fields[canonicalCountPrefix+name] = n
+	}
+	if c.dropped > 0 {
+		fields[CanonicalDroppedField] = c.dropped
+	}
+	return fields, c.err
+}
+
+// reserveLocked проверяет, можно ли добавить новое поле, и учитывает
+// отброшенные поля. Вызывается с захваченным мьютексом.
+func (c *CanonicalLine) reserveLocked() bool {
+	if c.finished {
+		return false
+	}
+	if len(c.fields)+len(c.timings)+len(c.counts) >= CanonicalLineMaxFields {
+		c.dropped++
+		return false
+	}
+	return true
+}
+
+// NewCanonicalLineMiddleware создает HTTP-middleware, которое для каждого
+// запроса создает накопитель канонической строки (см. StartCanonicalLine)
+// и по завершении обработки записывает одно сообщение "canonical-log-line"
+// с накопленными полями, полями HTTPRequestFields и HTTPResponseFields
+// и ошибкой из SetError. Уровень определяется кодом ответа так же,
+// как в NewAccessLogMiddleware. Поля http.* заменяют одноименные накопленные поля.
+func NewCanonicalLineMiddleware(logger Logger, opts ...HTTPFieldsOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx, canon := StartCanonicalLine(r.Context())
+			lw := &accessLogWriter{ResponseWriter: w}
+			next.ServeHTTP(lw, r.WithContext(ctx))
+
+			status := lw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fields, err := canon.Finish()
+			for k, v := range HTTPRequestFields(r, opts...) {
+				fields[k] = v
+			}
+			for k, v := range HTTPResponseFields(status, lw.size, time.Since(start)) {
+				fields[k] = v
+			}
+
+			logHTTP(ctx, logger, status, err, fields, "canonical-log-line")
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+)
+
+// ChainClose закрывает обертку и ее внутренний провайдер в установленном порядке.
+//
+// Обертки (провайдеры, передающие записи внутреннему провайдеру) закрываются
+// снаружи внутрь:
+//  1. обертка прекращает прием записей - последующие Write возвращают
+//     ErrProviderClosed, повторные Close возвращают nil;
+//  2. drain дописывает во внутренний провайдер уже принятые записи
+//     в пределах ctx (drain может быть nil, если очереди нет);
+//  3. внутренний провайдер закрывается ровно один раз, даже если drain
+//     завершился ошибкой или истек ctx.
+//
+// Шаг 1 выполняет сама обертка перед вызовом ChainClose, шаги 2 и 3 - ChainClose.
+// Ошибки drain и закрытия внутреннего провайдера объединяются через errors.Join.
+// Поскольку после ошибки drain фоновая запись может продолжаться, внутренний
+// провайдер должен корректно обрабатывать Write после Close.
+func ChainClose(ctx context.Context, inner LoggerProvider, drain func(ctx context.Context) error) error {
+	var errs []error
+	if drain != nil {
+		if err := drain(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := inner.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeCountingProvider запоминает записи и считает вызовы Close.
+type closeCountingProvider struct {
+	recordingProvider
+
+	closes  int32
+	failure error
+}
+
+func (p *closeCountingProvider) Close(ctx context.Context) error {
+	atomic.AddInt32(&p.closes, 1)
+	p.recordingProvider.Close(ctx)
+	return p.failure
+}
+
+func TestChainClose(t *testing.T) {
+	errDrain := errors.New("drain failed")
+	errInner := errors.New("inner close failed")
+
+	tests := []struct {
+		name     string
+		drainErr error
+		innerErr error
+		noDrain  bool
+		want     []error
+	}{
+		{name: "success"},
+		{name: "without drain", noDrain: true},
+		{name: "drain error", drainErr: errDrain, want: []error{errDrain}},
+		{name: "inner error", innerErr: errInner, want: []error{errInner}},
+		{name: "both errors", drainErr: errDrain, innerErr: errInner, want: []error{errDrain, errInner}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &closeCountingProvider{failure: tt.innerErr}
+			var drained bool
+			drain := func(ctx context.Context) error {
+				if atomic.LoadInt32(&inner.closes) != 0 {
+					t.Error("inner provider closed before drain")
+				}
+				drained = true
+				return tt.drainErr
+			}
+			if tt.noDrain {
+				drain = nil
+			}
+
+			err := ChainClose(context.Background(), inner, drain)
+			if len(tt.want) == 0 && err != nil {
+				t.Errorf("ChainClose = %v, want nil", err)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("ChainClose = %v, want %v", err, want)
+				}
+			}
+			if drained == tt.noDrain {
+				t.Errorf("drain called = %v, want %v", drained, !tt.noDrain)
+			}
+			if closes := atomic.LoadInt32(&inner.closes); closes != 1 {
+				t.Errorf("inner Close called %d times, want 1", closes)
+			}
+		})
+	}
+}
+
+func TestChainCloseComposedWrappers(t *testing.T) {
+	const (
+		writers = 10
+		perG    = 1000
+	)
+
+	// Большие MaxPending и MaxDelay исключают отметки о разрывах при медленном планировании
+	capture := &closeCountingProvider{}
+	timeout, err := NewTimeoutProvider(capture, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited, err := NewRateLimitProvider(timeout, RateLimitConfig{GlobalRate: 1e9, GlobalBurst: writers * perG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ordered, err := NewOrderedProvider(limited, OrderedConfig{MaxPending: writers * perG, MaxDelay: time.Minute, Diagnostics: &DiagnosticsConfig{Disabled: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(LoggerConfig{Diagnostics: &DiagnosticsConfig{Disabled: true}}, NewFieldsHandler(), ordered)
+
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				logger.Info(context.Background(), "g%d-%d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close = %v", err)
+	}
+	if got := len(capture.Entries()); got != writers*perG {
+		t.Errorf("inner received %d entries, want %d", got, writers*perG)
+	}
+	next := make([]int, writers)
+	for _, e := range capture.Entries() {
+		var g, i int
+		if _, err := fmt.Sscanf(e.Message, "g%d-%d", &g, &i); err != nil || i != next[g] {
+			t.Fatalf("entry %q out of order, want g%d-%d", e.Message, g, next[g])
+		}
+		next[g]++
+	}
+	if closes := atomic.LoadInt32(&capture.closes); closes != 1 {
+		t.Errorf("inner Close called %d times, want 1", closes)
+	}
+
+	// Повторный Close не доходит до внутреннего провайдера
+	ordered.Close(context.Background())
+	if closes := atomic.LoadInt32(&capture.closes); closes != 1 {
+		t.Errorf("inner Close called %d times after second Close, want 1", closes)
+	}
+	if err := ordered.Write(context.Background(), LevelInfo, "late", nil); err != ErrProviderClosed {
+		t.Errorf("Write after Close = %v, want ErrProviderClosed", err)
+	}
+}
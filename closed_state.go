@@ -0,0 +1,27 @@
+package sglogger
+
+import "sync/atomic"
+
+// closedState реализует поведение встроенных провайдеров после Close:
+//   - повторный Close возвращает nil и ничего не делает;
+//   - Write после Close возвращает ErrProviderClosed без паники;
+//   - ShouldLog после Close возвращает false.
+//
+// Встраивается в провайдер. Close вызывает markClosed и завершается,
+// если провайдер уже закрыт; Write и ShouldLog проверяют isClosed.
+// Проверку можно выполнять и под мьютексом провайдера, если Write
+// не должен начаться после закрытия.
+type closedState struct {
+	closed int32
+}
+
+// markClosed отмечает провайдер закрытым. Возвращает false, если провайдер
+// уже был закрыт, то есть Close вызван повторно.
+func (s *closedState) markClosed() bool {
+	return atomic.CompareAndSwapInt32(&s.closed, 0, 1)
+}
+
+// isClosed сообщает, вызван ли Close.
+func (s *closedState) isClosed() bool {
+	return atomic.LoadInt32(&s.closed) != 0
+}
@@ -1,13 +1,725 @@
 package sglogger
 
+import (
+	"io"
+	"os"
+	"time"
+)
+
 // LoggerConfig defines base configuration for all loggers and providers.
 // Contains common settings that apply to all logging components.
 type LoggerConfig struct {
+	Sampling    *SamplingConfig // Optional sampling of repeated messages, nil disables it
+	Fingerprint bool            // Attach a message template fingerprint for error grouping
+	// GoroutineInfo attaches the goroutine id and pprof labels of the calling
+	// goroutine. Off by default: it costs a runtime.Stack call per written entry.
+	GoroutineInfo bool
+	// EnrichRuntime attaches build and runtime metadata, collected once at
+	// logger construction, to every entry. Nil disables it.
+	EnrichRuntime *RuntimeEnrichment
+	// DedupKey attaches a dedup_key field (see DedupKey) so downstream
+	// consumers can process redelivered entries idempotently. Nil disables it.
+	DedupKey *DedupKeyConfig
+	// Enrichers selects, by name, enrichers registered with RegisterEnricher
+	// (usually from the init function of their package); they run in this
+	// order for every entry. NewLoggerWithOptions fails on an unknown name,
+	// NewLogger reports it through Diagnostics and skips it.
+	Enrichers []string
+	// Hooks run in order for every entry after its fields are assembled and
+	// before it reaches providers; they may rewrite the message and replace
+	// the fields (see Hook and PathSanitizer). Sampling and Fingerprint use
+	// the format template, which hooks do not change, so rewriting a message
+	// never splits its group; DedupKey, SanitizeUTF8 and NormalizeUnicode
+	// see the rewritten message and fields.
+	Hooks []Hook
+	// FieldRules adds fields to entries matching declarative rules (see
+	// NewFieldRules); the rules can be replaced while the logger runs.
+	// Nil disables them.
+	FieldRules *FieldRules
+	// Redaction replaces the values of sensitive fields, or in dry-run mode
+	// only reports them in a would_redact field (see NewRedaction); the mode
+	// can be switched while the logger runs. Nil disables it.
+	Redaction *Redaction
+	// TraceEscalation lowers the minimum level to LevelDebug for entries whose
+	// context carries the trace_id of a trace that already logged an entry at
+	// LevelError or above (see TraceEscalationConfig). Nil disables it.
+	TraceEscalation *TraceEscalationConfig
+	// ConvertValue, when set, is called for every field value before the
+	// entry reaches providers; when it returns true the value is replaced.
+	// It lets callers log values such as protobuf messages directly, see
+	// the sgproto module.
+	ConvertValue func(key string, value interface{}) (interface{}, bool)
+	// MaxPayloadSize caps json.RawMessage field values. JSON output embeds
+	// them as-is and text output quotes them; a longer value is replaced by
+	// its prefix ending in "...(truncated)" and its original size is written
+	// to the field "<key>.bytes". Zero means 64 KiB, a negative value
+	// disables the cap.
+	MaxPayloadSize int
+	// SanitizeUTF8 replaces every invalid UTF-8 byte in the message, field
+	// keys and string field values with U+FFFD before the entry reaches
+	// providers, so text, JSON and remote outputs render it identically.
+	// Sanitized entries are counted in LoggerStats.Sanitized.
+	SanitizeUTF8 bool
+	// NormalizeUnicode, when set, is applied to the message, field keys and
+	// string field values after SanitizeUTF8, for example norm.NFC.String
+	// from golang.org/x/text/unicode/norm. It is called for every string of
+	// every written entry.
+	NormalizeUnicode func(string) string
+	// EmptyProviders selects what happens when the logger is constructed
+	// without providers, see EmptyProvidersPolicy.
+	EmptyProviders EmptyProvidersPolicy
+	// StderrFallback writes entries at LevelWarn and above to stderr, rate
+	// limited, when every provider that accepted them failed to write them.
+	StderrFallback bool
+	// ContextDiagnostics attaches ctx_deadline, ctx_remaining and ctx_err
+	// to entries whose error is context.DeadlineExceeded or context.Canceled.
+	ContextDiagnostics bool
+	// StrictFormat detects fmt error markers ("%!s(MISSING)", "%!(EXTRA ...)"
+	// and the like) in formatted messages: the entry is still written, with
+	// format_error=true, and a warning with the call site goes to Diagnostics.
+	// An argument whose own text contains "%!" is reported too.
+	StrictFormat bool
+	// PanicOnFormatError panics after writing an entry that StrictFormat
+	// flagged. Intended for tests.
+	PanicOnFormatError bool
+	// StrictFields warns through Diagnostics, once per key, when an entry contains
+	// a field that was not declared with RegisterField.
+	StrictFields bool
+	// FieldValidation reports structured logging mistakes through Diagnostics,
+	// with the call site, once per rule and call site; entries are written
+	// unchanged. Nil disables it.
+	FieldValidation *FieldValidationConfig
+	// SlowWriteBudget reports, through Diagnostics, entries whose formatting and
+	// provider writes took longer than the budget, and counts them in Stats
+	// against the slowest provider. Zero disables the measurement.
+	SlowWriteBudget time.Duration
+	// LeakCheck reports, through Diagnostics, loggers and providers holding
+	// files, sockets or background goroutines that are garbage collected
+	// without Close, with the stack of their construction. Costs one stack
+	// capture per construction.
+	LeakCheck bool
+	// MaxProviderPanics disables a provider after this many consecutive
+	// panics in its methods; panics are always recovered and reported as
+	// errors. Zero means 3, a negative value never disables providers.
+	MaxProviderPanics int
+	// Diagnostics configures the package's own operational messages (dropped
+	// batches, retries, spool evictions). Nil writes them to stderr,
+	// rate limited; they never pass through a Logger or its providers.
+	Diagnostics *DiagnosticsConfig
+	// ErrorHandler is called with the provider name and the error whenever
+	// a provider fails to write an entry. It must not log through the same logger.
+	ErrorHandler func(provider string, err error)
 }
 
 // ProviderConfig extends LoggerConfig with provider-specific settings.
 // Embeds common configuration and adds provider-specific parameters.
+// When BufferSize is set, output is flushed every FlushInterval, on Close
+// and right after every entry at LevelError or above.
+// HonorContextLevel enables per-request debugging via ContextWithMinLevel.
 type ProviderConfig struct {
-	LoggerConfig        // Embedded base logger configuration
-	Level       Level   // Provider-specific log level
-}
\ No newline at end of file
+	LoggerConfig                      // Embedded base logger configuration
+	Name              string          // Provider name, defaults to the provider type
+	Level             Level           // Provider-specific log level
+	HonorContextLevel bool            // Use the ContextWithMinLevel level instead of Level when present
+	BufferSize        int             // Output buffer size in bytes, 0 disables buffering
+	FlushInterval     time.Duration   // Buffer flush period, defaults to one second
+	PauseBufferSize   int             // Bytes held while console output is paused (see Pauser), defaults to 1 MiB
+	LevelFormat       LevelFormat     // Level label rendering for text output
+	Align             AlignConfig     // Column-aligned text output
+	Hyperlinks        HyperlinkConfig // Clickable caller locations in text output
+	FloatFormat       FloatFormat     // Float field rendering
+	Durations         DurationPolicy  // time.Duration field rendering in text and JSON output
+	JSON              JSONFormat      // JSON output options, see NewJSONFormatter
+	Formatter         Formatter       // Line rendering, defaults to the text format built from the settings above
+	// EnabledWhen reports whether the provider is active; it is evaluated for
+	// every entry, so the result may change at runtime. Nil means always active.
+	EnabledWhen func() bool
+}
+
+// FloatFormat defines how float field values are rendered.
+// The zero value keeps the shortest representation that round-trips.
+type FloatFormat struct {
+	Precision         int  // Digits after the decimal point; 0 keeps the shortest representation
+	NonFiniteAsString bool // Encode NaN and ±Inf as strings in JSON instead of reporting an encode error
+}
+
+// JSONFormat defines options of the JSON format.
+type JSONFormat struct {
+	// Deterministic renders identical entries as identical lines: keys sorted
+	// at every level, no HTML escaping, UTC timestamps with fixed precision.
+	// Intended for golden files; slower than the default.
+	Deterministic bool
+}
+
+// AlignConfig defines column-aligned text output for terminals.
+// Level labels are padded to the widest label unless LevelFormat.Width
+// is set. Widths are measured in terminal columns: wide characters count
+// as two and ANSI color sequences are not counted.
+type AlignConfig struct {
+	Enabled      bool   // Enables column alignment
+	NameField    string // Field rendered as the name column, defaults to "component"
+	NameWidth    int    // Name column width, longer names are truncated; 0 omits the column
+	FieldsColumn int    // Column where fields start; lines with longer messages just overflow
+}
+
+// HyperlinkConfig renders the caller location field ("file.go:123") of text
+// output as an OSC 8 terminal hyperlink. URLTemplate placeholders:
+// {file} - the file as recorded, {abs} - the file made absolute against the
+// working directory, {line} - the line, {rev} - vcs.revision from build info.
+// Examples: "vscode://file/{abs}:{line}",
+// "https://github.com/org/repo/blob/{rev}/{file}#L{line}".
+// NewFmtProvider renders links only when stdout is a terminal; other
+// formats ignore the setting.
+type HyperlinkConfig struct {
+	Enabled     bool   // Enables hyperlinks
+	Field       string // Field holding the location, defaults to "caller"
+	URLTemplate string // Link target, defaults to "file://{abs}"
+}
+
+// LevelFormat defines how text output renders level labels.
+// When Labels is set it must define a label for every level
+// from LevelDebug to LevelFatal.
+type LevelFormat struct {
+	Labels map[Level]string // Custom labels, e.g. {LevelWarn: "WARN"}; defaults to Level.String
+	Case   LabelCase        // Letter case applied to labels
+	Width  int              // Pads labels with spaces to a fixed width for column alignment
+}
+
+// DurationPolicy defines how time.Duration field values are rendered.
+// Numeric policies rename the key with a unit suffix ("took" becomes
+// "took_ms") unless it already has one; a field already present under
+// the suffixed key is kept and the duration is dropped. Nested values
+// are not converted.
+type DurationPolicy int
+
+const (
+	DurationAsIs    DurationPolicy = iota // Text renders "1.5s", JSON renders integer nanoseconds
+	DurationString                        // "1.5s" in every format
+	DurationMillis                        // Float milliseconds under "<key>_ms"
+	DurationSeconds                       // Float seconds under "<key>_s"
+)
+
+// LabelCase defines the letter case of rendered level labels.
+type LabelCase int
+
+const (
+	LabelCaseAsIs  LabelCase = iota // Labels are rendered as defined
+	LabelCaseUpper                  // Labels are upper-cased
+	LabelCaseLower                  // Labels are lower-cased
+)
+
+// FieldRule adds fields to entries whose level lies in [MinLevel, MaxLevel]
+// and whose fields equal every value in FieldEquals. Values are compared as
+// strings, non-string field values in their %v form. A zero MaxLevel means
+// no upper bound.
+type FieldRule struct {
+	MinLevel    Level             // Lowest matching level
+	MaxLevel    Level             // Highest matching level, zero means LevelFatal
+	FieldEquals map[string]string // Required field values, exact match
+	Add         Fields            // Fields added when the entry does not have them
+	Set         Fields            // Fields added or replaced
+}
+
+// RedactionRule names a group of field keys whose values Redaction replaces.
+// Keys are matched exactly against top-level field names.
+type RedactionRule struct {
+	Name string   // Rule name in Redaction.Counts, defaults to the keys joined with ","
+	Keys []string // Field keys, e.g. "password", "authorization"
+}
+
+// RedactionMode selects what Redaction does with matching fields.
+type RedactionMode int32
+
+const (
+	// RedactEnforce replaces matching values with "[REDACTED]".
+	RedactEnforce RedactionMode = iota
+	// RedactDryRun leaves values intact and lists the matching keys in the
+	// would_redact field, so the effect of the rules can be reviewed first.
+	RedactDryRun
+)
+
+// DedupKeyConfig defines which fields, in addition to the level and the
+// normalized message, identify an entry for deduplication.
+type DedupKeyConfig struct {
+	Fields []string // Field keys included in the key, e.g. "request_id"
+}
+
+// SamplingConfig defines zap-style sampling: within every Tick the first
+// First entries of each distinct message template are logged, then only
+// every Thereafter-th one. Levels without a rule are never sampled.
+type SamplingConfig struct {
+	Tick    time.Duration          // Counting window, defaults to one second
+	MaxKeys int                    // Max distinct templates tracked per window, defaults to 1024
+	Levels  map[Level]SamplingRule // Per-level sampling rules
+}
+
+// SamplingRule defines how many entries are kept within a sampling window.
+type SamplingRule struct {
+	First      int // Entries logged unconditionally per template and window
+	Thereafter int // After First, log every Thereafter-th entry; 0 drops the rest
+}
+
+// EmptyProvidersPolicy defines the behavior of a logger constructed
+// without providers.
+type EmptyProvidersPolicy int
+
+const (
+	// EmptyProvidersDefault is EmptyProvidersError for NewLoggerWithOptions
+	// and EmptyProvidersAllow for NewLogger, which keeps its historical behavior.
+	EmptyProvidersDefault EmptyProvidersPolicy = iota
+	// EmptyProvidersError makes NewLoggerWithOptions return ErrNoProviders.
+	// NewLogger cannot return an error; it reports the problem through
+	// Diagnostics and discards entries.
+	EmptyProvidersError
+	// EmptyProvidersStderr writes entries to stderr in the text format and
+	// reports, once, through Diagnostics when the first entry is written.
+	EmptyProvidersStderr
+	// EmptyProvidersAllow silently discards entries, e.g. in tests.
+	EmptyProvidersAllow
+)
+
+// FieldValidationConfig selects the rules checked by LoggerConfig.FieldValidation.
+type FieldValidationConfig struct {
+	ErrorWithoutError bool // Entries at LevelError and above without an error field
+	ErrField          bool // An "err" field, which should be named "error"
+	PointerValues     bool // Pointer field values that text output renders as an address
+}
+
+// TraceEscalationConfig configures per-trace level escalation. After the
+// first entry at LevelError or above with a trace_id in its context, later
+// calls with the same trace_id are logged as if the context carried
+// ContextWithMinLevel(ctx, LevelDebug): the logger level and Silence are
+// bypassed, and providers with HonorContextLevel accept Debug entries.
+// Escalation lasts TTL from the first error and is not extended by later ones.
+type TraceEscalationConfig struct {
+	TTL       time.Duration // How long a trace stays escalated, defaults to one minute
+	MaxTraces int           // Max escalated traces; the oldest is dropped when full, defaults to 1024
+}
+
+// RuntimeEnrichment selects the build and runtime metadata attached to
+// every entry. Keys renames the default field names (for example
+// {"go_version": "runtime.go"}) for backends with strict field schemas.
+type RuntimeEnrichment struct {
+	GoVersion  bool              // go_version from runtime.Version
+	VCS        bool              // vcs.revision and vcs.time from build info
+	Hostname   bool              // hostname from os.Hostname
+	Kubernetes bool              // k8s.pod and k8s.namespace from POD_NAME/POD_NAMESPACE
+	Keys       map[string]string // Field renames, default name to custom name
+}
+
+// HTTPClientConfig defines transport settings shared by HTTP-based providers.
+// Use NewHTTPClient to build an *http.Client from it; invalid TLS files or
+// proxy URLs are reported at construction time.
+type HTTPClientConfig struct {
+	Timeout      time.Duration // Request timeout, defaults to 10 seconds
+	ProxyURL     string        // HTTP proxy URL, empty uses the environment settings
+	MaxIdleConns int           // Max idle connections, defaults to 100
+	TLS          TLSConfig     // TLS client settings
+	Auth         HTTPAuth      // Authentication added to every request
+}
+
+// HTTPBatchConfig defines batching and retries shared by HTTP-based providers.
+// Entries are queued by Write and sent in the background; send failures that
+// remain after retries are reported to ErrorHandler and the batch is dropped.
+type HTTPBatchConfig struct {
+	BatchSize     int           // Entries per request, defaults to 100
+	FlushInterval time.Duration // Max delay before a partial batch is sent, defaults to 1 second
+	MaxPending    int           // Queued entries before Write fails with ErrQueueFull, defaults to 10000
+	MaxRetries    int           // Retries on network errors, 429 and 5xx, defaults to 3; negative disables
+	// TenantHeaderFromField promotes the value of this field into the
+	// TenantHeader request header; batches are split per value. Entries
+	// without the field use the provider's static settings.
+	TenantHeaderFromField string
+	TenantHeader          string // Tenant header name; the provider documents its default
+	// SkewAdjust replaces timestamps older than this at send time with the
+	// send time and keeps the original in the "original_ts" field, so
+	// backends that reject old entries still accept replayed ones.
+	// Zero disables the adjustment.
+	SkewAdjust time.Duration
+	// Compression compresses request bodies and sets Content-Encoding.
+	// Defaults to HTTPCompressionNone.
+	Compression HTTPCompression
+	// CompressionLevel is the gzip level from 1 (fastest) to 9 (smallest);
+	// zero means gzip.DefaultCompression.
+	CompressionLevel int
+	// MaxInFlight is the number of requests a flush may have in flight at
+	// once, defaults to 1. Batches that share a stream (for Loki, the
+	// tenant and the level label) are never in flight together, so entries
+	// of a stream arrive in order; other providers send independent events
+	// whose batches may arrive in any order.
+	MaxInFlight int
+}
+
+// HTTPCompression selects request body compression for HTTP batch providers.
+type HTTPCompression int
+
+const (
+	// HTTPCompressionNone sends request bodies as is.
+	HTTPCompressionNone HTTPCompression = iota
+	// HTTPCompressionGzip gzips request bodies (Content-Encoding: gzip).
+	HTTPCompressionGzip
+)
+
+// TLSConfig defines TLS client settings for HTTP-based providers.
+type TLSConfig struct {
+	CAFile             string // PEM file with custom root CAs
+	CertFile           string // PEM client certificate for mutual TLS
+	KeyFile            string // PEM client key for mutual TLS
+	ServerName         string // Overrides the server name used for verification
+	InsecureSkipVerify bool   // Disables server certificate verification
+}
+
+// HTTPAuth defines authentication for HTTP-based providers.
+// BearerToken takes precedence over basic auth credentials.
+type HTTPAuth struct {
+	BearerToken string // Sent as "Authorization: Bearer <token>"
+	Username    string // Basic auth user name
+	Password    string // Basic auth password
+}
+
+// HoneycombConfig defines the Honeycomb events provider. Every entry becomes
+// an event with message, level and all fields as columns.
+type HoneycombConfig struct {
+	ProviderConfig                  // Level, name and float formatting options
+	APIKey         string           // Honeycomb API key, sent as X-Honeycomb-Team
+	Dataset        string           // Target dataset
+	APIHost        string           // API endpoint, defaults to https://api.honeycomb.io
+	HTTP           HTTPClientConfig // HTTP transport settings
+	Batch          HTTPBatchConfig  // Batching and retries
+}
+
+// VictoriaLogsConfig defines the VictoriaLogs JSON lines provider.
+// StreamFields are attached to every entry and declared as stream fields,
+// so they should identify the log source (service, host) and rarely change.
+type VictoriaLogsConfig struct {
+	ProviderConfig                   // Level, name and float formatting options
+	Endpoint       string            // Base URL, e.g. http://victorialogs:9428
+	StreamFields   map[string]string // Stream-level fields, e.g. service and host
+	AccountID      string            // Optional tenant AccountID header
+	ProjectID      string            // Optional tenant ProjectID header
+	Gzip           bool              // Compress request bodies; same as Batch.Compression set to HTTPCompressionGzip
+	HTTP           HTTPClientConfig  // HTTP transport settings
+	Batch          HTTPBatchConfig   // Batching and retries
+}
+
+// LokiConfig defines the Grafana Loki push provider. Labels identify the
+// stream and must stay low-cardinality (service, environment, host); entry
+// fields go into the JSON log line instead. Batch.BatchSize and
+// Batch.FlushInterval control when a batch is pushed; they are the batch
+// size and batch wait of other Loki clients (promtail's batchsize and
+// batchwait), shared with the other HTTP batch providers.
+type LokiConfig struct {
+	ProviderConfig                   // Level, name and float formatting options
+	URL            string            // Loki base URL, e.g. http://loki:3100, or the full push URL
+	Labels         map[string]string // Static stream labels, e.g. service and env
+	// DisableLevelLabel keeps the entry level only in the log line. By
+	// default it is also the "level" stream label, which splits every
+	// source into at most five streams.
+	DisableLevelLabel bool
+	TenantID          string           // Optional X-Scope-OrgID header for multi-tenant Loki
+	HTTP              HTTPClientConfig // HTTP transport settings
+	Batch             HTTPBatchConfig  // Batching and retries
+}
+
+// SentryConfig defines the Sentry provider (see NewSentryProvider and the
+// With* Sentry options). Level defaults to LevelError, so lower entries never
+// reach Sentry whatever the logger level is.
+type SentryConfig struct {
+	ProviderConfig                  // Level, name and float formatting options
+	Environment    string           // Sentry environment, e.g. production
+	Release        string           // Application release, e.g. a version or commit
+	ServerName     string           // Host name, defaults to os.Hostname
+	TagFields      []string         // Fields sent as event tags; all other fields go to extra
+	FlushTimeout   time.Duration    // Bound for Close and for the flush before Fatal exits, defaults to 2 seconds
+	HTTP           HTTPClientConfig // HTTP transport settings
+	Batch          HTTPBatchConfig  // Queueing and retries; BatchSize defaults to 1, one event per request
+}
+
+// DiagnosticsConfig defines where the package's operational messages go.
+type DiagnosticsConfig struct {
+	Disabled bool      // Drop all diagnostic messages
+	Output   io.Writer // Destination, defaults to os.Stderr; must not write back into a Logger
+	Rate     int       // Lines per second, defaults to 10; excess lines are counted and reported later
+}
+
+// TenantConfig configures tenant extraction (see WithTenantExtraction).
+type TenantConfig struct {
+	ContextKey interface{} // Context key holding the tenant, defaults to TenantKey
+	Field      string      // Field the tenant is written to, defaults to "tenant"
+}
+
+// TenantRouterConfig configures a provider that routes entries by tenant
+// (see NewTenantRouter).
+type TenantRouterConfig struct {
+	Name    string                    // Provider name, defaults to "tenant-router"
+	Field   string                    // Field holding the tenant, defaults to "tenant"
+	Routes  map[string]LoggerProvider // Providers by tenant
+	Default LoggerProvider            // Provider for unknown tenants and entries without one; nil drops them
+}
+
+// SpoolConfig defines the on-disk spool used by NewSpoolProvider.
+type SpoolConfig struct {
+	Name          string             // Provider name, defaults to the inner provider name
+	Dir           string             // Spool directory, created if missing
+	MaxBytes      int64              // Hard cap for all segments, oldest evicted first; defaults to 100 MiB
+	SegmentBytes  int64              // Segment size limit before a new one is started; defaults to 8 MiB
+	RetryInterval time.Duration      // Delay between replay attempts; defaults to 5 seconds
+	Diagnostics   *DiagnosticsConfig // Reports of evicted segments and failed replays, see LoggerConfig.Diagnostics
+}
+
+// FailoverConfig defines the providers of NewFailoverProvider and how the
+// primary is probed after a switch to the fallback.
+type FailoverConfig struct {
+	Name             string             // Provider name, defaults to "failover"
+	Primary          LoggerProvider     // Preferred provider, required
+	Fallback         LoggerProvider     // Provider written while the primary fails, required
+	ProbeInterval    time.Duration      // Delay before the first probe, doubled after every failed one; defaults to 1 second
+	MaxProbeInterval time.Duration      // Upper bound of the probe delay; defaults to 1 minute
+	Diagnostics      *DiagnosticsConfig // Reports of switches and failed probes, see LoggerConfig.Diagnostics
+	// ProbeEntry is written to the primary to probe it. Nil probes with
+	// HealthCheck when the primary implements HealthChecker, and otherwise
+	// writes a "failover probe" entry with failover_probe=true at the lowest
+	// level the primary accepts.
+	ProbeEntry *Entry
+}
+
+// SegmentConfig defines the NDJSON segment files written by NewSegmentProvider.
+// OnSegmentComplete is called with the path of every segment that will no
+// longer be written to: after a roll, on Close, and at startup for the newest
+// segment left by a previous run (reported again if that run closed cleanly,
+// so uploads must tolerate duplicates). It runs on the writing goroutine.
+type SegmentConfig struct {
+	ProviderConfig                      // Level, name and JSON formatting options
+	Dir               string            // Segment directory, created if missing
+	MaxBytes          int64             // Segment size limit before rolling; defaults to 8 MiB
+	MaxSegments       int               // Segments kept on disk, oldest deleted first; defaults to 16
+	OnSegmentComplete func(path string) // Optional notification for uploaders
+	// HashChain makes the segments tamper-evident. Every line gets a "seq"
+	// field and a "chain" field holding SHA-256 of the previous chain value
+	// and the line itself; the first line of a segment also carries the
+	// previous value in "chain_prev". The last value is kept in Dir/chain.head,
+	// so the chain continues across restarts. Check files with VerifyLogChain.
+	HashChain bool
+}
+
+// RotationInterval selects when NewFileProvider switches to a new file.
+type RotationInterval int
+
+const (
+	// RotateBySize renames the file to Path.1 when it would grow past
+	// MaxSize; older backups shift to Path.2 ... Path.MaxBackups.
+	RotateBySize RotationInterval = iota
+	// RotateDaily writes to a file named after the local calendar day, for
+	// example "app-2024-05-01.log" for Path "app.log", and switches to the
+	// next one at local midnight.
+	RotateDaily
+	// RotateHourly writes to a file named after the local hour, for example
+	// "app-2024-05-01-15.log", and switches to the next one every hour.
+	RotateHourly
+)
+
+// FileProviderConfig defines the log file written by NewFileProvider and
+// its rotation (see RotationInterval).
+type FileProviderConfig struct {
+	ProviderConfig                  // Level, name, buffering and formatting options; text format by default
+	Path           string           // Log file path; parent directories are created if missing
+	Rotation       RotationInterval // Size or calendar rotation, defaults to RotateBySize
+	MaxSize        int64            // Size in bytes that triggers RotateBySize; defaults to 100 MiB, negative disables rotation
+	MaxBackups     int              // Rotated files kept, oldest deleted first; defaults to 5, negative keeps none
+	MaxAgeDays     int              // Rotated files modified more than this many days ago are deleted; zero keeps them
+	Compress       bool             // Gzip rotated files in the background, adding the ".gz" suffix
+	FileMode       os.FileMode      // Mode of created log files; defaults to 0644
+	DirMode        os.FileMode      // Mode of created parent directories; defaults to 0755
+	// SyncEveryWrite fsyncs the file after every entry, so written entries
+	// survive a power loss. It is slow; SyncInterval bounds the loss instead.
+	SyncEveryWrite bool
+	// SyncInterval fsyncs the file from a background goroutine at this
+	// period. With either option the file is also synced on rotation and
+	// Close. Zero disables periodic syncing.
+	SyncInterval time.Duration
+	// WriteTimeout bounds a single write, for example to a hung network
+	// mount: Write then returns ErrTimeout and the write completes in the
+	// background. Defaults to 5 seconds, a negative value disables it.
+	WriteTimeout time.Duration
+}
+
+// ConsoleFileConfig defines a provider that writes entries below LevelError
+// to InfoWriter, entries at LevelError and above to ErrorWriter, and every
+// entry to a log file as well (see NewConsoleFileProvider). The rotation
+// settings have the meaning and defaults of FileProviderConfig.
+type ConsoleFileConfig struct {
+	ProviderConfig                  // Level, name, buffering and text formatting options for every output
+	InfoWriter     io.Writer        // Destination of entries below LevelError, defaults to os.Stdout
+	ErrorWriter    io.Writer        // Destination of entries at LevelError and above, defaults to os.Stderr
+	FilePath       string           // Log file receiving every entry; empty disables the file
+	Rotation       RotationInterval // Size or calendar rotation, defaults to RotateBySize
+	MaxSize        int64            // Size in bytes that triggers RotateBySize; defaults to 100 MiB
+	MaxBackups     int              // Rotated files kept; defaults to 5
+	MaxAgeDays     int              // Rotated files older than this many days are deleted; zero keeps them
+	Compress       bool             // Gzip rotated files in the background
+}
+
+// RingBufferConfig defines an in-memory provider that keeps the most recent
+// entries for live inspection (see NewRingBufferProvider and TailHandler).
+type RingBufferConfig struct {
+	ProviderConfig     // Level and name
+	Size           int // Entries kept, oldest overwritten first; defaults to 1000
+}
+
+// FilterConfig defines message filtering for NewFilterProvider. Patterns
+// are matched against the formatted message. An entry matching any allow
+// pattern is always written; otherwise an entry matching any deny pattern
+// is dropped. Globs use * and ? and must match the whole message.
+type FilterConfig struct {
+	Name          string   // Provider name, defaults to the inner provider name
+	AllowMessages []string // Regular expressions, evaluated first
+	DenyMessages  []string // Regular expressions
+	DenyGlobs     []string // Globs such as "connection pool stats: *"
+}
+
+// RateLimitConfig defines token-bucket rate limiting for NewRateLimitProvider.
+// At least one of Rate and GlobalRate must be set.
+type RateLimitConfig struct {
+	Name string // Provider name, defaults to the inner provider name
+	// KeyFunc selects the bucket of an entry, for example the tenant_id
+	// field. Nil puts every entry under the empty key.
+	KeyFunc     func(level Level, message string, fields Fields) string
+	Rate        float64 // Entries per second per key, 0 disables the per-key limit
+	Burst       int     // Per-key bucket size, defaults to one second of Rate and at least 1
+	GlobalRate  float64 // Entries per second across all keys, 0 disables the ceiling
+	GlobalBurst int     // Global bucket size, defaults to one second of GlobalRate and at least 1
+	MaxKeys     int     // Keys tracked at once, least recently used evicted first; defaults to 10000
+}
+
+// OrderedConfig defines the ordered mode of NewOrderedProvider. Larger bounds
+// tolerate more reordering at the cost of memory and delivery latency.
+type OrderedConfig struct {
+	Name        string             // Provider name, defaults to the inner provider name
+	MaxPending  int                // Entries held while waiting for earlier ones, defaults to 1024
+	MaxDelay    time.Duration      // Wait for a missing entry before releasing with a gap marker, defaults to 100ms
+	Diagnostics *DiagnosticsConfig // Reports of failed delayed writes, see LoggerConfig.Diagnostics
+}
+
+// ErrorBudgetConfig defines the error counting of NewErrorBudgetProvider.
+type ErrorBudgetConfig struct {
+	Name          string // Provider name, defaults to "error_budget"
+	Field         string // Field identifying the component, defaults to "component"
+	MaxComponents int    // Components counted separately, the rest fall into "other"; defaults to 100
+}
+
+// OversizePolicy selects what a datagram provider does with an entry whose
+// encoded form exceeds the datagram size limit (see OversizeConfig).
+type OversizePolicy int
+
+const (
+	// OversizeTruncate removes fields in priority order, then shortens the
+	// message, until the entry fits; removed keys are listed in the
+	// "dropped_fields" field.
+	OversizeTruncate OversizePolicy = iota
+	// OversizeSplit sends the entry in several datagrams following the
+	// protocol's chunking rules; providers whose protocol has none truncate.
+	OversizeSplit
+	// OversizeReject returns ErrEntryTooLarge to the error handler.
+	OversizeReject
+)
+
+// OversizeConfig defines how datagram providers handle entries larger than
+// a datagram. The zero value truncates with the default priorities.
+type OversizeConfig struct {
+	Policy  OversizePolicy // What to do with oversized entries, defaults to OversizeTruncate
+	MaxSize int            // Datagram size limit in bytes, defaults to the provider's protocol limit
+	// DropFirst lists fields removed first when truncating, in order;
+	// remaining fields are removed afterwards, largest value first.
+	// Defaults to "stack" and "stacktrace".
+	DropFirst []string
+	// Keep lists fields never removed when truncating. Defaults to "error"
+	// and "trace_id".
+	Keep []string
+}
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat int
+
+const (
+	// SyslogRFC5424 writes "<PRI>1 TIMESTAMP HOST APP PID - [SD] MSG" with
+	// fields as RFC 5424 structured data.
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 writes the BSD format "<PRI>Mmm dd hh:mm:ss HOST APP[PID]: MSG"
+	// with fields appended to the message as {key=value ...}.
+	SyslogRFC3164
+)
+
+// SyslogFacility is the syslog facility of written messages.
+type SyslogFacility int
+
+// Syslog facilities; the zero value means SyslogUser.
+const (
+	SyslogUser   SyslogFacility = 1
+	SyslogDaemon SyslogFacility = 3
+	SyslogAuth   SyslogFacility = 4
+	SyslogLocal0 SyslogFacility = 16
+	SyslogLocal1 SyslogFacility = 17
+	SyslogLocal2 SyslogFacility = 18
+	SyslogLocal3 SyslogFacility = 19
+	SyslogLocal4 SyslogFacility = 20
+	SyslogLocal5 SyslogFacility = 21
+	SyslogLocal6 SyslogFacility = 22
+	SyslogLocal7 SyslogFacility = 23
+)
+
+// SyslogConfig defines the syslog provider (see NewSyslogProvider).
+// With an empty Network the provider writes to the local syslog daemon
+// socket (/dev/log, /var/run/syslog or /var/run/log).
+type SyslogConfig struct {
+	ProviderConfig                // Level and name
+	Network        string         // "udp", "tcp", "unix" or "unixgram"; empty for the local daemon
+	Address        string         // Remote "host:port" or socket path
+	Format         SyslogFormat   // Message format, defaults to SyslogRFC5424
+	Facility       SyslogFacility // Defaults to SyslogUser
+	AppName        string         // APP-NAME / TAG, defaults to the executable name
+	Hostname       string         // HOSTNAME, defaults to os.Hostname
+	DialTimeout    time.Duration  // Connection timeout, defaults to 5 seconds
+	WriteTimeout   time.Duration  // Write deadline per message, defaults to 5 seconds
+	// Severities maps levels to syslog severities (0 EMERG .. 7 DEBUG).
+	// Levels missing from the map use DefaultSyslogSeverities; values
+	// outside 0..7 and unknown levels fail construction.
+	Severities map[Level]int
+	// Oversize handles messages larger than a datagram over udp and
+	// unixgram; the limit defaults to 2048 bytes for udp (RFC 5424
+	// recommends receivers accept it) and 8 KiB for the local socket.
+	// Syslog has no chunking, so OversizeSplit truncates.
+	Oversize OversizeConfig
+}
+
+// DualFormatConfig defines a provider that writes every entry in two formats
+// to two destinations while log consumers migrate between formats
+// (see NewDualFormatProvider). The caller owns both writers.
+type DualFormatConfig struct {
+	ProviderConfig           // Level, name and text formatting options for the legacy side
+	Legacy         io.Writer // Destination of the legacy format, required
+	LegacyFormat   Formatter // Legacy format, defaults to the text format built from ProviderConfig
+	Primary        io.Writer // Destination of the new format, required
+	PrimaryFormat  Formatter // New format, defaults to NewJSONFormatter
+	// LegacyEnabled reports whether the legacy side is still written. It is
+	// evaluated for every entry, so the cutover needs no new logger.
+	// Nil means always.
+	LegacyEnabled func() bool
+}
+
+// EncryptionConfig defines the key used by NewEncryptingProvider.
+// Entries are sealed with AES-GCM; KeyID is written in clear text in front
+// of every sealed entry so archives can be decrypted after key rotation.
+type EncryptionConfig struct {
+	Name  string // Provider name, defaults to the inner provider name
+	KeyID string // Key identifier, must not contain ':'
+	Key   []byte // AES key, 16, 24 or 32 bytes
+}
+
+// DebugHeaderConfig defines how NewDebugHeaderMiddleware recognizes requests
+// that should be logged at Debug level. A header value is accepted when it
+// matches one of Tokens or is a valid token signed with Secret (see SignDebugToken).
+type DebugHeaderConfig struct {
+	Header string   // Request header name, defaults to "X-Debug"
+	Tokens []string // Allowlisted header values
+	Secret []byte   // HMAC-SHA256 key for signed, expiring tokens
+}
@@ -0,0 +1,149 @@
+package sglogger
+
+import "fmt"
+
+// Конструкторы копируют отображения и срезы конфигурации, поэтому изменение
+// конфигурации после создания логгера или провайдера на них не влияет.
+// Не копируются значения, которые по смыслу разделяются с вызывающим:
+// FieldRules и Redaction (изменяются во время работы), писатели (io.Writer), форматтеры,
+// провайдеры и функции.
+
+// Validate проверяет конфигурацию логгера. Вызывается NewLoggerWithOptions,
+// который возвращает ее ошибку; NewLogger сообщает об ошибке в Diagnostics.
+func (c LoggerConfig) Validate() error {
+	if c.Sampling != nil {
+		if c.Sampling.Tick < 0 || c.Sampling.MaxKeys < 0 {
+			return fmt.Errorf("sglogger: invalid sampling window %s or key limit %d", c.Sampling.Tick, c.Sampling.MaxKeys)
+		}
+		for level, rule := range c.Sampling.Levels {
+			if !level.IsValid() {
+				return fmt.Errorf("sglogger: sampling rule for invalid level %s", level)
+			}
+			if rule.First < 0 || rule.Thereafter < 0 {
+				return fmt.Errorf("sglogger: invalid sampling rule for %s: first %d, thereafter %d", level, rule.First, rule.Thereafter)
+			}
+		}
+	}
+	if c.TraceEscalation != nil && (c.TraceEscalation.TTL < 0 || c.TraceEscalation.MaxTraces < 0) {
+		return fmt.Errorf("sglogger: invalid trace escalation TTL %s or trace limit %d", c.TraceEscalation.TTL, c.TraceEscalation.MaxTraces)
+	}
+	switch c.EmptyProviders {
+	case EmptyProvidersDefault, EmptyProvidersError, EmptyProvidersStderr, EmptyProvidersAllow:
+	default:
+		return fmt.Errorf("sglogger: invalid empty providers policy %d", c.EmptyProviders)
+	}
+	if c.Redaction != nil && !c.Redaction.Mode().valid() {
+		return fmt.Errorf("%w: %d", ErrInvalidRedactionMode, c.Redaction.Mode())
+	}
+	for _, name := range c.Enrichers {
+		if name == "" {
+			return fmt.Errorf("sglogger: empty enricher name")
+		}
+	}
+	if c.SlowWriteBudget < 0 {
+		return fmt.Errorf("sglogger: negative slow write budget %s", c.SlowWriteBudget)
+	}
+	if c.Diagnostics != nil && c.Diagnostics.Rate < 0 {
+		return fmt.Errorf("sglogger: negative diagnostics rate %d", c.Diagnostics.Rate)
+	}
+	return nil
+}
+
+// Validate проверяет конфигурацию провайдера, включая встроенную LoggerConfig.
+// Уровень вне диапазона ошибкой не считается: конструкторы приводят его
+// к ближайшей границе. Вызывается конструкторами провайдеров.
+func (c ProviderConfig) Validate() error {
+	if err := c.LoggerConfig.Validate(); err != nil {
+		return err
+	}
+	if c.BufferSize < 0 || c.PauseBufferSize < 0 {
+		return fmt.Errorf("sglogger: negative buffer size %d or pause buffer size %d", c.BufferSize, c.PauseBufferSize)
+	}
+	if c.FlushInterval < 0 {
+		return fmt.Errorf("sglogger: negative flush interval %s", c.FlushInterval)
+	}
+	if c.LevelFormat.Width < 0 {
+		return fmt.Errorf("sglogger: negative level label width %d", c.LevelFormat.Width)
+	}
+	switch c.LevelFormat.Case {
+	case LabelCaseAsIs, LabelCaseUpper, LabelCaseLower:
+	default:
+		return fmt.Errorf("sglogger: invalid label case %d", c.LevelFormat.Case)
+	}
+	if c.Align.NameWidth < 0 || c.Align.FieldsColumn < 0 {
+		return fmt.Errorf("sglogger: negative name width %d or fields column %d", c.Align.NameWidth, c.Align.FieldsColumn)
+	}
+	if c.FloatFormat.Precision < 0 {
+		return fmt.Errorf("sglogger: negative float precision %d", c.FloatFormat.Precision)
+	}
+	switch c.Durations {
+	case DurationAsIs, DurationString, DurationMillis, DurationSeconds:
+	default:
+		return fmt.Errorf("sglogger: invalid duration policy %d", c.Durations)
+	}
+	return nil
+}
+
+// clone возвращает копию конфигурации с собственными отображениями и срезами.
+func (c LoggerConfig) clone() LoggerConfig {
+	if c.Sampling != nil {
+		sampling := *c.Sampling
+		if sampling.Levels != nil {
+			sampling.Levels = make(map[Level]SamplingRule, len(c.Sampling.Levels))
+			for level, rule := range c.Sampling.Levels {
+				sampling.Levels[level] = rule
+			}
+		}
+		c.Sampling = &sampling
+	}
+	if c.EnrichRuntime != nil {
+		enrich := *c.EnrichRuntime
+		enrich.Keys = cloneStrings(enrich.Keys)
+		c.EnrichRuntime = &enrich
+	}
+	if c.DedupKey != nil {
+		dedup := *c.DedupKey
+		dedup.Fields = append([]string(nil), dedup.Fields...)
+		c.DedupKey = &dedup
+	}
+	if c.TraceEscalation != nil {
+		escalation := *c.TraceEscalation
+		c.TraceEscalation = &escalation
+	}
+	if c.FieldValidation != nil {
+		validation := *c.FieldValidation
+		c.FieldValidation = &validation
+	}
+	if c.Diagnostics != nil {
+		diagnostics := *c.Diagnostics
+		c.Diagnostics = &diagnostics
+	}
+	c.Enrichers = append([]string(nil), c.Enrichers...)
+	c.Hooks = append([]Hook(nil), c.Hooks...)
+	return c
+}
+
+// clone возвращает копию конфигурации с собственными отображениями и срезами.
+func (c ProviderConfig) clone() ProviderConfig {
+	c.LoggerConfig = c.LoggerConfig.clone()
+	if c.LevelFormat.Labels != nil {
+		labels := make(map[Level]string, len(c.LevelFormat.Labels))
+		for level, label := range c.LevelFormat.Labels {
+			labels[level] = label
+		}
+		c.LevelFormat.Labels = labels
+	}
+	return c
+}
+
+// cloneStrings возвращает копию отображения строк; nil остается nil.
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,150 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LoggerConfig
+		wantErr bool
+	}{
+		{name: "zero value", config: LoggerConfig{}},
+		{name: "sampling", config: LoggerConfig{Sampling: &SamplingConfig{Tick: time.Second, Levels: map[Level]SamplingRule{LevelInfo: {First: 1}}}}},
+		{name: "negative sampling tick", config: LoggerConfig{Sampling: &SamplingConfig{Tick: -time.Second}}, wantErr: true},
+		{name: "negative sampling keys", config: LoggerConfig{Sampling: &SamplingConfig{MaxKeys: -1}}, wantErr: true},
+		{name: "sampling invalid level", config: LoggerConfig{Sampling: &SamplingConfig{Levels: map[Level]SamplingRule{Level(99): {}}}}, wantErr: true},
+		{name: "negative sampling rule", config: LoggerConfig{Sampling: &SamplingConfig{Levels: map[Level]SamplingRule{LevelInfo: {First: -1}}}}, wantErr: true},
+		{name: "negative escalation TTL", config: LoggerConfig{TraceEscalation: &TraceEscalationConfig{TTL: -time.Second}}, wantErr: true},
+		{name: "invalid empty providers policy", config: LoggerConfig{EmptyProviders: EmptyProvidersPolicy(42)}, wantErr: true},
+		{name: "empty enricher name", config: LoggerConfig{Enrichers: []string{""}}, wantErr: true},
+		{name: "negative slow write budget", config: LoggerConfig{SlowWriteBudget: -time.Millisecond}, wantErr: true},
+		{name: "negative diagnostics rate", config: LoggerConfig{Diagnostics: &DiagnosticsConfig{Rate: -1}}, wantErr: true},
+		{name: "invalid redaction mode", config: LoggerConfig{Redaction: NewRedaction(RedactionMode(42))}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ProviderConfig
+		wantErr bool
+	}{
+		{name: "zero value", config: ProviderConfig{}},
+		{name: "level out of range is clamped", config: ProviderConfig{Level: Level(99)}},
+		{name: "invalid logger config", config: ProviderConfig{LoggerConfig: LoggerConfig{SlowWriteBudget: -1}}, wantErr: true},
+		{name: "negative buffer", config: ProviderConfig{BufferSize: -1}, wantErr: true},
+		{name: "negative pause buffer", config: ProviderConfig{PauseBufferSize: -1}, wantErr: true},
+		{name: "negative flush interval", config: ProviderConfig{FlushInterval: -time.Second}, wantErr: true},
+		{name: "negative label width", config: ProviderConfig{LevelFormat: LevelFormat{Width: -1}}, wantErr: true},
+		{name: "invalid label case", config: ProviderConfig{LevelFormat: LevelFormat{Case: LabelCase(42)}}, wantErr: true},
+		{name: "negative align column", config: ProviderConfig{Align: AlignConfig{FieldsColumn: -1}}, wantErr: true},
+		{name: "negative float precision", config: ProviderConfig{FloatFormat: FloatFormat{Precision: -1}}, wantErr: true},
+		{name: "invalid duration policy", config: ProviderConfig{Durations: DurationPolicy(42)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConstructorsRejectInvalidConfig(t *testing.T) {
+	invalid := ProviderConfig{BufferSize: -1}
+
+	if _, err := NewFileProvider(FileProviderConfig{ProviderConfig: invalid, Path: filepath.Join(t.TempDir(), "app.log")}); err == nil {
+		t.Error("NewFileProvider accepted an invalid config")
+	}
+	if err := NewFmtProviderWithWriter(invalid, &bytes.Buffer{}).Write(context.Background(), LevelInfo, "x", nil); err == nil {
+		t.Error("NewFmtProvider with an invalid config returned a working provider")
+	}
+	if _, err := NewLoggerWithOptions(LoggerConfig{SlowWriteBudget: -1}, NewFieldsHandler(), &recordingProvider{}); err == nil {
+		t.Error("NewLoggerWithOptions accepted an invalid config")
+	}
+}
+
+func TestProviderConfigMutationAfterConstruction(t *testing.T) {
+	out := &bytes.Buffer{}
+	config := ProviderConfig{LevelFormat: LevelFormat{Labels: map[Level]string{
+		LevelDebug: "DBG", LevelInfo: "INF", LevelWarn: "WRN", LevelError: "ERR", LevelFatal: "FTL",
+	}}}
+	p := NewFmtProviderWithWriter(config, out)
+
+	config.LevelFormat.Labels[LevelInfo] = "CHANGED"
+	config.Level = LevelError
+
+	if err := p.Write(context.Background(), LevelInfo, "started", nil); err != nil {
+		t.Fatal(err)
+	}
+	if line := out.String(); !strings.Contains(line, "INF") || strings.Contains(line, "CHANGED") {
+		t.Errorf("line = %q, want the label from construction time", line)
+	}
+	if !p.ShouldLog(context.Background(), LevelInfo) {
+		t.Error("changing Level after construction changed the provider")
+	}
+}
+
+func TestLoggerConfigMutationAfterConstruction(t *testing.T) {
+	recorder := &recordingProvider{}
+	config := LoggerConfig{Sampling: &SamplingConfig{
+		Tick:   time.Hour,
+		Levels: map[Level]SamplingRule{LevelInfo: {First: 1}},
+	}}
+	logger := NewLogger(config, NewFieldsHandler(), recorder)
+
+	config.Sampling.Levels[LevelInfo] = SamplingRule{First: 100}
+	config.Sampling.Tick = time.Nanosecond
+
+	for i := 0; i < 5; i++ {
+		logger.Info(context.Background(), "request %d", i)
+	}
+	if got := len(recorder.Entries()); got != 1 {
+		t.Errorf("logged %d entries, want 1: sampling must use the rules from construction time", got)
+	}
+}
+
+func TestLoggerConfigClone(t *testing.T) {
+	original := LoggerConfig{
+		Sampling:        &SamplingConfig{Levels: map[Level]SamplingRule{LevelInfo: {First: 1}}},
+		EnrichRuntime:   &RuntimeEnrichment{Keys: map[string]string{"host": "hostname"}},
+		DedupKey:        &DedupKeyConfig{Fields: []string{"user"}},
+		FieldValidation: &FieldValidationConfig{ErrField: true},
+		Enrichers:       []string{"build"},
+	}
+	snapshot := LoggerConfig{
+		Sampling:        &SamplingConfig{Levels: map[Level]SamplingRule{LevelInfo: {First: 1}}},
+		EnrichRuntime:   &RuntimeEnrichment{Keys: map[string]string{"host": "hostname"}},
+		DedupKey:        &DedupKeyConfig{Fields: []string{"user"}},
+		FieldValidation: &FieldValidationConfig{ErrField: true},
+		Enrichers:       []string{"build"},
+	}
+
+	clone := original.clone()
+	clone.Sampling.Levels[LevelInfo] = SamplingRule{First: 9}
+	clone.EnrichRuntime.Keys["host"] = "changed"
+	clone.DedupKey.Fields[0] = "changed"
+	clone.FieldValidation.ErrField = false
+	clone.Enrichers[0] = "changed"
+
+	if !reflect.DeepEqual(original, snapshot) {
+		t.Errorf("changing the clone changed the original: %+v", original)
+	}
+}
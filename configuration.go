@@ -0,0 +1,208 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maskedValue заменяет значения секретов в описании конфигурации.
+const maskedValue = "[REDACTED]"
+
+// secretKeyParts - части имен параметров, значения которых маскируются.
+var secretKeyParts = []string{
+	"password", "passwd", "secret", "token", "apikey", "api_key",
+	"authorization", "credential", "private_key", "dsn",
+}
+
+// LogConfiguration записывает сообщение "logging configuration" уровнем
+// LevelInfo с описанием действующей конфигурации: версией пакета, уровнем
+// логгера, статическими полями, семплированием, включенными возможностями
+// LoggerConfig, обогатителями и провайдерами (имя, тип, активность и описание провайдеров,
+// реализующих Describer). Значения, похожие на секреты, маскируются.
+// Предназначен для вызова при запуске сервиса.
+func (l *logger) LogConfiguration(ctx context.Context) {
+	l.mu.RLock()
+	providers := make([]interface{}, 0, len(l.providers))
+	for _, rp := range l.providers {
+		description := describeProvider(rp.provider)
+		description["name"] = rp.name
+		providers = append(providers, maskSecrets(description))
+	}
+	var sampling *SamplingConfig
+	if l.sampler != nil {
+		sampling = &l.sampler.config
+	}
+	static := make(map[string]interface{}, len(l.staticFields))
+	for k, v := range l.staticFields {
+		static[k] = v
+	}
+	l.mu.RUnlock()
+
+	l.InfoWithFields(ctx, Fields{
+		"sglogger.version": Version(),
+		"level":            l.GetLevel().String(),
+		"static_fields":    maskSecrets(static),
+		"sampling":         describeSampling(sampling),
+		"features":         configFeatures(l.config),
+		"enrichers":        l.config.Enrichers,
+		"providers":        providers,
+	}, "logging configuration")
+}
+
+// describeProvider возвращает тип, активность и, если провайдер реализует
+// Describer, его описание. Используется и обертками для описания внутреннего провайдера.
+func describeProvider(p LoggerProvider) map[string]interface{} {
+	description := make(map[string]interface{})
+	if describer, ok := p.(Describer); ok {
+		for k, v := range describer.Describe() {
+			description[k] = v
+		}
+	}
+	description["type"] = fmt.Sprintf("%T", p)
+	description["active"] = providerActive(p)
+	return description
+}
+
+// describeProviderConfig описывает общие параметры ProviderConfig.
+func describeProviderConfig(config ProviderConfig) map[string]interface{} {
+	description := map[string]interface{}{
+		"level":  config.Level.String(),
+		"format": describeFormatter(config.Formatter),
+	}
+	if config.HonorContextLevel {
+		description["honor_context_level"] = true
+	}
+	if config.BufferSize > 0 {
+		description["buffer_size"] = config.BufferSize
+		description["flush_interval"] = config.FlushInterval.String()
+	}
+	return description
+}
+
+// describeHTTPBatch описывает параметры пакетной отправки.
+func describeHTTPBatch(config HTTPBatchConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"batch_size":     config.BatchSize,
+		"flush_interval": config.FlushInterval.String(),
+		"max_pending":    config.MaxPending,
+		"max_retries":    httpMaxRetries(config),
+		"max_in_flight":  config.MaxInFlight,
+		"gzip":           config.Compression == HTTPCompressionGzip,
+	}
+}
+
+// describeFormatter возвращает название формата строк.
+func describeFormatter(f Formatter) string {
+	switch f.(type) {
+	case nil, *textFormatter:
+		return "text"
+	case *jsonFormatter:
+		return "json"
+	}
+	return fmt.Sprintf("%T", f)
+}
+
+// describeSampling описывает правила семплирования по уровням.
+func describeSampling(config *SamplingConfig) interface{} {
+	if config == nil || len(config.Levels) == 0 {
+		return "disabled"
+	}
+	levels := make(map[string]interface{}, len(config.Levels))
+	for level, rule := range config.Levels {
+		levels[level.String()] = fmt.Sprintf("first %d, thereafter %d", rule.First, rule.Thereafter)
+	}
+	return map[string]interface{}{
+		"tick":     config.Tick.String(),
+		"max_keys": config.MaxKeys,
+		"levels":   levels,
+	}
+}
+
+// configFeatures возвращает отсортированные названия включенных возможностей LoggerConfig.
+func configFeatures(config LoggerConfig) []string {
+	var features []string
+	add := func(enabled bool, name string) {
+		if enabled {
+			features = append(features, name)
+		}
+	}
+	add(config.Fingerprint, "fingerprint")
+	add(config.GoroutineInfo, "goroutine_info")
+	add(config.EnrichRuntime != nil, "enrich_runtime")
+	add(config.DedupKey != nil, "dedup_key")
+	add(config.FieldRules != nil, "field_rules")
+	add(config.Redaction != nil, "redaction")
+	add(len(config.Hooks) > 0, "hooks")
+	add(config.TraceEscalation != nil, "trace_escalation")
+	add(config.ConvertValue != nil, "convert_value")
+	add(config.SanitizeUTF8, "sanitize_utf8")
+	add(config.NormalizeUnicode != nil, "normalize_unicode")
+	add(config.StderrFallback, "stderr_fallback")
+	add(config.ContextDiagnostics, "context_diagnostics")
+	add(config.StrictFormat, "strict_format")
+	add(config.StrictFields, "strict_fields")
+	add(config.FieldValidation != nil, "field_validation")
+	add(config.SlowWriteBudget > 0, "slow_write_budget")
+	add(config.LeakCheck, "leak_check")
+	sort.Strings(features)
+	return features
+}
+
+// maskSecrets возвращает копию описания, в которой значения параметров
+// с именами, похожими на секреты, заменены на "[REDACTED]", а пароли в URL
+// скрыты. Вложенные описания обрабатываются рекурсивно.
+func maskSecrets(description map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(description))
+	for k, v := range description {
+		masked[k] = maskValue(k, v)
+	}
+	return masked
+}
+
+// maskValue маскирует значение параметра key.
+func maskValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return maskSecrets(v)
+	case Fields:
+		return maskSecrets(v)
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskValue(key, item)
+		}
+		return masked
+	case string:
+		if v != "" && secretKey(key) {
+			return maskedValue
+		}
+		return maskURLPassword(v)
+	}
+	return value
+}
+
+// secretKey сообщает, похоже ли имя параметра на имя секрета.
+func secretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskURLPassword скрывает пароль в строке, если она является URL с учетными данными.
+func maskURLPassword(s string) string {
+	if !strings.Contains(s, "@") || !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
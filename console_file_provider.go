@@ -0,0 +1,171 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// consoleFileProvider направляет записи в консоль по уровню и дублирует их в файл.
+type consoleFileProvider struct {
+	closedState
+
+	config  ConsoleFileConfig
+	info    LoggerProvider
+	errs    LoggerProvider
+	file    LoggerProvider
+	created []LoggerProvider
+}
+
+// NewConsoleFileProvider создает провайдер для типичной схемы вывода:
+// записи ниже LevelError выводятся в InfoWriter (по умолчанию stdout),
+// LevelError и выше - в ErrorWriter (по умолчанию stderr), а все записи,
+// если задан FilePath, также дописываются в файл с ротацией.
+// Консольный вывод строится NewFmtProviderWithWriter, файловый -
+// NewFileProvider с теми же ProviderConfig; Close закрывает все созданные
+// провайдеры, но не InfoWriter и ErrorWriter. Если запись в консоль или в файл
+// не удалась, вторая все равно выполняется, а ошибки объединяются.
+// Возвращает ошибку, если конфигурация некорректна или файл не удается открыть.
+func NewConsoleFileProvider(config ConsoleFileConfig) (LoggerProvider, error) {
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+	if config.InfoWriter == nil {
+		config.InfoWriter = os.Stdout
+	}
+	if config.ErrorWriter == nil {
+		config.ErrorWriter = os.Stderr
+	}
+
+	p := &consoleFileProvider{
+		config: config,
+		info:   NewFmtProviderWithWriter(config.ProviderConfig, config.InfoWriter),
+		errs:   NewFmtProviderWithWriter(config.ProviderConfig, config.ErrorWriter),
+	}
+	p.created = []LoggerProvider{p.info, p.errs}
+	// Консольные провайдеры создаются из одной конфигурации и сообщают
+	// об ошибке через NewFailedProvider
+	if failed, ok := p.info.(*failedProvider); ok {
+		p.closeCreated(context.Background())
+		return nil, failed.err
+	}
+
+	if config.FilePath != "" {
+		file, err := NewFileProvider(FileProviderConfig{
+			ProviderConfig: config.ProviderConfig,
+			Path:           config.FilePath,
+			Rotation:       config.Rotation,
+			MaxSize:        config.MaxSize,
+			MaxBackups:     config.MaxBackups,
+			MaxAgeDays:     config.MaxAgeDays,
+			Compress:       config.Compress,
+		})
+		if err != nil {
+			p.closeCreated(context.Background())
+			return nil, err
+		}
+		p.file = file
+		p.created = append(p.created, file)
+	}
+	return p, nil
+}
+
+// Write выводит запись в консоль согласно уровню и в файл.
+func (p *consoleFileProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+
+	console := p.info
+	if level >= LevelError {
+		console = p.errs
+	}
+	err := console.Write(ctx, level, message, fields)
+	if p.file == nil {
+		return err
+	}
+	return errors.Join(err, p.file.Write(ctx, level, message, fields))
+}
+
+// Name возвращает имя провайдера из конфигурации или "console-file" по умолчанию.
+func (p *consoleFileProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "console-file"
+}
+
+// Describe возвращает описания консольного и файлового вывода (см. Describer).
+func (p *consoleFileProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["info"] = describeProvider(p.info)
+	description["error"] = describeProvider(p.errs)
+	if p.file != nil {
+		description["file"] = describeProvider(p.file)
+	}
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *consoleFileProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *consoleFileProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Flush сбрасывает буферизованный вывод консоли и файла.
+func (p *consoleFileProvider) Flush(ctx context.Context) error {
+	var errs []error
+	for _, provider := range p.created {
+		if flusher, ok := provider.(Flusher); ok {
+			if err := flusher.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reopen открывает файл заново (см. Reopener); без файла ничего не делает.
+func (p *consoleFileProvider) Reopen() error {
+	if p.file == nil {
+		return nil
+	}
+	return p.file.(Reopener).Reopen()
+}
+
+// Close закрывает консольные и файловый провайдеры.
+func (p *consoleFileProvider) Close(ctx context.Context) error {
+	if !p.markClosed() {
+		return nil
+	}
+	return p.closeCreated(ctx)
+}
+
+// closeCreated закрывает все созданные провайдеры и объединяет их ошибки.
+func (p *consoleFileProvider) closeCreated(ctx context.Context) error {
+	var errs []error
+	for _, provider := range p.created {
+		if err := provider.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
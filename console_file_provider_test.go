@@ -0,0 +1,81 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConsoleFileProviderRoutesByLevel(t *testing.T) {
+	var info, errs bytes.Buffer
+	path := filepath.Join(t.TempDir(), "app.log")
+	p, err := NewConsoleFileProvider(ConsoleFileConfig{
+		InfoWriter:  &info,
+		ErrorWriter: &errs,
+		FilePath:    path,
+	})
+	if err != nil {
+		t.Fatalf("NewConsoleFileProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, e := range []struct {
+		level   Level
+		message string
+	}{
+		{LevelInfo, "started"},
+		{LevelWarn, "slow"},
+		{LevelError, "failed"},
+	} {
+		if err := p.Write(ctx, e.level, e.message, nil); err != nil {
+			t.Fatalf("Write(%s): %v", e.level, err)
+		}
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if got := info.String(); !strings.Contains(got, "started") || !strings.Contains(got, "slow") || strings.Contains(got, "failed") {
+		t.Errorf("info output = %q, want entries below error only", got)
+	}
+	if got := errs.String(); !strings.Contains(got, "failed") || strings.Contains(got, "started") {
+		t.Errorf("error output = %q, want error entries only", got)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range []string{"started", "slow", "failed"} {
+		if !strings.Contains(string(data), message) {
+			t.Errorf("file is missing %q:\n%s", message, data)
+		}
+	}
+
+	// Close закрывает файловый провайдер: повторная запись не проходит
+	if err := p.Write(ctx, LevelInfo, "late", nil); err != ErrProviderClosed {
+		t.Errorf("Write after Close = %v, want ErrProviderClosed", err)
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+}
+
+func TestConsoleFileProviderInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	// Путь указывает внутрь обычного файла, поэтому каталог не создается
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewConsoleFileProvider(ConsoleFileConfig{
+		InfoWriter:  &bytes.Buffer{},
+		ErrorWriter: &bytes.Buffer{},
+		FilePath:    filepath.Join(blocker, "app.log"),
+	})
+	if err == nil {
+		t.Fatal("NewConsoleFileProvider succeeded for a path under a regular file")
+	}
+}
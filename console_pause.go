@@ -0,0 +1,112 @@
+package sglogger
+
+import (
+	"io"
+	"sync"
+)
+
+// defaultPauseBufferSize ограничивает объем записей, накапливаемых
+// во время паузы, по умолчанию.
+const defaultPauseBufferSize = 1 << 20
+
+// consoleGate выводит строки консольного провайдера и задерживает их,
+// пока вывод приостановлен (см. Pauser).
+type consoleGate struct {
+	out     io.Writer
+	limit   int
+	paused  int
+	pending []byte
+	mu      sync.Mutex
+}
+
+// newConsoleGate создает вывод с паузой поверх out.
+func newConsoleGate(out io.Writer, limit int) *consoleGate {
+	if limit <= 0 {
+		limit = defaultPauseBufferSize
+	}
+	return &consoleGate{out: out, limit: limit}
+}
+
+// write выводит строку или, если вывод приостановлен и уровень ниже LevelError,
+// сохраняет ее копию. Строка LevelError и выше во время паузы выводится одна:
+// накопленные строки остаются в буфере до resume. Возвращает ErrQueueFull,
+// если строка не помещается в буфер.
+func (g *consoleGate) write(level Level, line []byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused > 0 {
+		if level >= LevelError {
+			_, err := g.out.Write(line)
+			return err
+		}
+		if len(g.pending)+len(line) > g.limit {
+			return ErrQueueFull
+		}
+		g.pending = append(g.pending, line...)
+		return nil
+	}
+
+	if err := g.releaseLocked(); err != nil {
+		return err
+	}
+	_, err := g.out.Write(line)
+	return err
+}
+
+// pause приостанавливает вывод.
+func (g *consoleGate) pause() {
+	g.mu.Lock()
+	g.paused++
+	g.mu.Unlock()
+}
+
+// resume снимает одну паузу и, если пауз не осталось, выводит накопленные строки.
+func (g *consoleGate) resume() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused > 0 {
+		g.paused--
+	}
+	if g.paused > 0 {
+		return nil
+	}
+	return g.releaseLocked()
+}
+
+// release выводит накопленные строки независимо от паузы.
+func (g *consoleGate) release() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.releaseLocked()
+}
+
+// releaseLocked выводит накопленные строки. Вызывается с захваченным мьютексом.
+func (g *consoleGate) releaseLocked() error {
+	if len(g.pending) == 0 {
+		return nil
+	}
+	_, err := g.out.Write(g.pending)
+	if cap(g.pending) > maxPooledLineSize {
+		g.pending = nil
+	} else {
+		g.pending = g.pending[:0]
+	}
+	return err
+}
+
+// synchronizedWriter выводит данные под мьютексом consoleGate.
+type synchronizedWriter struct {
+	gate *consoleGate
+	w    io.Writer
+}
+
+// Write записывает p, не допуская чередования со строками провайдера.
+func (s *synchronizedWriter) Write(p []byte) (int, error) {
+	s.gate.mu.Lock()
+	defer s.gate.mu.Unlock()
+
+	return s.w.Write(p)
+}
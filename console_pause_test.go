@@ -0,0 +1,140 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// messages возвращает сообщения строк вывода в порядке вывода.
+func messages(out string, known ...string) []string {
+	var got []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		for _, message := range known {
+			if strings.Contains(line, message) {
+				got = append(got, message)
+			}
+		}
+	}
+	return got
+}
+
+func TestConsolePause(t *testing.T) {
+	type write struct {
+		level   Level
+		message string
+	}
+	known := []string{"before", "progress", "failed", "done"}
+	tests := []struct {
+		name        string
+		writes      []write
+		whilePaused []string // Выведено до Resume
+		afterResume []string // Выведено после Resume
+	}{
+		{
+			name:        "buffers entries below error",
+			writes:      []write{{LevelInfo, "progress"}, {LevelWarn, "done"}},
+			afterResume: []string{"progress", "done"},
+		},
+		{
+			// Ошибка выводится сразу, а накопленные записи ждут Resume
+			name:        "error bypasses the pause alone",
+			writes:      []write{{LevelInfo, "progress"}, {LevelError, "failed"}, {LevelInfo, "done"}},
+			whilePaused: []string{"failed"},
+			afterResume: []string{"failed", "progress", "done"},
+		},
+		{
+			name:        "fatal bypasses the pause alone",
+			writes:      []write{{LevelInfo, "progress"}, {LevelFatal, "failed"}},
+			whilePaused: []string{"failed"},
+			afterResume: []string{"failed", "progress"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			provider := NewFmtProviderWithWriter(ProviderConfig{}, &out)
+			ctx := context.Background()
+			provider.Write(ctx, LevelInfo, "before", nil)
+
+			provider.(Pauser).Pause()
+			for _, w := range tt.writes {
+				if err := provider.Write(ctx, w.level, w.message, nil); err != nil {
+					t.Fatalf("Write(%s): %v", w.message, err)
+				}
+			}
+			if got, want := messages(out.String(), known...), append([]string{"before"}, tt.whilePaused...); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("output while paused = %v, want %v", got, want)
+			}
+
+			if err := provider.(Pauser).Resume(); err != nil {
+				t.Fatalf("Resume: %v", err)
+			}
+			if got, want := messages(out.String(), known...), append([]string{"before"}, tt.afterResume...); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("output after Resume = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestConsolePauseNested(t *testing.T) {
+	var out bytes.Buffer
+	provider := NewFmtProviderWithWriter(ProviderConfig{}, &out)
+	pauser := provider.(Pauser)
+
+	pauser.Pause()
+	pauser.Pause()
+	provider.Write(context.Background(), LevelInfo, "progress", nil)
+	pauser.Resume()
+	if out.Len() != 0 {
+		t.Errorf("output after the inner Resume: %q", out.String())
+	}
+	pauser.Resume()
+	if !strings.Contains(out.String(), "progress") {
+		t.Errorf("output after the outer Resume: %q", out.String())
+	}
+}
+
+func TestConsolePauseBufferLimit(t *testing.T) {
+	var out bytes.Buffer
+	provider := NewFmtProviderWithWriter(ProviderConfig{PauseBufferSize: 100}, &out)
+	ctx := context.Background()
+
+	provider.(Pauser).Pause()
+	if err := provider.Write(ctx, LevelInfo, "progress", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := provider.Write(ctx, LevelInfo, strings.Repeat("x", 100), nil); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Write beyond PauseBufferSize = %v, want ErrQueueFull", err)
+	}
+	// Ошибки не занимают буфер паузы
+	if err := provider.Write(ctx, LevelError, strings.Repeat("y", 100), nil); err != nil {
+		t.Errorf("Write(error) with a full buffer: %v", err)
+	}
+	if err := provider.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !strings.Contains(out.String(), "progress") {
+		t.Errorf("Close did not release the paused entries: %q", out.String())
+	}
+}
+
+func TestConsoleSynchronizedWriter(t *testing.T) {
+	var out bytes.Buffer
+	provider := NewFmtProviderWithWriter(ProviderConfig{}, &out)
+	w := provider.(Pauser).SynchronizedWriter(&out)
+
+	provider.(Pauser).Pause()
+	w.Write([]byte("\r[=====     ] 50%"))
+	provider.Write(context.Background(), LevelInfo, "progress", nil)
+	w.Write([]byte("\r[==========] 100%\n"))
+	provider.(Pauser).Resume()
+
+	got := out.String()
+	if bar, entry := strings.Index(got, "100%"), strings.Index(got, "progress"); bar < 0 || entry < bar {
+		t.Errorf("paused entry was written before the progress bar finished: %q", got)
+	}
+}
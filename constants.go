@@ -4,4 +4,19 @@ type contextKey string
 
 const (
     TraceIDKey contextKey = "trace_id"
+    
+    // TenantKey хранит арендатора по умолчанию (см. ContextWithTenant)
+    TenantKey contextKey = "tenant"
+    
+    // minLevelKey хранит переопределение минимального уровня (см. ContextWithMinLevel)
+    minLevelKey contextKey = "min_level"
+    
+    // fieldsKey хранит поля, привязанные к контексту (см. ContextWithFields)
+    fieldsKey contextKey = "fields"
+    
+    // canonicalLineKey хранит накопитель канонической строки (см. StartCanonicalLine)
+    canonicalLineKey contextKey = "canonical_line"
+
+    // entryTimeKey хранит время записи (см. ContextWithEntryTime)
+    entryTimeKey contextKey = "entry_time"
 )
\ No newline at end of file
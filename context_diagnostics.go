@@ -0,0 +1,33 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Поля диагностики контекста, добавляемые при LoggerConfig.ContextDiagnostics.
+const (
+	CtxDeadlineField  = "ctx_deadline"  // Абсолютный дедлайн контекста (RFC 3339)
+	CtxRemainingField = "ctx_remaining" // Время до дедлайна на момент записи; отрицательное, если дедлайн прошел
+	CtxErrField       = "ctx_err"       // Ошибка контекста (ctx.Err) на момент записи
+)
+
+// contextDiagnosticFields возвращает поля, описывающие дедлайн и отмену контекста,
+// если ошибка записи вызвана context.DeadlineExceeded или context.Canceled.
+// Для остальных ошибок возвращает nil.
+func contextDiagnosticFields(ctx context.Context, err error) Fields {
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return nil
+	}
+
+	fields := make(Fields, 3)
+	if deadline, ok := ctx.Deadline(); ok {
+		fields[CtxDeadlineField] = deadline.Format(time.RFC3339Nano)
+		fields[CtxRemainingField] = time.Until(deadline).String()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		fields[CtxErrField] = ctxErr.Error()
+	}
+	return fields
+}
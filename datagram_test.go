@@ -0,0 +1,236 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePacketConn - датаграммное соединение, отклоняющее датаграммы
+// больше limit, как сокет UDP с ограничением размера.
+type fakePacketConn struct {
+	net.Conn
+
+	limit   int
+	mu      sync.Mutex
+	packets [][]byte
+}
+
+func (c *fakePacketConn) Write(b []byte) (int, error) {
+	if len(b) > c.limit {
+		return 0, errors.New("message too long")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.packets = append(c.packets, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (c *fakePacketConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *fakePacketConn) Close() error { return nil }
+
+// send записывает датаграммы в соединение.
+func (c *fakePacketConn) send(t *testing.T, packets [][]byte) {
+	t.Helper()
+	for _, packet := range packets {
+		if _, err := c.Write(packet); err != nil {
+			t.Fatalf("send %d bytes: %v", len(packet), err)
+		}
+	}
+}
+
+// newTestFitter создает datagramFitter, кодирующий записи в JSON.
+func newTestFitter(t *testing.T, config OversizeConfig, chunk func([]byte) [][]byte) *datagramFitter {
+	t.Helper()
+
+	formatter := NewJSONFormatter(ProviderConfig{})
+	fitter, err := newDatagramFitter(config, 1024, func(e Entry) []byte {
+		return formatter.AppendFormat(nil, e)
+	}, chunk)
+	if err != nil {
+		t.Fatalf("newDatagramFitter: %v", err)
+	}
+	return fitter
+}
+
+// oversizeEntry возвращает запись со стеком, отладочным полем и полями,
+// которые нельзя удалять.
+func oversizeEntry() Entry {
+	return Entry{
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:   LevelError,
+		Message: "payment failed",
+		Fields: Fields{
+			"stack":    strings.Repeat("main.handler()\n", 20),
+			"request":  strings.Repeat("x", 100),
+			"error":    "card declined",
+			"trace_id": "4bf92f3577b34da6",
+		},
+	}
+}
+
+func TestDatagramFitterSplit(t *testing.T) {
+	const limit = 128
+	conn := &fakePacketConn{limit: limit}
+	var encoded []byte
+	chunk := func(data []byte) [][]byte {
+		encoded = append([]byte(nil), data...)
+		var chunks [][]byte
+		for len(data) > limit {
+			chunks = append(chunks, data[:limit])
+			data = data[limit:]
+		}
+		return append(chunks, data)
+	}
+	fitter := newTestFitter(t, OversizeConfig{Policy: OversizeSplit, MaxSize: limit}, chunk)
+
+	packets, err := fitter.fit(oversizeEntry())
+	if err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+	if len(packets) < 2 {
+		t.Fatalf("fit returned %d datagrams, want several", len(packets))
+	}
+	conn.send(t, packets)
+	if got := bytes.Join(conn.packets, nil); !bytes.Equal(got, encoded) {
+		t.Errorf("reassembled datagrams differ from the entry:\n%s\nwant\n%s", got, encoded)
+	}
+}
+
+func TestDatagramFitterSplitWithoutChunking(t *testing.T) {
+	conn := &fakePacketConn{limit: 200}
+	fitter := newTestFitter(t, OversizeConfig{Policy: OversizeSplit, MaxSize: 200}, nil)
+
+	// Протокол без разбиения на части усекает запись
+	packets, err := fitter.fit(oversizeEntry())
+	if err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+	if len(packets) != 1 {
+		t.Fatalf("fit returned %d datagrams, want 1", len(packets))
+	}
+	conn.send(t, packets)
+}
+
+func TestDatagramFitterTruncate(t *testing.T) {
+	const limit = 300
+	conn := &fakePacketConn{limit: limit}
+	fitter := newTestFitter(t, OversizeConfig{MaxSize: limit}, nil)
+
+	e := oversizeEntry()
+	packets, err := fitter.fit(e)
+	if err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+	conn.send(t, packets)
+
+	packet := string(conn.packets[0])
+	// Стек удаляется первым, его удаления достаточно
+	if strings.Contains(packet, "main.handler") {
+		t.Errorf("stack was kept: %s", packet)
+	}
+	for _, want := range []string{`"dropped_fields":["stack"]`, `"error":"card declined"`, `"trace_id":"4bf92f3577b34da6"`, `"request":"xxx`, "payment failed"} {
+		if !strings.Contains(packet, want) {
+			t.Errorf("datagram is missing %s: %s", want, packet)
+		}
+	}
+	if _, ok := e.Fields["stack"]; !ok {
+		t.Error("fit removed a field from the caller's map")
+	}
+}
+
+func TestDatagramFitterTruncateByPriority(t *testing.T) {
+	const limit = 200
+	conn := &fakePacketConn{limit: limit}
+	fitter := newTestFitter(t, OversizeConfig{MaxSize: limit}, nil)
+
+	packets, err := fitter.fit(oversizeEntry())
+	if err != nil {
+		t.Fatalf("fit: %v", err)
+	}
+	conn.send(t, packets)
+
+	// После стека удаляются остальные поля, начиная с наибольшего
+	packet := string(conn.packets[0])
+	for _, want := range []string{`"dropped_fields":["stack","request"]`, `"error":"card declined"`, `"trace_id":"4bf92f3577b34da6"`} {
+		if !strings.Contains(packet, want) {
+			t.Errorf("datagram is missing %s: %s", want, packet)
+		}
+	}
+}
+
+func TestDatagramFitterReject(t *testing.T) {
+	conn := &fakePacketConn{limit: 256}
+	fitter := newTestFitter(t, OversizeConfig{Policy: OversizeReject, MaxSize: 256}, nil)
+
+	packets, err := fitter.fit(oversizeEntry())
+	if !errors.Is(err, ErrEntryTooLarge) {
+		t.Fatalf("fit = %v, want ErrEntryTooLarge", err)
+	}
+	conn.send(t, packets)
+	if len(conn.packets) != 0 {
+		t.Errorf("rejected entry sent %d datagrams", len(conn.packets))
+	}
+
+	// Запись в пределах ограничения отправляется без изменений
+	small := Entry{Level: LevelInfo, Message: "ok"}
+	if packets, err = fitter.fit(small); err != nil {
+		t.Fatalf("fit small entry: %v", err)
+	}
+	conn.send(t, packets)
+}
+
+func TestDatagramFitterKeptFieldsExceedLimit(t *testing.T) {
+	const limit = 128
+	fitter := newTestFitter(t, OversizeConfig{MaxSize: limit}, nil)
+
+	e := oversizeEntry()
+	e.Fields["error"] = strings.Repeat("card declined; ", 20)
+	// Поля Keep не удаляются даже после усечения сообщения
+	if _, err := fitter.fit(e); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("fit = %v, want ErrEntryTooLarge", err)
+	}
+}
+
+func TestSyslogProviderOversizeTruncate(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	const limit = 300
+	provider, err := NewSyslogProvider(SyslogConfig{
+		Network:  "udp",
+		Address:  listener.LocalAddr().String(),
+		Oversize: OversizeConfig{MaxSize: limit},
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	p := provider.(*syslogProvider)
+	conn := &fakePacketConn{limit: limit}
+	p.mu.Lock()
+	p.conn.Close()
+	p.conn = conn
+	p.mu.Unlock()
+
+	e := oversizeEntry()
+	if err := provider.Write(context.Background(), e.Level, e.Message, e.Fields); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(conn.packets) != 1 {
+		t.Fatalf("sent %d datagrams, want 1", len(conn.packets))
+	}
+	if packet := string(conn.packets[0]); !strings.Contains(packet, `trace_id="4bf92f3577b34da6"`) || strings.Contains(packet, "main.handler") {
+		t.Errorf("datagram = %s", packet)
+	}
+}
@@ -0,0 +1,83 @@
+package sglogger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDebugHeader - заголовок, включающий отладочное логирование запроса.
+	defaultDebugHeader = "X-Debug"
+
+	// DebugSessionField - поле, которым помечаются сообщения отладочного запроса.
+	DebugSessionField = "debug_session"
+)
+
+// NewDebugHeaderMiddleware создает HTTP-middleware, включающее уровень Debug
+// для отдельного запроса. Если запрос содержит разрешенный заголовок
+// (см. DebugHeaderConfig), контекст запроса получает минимальный уровень LevelDebug
+// и поле debug_session=true. Провайдеры применяют переопределение уровня,
+// только если в их конфигурации включен HonorContextLevel.
+func NewDebugHeaderMiddleware(config DebugHeaderConfig) func(http.Handler) http.Handler {
+	if config.Header == "" {
+		config.Header = defaultDebugHeader
+	}
+	config.Tokens = append([]string(nil), config.Tokens...)
+	config.Secret = append([]byte(nil), config.Secret...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(config.Header)
+			if value != "" && debugTokenAllowed(config, value) {
+				ctx := ContextWithMinLevel(r.Context(), LevelDebug)
+				ctx = ContextWithFields(ctx, Fields{DebugSessionField: true})
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SignDebugToken создает подписанный токен для заголовка отладки,
+// действительный до момента expires. Формат: "<unix-время>.<hex(HMAC-SHA256)>".
+func SignDebugToken(secret []byte, expires time.Time) string {
+	payload := strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + debugTokenSignature(secret, payload)
+}
+
+// debugTokenAllowed проверяет значение заголовка по списку разрешенных токенов
+// и, если задан секрет, как подписанный токен с неистекшим сроком действия.
+func debugTokenAllowed(config DebugHeaderConfig, value string) bool {
+	for _, token := range config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(value)) == 1 {
+			return true
+		}
+	}
+
+	if len(config.Secret) == 0 {
+		return false
+	}
+
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(debugTokenSignature(config.Secret, payload)))
+}
+
+// debugTokenSignature вычисляет подпись полезной нагрузки токена.
+func debugTokenSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
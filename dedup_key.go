@@ -0,0 +1,44 @@
+package sglogger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// DedupKeyField - имя поля с ключом дедупликации записи. Если вызывающий код
+// передает это поле явно, его значение не перезаписывается.
+const DedupKeyField = "dedup_key"
+
+// DedupKey вычисляет ключ дедупликации записи, по которому получатели
+// (потребители Kafka, приемники webhook) могут идемпотентно обрабатывать
+// записи, повторно доставленные после сбоев (например, из дискового спула).
+//
+// Ключ - FNV-128a в шестнадцатеричном виде от следующей последовательности,
+// элементы которой разделены нулевым байтом:
+//   - уровень записи (Level.String);
+//   - сообщение, нормализованное NormalizeMessage;
+//   - для каждого ключа из keys в лексикографическом порядке - "ключ=значение",
+//     значение в формате %v; отсутствующие поля пропускаются.
+//
+// Алгоритм не зависит от процесса, поэтому ключ стабилен между перезапусками
+// и может быть вычислен получателем самостоятельно.
+func DedupKey(level Level, message string, fields Fields, keys []string) string {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	h := fnv.New128a()
+	h.Write([]byte(level.String()))
+	h.Write([]byte{0})
+	h.Write([]byte(NormalizeMessage(message)))
+	for _, k := range sorted {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		h.Write([]byte{0})
+		fmt.Fprintf(h, "%s=%v", k, v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
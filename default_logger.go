@@ -0,0 +1,34 @@
+package sglogger
+
+import "sync/atomic"
+
+// defaultHolder оборачивает Logger, так как atomic.Value требует
+// одинакового конкретного типа у всех сохраняемых значений.
+type defaultHolder struct {
+	logger Logger
+}
+
+var defaultLogger atomic.Value
+
+// Default возвращает логгер по умолчанию для кода, которому неудобно передавать
+// Logger явно. Пока SetDefault не вызывался, возвращает логгер с fmtProvider
+// и уровнем LevelInfo.
+func Default() Logger {
+	if h, ok := defaultLogger.Load().(defaultHolder); ok {
+		return h.logger
+	}
+	defaultLogger.CompareAndSwap(nil, defaultHolder{
+		logger: NewLoggerDefault(ProviderConfig{Level: LevelInfo}, NewFieldsHandler()),
+	})
+	return defaultLogger.Load().(defaultHolder).logger
+}
+
+// SetDefault заменяет логгер по умолчанию. Вызов с nil игнорируется.
+// В тестах вместо SetDefault используйте sglogtest.WithDefault,
+// которая восстанавливает предыдущий логгер по завершении теста.
+func SetDefault(l Logger) {
+	if l == nil {
+		return
+	}
+	defaultLogger.Store(defaultHolder{logger: l})
+}
@@ -2,84 +2,204 @@ package sglogger
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
+	"io"
+	"os"
 )
 
 // fmtProvider реализует LoggerProvider для вывода логов в стандартный вывод
 // с использованием пакета fmt. Подходит для разработки и отладки.
 type fmtProvider struct {
-	config ProviderConfig
+	closedState
+
+	config    ProviderConfig
+	formatter Formatter
+	out       io.Writer
+	buffer    *bufferedWriter
+	gate      *consoleGate
+	leakCheck *leakCheck
+	sizes     sizeHistogram
 }
 
 // NewFmtProvider создает новый экземпляр fmtProvider с заданной конфигурацией.
 // Возвращает интерфейс LoggerProvider для использования в системе логирования.
+// Если в конфигурации задан BufferSize, вывод буферизуется.
+// Уровень вне диапазона LevelDebug..LevelFatal приводится к ближайшей границе.
+// Строки формируются config.Formatter, по умолчанию - текстовым форматом
+// (см. NewTextFormatter); ссылки Hyperlinks выводятся, только если
+// стандартный вывод - терминал. Если текстовый формат настроен некорректно,
+// возвращается провайдер, каждая запись в который завершается ошибкой.
+// Провайдер реализует Pauser: на время паузы записи ниже LevelError
+// накапливаются (не более PauseBufferSize байт, сверх него запись завершается
+// ErrQueueFull) и выводятся при Resume, записи LevelError и выше выводятся сразу,
+// не дожидаясь накопленных.
 func NewFmtProvider(config ProviderConfig) LoggerProvider {
-	return &fmtProvider{
-		config: config,
+	return newFmtProvider(config, os.Stdout)
+}
+
+// NewFmtProviderWithWriter создает провайдер, как NewFmtProvider, но выводящий
+// записи в w, например в os.Stderr, файл или bytes.Buffer в тестах; nil
+// означает стандартный вывод. Строки записываются в w целиком и по одной,
+// поэтому записи из разных горутин не перемешиваются, а ошибка w.Write
+// возвращается из Write. Ссылки Hyperlinks выводятся, только если w -
+// терминал.
+func NewFmtProviderWithWriter(config ProviderConfig, w io.Writer) LoggerProvider {
+	if w == nil {
+		w = os.Stdout
+	}
+	return newFmtProvider(config, w)
+}
+
+// newFmtProvider создает fmtProvider, выводящий записи в out.
+func newFmtProvider(config ProviderConfig, out io.Writer) LoggerProvider {
+	if err := config.Validate(); err != nil {
+		return NewFailedProvider(err)
+	}
+	config = config.clone()
+	config.Level = clampLevel(config.Level)
+
+	formatter := config.Formatter
+	if formatter == nil {
+		// Последовательности ссылок не должны попадать в файлы и каналы.
+		if f, ok := out.(*os.File); config.Hyperlinks.Enabled && (!ok || !isTerminal(f)) {
+			config.Hyperlinks.Enabled = false
+		}
+		var err error
+		if formatter, err = NewTextFormatter(config); err != nil {
+			return NewFailedProvider(err)
+		}
+	}
+
+	p := &fmtProvider{
+		config:    config,
+		formatter: formatter,
+		out:       out,
+	}
+	if config.BufferSize > 0 {
+		p.buffer = newBufferedWriter(p.out, config.BufferSize, config.FlushInterval)
+		p.out = p.buffer
+		p.leakCheck = newLeakCheck(config.LeakCheck, p, "buffered provider "+p.Name(), newDiagnostics(config.Diagnostics))
 	}
+	p.gate = newConsoleGate(p.out, config.PauseBufferSize)
+	return p
 }
 
 // Write записывает лог-сообщение в стандартный вывод, если уровень логирования
 // соответствует конфигурации провайдера.
 func (p *fmtProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
 	if !p.ShouldLog(ctx, level) {
 		return nil
 	}
 
-	var levelStr string
-	switch level {
-	case LevelDebug:
-		levelStr = "debug"
-	case LevelInfo:
-		levelStr = "info"
-	case LevelWarn:
-		levelStr = "warning"
-	case LevelError:
-		levelStr = "error"
-	case LevelFatal:
-		levelStr = "critical"
+	bp, line := acquireLineBuffer()
+	line = p.formatter.AppendFormat(line, Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+
+	err := p.gate.write(level, line)
+	p.sizes.observe(len(line))
+	releaseLineBuffer(bp, line)
+	if err != nil {
+		return err
+	}
+
+	// Ошибки и критические сообщения не должны задерживаться в буфере
+	if p.buffer != nil && level >= LevelError {
+		return p.buffer.Flush()
 	}
 
-	fmt.Printf("[%s] %s \"%s\" %s\n", 
-		time.Now().Format("2006-01-02 15:04:05"),
-		levelStr,
-		message, 
-		serializeFields(fields),
-	)
+	return nil
+}
+
+// Pause приостанавливает вывод записей ниже LevelError (см. Pauser).
+func (p *fmtProvider) Pause() {
+	p.gate.pause()
+}
 
+// Resume возобновляет вывод и выводит записи, накопленные во время паузы.
+func (p *fmtProvider) Resume() error {
+	if err := p.gate.resume(); err != nil {
+		return err
+	}
+	if p.buffer != nil {
+		return p.buffer.Flush()
+	}
 	return nil
 }
 
+// SynchronizedWriter возвращает writer, запись в который не чередуется
+// со строками провайдера.
+func (p *fmtProvider) SynchronizedWriter(w io.Writer) io.Writer {
+	return &synchronizedWriter{gate: p.gate, w: w}
+}
+
+// EntrySizes возвращает гистограмму размеров выведенных строк.
+func (p *fmtProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// Name возвращает имя провайдера из конфигурации или "fmt" по умолчанию.
+func (p *fmtProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "fmt"
+}
+
+// Describe возвращает уровень, формат и параметры буферизации (см. Describer).
+func (p *fmtProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config)
+	description["format"] = describeFormatter(p.formatter)
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *fmtProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
 // ShouldLog определяет, нужно ли логировать сообщение данного уровня.
 // Использует минимальный уровень логирования из конфигурации провайдера.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
 func (p *fmtProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
 	return level >= p.config.Level
 }
 
-// Close реализует метод закрытия провайдера. 
-// В данной реализации не выполняет никаких действий, так как вывод в stdout
-// не требует очистки ресурсов.
-func (p *fmtProvider) Close(ctx context.Context) error {
-	return nil
+// Flush сбрасывает буферизованный вывод, если буферизация включена.
+func (p *fmtProvider) Flush(ctx context.Context) error {
+	if p.buffer == nil {
+		return nil
+	}
+	return p.buffer.Flush()
 }
 
-// serializeFields преобразует map полей в строку формата "key1=value1 key2=value2".
-// Строковые значения заключаются в кавычки, остальные выводятся как есть.
-func serializeFields(fields map[string]interface{}) string {
-	if len(fields) == 0 {
-		return ""
-	}
-	
-	var pairs []string
-	for k, v := range fields {
-		switch val := v.(type) {
-		case string:
-			pairs = append(pairs, fmt.Sprintf("%s=%q", k, val))
-		default:
-			pairs = append(pairs, fmt.Sprintf("%s=%v", k, val))
-		}
+// Close реализует метод закрытия провайдера. 
+// Выводит записи, накопленные во время паузы, и сбрасывает
+// буферизованный вывод, если буферизация включена;
+// сам stdout не закрывается.
+func (p *fmtProvider) Close(ctx context.Context) error {
+	if !p.markClosed() {
+		return nil
+	}
+	p.leakCheck.markClosed()
+	if err := p.gate.release(); err != nil {
+		return err
 	}
-	return "{" + strings.Join(pairs, " ") + "}"
-}
\ No newline at end of file
+	if p.buffer == nil {
+		return nil
+	}
+	return p.buffer.Close()
+}
@@ -0,0 +1,148 @@
+package sglogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AppendDeltaBatch добавляет к buf пакет записей в формате JSON, в котором
+// поля, одинаковые во всех записях пакета (service, env, host и другие
+// статические поля), вынесены в общий объект "resource", как в OTLP:
+//
+//	{"resource":{"service":"api","env":"prod"},
+//	 "entries":[{"ts":"<RFC3339Nano>","level":"info","msg":"...","key":value},...]}
+//
+// Записи кодируются как строки NewJSONFormatter без общих полей; поля
+// с зарезервированными именами ("ts", "level", "msg") получают префикс
+// "fields." и в resource, и в записях. Выносятся только поля со строковыми,
+// логическими и числовыми значениями, присутствующие во всех записях;
+// пакет из одной записи передается без resource. Получатель восстанавливает
+// записи функцией ParseDeltaBatch.
+func AppendDeltaBatch(buf []byte, entries []Entry, floats FloatFormat) []byte {
+	resource := commonFields(entries)
+
+	buf = append(buf, '{')
+	if len(resource) > 0 {
+		buf = append(buf, `"resource":{`...)
+		start := len(buf)
+		buf = appendJSONFields(buf, resource, floats, "ts", "level", "msg")
+		buf = append(buf[:start], buf[start+1:]...)
+		buf = append(buf, "},"...)
+	}
+	buf = append(buf, `"entries":[`...)
+
+	reserved := []string{"ts", "level", "msg"}
+	for i, e := range entries {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, `{"ts":"`...)
+		buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, `","level":`...)
+		buf = appendJSONString(buf, e.Level.String())
+		buf = append(buf, `,"msg":`...)
+		buf = appendJSONString(buf, e.Message)
+
+		var encodeErrors []string
+		for k, v := range e.Fields {
+			if _, ok := resource[k]; ok {
+				continue
+			}
+			buf, encodeErrors = appendJSONField(buf, encodeErrors, k, v, floats, false, reserved)
+		}
+		buf = appendJSONEncodeErrors(buf, encodeErrors)
+		buf = append(buf, '}')
+	}
+	return append(buf, "]}"...)
+}
+
+// ParseDeltaBatch восстанавливает записи пакета AppendDeltaBatch: к полям
+// каждой записи добавляются поля resource. Числа преобразуются так же,
+// как в ParseJSONLine.
+func ParseDeltaBatch(data []byte) ([]Entry, error) {
+	var batch struct {
+		Resource json.RawMessage   `json:"resource"`
+		Entries  []json.RawMessage `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("sglogger: parse delta batch: %w", err)
+	}
+
+	var resource map[string]interface{}
+	if len(batch.Resource) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(batch.Resource))
+		dec.UseNumber()
+		if err := dec.Decode(&resource); err != nil {
+			return nil, fmt.Errorf("sglogger: parse delta batch resource: %w", err)
+		}
+	}
+
+	entries := make([]Entry, 0, len(batch.Entries))
+	for i, raw := range batch.Entries {
+		e, err := ParseJSONLine(raw)
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: delta batch entry %d: %w", i, err)
+		}
+		if len(resource) > 0 && e.Fields == nil {
+			e.Fields = make(Fields, len(resource))
+		}
+		for k, v := range resource {
+			if name := strings.TrimPrefix(k, jsonReservedPrefix); name != k {
+				switch name {
+				case "ts", "level", "msg", FieldEncodeErrorField:
+					k = name
+				}
+			}
+			if _, ok := e.Fields[k]; !ok {
+				e.Fields[k] = jsonNumbers(v)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// commonFields возвращает поля с одинаковыми скалярными значениями во всех
+// записях или nil, если таких полей нет или записей меньше двух.
+func commonFields(entries []Entry) Fields {
+	if len(entries) < 2 {
+		return nil
+	}
+
+	var common Fields
+	for k, v := range entries[0].Fields {
+		if k == FieldEncodeErrorField || !scalarField(v) {
+			continue
+		}
+		shared := true
+		for _, e := range entries[1:] {
+			if other, ok := e.Fields[k]; !ok || !scalarField(other) || other != v {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			if common == nil {
+				common = make(Fields)
+			}
+			common[k] = v
+		}
+	}
+	return common
+}
+
+// scalarField сообщает, является ли значение строкой, логическим значением
+// или числом, то есть может ли оно безопасно сравниваться оператором ==.
+func scalarField(v interface{}) bool {
+	switch v.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return true
+	}
+	return false
+}
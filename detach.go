@@ -0,0 +1,60 @@
+package sglogger
+
+import (
+	"context"
+	"sync"
+)
+
+// detachedKeys содержит ключи контекста, которые копирует DetachFields.
+var detachedKeys = struct {
+	mu   sync.RWMutex
+	keys []interface{}
+}{keys: []interface{}{fieldsKey, TraceIDKey, TenantKey, minLevelKey}}
+
+// RegisterContextKey добавляет ключ контекста к значениям, которые DetachFields
+// переносит в отсоединенный контекст. Используется извлекателями полей из
+// контекста (например, WithTenantExtraction регистрирует свой ключ);
+// повторная регистрация ключа ничего не меняет. Ключ должен быть сравнимым,
+// как и любой ключ context.WithValue.
+func RegisterContextKey(key interface{}) {
+	if key == nil {
+		return
+	}
+
+	detachedKeys.mu.Lock()
+	defer detachedKeys.mu.Unlock()
+
+	for _, existing := range detachedKeys.keys {
+		if existing == key {
+			return
+		}
+	}
+	detachedKeys.keys = append(detachedKeys.keys, key)
+}
+
+// DetachFields возвращает новый фоновый контекст, в который скопированы
+// значения, используемые логгером: поля ContextWithFields и MDC, trace_id,
+// арендатор, переопределение уровня ContextWithMinLevel и ключи, добавленные
+// через RegisterContextKey. Отмена и дедлайн ctx не переносятся, поэтому
+// работа, запланированная во время запроса (например, в пуле обработчиков),
+// логирует с полями запроса и не прерывается после его завершения.
+//
+// Пример:
+//
+//	queue <- job{ctx: sglogger.DetachFields(r.Context()), item: item}
+func DetachFields(ctx context.Context) context.Context {
+	detached := context.Background()
+	if ctx == nil {
+		return detached
+	}
+
+	detachedKeys.mu.RLock()
+	defer detachedKeys.mu.RUnlock()
+
+	for _, key := range detachedKeys.keys {
+		if value := ctx.Value(key); value != nil {
+			detached = context.WithValue(detached, key, value)
+		}
+	}
+	return detached
+}
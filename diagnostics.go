@@ -0,0 +1,88 @@
+package sglogger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultDiagnosticsRate ограничивает количество диагностических строк в секунду по умолчанию.
+const defaultDiagnosticsRate = 10
+
+// stderrDiagnostics - общий диагностический канал для компонентов без DiagnosticsConfig.
+var stderrDiagnostics = &diagnostics{out: os.Stderr, rate: defaultDiagnosticsRate}
+
+// diagnostics выводит служебные сообщения пакета: отброшенные пакеты, повторы
+// запросов, вытеснение данных спула. Строки пишутся напрямую в Output,
+// без обращения к логгеру и его провайдерам, поэтому сбой конвейера логирования
+// не может привести к рекурсии. Количество строк ограничено rate в секунду;
+// количество пропущенных сообщений выводится в следующей строке.
+// Нулевой указатель отбрасывает сообщения.
+type diagnostics struct {
+	out        io.Writer
+	rate       int
+	window     time.Time
+	count      int
+	suppressed int
+	mu         sync.Mutex
+}
+
+// newDiagnostics создает диагностический канал по конфигурации.
+// Для nil возвращает общий канал в stderr, для Disabled - nil.
+func newDiagnostics(config *DiagnosticsConfig) *diagnostics {
+	if config == nil {
+		return stderrDiagnostics
+	}
+	if config.Disabled {
+		return nil
+	}
+
+	d := &diagnostics{out: config.Output, rate: config.Rate}
+	if d.out == nil {
+		d.out = os.Stderr
+	}
+	if d.rate <= 0 {
+		d.rate = defaultDiagnosticsRate
+	}
+	return d
+}
+
+// reportf выводит сообщение компонента source.
+func (d *diagnostics) reportf(source, format string, args ...interface{}) {
+	if d == nil {
+		return
+	}
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.window) >= time.Second {
+		d.window = now
+		d.count = 0
+	}
+	if d.count >= d.rate {
+		d.suppressed++
+		return
+	}
+	d.count++
+
+	line := make([]byte, 0, 128)
+	line = now.AppendFormat(line, time.RFC3339)
+	line = append(line, " sglogger diagnostics: "...)
+	line = append(line, source...)
+	line = append(line, ": "...)
+	line = fmt.Appendf(line, format, args...)
+	if d.suppressed > 0 {
+		line = append(line, " ("...)
+		line = strconv.AppendInt(line, int64(d.suppressed), 10)
+		line = append(line, " messages suppressed)"...)
+		d.suppressed = 0
+	}
+	line = append(line, '\n')
+
+	d.out.Write(line)
+}
@@ -0,0 +1,164 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DualFormatError возвращается NewDualFormatProvider, если запись хотя бы
+// в одно из мест назначения завершилась ошибкой. Нулевое поле означает,
+// что соответствующая запись выполнена.
+type DualFormatError struct {
+	Legacy  error
+	Primary error
+}
+
+// Error возвращает описание ошибки.
+func (e *DualFormatError) Error() string {
+	switch {
+	case e.Legacy != nil && e.Primary != nil:
+		return fmt.Sprintf("sglogger: legacy format: %v; primary format: %v", e.Legacy, e.Primary)
+	case e.Legacy != nil:
+		return fmt.Sprintf("sglogger: legacy format: %v", e.Legacy)
+	}
+	return fmt.Sprintf("sglogger: primary format: %v", e.Primary)
+}
+
+// Unwrap возвращает ошибки обоих мест назначения.
+func (e *DualFormatError) Unwrap() []error {
+	var errs []error
+	if e.Legacy != nil {
+		errs = append(errs, e.Legacy)
+	}
+	if e.Primary != nil {
+		errs = append(errs, e.Primary)
+	}
+	return errs
+}
+
+// dualFormatProvider записывает каждую запись в двух форматах.
+type dualFormatProvider struct {
+	closedState
+
+	config        DualFormatConfig
+	legacyFormat  Formatter
+	primaryFormat Formatter
+	mu            sync.Mutex
+}
+
+// NewDualFormatProvider создает провайдер для перехода между форматами логов:
+// каждая запись выводится в прежнем формате (по умолчанию текстовом) в Legacy
+// и в новом (по умолчанию JSON) в Primary. Обе строки формируются до записи
+// и выводятся под одной блокировкой, поэтому порядок записей в обоих местах
+// назначения совпадает. Если хотя бы одна запись не удалась, возвращается
+// *DualFormatError с указанием стороны; другая сторона при этом записывается.
+// После перехода LegacyEnabled отключает прежний формат без пересоздания логгера.
+// Возвращает ошибку, если не задано одно из мест назначения или текстовый формат
+// настроен некорректно.
+func NewDualFormatProvider(config DualFormatConfig) (LoggerProvider, error) {
+	if config.Legacy == nil || config.Primary == nil {
+		return nil, fmt.Errorf("sglogger: dual format provider requires legacy and primary writers")
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+
+	p := &dualFormatProvider{
+		config:        config,
+		legacyFormat:  config.LegacyFormat,
+		primaryFormat: config.PrimaryFormat,
+	}
+	if p.legacyFormat == nil {
+		// Устаревший формат читают парсеры, а не терминал, поэтому ссылки отключаются.
+		textConfig := config.ProviderConfig
+		textConfig.Hyperlinks.Enabled = false
+		var err error
+		if p.legacyFormat, err = NewTextFormatter(textConfig); err != nil {
+			return nil, err
+		}
+	}
+	if p.primaryFormat == nil {
+		p.primaryFormat = NewJSONFormatter(config.ProviderConfig)
+	}
+	return p, nil
+}
+
+// Write выводит запись в обоих форматах.
+func (p *dualFormatProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+
+	e := Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	}
+	legacy := p.config.LegacyEnabled == nil || p.config.LegacyEnabled()
+
+	primaryBP, primaryLine := acquireLineBuffer()
+	primaryLine = p.primaryFormat.AppendFormat(primaryLine, e)
+	defer func() { releaseLineBuffer(primaryBP, primaryLine) }()
+
+	var legacyBP *[]byte
+	var legacyLine []byte
+	if legacy {
+		legacyBP, legacyLine = acquireLineBuffer()
+		legacyLine = p.legacyFormat.AppendFormat(legacyLine, e)
+		defer func() { releaseLineBuffer(legacyBP, legacyLine) }()
+	}
+
+	var result DualFormatError
+	p.mu.Lock()
+	if legacy {
+		_, result.Legacy = p.config.Legacy.Write(legacyLine)
+	}
+	_, result.Primary = p.config.Primary.Write(primaryLine)
+	p.mu.Unlock()
+
+	if result.Legacy != nil || result.Primary != nil {
+		return &result
+	}
+	return nil
+}
+
+// Name возвращает имя провайдера из конфигурации или "dual-format" по умолчанию.
+func (p *dualFormatProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "dual-format"
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *dualFormatProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *dualFormatProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Close прекращает прием записей; места назначения принадлежат вызывающему
+// и не закрываются.
+func (p *dualFormatProvider) Close(ctx context.Context) error {
+	p.markClosed()
+	return nil
+}
@@ -0,0 +1,60 @@
+package sglogger
+
+import (
+	"strings"
+	"time"
+)
+
+// durationFields применяет DurationPolicy к значениям time.Duration верхнего
+// уровня. Если преобразования не нужны, возвращает исходные поля без копирования.
+func durationFields(fields Fields, policy DurationPolicy) Fields {
+	if policy == DurationAsIs {
+		return fields
+	}
+
+	needsCopy := false
+	for _, v := range fields {
+		if _, ok := v.(time.Duration); ok {
+			needsCopy = true
+			break
+		}
+	}
+	if !needsCopy {
+		return fields
+	}
+
+	result := make(Fields, len(fields))
+	for k, v := range fields {
+		if _, ok := v.(time.Duration); !ok {
+			result[k] = v
+		}
+	}
+	for k, v := range fields {
+		d, ok := v.(time.Duration)
+		if !ok {
+			continue
+		}
+		switch policy {
+		case DurationString:
+			result[k] = d.String()
+		case DurationMillis:
+			setDurationField(result, k, "_ms", float64(d)/float64(time.Millisecond))
+		case DurationSeconds:
+			setDurationField(result, k, "_s", d.Seconds())
+		default:
+			result[k] = d
+		}
+	}
+	return result
+}
+
+// setDurationField записывает длительность под ключом с суффиксом единицы,
+// не заменяя поле, уже заданное под этим ключом.
+func setDurationField(fields Fields, key, suffix string, value float64) {
+	if !strings.HasSuffix(key, suffix) {
+		key += suffix
+	}
+	if _, ok := fields[key]; !ok {
+		fields[key] = value
+	}
+}
@@ -0,0 +1,133 @@
+package sglogger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DurationPolicy
+		fields Fields
+		want   Fields
+	}{
+		{
+			name:   "as is",
+			policy: DurationAsIs,
+			fields: Fields{"took": 1500 * time.Millisecond},
+			want:   Fields{"took": 1500 * time.Millisecond},
+		},
+		{
+			name:   "string",
+			policy: DurationString,
+			fields: Fields{"took": 1500 * time.Millisecond, "status": 200},
+			want:   Fields{"took": "1.5s", "status": 200},
+		},
+		{
+			name:   "millis renames key",
+			policy: DurationMillis,
+			fields: Fields{"took": 1500 * time.Millisecond},
+			want:   Fields{"took_ms": 1500.0},
+		},
+		{
+			name:   "millis keeps existing suffix",
+			policy: DurationMillis,
+			fields: Fields{"wait_ms": 250 * time.Microsecond},
+			want:   Fields{"wait_ms": 0.25},
+		},
+		{
+			name:   "seconds",
+			policy: DurationSeconds,
+			fields: Fields{"took": 1500 * time.Millisecond},
+			want:   Fields{"took_s": 1.5},
+		},
+		{
+			name:   "suffixed key already set",
+			policy: DurationMillis,
+			fields: Fields{"took": time.Second, "took_ms": "explicit"},
+			want:   Fields{"took_ms": "explicit"},
+		},
+		{
+			name:   "nested durations untouched",
+			policy: DurationMillis,
+			fields: Fields{"db": Fields{"took": time.Second}},
+			want:   Fields{"db": Fields{"took": time.Second}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := cloneFields(tt.fields)
+			if got := durationFields(tt.fields, tt.policy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("durationFields = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.fields, original) {
+				t.Errorf("input fields modified: %v", tt.fields)
+			}
+		})
+	}
+}
+
+func TestDurationPolicyFormats(t *testing.T) {
+	entry := Entry{
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "done",
+		Fields:  Fields{"took": 1500 * time.Millisecond, "status": 200},
+	}
+
+	tests := []struct {
+		name     string
+		policy   DurationPolicy
+		wantText []string
+		wantJSON string
+	}{
+		{
+			name:     "as is",
+			policy:   DurationAsIs,
+			wantText: []string{"took=1.5s", "status=200"},
+			wantJSON: `{"ts":"2024-05-01T12:00:00.000000000Z","level":"info","msg":"done","status":200,"took":1500000000}`,
+		},
+		{
+			name:     "string",
+			policy:   DurationString,
+			wantText: []string{`took="1.5s"`, "status=200"},
+			wantJSON: `{"ts":"2024-05-01T12:00:00.000000000Z","level":"info","msg":"done","status":200,"took":"1.5s"}`,
+		},
+		{
+			name:     "millis",
+			policy:   DurationMillis,
+			wantText: []string{"took_ms=1500", "status=200"},
+			wantJSON: `{"ts":"2024-05-01T12:00:00.000000000Z","level":"info","msg":"done","status":200,"took_ms":1500}`,
+		},
+		{
+			name:     "seconds",
+			policy:   DurationSeconds,
+			wantText: []string{"took_s=1.5", "status=200"},
+			wantJSON: `{"ts":"2024-05-01T12:00:00.000000000Z","level":"info","msg":"done","status":200,"took_s":1.5}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := NewTextFormatter(ProviderConfig{Durations: tt.policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			line := string(text.AppendFormat(nil, entry))
+			for _, want := range tt.wantText {
+				if !strings.Contains(line, want) {
+					t.Errorf("text line %q does not contain %q", line, want)
+				}
+			}
+
+			json := NewJSONFormatter(ProviderConfig{Durations: tt.policy, JSON: JSONFormat{Deterministic: true}})
+			if got := strings.TrimSuffix(string(json.AppendFormat(nil, entry)), "\n"); got != tt.wantJSON {
+				t.Errorf("JSON line = %s, want %s", got, tt.wantJSON)
+			}
+		})
+	}
+}
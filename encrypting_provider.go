@@ -0,0 +1,185 @@
+package sglogger
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownKey возвращается Decrypt, если для идентификатора ключа нет ключа.
+var ErrUnknownKey = errors.New("sglogger: unknown encryption key id")
+
+// encryptingProvider шифрует каждую запись перед передачей внутреннему провайдеру.
+// Запись сериализуется в JSON и запечатывается AES-GCM; внутренний провайдер
+// получает сообщение вида "<key-id>:<base64(nonce|ciphertext)>" без полей.
+// Уровень записи передается в открытом виде, чтобы внутренний провайдер
+// мог выполнять фильтрацию.
+type encryptingProvider struct {
+	closedState
+
+	inner  LoggerProvider
+	config EncryptionConfig
+	aead   cipher.AEAD
+}
+
+// NewEncryptingProvider создает обертку, шифрующую записи для передачи через
+// недоверенные каналы. Возвращает ошибку при некорректном ключе или идентификаторе.
+// Для чтения зашифрованных записей используется Decrypt.
+func NewEncryptingProvider(inner LoggerProvider, config EncryptionConfig) (LoggerProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("sglogger: encrypting provider requires an inner provider")
+	}
+	if config.KeyID == "" || strings.Contains(config.KeyID, ":") {
+		return nil, fmt.Errorf("sglogger: invalid encryption key id %q", config.KeyID)
+	}
+
+	aead, err := newAEAD(config.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptingProvider{
+		inner:  inner,
+		config: config,
+		aead:   aead,
+	}, nil
+}
+
+// Write шифрует запись и передает ее внутреннему провайдеру.
+func (p *encryptingProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	sealed, err := p.seal(Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+	if err != nil {
+		return err
+	}
+	return p.inner.Write(ctx, level, sealed, nil)
+}
+
+// WriteBatch шифрует записи пакета и передает их внутреннему провайдеру
+// через WriteEntries, сохраняя время создания записей.
+func (p *encryptingProvider) WriteBatch(ctx context.Context, entries []Entry) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	sealed := make([]Entry, len(entries))
+	for i, e := range entries {
+		message, err := p.seal(e)
+		if err != nil {
+			return err
+		}
+		sealed[i] = Entry{Time: e.Time, Level: e.Level, Message: message}
+	}
+
+	written, err := WriteEntries(ctx, p.inner, sealed)
+	if err != nil && written > 0 {
+		return &BatchError{Written: written, Err: err}
+	}
+	return err
+}
+
+// seal сериализует запись и запечатывает ее в формат "<key-id>:<base64(nonce|ciphertext)>".
+func (p *encryptingProvider) seal(e Entry) (string, error) {
+	e.Fields = jsonSafeFields(e.Fields, FloatFormat{})
+	plaintext, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("sglogger: encode entry: %w", err)
+	}
+
+	nonce := make([]byte, p.aead.NonceSize(), p.aead.NonceSize()+len(plaintext)+p.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("sglogger: generate nonce: %w", err)
+	}
+	sealed := p.aead.Seal(nonce, nonce, plaintext, []byte(p.config.KeyID))
+
+	return p.config.KeyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Name возвращает имя из конфигурации или имя внутреннего провайдера.
+func (p *encryptingProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return ProviderName(p.inner)
+}
+
+// Active делегирует проверку активности внутреннему провайдеру.
+func (p *encryptingProvider) Active() bool {
+	return providerActive(p.inner)
+}
+
+// ShouldLog делегирует проверку уровня внутреннему провайдеру.
+func (p *encryptingProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.inner.ShouldLog(ctx, level)
+}
+
+// Close прекращает прием записей и закрывает внутренний провайдер (см. ChainClose).
+func (p *encryptingProvider) Close(ctx context.Context) error {
+	if !p.markClosed() {
+		return nil
+	}
+	return ChainClose(ctx, p.inner, nil)
+}
+
+// Decrypt расшифровывает запись, созданную encryptingProvider.
+// keys сопоставляет идентификаторы ключей с ключами, что позволяет читать
+// архивы, записанные до ротации ключа.
+func Decrypt(keys map[string][]byte, sealed string) (Entry, error) {
+	keyID, payload, ok := strings.Cut(strings.TrimSpace(sealed), ":")
+	if !ok {
+		return Entry{}, fmt.Errorf("sglogger: malformed encrypted entry")
+	}
+
+	key, ok := keys[keyID]
+	if !ok {
+		return Entry{}, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return Entry{}, fmt.Errorf("sglogger: decode encrypted entry: %w", err)
+	}
+	if len(data) < aead.NonceSize() {
+		return Entry{}, fmt.Errorf("sglogger: encrypted entry is too short")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return Entry{}, fmt.Errorf("sglogger: decrypt entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(plaintext, &entry); err != nil {
+		return Entry{}, fmt.Errorf("sglogger: decode entry: %w", err)
+	}
+	return entry, nil
+}
+
+// newAEAD создает AES-GCM для ключа длиной 16, 24 или 32 байта.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("sglogger: invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
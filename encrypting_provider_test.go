@@ -0,0 +1,135 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestEncryptingProvider создает шифрующую обертку над recordingProvider.
+func newTestEncryptingProvider(t *testing.T, keyID string, key []byte) (LoggerProvider, *recordingProvider) {
+	t.Helper()
+
+	inner := &recordingProvider{}
+	provider, err := NewEncryptingProvider(inner, EncryptionConfig{KeyID: keyID, Key: key})
+	if err != nil {
+		t.Fatalf("NewEncryptingProvider: %v", err)
+	}
+	return provider, inner
+}
+
+func TestEncryptingProviderRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	provider, inner := newTestEncryptingProvider(t, "2024-05", key)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ctx := ContextWithEntryTime(context.Background(), at)
+	large := strings.Repeat("payload ", 128*1024)
+	for _, fields := range []Fields{
+		{"user": "alice"},
+		{"body": large},
+	} {
+		if err := provider.Write(ctx, LevelWarn, "request", fields); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	entries := inner.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("inner provider received %d entries, want 2", len(entries))
+	}
+	for i, want := range []string{"alice", large} {
+		sealed := entries[i]
+		if sealed.Fields != nil || !strings.HasPrefix(sealed.Message, "2024-05:") || strings.Contains(sealed.Message, "request") {
+			t.Errorf("inner entry %d is not sealed: %.80q", i, sealed.Message)
+		}
+		e, err := Decrypt(map[string][]byte{"2024-05": key}, sealed.Message)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		got := e.Fields["user"]
+		if i == 1 {
+			got = e.Fields["body"]
+		}
+		if e.Message != "request" || e.Level != LevelWarn || !e.Time.Equal(at) || got != want {
+			t.Errorf("decrypted entry %d = %s %s %v, fields differ", i, e.Level, e.Message, e.Time)
+		}
+	}
+}
+
+func TestEncryptingProviderKeyRotation(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 16)
+	oldProvider, oldInner := newTestEncryptingProvider(t, "old", oldKey)
+	newProvider, newInner := newTestEncryptingProvider(t, "new", newKey)
+
+	ctx := context.Background()
+	oldProvider.Write(ctx, LevelInfo, "before rotation", nil)
+	newProvider.Write(ctx, LevelInfo, "after rotation", nil)
+
+	// Архив читается набором ключей, включающим прежний
+	keys := map[string][]byte{"old": oldKey, "new": newKey}
+	for inner, want := range map[*recordingProvider]string{oldInner: "before rotation", newInner: "after rotation"} {
+		e, err := Decrypt(keys, inner.Entries()[0].Message)
+		if err != nil || e.Message != want {
+			t.Errorf("Decrypt = %q, %v, want %q", e.Message, err, want)
+		}
+	}
+
+	if _, err := Decrypt(map[string][]byte{"new": newKey}, oldInner.Entries()[0].Message); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt without the old key = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, 32)
+	provider, inner := newTestEncryptingProvider(t, "k1", key)
+	provider.Write(context.Background(), LevelError, "payment failed", nil)
+	sealed := inner.Entries()[0].Message
+
+	keys := map[string][]byte{"k1": key, "k2": key}
+	payload := []byte(sealed)
+	payload[len(payload)-2] ^= 1
+	// Идентификатор ключа входит в дополнительные данные AEAD
+	relabeled := "k2" + strings.TrimPrefix(sealed, "k1")
+	for name, input := range map[string]string{
+		"modified ciphertext": string(payload),
+		"relabeled key id":    relabeled,
+		"missing key id":      strings.TrimPrefix(sealed, "k1:"),
+		"short payload":       "k1:AAAA",
+	} {
+		if _, err := Decrypt(keys, input); err == nil {
+			t.Errorf("Decrypt accepted %s", name)
+		}
+	}
+}
+
+func TestEncryptingProviderWriteBatch(t *testing.T) {
+	key := bytes.Repeat([]byte{4}, 24)
+	provider, inner := newTestEncryptingProvider(t, "batch", key)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: at, Level: LevelInfo, Message: "first"},
+		{Time: at.Add(time.Second), Level: LevelError, Message: "second", Fields: Fields{"order": "42"}},
+	}
+	if err := provider.(BatchWriter).WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+
+	sealed := inner.Entries()
+	if len(sealed) != len(entries) {
+		t.Fatalf("inner provider received %d entries, want %d", len(sealed), len(entries))
+	}
+	for i, want := range entries {
+		e, err := Decrypt(map[string][]byte{"batch": key}, sealed[i].Message)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if e.Message != want.Message || e.Level != want.Level || !e.Time.Equal(want.Time) || sealed[i].Level != want.Level {
+			t.Errorf("entry %d = %+v, want %+v", i, e, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Enricher возвращает поля, добавляемые к записи, например центр обработки
+// данных, кластер или центр затрат. fields - поля записи, их нельзя изменять.
+// Поля записи имеют приоритет над полями обогатителя.
+// Вызывается для каждой записи, поэтому должен выполняться быстро.
+type Enricher func(ctx context.Context, level Level, message string, fields Fields) Fields
+
+// enricherRegistry хранит обогатители, зарегистрированные через RegisterEnricher.
+var enricherRegistry = struct {
+	mu        sync.RWMutex
+	enrichers map[string]Enricher
+}{enrichers: make(map[string]Enricher)}
+
+// RegisterEnricher регистрирует обогатитель под именем name для выбора через
+// LoggerConfig.Enrichers. Предназначен для вызова из init пакета обогатителя,
+// чтобы сервису было достаточно импортировать пакет:
+//
+//	func init() {
+//		if err := sglogger.RegisterEnricher("company", enrich); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Возвращает ошибку, если имя пустое, обогатитель не задан или имя уже занято.
+func RegisterEnricher(name string, enricher Enricher) error {
+	if name == "" {
+		return fmt.Errorf("sglogger: enricher name is empty")
+	}
+	if enricher == nil {
+		return fmt.Errorf("sglogger: enricher %q is nil", name)
+	}
+
+	enricherRegistry.mu.Lock()
+	defer enricherRegistry.mu.Unlock()
+
+	if _, ok := enricherRegistry.enrichers[name]; ok {
+		return fmt.Errorf("sglogger: enricher %q is already registered", name)
+	}
+	enricherRegistry.enrichers[name] = enricher
+	return nil
+}
+
+// RegisteredEnrichers возвращает отсортированные имена зарегистрированных обогатителей.
+func RegisteredEnrichers() []string {
+	enricherRegistry.mu.RLock()
+	defer enricherRegistry.mu.RUnlock()
+
+	return sortedKeys(enricherRegistry.enrichers)
+}
+
+// resolveEnrichers возвращает обогатители с именами names в том же порядке.
+// Для незарегистрированного имени возвращает ErrUnknownEnricher и обогатители
+// остальных имен.
+func resolveEnrichers(names []string) ([]Enricher, error) {
+	enricherRegistry.mu.RLock()
+	defer enricherRegistry.mu.RUnlock()
+
+	var enrichers []Enricher
+	var err error
+	for _, name := range names {
+		enricher, ok := enricherRegistry.enrichers[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("%w: %q (registered: %v)", ErrUnknownEnricher, name, sortedKeys(enricherRegistry.enrichers))
+			}
+			continue
+		}
+		enrichers = append(enrichers, enricher)
+	}
+	return enrichers, err
+}
+
+// sortedKeys возвращает отсортированные имена обогатителей.
+func sortedKeys(m map[string]Enricher) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// enrich добавляет к полям записи поля обогатителей в порядке
+// LoggerConfig.Enrichers; поля записи и более ранних обогатителей имеют приоритет.
+func (l *logger) enrich(ctx context.Context, level Level, message string, fields Fields) Fields {
+	for _, enricher := range l.enrichers {
+		if extra := enricher(ctx, level, message, fields); len(extra) > 0 {
+			fields = l.mergeFields(extra, fields)
+		}
+	}
+	return fields
+}
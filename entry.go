@@ -0,0 +1,99 @@
+package sglogger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry представляет отдельную запись лога вместе со временем ее создания.
+// Используется провайдерами, которым необходимо сохранять или пересылать
+// записи целиком (например, дисковый спул).
+//
+// Набор полей записи общий для всех провайдеров логгера: провайдер или обертка,
+// которым нужно изменить поля (маскирование, нормализация), должны сначала
+// получить копию через Clone. Сборка с тегом sglogger_debug проверяет это правило.
+type Entry struct {
+	Time    time.Time `json:"ts"`
+	Level   Level     `json:"level"`
+	Message string    `json:"msg"`
+	Fields  Fields    `json:"fields,omitempty"`
+}
+
+// Clone возвращает копию записи, поля которой можно изменять, не затрагивая
+// исходную запись. Копируются набор полей и вложенные значения типа Fields;
+// остальные значения (срезы, указатели, другие карты) остаются общими.
+func (e Entry) Clone() Entry {
+	e.Fields = cloneFields(e.Fields)
+	return e
+}
+
+// cloneFields копирует набор полей и вложенные наборы Fields.
+func cloneFields(fields Fields) Fields {
+	if fields == nil {
+		return nil
+	}
+	result := make(Fields, len(fields))
+	for k, v := range fields {
+		if nested, ok := v.(Fields); ok {
+			v = cloneFields(nested)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// FieldEncodeErrorField - поле, в которое записываются ошибки сериализации
+// значений полей в JSON.
+const FieldEncodeErrorField = "field_encode_error"
+
+// EncodeErrorField - поле минимальной записи, которую формат JSON выводит
+// вместо записи, которую не удалось сформировать целиком.
+const EncodeErrorField = "encode_error"
+
+// jsonSafeFields возвращает копию полей, пригодную для сериализации в JSON,
+// поэтому одно некорректное значение никогда не приводит к потере всей записи.
+// Числа с плавающей точкой выводятся согласно format. Значения, которые не
+// удается сериализовать (каналы, функции, NaN и ±Inf), заменяются их строковым
+// представлением в формате %v, в циклических и слишком глубоких значениях
+// вложенные значения заменяются на "<max depth>", а ошибки записываются
+// в поле FieldEncodeErrorField.
+func jsonSafeFields(fields Fields, format FloatFormat) Fields {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	result := make(Fields, len(fields))
+	var encodeErrors []string
+	for k, v := range fields {
+		var err error
+		switch val := v.(type) {
+		case float64:
+			if result[k], err = jsonFloat(val, 64, format); err == nil {
+				continue
+			}
+		case float32:
+			if result[k], err = jsonFloat(float64(val), 32, format); err == nil {
+				continue
+			}
+		default:
+			var truncated bool
+			if v, truncated = boundJSONDepth(v); truncated {
+				encodeErrors = append(encodeErrors, k+": "+errJSONMaxDepth.Error())
+			}
+			if _, err = safeMarshal(v); err == nil {
+				result[k] = v
+				continue
+			}
+		}
+		result[k] = fmt.Sprintf("%v", v)
+		encodeErrors = append(encodeErrors, k+": "+err.Error())
+	}
+
+	if len(encodeErrors) > 0 {
+		sort.Strings(encodeErrors)
+		result[FieldEncodeErrorField] = strings.Join(encodeErrors, "; ")
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package sglogger
+
+import (
+	"context"
+	"time"
+)
+
+// OriginalTimeField содержит исходное время записи, замененное
+// при отправке из-за HTTPBatchConfig.SkewAdjust.
+const OriginalTimeField = "original_ts"
+
+// ContextWithEntryTime возвращает контекст, в котором провайдеры используют t
+// как время записи вместо момента вызова Write. Через него передается время
+// записей, доставляемых повторно (спул, WriteEntries), чтобы они сохраняли
+// время возникновения, а не время доставки.
+func ContextWithEntryTime(ctx context.Context, t time.Time) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, entryTimeKey, t)
+}
+
+// EntryTimeFromContext возвращает время записи, заданное ContextWithEntryTime.
+func EntryTimeFromContext(ctx context.Context) (time.Time, bool) {
+	if ctx == nil {
+		return time.Time{}, false
+	}
+	t, ok := ctx.Value(entryTimeKey).(time.Time)
+	return t, ok
+}
+
+// entryTime возвращает время записи из контекста или текущее время.
+// Провайдеры используют его вместо time.Now.
+func entryTime(ctx context.Context) time.Time {
+	if t, ok := EntryTimeFromContext(ctx); ok {
+		return t
+	}
+	return time.Now()
+}
+
+// adjustSkew заменяет время записей старше maxAge текущим временем,
+// сохраняя исходное время в поле OriginalTimeField. Исходный срез и поля
+// записей не изменяются: при необходимости они копируются.
+func adjustSkew(entries []Entry, maxAge time.Duration) []Entry {
+	if maxAge <= 0 {
+		return entries
+	}
+
+	now := time.Now()
+	copied := false
+	for i, e := range entries {
+		if now.Sub(e.Time) <= maxAge {
+			continue
+		}
+		if !copied {
+			entries = append([]Entry(nil), entries...)
+			copied = true
+		}
+		fields := make(Fields, len(e.Fields)+1)
+		for k, v := range e.Fields {
+			fields[k] = v
+		}
+		fields[OriginalTimeField] = e.Time.Format(time.RFC3339Nano)
+		entries[i].Fields = fields
+		entries[i].Time = now
+	}
+	return entries
+}
@@ -0,0 +1,64 @@
+package sglogger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAdjustSkew(t *testing.T) {
+	recent := time.Now().Add(-time.Minute)
+	old := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		maxAge       time.Duration
+		entry        Entry
+		wantAdjusted bool
+	}{
+		{name: "disabled", maxAge: 0, entry: Entry{Time: old, Message: "old"}},
+		{name: "recent entry kept", maxAge: time.Hour, entry: Entry{Time: recent, Message: "recent"}},
+		{name: "old entry clamped", maxAge: time.Hour, entry: Entry{Time: old, Message: "old", Fields: Fields{"user": "bob"}}, wantAdjusted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []Entry{tt.entry}
+			before := time.Now()
+			got := adjustSkew(input, tt.maxAge)[0]
+
+			if !reflect.DeepEqual(input[0], tt.entry) {
+				t.Errorf("input entry modified: %+v", input[0])
+			}
+			if !tt.wantAdjusted {
+				if !reflect.DeepEqual(got, tt.entry) {
+					t.Errorf("entry = %+v, want unchanged", got)
+				}
+				return
+			}
+			if got.Time.Before(before) {
+				t.Errorf("time = %v, want the send time", got.Time)
+			}
+			if got.Fields[OriginalTimeField] != old.Format(time.RFC3339Nano) {
+				t.Errorf("%s = %v, want %s", OriginalTimeField, got.Fields[OriginalTimeField], old.Format(time.RFC3339Nano))
+			}
+			if got.Fields["user"] != "bob" {
+				t.Errorf("fields = %v, want the original fields kept", got.Fields)
+			}
+		})
+	}
+}
+
+func TestEntryTimeFromContext(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if got := entryTime(ContextWithEntryTime(context.Background(), at)); !got.Equal(at) {
+		t.Errorf("entryTime = %v, want %v", got, at)
+	}
+	if _, ok := EntryTimeFromContext(context.Background()); ok {
+		t.Error("EntryTimeFromContext reported a time for a plain context")
+	}
+	if got := entryTime(context.Background()); time.Since(got) > time.Minute {
+		t.Errorf("entryTime without override = %v, want now", got)
+	}
+}
@@ -0,0 +1,244 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultErrorBudgetMaxComponents ограничивает количество отдельно
+	// учитываемых компонентов по умолчанию.
+	defaultErrorBudgetMaxComponents = 100
+
+	// errorBudgetResolution - длительность одного интервала подсчета.
+	errorBudgetResolution = 10 * time.Second
+
+	// errorBudgetBuckets - количество интервалов, покрывающее наибольшее окно (1 час).
+	errorBudgetBuckets = int(time.Hour / errorBudgetResolution)
+
+	// ErrorBudgetOther - компонент, под которым учитываются записи компонентов
+	// сверх ErrorBudgetConfig.MaxComponents.
+	ErrorBudgetOther = "other"
+
+	// ErrorBudgetUnknown - компонент записей без поля компонента.
+	ErrorBudgetUnknown = "unknown"
+)
+
+// errorBudgetWindowLabels - обозначения окон ErrorCounts в метриках Prometheus.
+var errorBudgetWindowLabels = [...]string{"1m", "5m", "1h"}
+
+// ErrorCounts содержит количество записей уровня LevelError и выше
+// за скользящие окна.
+type ErrorCounts struct {
+	LastMinute   uint64
+	Last5Minutes uint64
+	LastHour     uint64
+}
+
+// errorCounter считает записи по интервалам errorBudgetResolution в кольце,
+// покрывающем час. epochs хранит номер интервала, к которому относится
+// счетчик, чтобы не учитывать устаревшие значения; last - последний
+// интервал с записью.
+type errorCounter struct {
+	counts [errorBudgetBuckets]uint64
+	epochs [errorBudgetBuckets]int64
+	last   int64
+}
+
+// add учитывает запись в интервале epoch. Запись интервала, место которого
+// в кольце уже занято более поздним интервалом, не учитывается.
+func (c *errorCounter) add(epoch int64) {
+	i := int(epoch % int64(errorBudgetBuckets))
+	if c.epochs[i] > epoch {
+		return
+	}
+	if c.epochs[i] != epoch {
+		c.epochs[i] = epoch
+		c.counts[i] = 0
+	}
+	c.counts[i]++
+	if epoch > c.last {
+		c.last = epoch
+	}
+}
+
+// sum возвращает количество записей за n последних интервалов, включая epoch.
+func (c *errorCounter) sum(epoch int64, n int) uint64 {
+	var total uint64
+	for e := epoch; e > epoch-int64(n); e-- {
+		i := int(e % int64(errorBudgetBuckets))
+		if c.epochs[i] == e {
+			total += c.counts[i]
+		}
+	}
+	return total
+}
+
+// ErrorBudgetProvider считает записи уровня LevelError и выше по компонентам
+// за скользящие окна 1 минута, 5 минут и 1 час с точностью 10 секунд - входные
+// данные для оповещений о расходе бюджета ошибок без разбора текста логов.
+type ErrorBudgetProvider struct {
+	closedState
+
+	config     ErrorBudgetConfig
+	components map[string]*errorCounter
+	mu         sync.Mutex
+}
+
+// NewErrorBudgetProvider создает провайдер, считающий ошибки по значению поля
+// config.Field. Количество учитываемых компонентов ограничено MaxComponents;
+// записи остальных компонентов учитываются под ErrorBudgetOther (место
+// компонента без ошибок за последний час освобождается), записи без
+// поля - под ErrorBudgetUnknown. Счетчики доступны через Snapshot и в формате
+// Prometheus через WritePrometheus. Как и NewRingBufferProvider, возвращает
+// конкретный тип.
+func NewErrorBudgetProvider(config ErrorBudgetConfig) (*ErrorBudgetProvider, error) {
+	if config.MaxComponents < 0 {
+		return nil, fmt.Errorf("sglogger: negative error budget component limit %d", config.MaxComponents)
+	}
+	if config.Field == "" {
+		config.Field = defaultNameField
+	}
+	if config.MaxComponents == 0 {
+		config.MaxComponents = defaultErrorBudgetMaxComponents
+	}
+	return &ErrorBudgetProvider{
+		config:     config,
+		components: make(map[string]*errorCounter),
+	}, nil
+}
+
+// Write учитывает запись уровня LevelError и выше.
+func (p *ErrorBudgetProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if level < LevelError {
+		return nil
+	}
+
+	component := ErrorBudgetUnknown
+	if v, ok := fields[p.config.Field]; ok {
+		if s := fieldString(v); s != "" {
+			component = s
+		}
+	}
+	epoch := entryTime(ctx).UnixNano() / int64(errorBudgetResolution)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.components[component]
+	if !ok {
+		// Компонент ErrorBudgetOther не занимает место в лимите.
+		tracked := len(p.components)
+		if _, ok := p.components[ErrorBudgetOther]; ok {
+			tracked--
+		}
+		if tracked >= p.config.MaxComponents && !p.reclaim(epoch) {
+			component = ErrorBudgetOther
+		}
+		if c, ok = p.components[component]; !ok {
+			c = &errorCounter{}
+			p.components[component] = c
+		}
+	}
+	c.add(epoch)
+	return nil
+}
+
+// reclaim освобождает место компонента без записей за последний час.
+// Вызывается с удерживаемой блокировкой.
+func (p *ErrorBudgetProvider) reclaim(epoch int64) bool {
+	for component, c := range p.components {
+		if component != ErrorBudgetOther && epoch-c.last >= int64(errorBudgetBuckets) {
+			delete(p.components, component)
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot возвращает количество ошибок по компонентам за каждое окно.
+// Компоненты без ошибок за последний час не включаются.
+func (p *ErrorBudgetProvider) Snapshot() map[string]ErrorCounts {
+	epoch := time.Now().UnixNano() / int64(errorBudgetResolution)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make(map[string]ErrorCounts, len(p.components))
+	for component, c := range p.components {
+		counts := ErrorCounts{
+			LastMinute:   c.sum(epoch, int(time.Minute/errorBudgetResolution)),
+			Last5Minutes: c.sum(epoch, int(5*time.Minute/errorBudgetResolution)),
+			LastHour:     c.sum(epoch, errorBudgetBuckets),
+		}
+		if counts.LastHour > 0 {
+			snapshot[component] = counts
+		}
+	}
+	return snapshot
+}
+
+// WritePrometheus записывает счетчики Snapshot в текстовом формате Prometheus
+// как метрику sglogger_error_entries с метками component и window ("1m", "5m",
+// "1h"), например из обработчика /metrics или коллектора.
+func (p *ErrorBudgetProvider) WritePrometheus(w io.Writer) error {
+	snapshot := p.Snapshot()
+	components := make([]string, 0, len(snapshot))
+	for component := range snapshot {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	var b strings.Builder
+	b.WriteString("# HELP sglogger_error_entries Error and fatal log entries per component over a sliding window.\n")
+	b.WriteString("# TYPE sglogger_error_entries gauge\n")
+	for _, component := range components {
+		counts := snapshot[component]
+		for i, value := range []uint64{counts.LastMinute, counts.Last5Minutes, counts.LastHour} {
+			fmt.Fprintf(&b, "sglogger_error_entries{component=\"%s\",window=\"%s\"} %d\n",
+				escapePrometheusLabel(component), errorBudgetWindowLabels[i], value)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapePrometheusLabel экранирует значение метки Prometheus.
+func escapePrometheusLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Name возвращает имя из конфигурации или "error_budget".
+func (p *ErrorBudgetProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "error_budget"
+}
+
+// Describe возвращает поле компонента и лимит компонентов (см. Describer).
+func (p *ErrorBudgetProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"field":          p.config.Field,
+		"max_components": p.config.MaxComponents,
+	}
+}
+
+// ShouldLog принимает записи уровня LevelError и выше.
+func (p *ErrorBudgetProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && level >= LevelError
+}
+
+// Close прекращает учет записей; счетчики остаются доступны после закрытия логгера.
+func (p *ErrorBudgetProvider) Close(ctx context.Context) error {
+	p.markClosed()
+	return nil
+}
@@ -0,0 +1,51 @@
+package sglogger
+
+import "errors"
+
+var (
+	// ErrTimeout возвращается, когда провайдер не успел записать сообщение
+	// за отведенное время.
+	ErrTimeout = errors.New("sglogger: provider write timed out")
+
+	// ErrProviderClosed возвращается при попытке записи в уже закрытый провайдер.
+	ErrProviderClosed = errors.New("sglogger: provider is closed")
+
+	// ErrProviderNotFound возвращается, если провайдер с указанным именем не зарегистрирован.
+	ErrProviderNotFound = errors.New("sglogger: provider not found")
+
+	// ErrQueueFull возвращается, когда очередь записей провайдера заполнена.
+	ErrQueueFull = errors.New("sglogger: provider queue is full")
+
+	// ErrProviderPanicked оборачивает панику, перехваченную при вызове провайдера.
+	ErrProviderPanicked = errors.New("sglogger: provider panicked")
+
+	// ErrInvalidLevel возвращается для значений уровня вне диапазона LevelDebug..LevelFatal.
+	ErrInvalidLevel = errors.New("sglogger: invalid log level")
+
+	// ErrInvalidRedactionMode возвращается Redaction.SetMode для значений,
+	// отличных от RedactEnforce и RedactDryRun.
+	ErrInvalidRedactionMode = errors.New("sglogger: invalid redaction mode")
+
+	// ErrLogChainBroken возвращается VerifyLogChain, если цепочка хешей сегмента нарушена.
+	ErrLogChainBroken = errors.New("sglogger: log chain is broken")
+
+	// ErrNoProviders возвращается NewLoggerWithOptions, если не передано
+	// ни одного провайдера (см. EmptyProvidersPolicy).
+	ErrNoProviders = errors.New("sglogger: logger has no providers")
+
+	// ErrUnknownEnricher возвращается NewLoggerWithOptions, если в LoggerConfig.Enrichers
+	// указано имя, не зарегистрированное через RegisterEnricher.
+	ErrUnknownEnricher = errors.New("sglogger: unknown enricher")
+
+	// ErrEntryTooLarge возвращается провайдерами датаграмм, если запись
+	// не помещается в датаграмму (см. OversizeConfig).
+	ErrEntryTooLarge = errors.New("sglogger: entry exceeds datagram size limit")
+
+	// ErrFeatureUnavailable возвращается RequireFeature, если возможность
+	// недоступна в этой сборке (см. Features).
+	ErrFeatureUnavailable = errors.New("sglogger: feature unavailable")
+
+	// ErrOpNotCompleted записывается Operation.Finish, если операция
+	// не была завершена Success или Fail.
+	ErrOpNotCompleted = errors.New("sglogger: operation finished without Success or Fail")
+)
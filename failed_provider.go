@@ -0,0 +1,54 @@
+package sglogger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// failedProvider заменяет провайдер, который не удалось создать.
+// Первая запись возвращает сохраненную ошибку создания, поэтому проблема
+// конфигурации видна через LoggerConfig.ErrorHandler и статистику, а не теряется.
+// После этого провайдер отказывается от всех записей в ShouldLog: каждая
+// следующая запись повторяла бы ту же ошибку и лишала логгер быстрого пути
+// для выключенных уровней.
+type failedProvider struct {
+	closedState
+
+	err      error
+	reported int32
+}
+
+// NewFailedProvider возвращает провайдер, первый вызов Write которого возвращает err.
+// Предназначен для конструкторов без возвращаемой ошибки: вместо паники при
+// некорректной конфигурации они возвращают такой провайдер.
+func NewFailedProvider(err error) LoggerProvider {
+	return &failedProvider{err: err}
+}
+
+// Write возвращает ошибку создания провайдера при первом вызове и nil при следующих.
+func (p *failedProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if atomic.CompareAndSwapInt32(&p.reported, 0, 1) {
+		return p.err
+	}
+	return nil
+}
+
+// Name возвращает имя "failed".
+func (p *failedProvider) Name() string {
+	return "failed"
+}
+
+// ShouldLog возвращает true, пока ошибка создания не возвращена из Write,
+// и false после этого или после Close.
+func (p *failedProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && atomic.LoadInt32(&p.reported) == 0
+}
+
+// Close прекращает прием записей.
+func (p *failedProvider) Close(ctx context.Context) error {
+	p.markClosed()
+	return nil
+}
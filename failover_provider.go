@@ -0,0 +1,252 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultFailoverProbeInterval задает паузу перед первой проверкой
+	// основного провайдера по умолчанию.
+	defaultFailoverProbeInterval = time.Second
+
+	// defaultFailoverMaxProbeInterval ограничивает паузу между проверками по умолчанию.
+	defaultFailoverMaxProbeInterval = time.Minute
+
+	// failoverProbeMessage - сообщение проверочной записи по умолчанию.
+	failoverProbeMessage = "failover probe"
+)
+
+// FailoverProbeField отмечает проверочные записи, которые провайдер
+// NewFailoverProvider пишет в основной провайдер (см. FailoverConfig.ProbeEntry).
+const FailoverProbeField = "failover_probe"
+
+// failoverProvider пишет записи в основной провайдер, а после его ошибки -
+// в резервный, пока фоновая проверка не подтвердит, что основной снова работает.
+type failoverProvider struct {
+	closedState
+
+	config      FailoverConfig
+	diagnostics *diagnostics
+	failed      int32 // 1, пока записи передаются резервному провайдеру
+	mu          sync.Mutex
+	probes      sync.WaitGroup
+	ctx         context.Context // отменяется при Close и прерывает проверку
+	cancel      context.CancelFunc
+}
+
+// NewFailoverProvider создает провайдер, переключающийся на резервный
+// провайдер при ошибке записи в основной. Запись, на которой основной
+// провайдер вернул ошибку, передается резервному, поэтому записи
+// не теряются при переключении и не дублируются.
+//
+// После переключения основной провайдер не вызывается при записи: его
+// проверяет фоновый горутин с экспоненциально растущей паузой (от
+// ProbeInterval до MaxProbeInterval, со случайным разбросом до половины
+// паузы, чтобы экземпляры сервиса не проверяли его одновременно). После
+// успешной проверки записи снова передаются основному провайдеру.
+// Переключения и неудачные проверки сообщаются в Diagnostics.
+//
+// Возвращает ошибку, если не задан основной или резервный провайдер.
+func NewFailoverProvider(config FailoverConfig) (LoggerProvider, error) {
+	if config.Primary == nil || config.Fallback == nil {
+		return nil, fmt.Errorf("sglogger: failover provider requires primary and fallback providers")
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = defaultFailoverProbeInterval
+	}
+	if config.MaxProbeInterval <= 0 {
+		config.MaxProbeInterval = defaultFailoverMaxProbeInterval
+	}
+	if config.MaxProbeInterval < config.ProbeInterval {
+		config.MaxProbeInterval = config.ProbeInterval
+	}
+	if config.ProbeEntry != nil {
+		entry := *config.ProbeEntry
+		entry.Fields = cloneFields(entry.Fields)
+		config.ProbeEntry = &entry
+	}
+
+	p := &failoverProvider{
+		config:      config,
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p, nil
+}
+
+// Write передает запись основному провайдеру, а если он вернул ошибку
+// или проверка еще не подтвердила его восстановление, - резервному.
+func (p *failoverProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	if atomic.LoadInt32(&p.failed) == 0 {
+		err := p.config.Primary.Write(ctx, level, message, fields)
+		if err == nil {
+			return nil
+		}
+		p.failover(err)
+	}
+	if !p.config.Fallback.ShouldLog(ctx, level) {
+		return nil
+	}
+	return p.config.Fallback.Write(ctx, level, message, fields)
+}
+
+// failover переключает записи на резервный провайдер и запускает проверку
+// основного, если это еще не сделала другая запись.
+func (p *failoverProvider) failover(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() || atomic.LoadInt32(&p.failed) != 0 {
+		return
+	}
+	atomic.StoreInt32(&p.failed, 1)
+	p.diagnostics.reportf("failover provider", "primary %q failed, switching to fallback %q: %v",
+		ProviderName(p.config.Primary), ProviderName(p.config.Fallback), err)
+
+	p.probes.Add(1)
+	go p.probeLoop()
+}
+
+// probeLoop проверяет основной провайдер, пока проверка не пройдет
+// или провайдер не будет закрыт.
+func (p *failoverProvider) probeLoop() {
+	defer p.probes.Done()
+
+	delay := p.config.ProbeInterval
+	for {
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-timer.C:
+		case <-p.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		err := p.probe()
+		if err == nil {
+			atomic.StoreInt32(&p.failed, 0)
+			p.diagnostics.reportf("failover provider", "primary %q recovered, switching back from fallback %q",
+				ProviderName(p.config.Primary), ProviderName(p.config.Fallback))
+			return
+		}
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		if delay *= 2; delay > p.config.MaxProbeInterval {
+			delay = p.config.MaxProbeInterval
+		}
+		p.diagnostics.reportf("failover provider", "primary %q probe failed, next probe in about %s: %v",
+			ProviderName(p.config.Primary), delay, err)
+	}
+}
+
+// probe один раз проверяет основной провайдер (см. FailoverConfig.ProbeEntry).
+func (p *failoverProvider) probe() error {
+	primary := p.config.Primary
+	if e := p.config.ProbeEntry; e != nil {
+		return primary.Write(p.ctx, e.Level, e.Message, e.Fields)
+	}
+	if checker, ok := primary.(HealthChecker); ok {
+		return checker.HealthCheck(p.ctx)
+	}
+
+	level := LevelDebug
+	for level < LevelFatal && !primary.ShouldLog(p.ctx, level) {
+		level++
+	}
+	return primary.Write(p.ctx, level, failoverProbeMessage, Fields{FailoverProbeField: true})
+}
+
+// jitter возвращает случайную паузу от половины delay до delay.
+func jitter(delay time.Duration) time.Duration {
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// current возвращает провайдер, которому сейчас передаются записи.
+func (p *failoverProvider) current() LoggerProvider {
+	if atomic.LoadInt32(&p.failed) != 0 {
+		return p.config.Fallback
+	}
+	return p.config.Primary
+}
+
+// Name возвращает имя провайдера из конфигурации или "failover" по умолчанию.
+func (p *failoverProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "failover"
+}
+
+// Describe возвращает параметры проверки и описания обоих провайдеров (см. Describer).
+func (p *failoverProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"probe_interval":     p.config.ProbeInterval.String(),
+		"max_probe_interval": p.config.MaxProbeInterval.String(),
+		"primary":            describeProvider(p.config.Primary),
+		"fallback":           describeProvider(p.config.Fallback),
+	}
+}
+
+// Active делегирует проверку активности текущему провайдеру.
+func (p *failoverProvider) Active() bool {
+	return providerActive(p.current())
+}
+
+// ShouldLog делегирует проверку уровня текущему провайдеру.
+func (p *failoverProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.current().ShouldLog(ctx, level)
+}
+
+// Flush сбрасывает буферы обоих провайдеров, реализующих Flusher,
+// и объединяет их ошибки.
+func (p *failoverProvider) Flush(ctx context.Context) error {
+	var errs []error
+	for _, provider := range []LoggerProvider{p.config.Primary, p.config.Fallback} {
+		if flusher, ok := provider.(Flusher); ok {
+			if err := flusher.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close прекращает прием записей, останавливает проверку основного
+// провайдера и закрывает оба провайдера (см. ChainClose).
+func (p *failoverProvider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.markClosed() {
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	p.cancel()
+	err := ChainClose(ctx, p.config.Primary, func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			p.probes.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	return errors.Join(err, p.config.Fallback.Close(ctx))
+}
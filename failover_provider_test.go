@@ -0,0 +1,296 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// attemptCountingProvider запоминает сообщения всех вызовов Write,
+// включая отклоненные.
+type attemptCountingProvider struct {
+	flakyProvider
+	attemptsMu sync.Mutex
+	attempts   []string
+}
+
+func (p *attemptCountingProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	p.attemptsMu.Lock()
+	p.attempts = append(p.attempts, message)
+	p.attemptsMu.Unlock()
+	return p.flakyProvider.Write(ctx, level, message, fields)
+}
+
+// attempted возвращает сообщения вызовов Write, кроме проверочных.
+func (p *attemptCountingProvider) attempted(probeMessage string) []string {
+	p.attemptsMu.Lock()
+	defer p.attemptsMu.Unlock()
+
+	var messages []string
+	for _, m := range p.attempts {
+		if m != probeMessage {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// healthCheckedProvider проверяет свое состояние через HealthCheck.
+type healthCheckedProvider struct {
+	attemptCountingProvider
+}
+
+func (p *healthCheckedProvider) HealthCheck(ctx context.Context) error {
+	if atomic.LoadInt32(&p.failing) != 0 {
+		return errors.New("collector unavailable")
+	}
+	return nil
+}
+
+// waitFailover ждет, пока провайдер не начнет передавать записи основному
+// провайдеру (failed = false) или резервному (failed = true).
+func waitFailover(t *testing.T, p *failoverProvider, failed bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for (atomic.LoadInt32(&p.failed) != 0) != failed {
+		if time.Now().After(deadline) {
+			t.Fatalf("failover state did not become failed=%v", failed)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// splitProbes разделяет записи на сообщения и проверочные записи.
+func splitProbes(entries []Entry, isProbe func(Entry) bool) (messages []string, probes []Entry) {
+	for _, e := range entries {
+		if isProbe(e) {
+			probes = append(probes, e)
+			continue
+		}
+		messages = append(messages, e.Message)
+	}
+	return messages, probes
+}
+
+func TestFailoverProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		primary    func() (LoggerProvider, *attemptCountingProvider)
+		probeEntry *Entry
+		isProbe    func(Entry) bool
+		wantProbe  *Entry // проверочная запись, принятая основным провайдером
+	}{
+		{
+			name: "default probe entry",
+			primary: func() (LoggerProvider, *attemptCountingProvider) {
+				p := &attemptCountingProvider{}
+				return p, p
+			},
+			isProbe:   func(e Entry) bool { return e.Fields[FailoverProbeField] == true },
+			wantProbe: &Entry{Level: LevelDebug, Message: "failover probe", Fields: Fields{FailoverProbeField: true}},
+		},
+		{
+			name: "configured probe entry",
+			primary: func() (LoggerProvider, *attemptCountingProvider) {
+				p := &attemptCountingProvider{}
+				return p, p
+			},
+			probeEntry: &Entry{Level: LevelInfo, Message: "ping", Fields: Fields{"probe": true}},
+			isProbe:    func(e Entry) bool { return e.Message == "ping" },
+			wantProbe:  &Entry{Level: LevelInfo, Message: "ping", Fields: Fields{"probe": true}},
+		},
+		{
+			name: "health check",
+			primary: func() (LoggerProvider, *attemptCountingProvider) {
+				p := &healthCheckedProvider{}
+				return p, &p.attemptCountingProvider
+			},
+			isProbe: func(e Entry) bool { return e.Fields[FailoverProbeField] == true },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, counter := tt.primary()
+			probeMessage := "failover probe"
+			if tt.probeEntry != nil {
+				probeMessage = tt.probeEntry.Message
+			}
+			fallback := &flakyProvider{}
+			diag := &syncBuffer{}
+			provider, err := NewFailoverProvider(FailoverConfig{
+				Primary:          primary,
+				Fallback:         fallback,
+				ProbeInterval:    time.Millisecond,
+				MaxProbeInterval: 5 * time.Millisecond,
+				ProbeEntry:       tt.probeEntry,
+				Diagnostics:      &DiagnosticsConfig{Output: diag, Rate: 1000},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer provider.Close(context.Background())
+			p := provider.(*failoverProvider)
+
+			write := func(from, to int) {
+				for i := from; i < to; i++ {
+					if err := provider.Write(context.Background(), LevelInfo, fmt.Sprintf("m%02d", i), nil); err != nil {
+						t.Fatalf("Write m%02d = %v", i, err)
+					}
+				}
+			}
+
+			write(0, 2)
+			counter.setFailing(true)
+			write(2, 6)
+			counter.setFailing(false)
+			waitFailover(t, p, false)
+			write(6, 8)
+
+			primaryMessages, probes := splitProbes(counter.Entries(), tt.isProbe)
+			fallbackMessages, _ := splitProbes(fallback.Entries(), tt.isProbe)
+			if want := []string{"m00", "m01", "m06", "m07"}; !reflect.DeepEqual(primaryMessages, want) {
+				t.Errorf("primary got %v, want %v", primaryMessages, want)
+			}
+			if want := []string{"m02", "m03", "m04", "m05"}; !reflect.DeepEqual(fallbackMessages, want) {
+				t.Errorf("fallback got %v, want %v", fallbackMessages, want)
+			}
+			// После переключения основной провайдер вызывается только для проверки
+			if got, want := counter.attempted(probeMessage), []string{"m00", "m01", "m02", "m06", "m07"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("primary write attempts = %v, want %v", got, want)
+			}
+
+			switch {
+			case tt.wantProbe == nil && len(probes) != 0:
+				t.Errorf("primary got probe entries %v, want HealthCheck probes", probes)
+			case tt.wantProbe != nil && len(probes) != 1:
+				t.Errorf("primary got %d probe entries, want the successful one", len(probes))
+			case tt.wantProbe != nil:
+				got := Entry{Level: probes[0].Level, Message: probes[0].Message, Fields: probes[0].Fields}
+				if !reflect.DeepEqual(got, *tt.wantProbe) {
+					t.Errorf("probe entry = %+v, want %+v", got, *tt.wantProbe)
+				}
+			}
+
+			report := diag.String()
+			for _, want := range []string{"switching to fallback", "recovered, switching back"} {
+				if !strings.Contains(report, want) {
+					t.Errorf("diagnostics = %q, want %q", report, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFailoverProviderBackoff(t *testing.T) {
+	primary := &flakyProvider{}
+	primary.setFailing(true)
+	diag := &syncBuffer{}
+	provider, err := NewFailoverProvider(FailoverConfig{
+		Primary:          primary,
+		Fallback:         &flakyProvider{},
+		ProbeInterval:    2 * time.Millisecond,
+		MaxProbeInterval: 8 * time.Millisecond,
+		Diagnostics:      &DiagnosticsConfig{Output: diag, Rate: 1000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer provider.Close(context.Background())
+
+	if err := provider.Write(context.Background(), LevelInfo, "m00", nil); err != nil {
+		t.Fatalf("Write = %v", err)
+	}
+
+	// Пауза удваивается после каждой неудачной проверки до MaxProbeInterval
+	want := []string{"4ms", "8ms", "8ms"}
+	var delays []string
+	deadline := time.Now().Add(5 * time.Second)
+	for len(delays) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		delays = delays[:0]
+		for _, line := range strings.Split(diag.String(), "\n") {
+			if _, after, ok := strings.Cut(line, "next probe in about "); ok {
+				delays = append(delays, strings.SplitN(after, ":", 2)[0])
+			}
+		}
+	}
+	if len(delays) < len(want) || !reflect.DeepEqual(delays[:len(want)], want) {
+		t.Errorf("probe delays = %v, want %v", delays, want)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for _, delay := range []time.Duration{time.Nanosecond, time.Millisecond, time.Minute} {
+		for i := 0; i < 1000; i++ {
+			if got := jitter(delay); got < delay/2 || got > delay {
+				t.Fatalf("jitter(%s) = %s, want between %s and %s", delay, got, delay/2, delay)
+			}
+		}
+	}
+}
+
+func TestFailoverProviderClose(t *testing.T) {
+	primary := &attemptCountingProvider{}
+	primary.setFailing(true)
+	fallback := &closeCountingProvider{}
+	provider, err := NewFailoverProvider(FailoverConfig{
+		Primary:       primary,
+		Fallback:      fallback,
+		ProbeInterval: time.Millisecond,
+		Diagnostics:   &DiagnosticsConfig{Disabled: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.Write(context.Background(), LevelInfo, "m00", nil); err != nil {
+		t.Fatalf("Write = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := provider.Close(context.Background()); err != nil {
+			t.Fatalf("Close = %v", err)
+		}
+	}
+
+	// Проверка остановлена до возврата из Close
+	attempts := len(primary.attempted(""))
+	time.Sleep(20 * time.Millisecond)
+	if got := len(primary.attempted("")); got != attempts {
+		t.Errorf("primary probed %d times after Close", got-attempts)
+	}
+	if closes := atomic.LoadInt32(&fallback.closes); closes != 1 {
+		t.Errorf("fallback closed %d times, want 1", closes)
+	}
+	if err := provider.Write(context.Background(), LevelInfo, "m01", nil); !errors.Is(err, ErrProviderClosed) {
+		t.Errorf("Write after Close = %v, want ErrProviderClosed", err)
+	}
+	if got := fallback.Entries(); len(got) != 1 || got[0].Message != "m00" {
+		t.Errorf("fallback got %v, want m00", got)
+	}
+}
+
+func TestNewFailoverProviderRequiresProviders(t *testing.T) {
+	tests := []struct {
+		name     string
+		primary  LoggerProvider
+		fallback LoggerProvider
+	}{
+		{name: "no primary", fallback: &flakyProvider{}},
+		{name: "no fallback", primary: &flakyProvider{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFailoverProvider(FailoverConfig{Primary: tt.primary, Fallback: tt.fallback}); err == nil {
+				t.Error("NewFailoverProvider accepted a missing provider")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package sglogger
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fallbackRate ограничивает количество строк резервного вывода в секунду.
+const fallbackRate = 10
+
+// stderrFallback выводит записи в stderr, когда ни один провайдер не смог их записать.
+// Вывод выполняется напрямую, без обращения к логгеру, поэтому не может
+// вызвать рекурсию. Количество строк ограничено fallbackRate в секунду;
+// количество пропущенных записей выводится в следующей строке.
+type stderrFallback struct {
+	out        io.Writer
+	window     time.Time
+	count      int
+	suppressed int
+	mu         sync.Mutex
+}
+
+// newStderrFallback создает резервный вывод или возвращает nil, если он отключен.
+func newStderrFallback(enabled bool) *stderrFallback {
+	if !enabled {
+		return nil
+	}
+	return &stderrFallback{out: os.Stderr}
+}
+
+// write выводит запись в одну строку. Возвращает false, если запись
+// пропущена из-за ограничения частоты.
+func (f *stderrFallback) write(level Level, message string, fields Fields) bool {
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if now.Sub(f.window) >= time.Second {
+		f.window = now
+		f.count = 0
+	}
+	if f.count >= fallbackRate {
+		f.suppressed++
+		return false
+	}
+	f.count++
+
+	line := make([]byte, 0, 128)
+	line = now.AppendFormat(line, time.RFC3339)
+	line = append(line, " sglogger fallback: "...)
+	line = append(line, level.String()...)
+	line = append(line, ' ')
+	line = strconv.AppendQuote(line, message)
+	if len(fields) > 0 {
+		line = append(line, ' ')
+		line = appendFields(line, fields, "", FloatFormat{})
+	}
+	if f.suppressed > 0 {
+		line = append(line, " ("...)
+		line = strconv.AppendInt(line, int64(f.suppressed), 10)
+		line = append(line, " entries suppressed)"...)
+		f.suppressed = 0
+	}
+	line = append(line, '\n')
+
+	f.out.Write(line)
+	return true
+}
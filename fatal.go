@@ -0,0 +1,69 @@
+package sglogger
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultFatalTimeout ограничивает запись фатального сообщения и сброс буферов
+// перед завершением для провайдеров, не реализующих WriteTimeouter.
+const DefaultFatalTimeout = 5 * time.Second
+
+// WriteTimeouter определяет интерфейс провайдеров с собственным таймаутом записи.
+// Таймаут используется для фатальных сообщений, которые пишутся без учета
+// отмены контекста вызывающего.
+type WriteTimeouter interface {
+	// WriteTimeout возвращает максимальное время одной записи
+	WriteTimeout() time.Duration
+}
+
+// detachedContext сохраняет значения родительского контекста,
+// но не наследует его дедлайн и отмену.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// exitContext возвращает контекст для записи фатального сообщения или сброса
+// буферов перед завершением. Фатальные сообщения пишутся чаще всего именно
+// тогда, когда контекст вызывающего уже отменен, поэтому отмена игнорируется,
+// а время записи ограничивается таймаутом самого провайдера.
+func exitContext(ctx context.Context, provider LoggerProvider) (context.Context, context.CancelFunc) {
+	timeout := DefaultFatalTimeout
+	if t, ok := provider.(WriteTimeouter); ok && t.WriteTimeout() > 0 {
+		timeout = t.WriteTimeout()
+	}
+	return context.WithTimeout(detachedContext{ctx}, timeout)
+}
+
+// providerContext возвращает контекст записи для провайдера: для фатальных
+// сообщений - контекст exitContext, для остальных - контекст вызывающего.
+func providerContext(ctx context.Context, level Level, provider LoggerProvider) (context.Context, context.CancelFunc) {
+	if level < LevelFatal {
+		return ctx, func() {}
+	}
+	return exitContext(ctx, provider)
+}
+
+// flushForExit сбрасывает буферы провайдеров перед завершением приложения,
+// не учитывая отмену контекста вызывающего. Ошибки передаются ErrorHandler.
+func (l *logger) flushForExit(ctx context.Context) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for i := range l.providers {
+		rp := &l.providers[i]
+		if _, ok := rp.provider.(Flusher); !ok {
+			continue
+		}
+		flushCtx, cancel := exitContext(ctx, rp.provider)
+		err := l.flushProvider(flushCtx, rp)
+		cancel()
+		if err != nil && l.config.ErrorHandler != nil {
+			l.config.ErrorHandler(rp.name, err)
+		}
+	}
+}
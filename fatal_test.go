@@ -0,0 +1,92 @@
+package sglogger
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowProvider записывает запись через delay, если контекст записи
+// не отменен раньше, и сообщает собственный таймаут записи.
+type slowProvider struct {
+	delay   time.Duration
+	timeout time.Duration
+
+	written int32
+	flushed int32
+}
+
+func (p *slowProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	select {
+	case <-time.After(p.delay):
+		atomic.AddInt32(&p.written, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *slowProvider) ShouldLog(ctx context.Context, level Level) bool { return true }
+
+func (p *slowProvider) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	atomic.AddInt32(&p.flushed, 1)
+	return nil
+}
+
+func (p *slowProvider) WriteTimeout() time.Duration { return p.timeout }
+
+func (p *slowProvider) Close(ctx context.Context) error { return nil }
+
+func TestFatalIgnoresCanceledContext(t *testing.T) {
+	provider := &slowProvider{delay: 20 * time.Millisecond, timeout: time.Second}
+	l := NewLogger(LoggerConfig{}, NewFieldsHandler(), provider).(*logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Запись уровня Error учитывает отмену контекста вызывающего
+	l.Error(ctx, "request canceled")
+	if n := atomic.LoadInt32(&provider.written); n != 0 {
+		t.Fatalf("error entry with a canceled context was written")
+	}
+
+	// Fatal вызывает os.Exit, поэтому проверяются его шаги
+	l.writeLog(ctx, LevelFatal, nil, nil, "shutting down: %v", []interface{}{ctx.Err()})
+	l.flushForExit(ctx)
+	if n := atomic.LoadInt32(&provider.written); n != 1 {
+		t.Errorf("fatal entry written %d times, want 1", n)
+	}
+	if n := atomic.LoadInt32(&provider.flushed); n != 1 {
+		t.Errorf("provider flushed %d times before exit, want 1", n)
+	}
+}
+
+func TestFatalBoundedByProviderTimeout(t *testing.T) {
+	provider := &slowProvider{delay: time.Hour, timeout: 20 * time.Millisecond}
+	l := NewLogger(LoggerConfig{Diagnostics: &DiagnosticsConfig{Disabled: true}}, NewFieldsHandler(), provider).(*logger)
+
+	start := time.Now()
+	l.writeLog(context.Background(), LevelFatal, nil, nil, "shutting down", nil)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("fatal write took %s with a 20ms provider timeout", elapsed)
+	}
+	if n := atomic.LoadInt32(&provider.written); n != 0 {
+		t.Errorf("entry written %d times, want the write to time out", n)
+	}
+}
+
+func TestExitContextUsesDefaultTimeout(t *testing.T) {
+	ctx, cancel := exitContext(context.Background(), &recordingProvider{})
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("exit context has no deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > DefaultFatalTimeout {
+		t.Errorf("exit context deadline in %s, want within %s", remaining, DefaultFatalTimeout)
+	}
+}
@@ -0,0 +1,128 @@
+package sglogger
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// modulePath - путь модуля sglogger, по которому определяется его версия.
+const modulePath = "github.com/SergeiKhanlarov/seri-go-logger"
+
+// builtinFeatures - возможности, доступные в любой сборке: встроенные провайдеры.
+var builtinFeatures = []string{
+	"provider.console_file",
+	"provider.dual_format",
+	"provider.encrypting",
+	"provider.error_budget",
+	"provider.file",
+	"provider.filter",
+	"provider.fmt",
+	"provider.honeycomb",
+	"provider.json",
+	"provider.loki",
+	"provider.ordered",
+	"provider.rate_limit",
+	"provider.ring_buffer",
+	"provider.segment",
+	"provider.sentry",
+	"provider.spool",
+	"provider.syslog",
+	"provider.tenant_router",
+	"provider.timeout",
+	"provider.victorialogs",
+}
+
+// featureRegistry хранит возможности, зарегистрированные через RegisterFeature.
+var featureRegistry = struct {
+	mu       sync.RWMutex
+	features map[string]struct{}
+}{features: make(map[string]struct{})}
+
+// Version возвращает версию модуля sglogger из информации о сборке, например
+// "v1.4.0", "(devel)" при сборке из рабочей копии (в том числе подключенной
+// директивой replace) или "unknown", если информация о сборке недоступна.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := "unknown"
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Version
+			}
+			break
+		}
+	}
+	if version == "" {
+		return "(devel)"
+	}
+	return version
+}
+
+// RegisterFeature отмечает необязательную возможность как доступную в этой
+// сборке. Вызывается из init файлов с тегами сборки и дополнительных модулей,
+// чтобы Features и RequireFeature знали о них без прямой зависимости:
+// например, sglogr регистрирует "logr". Имена провайдеров принято
+// записывать как "provider.<имя>". Повторная регистрация ничего не меняет.
+func RegisterFeature(name string) {
+	if name == "" {
+		return
+	}
+	featureRegistry.mu.Lock()
+	defer featureRegistry.mu.Unlock()
+	featureRegistry.features[name] = struct{}{}
+}
+
+// Features возвращает отсортированные имена возможностей, доступных в этой
+// сборке: встроенные провайдеры ("provider.fmt", "provider.honeycomb" и т. д.),
+// возможности, зависящие от платформы (например, "reopen_signals" на Unix),
+// и возможности, зарегистрированные через RegisterFeature.
+func Features() []string {
+	featureRegistry.mu.RLock()
+	defer featureRegistry.mu.RUnlock()
+
+	features := make([]string, 0, len(builtinFeatures)+len(featureRegistry.features))
+	features = append(features, builtinFeatures...)
+	for name := range featureRegistry.features {
+		if !containsString(builtinFeatures, name) {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// HasFeature сообщает, доступна ли возможность name в этой сборке (см. Features).
+func HasFeature(name string) bool {
+	if containsString(builtinFeatures, name) {
+		return true
+	}
+	featureRegistry.mu.RLock()
+	defer featureRegistry.mu.RUnlock()
+	_, ok := featureRegistry.features[name]
+	return ok
+}
+
+// RequireFeature возвращает ErrFeatureUnavailable, если возможность name
+// недоступна в этой сборке. Предназначен для построителей логгера
+// по конфигурации: ошибка называет недоступный провайдер и версию пакета,
+// например `sglogger: feature unavailable: provider "journald" is not
+// available in this build of sglogger v1.4.0`, вместо ошибки неизвестного типа.
+func RequireFeature(name string) error {
+	if HasFeature(name) {
+		return nil
+	}
+	if provider := strings.TrimPrefix(name, "provider."); provider != name {
+		return fmt.Errorf("%w: provider %q is not available in this build of sglogger %s", ErrFeatureUnavailable, provider, Version())
+	}
+	return fmt.Errorf("%w: %q is not available in this build of sglogger %s", ErrFeatureUnavailable, name, Version())
+}
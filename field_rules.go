@@ -0,0 +1,90 @@
+package sglogger
+
+import "sync/atomic"
+
+// FieldRules содержит набор правил FieldRule, который можно заменять во время
+// работы логгера (см. LoggerConfig.FieldRules).
+type FieldRules struct {
+	rules atomic.Pointer[[]FieldRule]
+}
+
+// NewFieldRules создает набор правил. Правила проверяются по порядку после
+// извлечения полей из контекста и до передачи записи провайдерам; каждое
+// следующее правило видит поля, добавленные предыдущими. Сравнение выполняется
+// только на точное совпадение, без регулярных выражений.
+func NewFieldRules(rules ...FieldRule) *FieldRules {
+	r := &FieldRules{}
+	r.Set(rules...)
+	return r
+}
+
+// Set заменяет правила. Записи, обрабатываемые в момент замены,
+// используют старый или новый набор целиком.
+func (r *FieldRules) Set(rules ...FieldRule) {
+	copied := make([]FieldRule, len(rules))
+	for i, rule := range rules {
+		if rule.MaxLevel == 0 {
+			rule.MaxLevel = LevelFatal
+		}
+		copied[i] = rule
+	}
+	r.rules.Store(&copied)
+}
+
+// Rules возвращает текущие правила.
+func (r *FieldRules) Rules() []FieldRule {
+	return append([]FieldRule(nil), *r.rules.Load()...)
+}
+
+// apply возвращает поля записи с учетом правил. Если ни одно правило
+// не сработало, возвращает fields без копирования.
+func (r *FieldRules) apply(level Level, fields Fields) Fields {
+	if r == nil {
+		return fields
+	}
+
+	copied := false
+	for _, rule := range *r.rules.Load() {
+		if !rule.matches(level, fields) {
+			continue
+		}
+		if !copied {
+			result := make(Fields, len(fields)+len(rule.Add)+len(rule.Set))
+			for k, v := range fields {
+				result[k] = v
+			}
+			fields = result
+			copied = true
+		}
+		for k, v := range rule.Add {
+			if _, ok := fields[k]; !ok {
+				fields[k] = v
+			}
+		}
+		for k, v := range rule.Set {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// matches сообщает, подходит ли запись под правило.
+func (rule *FieldRule) matches(level Level, fields Fields) bool {
+	if level < rule.MinLevel || level > rule.MaxLevel {
+		return false
+	}
+	for k, want := range rule.FieldEquals {
+		v, ok := fields[k]
+		if !ok {
+			return false
+		}
+		if s, isString := v.(string); isString {
+			if s != want {
+				return false
+			}
+		} else if fieldString(v) != want {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package sglogger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// fieldValidator проверяет записи по правилам FieldValidationConfig и сообщает
+// о нарушениях через диагностический канал логгера, не более одного раза
+// для каждого правила и места вызова.
+type fieldValidator struct {
+	config      FieldValidationConfig
+	diagnostics *diagnostics
+	warned      sync.Map
+}
+
+// newFieldValidator создает проверку полей или возвращает nil, если она отключена.
+func newFieldValidator(config *FieldValidationConfig, diag *diagnostics) *fieldValidator {
+	if config == nil {
+		return nil
+	}
+	return &fieldValidator{config: *config, diagnostics: diag}
+}
+
+// violations возвращает описания нарушений правил в записи.
+func (v *fieldValidator) violations(level Level, fields Fields) []string {
+	var found []string
+	if v.config.ErrorWithoutError && level >= LevelError {
+		if _, ok := fields["error"]; !ok {
+			found = append(found, fmt.Sprintf("%s entry without an error field, use the *Err methods", level))
+		}
+	}
+	if v.config.ErrField {
+		if _, ok := fields["err"]; ok {
+			found = append(found, `field "err" should be named "error"`)
+		}
+	}
+	if v.config.PointerValues {
+		var keys []string
+		for k, value := range fields {
+			if rendersAsAddress(value) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			found = append(found, fmt.Sprintf("field %q is a %T and renders as an address", k, fields[k]))
+		}
+	}
+	return found
+}
+
+// report сообщает о нарушениях, о которых еще не сообщалось для места вызова caller.
+func (v *fieldValidator) report(violations []string, caller string) {
+	for _, violation := range violations {
+		if _, loaded := v.warned.LoadOrStore(violation+"@"+caller, struct{}{}); !loaded {
+			v.diagnostics.reportf("field validation", "%s at %s", violation, caller)
+		}
+	}
+}
+
+// rendersAsAddress сообщает, выводится ли значение в текстовом формате
+// как адрес: это указатели на значения, отличные от структур, массивов,
+// срезов и отображений, не реализующие fmt.Stringer и error.
+func rendersAsAddress(value interface{}) bool {
+	switch value.(type) {
+	case nil, fmt.Stringer, error:
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+	switch rv.Elem().Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		return false
+	}
+	return true
+}
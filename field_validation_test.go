@@ -0,0 +1,102 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldValidatorViolations(t *testing.T) {
+	count := 3
+	name := "bob"
+	all := FieldValidationConfig{ErrorWithoutError: true, ErrField: true, PointerValues: true}
+
+	tests := []struct {
+		name   string
+		config FieldValidationConfig
+		level  Level
+		fields Fields
+		want   []string
+	}{
+		{name: "clean entry", config: all, level: LevelInfo, fields: Fields{"user": "bob"}},
+		{name: "error without error field", config: all, level: LevelError, want: []string{"error entry without an error field, use the *Err methods"}},
+		{name: "error with error field", config: all, level: LevelError, fields: Fields{"error": "timeout"}},
+		{name: "error rule disabled", config: FieldValidationConfig{ErrField: true}, level: LevelError},
+		{name: "err field", config: all, level: LevelInfo, fields: Fields{"err": "timeout"}, want: []string{`field "err" should be named "error"`}},
+		{name: "err rule disabled", config: FieldValidationConfig{PointerValues: true}, level: LevelInfo, fields: Fields{"err": "timeout"}},
+		{
+			name:   "pointer values sorted by key",
+			config: all,
+			level:  LevelInfo,
+			fields: Fields{"name": &name, "count": &count},
+			want:   []string{`field "count" is a *int and renders as an address`, `field "name" is a *string and renders as an address`},
+		},
+		{name: "pointer rule disabled", config: FieldValidationConfig{ErrorWithoutError: true}, level: LevelInfo, fields: Fields{"count": &count}},
+		{
+			name:   "pointers rendered by value",
+			config: all,
+			level:  LevelInfo,
+			fields: Fields{
+				"struct":   &struct{ A int }{1},
+				"slice":    &[]int{1},
+				"map":      &map[string]int{},
+				"stringer": &strings.Builder{},
+				"error":    errors.New("boom"),
+				"nil":      (*int)(nil),
+				"duration": time.Second,
+			},
+		},
+		{
+			name:   "all rules at once",
+			config: all,
+			level:  LevelFatal,
+			fields: Fields{"err": "boom", "count": &count},
+			want: []string{
+				"critical entry without an error field, use the *Err methods",
+				`field "err" should be named "error"`,
+				`field "count" is a *int and renders as an address`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newFieldValidator(&tt.config, nil)
+			if got := v.violations(tt.level, tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldValidationReport(t *testing.T) {
+	diag := &syncBuffer{}
+	recorder := &recordingProvider{}
+	config := LoggerConfig{
+		FieldValidation: &FieldValidationConfig{ErrField: true},
+		Diagnostics:     &DiagnosticsConfig{Output: diag},
+	}
+	logger := NewLogger(config, NewFieldsHandler(), recorder)
+
+	// Одно место вызова сообщает о нарушении один раз
+	for i := 0; i < 3; i++ {
+		logger.InfoWithFields(context.Background(), Fields{"err": "timeout"}, "request failed")
+	}
+	logger.InfoWithFields(context.Background(), Fields{"err": "timeout"}, "request failed again")
+
+	report := diag.String()
+	if got := strings.Count(report, `field "err" should be named "error"`); got != 2 {
+		t.Errorf("reported %d times, want once per call site: %q", got, report)
+	}
+	if !strings.Contains(report, "field_validation_test.go:") {
+		t.Errorf("report %q does not name the call site", report)
+	}
+	// Записи не изменяются и не блокируются
+	entries := recorder.Entries()
+	if len(entries) != 4 || entries[0].Fields["err"] != "timeout" {
+		t.Errorf("entries = %v, want all four written unchanged", entries)
+	}
+}
@@ -10,6 +10,41 @@ import (
 // Пример: Fields{"user_id": 123, "request_id": "abc-123"}
 type Fields map[string]interface{}
 
+// VerboseValue помечает значение поля как диагностическое: такое поле выводится
+// только в сообщениях уровня Debug и удаляется из сообщений более высоких уровней
+// до передачи провайдерам, поэтому затраты на сериализацию больших значений
+// (заголовки запросов, история повторов) в production не возникают.
+type VerboseValue struct {
+	Value interface{}
+}
+
+// DebugOnlyFields возвращает копию полей, в которой каждое значение обернуто в VerboseValue.
+// Пример: logger.InfoWithFields(ctx, DebugOnlyFields(Fields{"headers": r.Header}), "запрос")
+func DebugOnlyFields(fields Fields) Fields {
+	result := make(Fields, len(fields))
+	for k, v := range fields {
+		result[k] = VerboseValue{Value: v}
+	}
+	return result
+}
+
+// ContextWithFields возвращает контекст, к которому привязаны дополнительные поля.
+// Поля добавляются ко всем сообщениям, записанным с этим контекстом;
+// повторный вызов объединяет новые поля с уже привязанными.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result := make(Fields)
+	if existing, ok := ctx.Value(fieldsKey).(Fields); ok {
+		maps.Copy(result, existing)
+	}
+	maps.Copy(result, fields)
+
+	return context.WithValue(ctx, fieldsKey, result)
+}
+
 // FieldsHandler определяет интерфейс для работы с дополнительными полями логов.
 // Обеспечивает извлечение полей из контекста и объединение наборов полей.
 type FieldsHandler interface {
@@ -32,18 +67,30 @@ func NewFieldsHandler() FieldsHandler {
 }
 
 // ExtractFieldsFromContext извлекает поля из контекста и объединяет их с переданными полями.
-// В текущей реализации извлекает только trace_id из контекста.
-// Если контекст равен nil, возвращает исходные поля без изменений.
+// Извлекает поля, добавленные через ContextWithFields (явно переданные поля имеют
+// приоритет над ними), и trace_id.
+// Всегда возвращает новый набор полей, даже если контекст равен nil, поэтому
+// изменение результата (хуками, асинхронными провайдерами) не затрагивает
+// карту, переданную вызывающим кодом. Если полей нет, возвращает nil без выделения памяти.
 func (h *fieldsHandler) ExtractFieldsFromContext(ctx context.Context, fields Fields) Fields {
-	if ctx == nil {
-		return fields
+	var contextFields Fields
+	var traceID string
+	var hasTraceID bool
+	if ctx != nil {
+		contextFields, _ = ctx.Value(fieldsKey).(Fields)
+		traceID, hasTraceID = ctx.Value(TraceIDKey).(string)
 	}
 
-	result := make(Fields)
+	if len(fields) == 0 && len(contextFields) == 0 && !hasTraceID {
+		return nil
+	}
+
+	result := make(Fields, len(contextFields)+len(fields)+1)
+	maps.Copy(result, contextFields)
 	maps.Copy(result, fields)
 
-	// Извлекаем trace_id из контекста, если он присутствует
-	if traceID, ok := ctx.Value(TraceIDKey).(string); ok {
+	// Добавляем trace_id из контекста, если он присутствует
+	if hasTraceID {
 		result["trace_id"] = traceID
 	}
 
@@ -52,12 +99,41 @@ func (h *fieldsHandler) ExtractFieldsFromContext(ctx context.Context, fields Fie
 
 // MergeFields объединяет два набора полей. При совпадении ключей
 // значения из fields2 имеют приоритет над значениями из fields1.
-// Возвращает новый набор полей, содержащий объединенные данные.
+// Возвращает новый набор полей, содержащий объединенные данные,
+// даже если один из аргументов равен nil.
 func (h *fieldsHandler) MergeFields(fields1, fields2 Fields) Fields {
 	result := make(Fields)
 	
 	maps.Copy(result, fields1)
 	maps.Copy(result, fields2)
 	
+	return result
+}
+
+// resolveVerboseFields обрабатывает поля с VerboseValue для сообщения указанного уровня:
+// для уровня Debug значения разворачиваются, для остальных уровней поля удаляются.
+// Если таких полей нет, возвращает исходный набор без копирования.
+func resolveVerboseFields(level Level, fields Fields) Fields {
+	hasVerbose := false
+	for _, v := range fields {
+		if _, ok := v.(VerboseValue); ok {
+			hasVerbose = true
+			break
+		}
+	}
+	if !hasVerbose {
+		return fields
+	}
+
+	result := make(Fields, len(fields))
+	for k, v := range fields {
+		verbose, ok := v.(VerboseValue)
+		switch {
+		case !ok:
+			result[k] = v
+		case level <= LevelDebug:
+			result[k] = verbose.Value
+		}
+	}
 	return result
 }
\ No newline at end of file
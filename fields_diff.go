@@ -0,0 +1,163 @@
+package sglogger
+
+import (
+	"math"
+	"reflect"
+	"sort"
+)
+
+const (
+	// DiffMaxChanges ограничивает количество измененных ключей в результате DiffFields.
+	// Остальные изменения учитываются только в поле "changed.truncated".
+	DiffMaxChanges = 50
+
+	// DiffMaxValueLen ограничивает длину строкового представления значения
+	// в результате DiffFields; более длинные значения обрезаются.
+	DiffMaxValueLen = 256
+)
+
+// DiffFields сравнивает два набора полей и возвращает различия в виде полей
+// для журнала изменений:
+//   - "changed.<key>.old" и "changed.<key>.new" - старое и новое значение ключа;
+//   - "added" и "removed" - отсортированные списки добавленных и удаленных ключей.
+//
+// Вложенные словари со строковыми ключами сравниваются на один уровень вглубь:
+// изменение вложенного ключа записывается как "changed.<key>.<sub>.old/new",
+// а добавленные и удаленные вложенные ключи - как "<key>.<sub>".
+// Значения сравниваются через reflect.DeepEqual (NaN равен NaN), поэтому
+// DiffFields принимает значения любых типов.
+//
+// Результат ограничен: не более DiffMaxChanges измененных ключей (количество
+// пропущенных записывается в "changed.truncated"), строковые значения длиннее
+// DiffMaxValueLen байт обрезаются, остальные значения передаются как есть.
+// Если наборы полей совпадают, возвращает nil.
+//
+// Пример:
+//
+//	logger.InfoWithFields(ctx, DiffFields(before, after), "settings changed")
+func DiffFields(before, after Fields) Fields {
+	d := &fieldsDiff{result: make(Fields)}
+	d.compare("", before, after, 1)
+
+	if len(d.added) > 0 {
+		d.result["added"] = d.added
+	}
+	if len(d.removed) > 0 {
+		d.result["removed"] = d.removed
+	}
+	if d.truncated > 0 {
+		d.result["changed.truncated"] = d.truncated
+	}
+	if len(d.result) == 0 {
+		return nil
+	}
+	return d.result
+}
+
+// fieldsDiff накапливает результат DiffFields.
+type fieldsDiff struct {
+	result    Fields
+	added     []string
+	removed   []string
+	changes   int
+	truncated int
+}
+
+// compare сравнивает два словаря с префиксом ключей prefix.
+// depth определяет, на сколько уровней вглубь сравниваются вложенные словари.
+func (d *fieldsDiff) compare(prefix string, before, after map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		oldValue, inBefore := before[k]
+		newValue, inAfter := after[k]
+		key := prefix + k
+
+		switch {
+		case !inBefore:
+			d.added = append(d.added, key)
+		case !inAfter:
+			d.removed = append(d.removed, key)
+		case diffEqual(oldValue, newValue):
+		default:
+			oldMap, oldOK := stringMap(oldValue)
+			newMap, newOK := stringMap(newValue)
+			if depth > 0 && oldOK && newOK {
+				d.compare(key+".", oldMap, newMap, depth-1)
+				continue
+			}
+			d.change(key, oldValue, newValue)
+		}
+	}
+}
+
+// change записывает изменение ключа с учетом ограничения DiffMaxChanges.
+func (d *fieldsDiff) change(key string, oldValue, newValue interface{}) {
+	if d.changes >= DiffMaxChanges {
+		d.truncated++
+		return
+	}
+	d.changes++
+	d.result["changed."+key+".old"] = diffValue(oldValue)
+	d.result["changed."+key+".new"] = diffValue(newValue)
+}
+
+// diffEqual сравнивает значения полей. В отличие от reflect.DeepEqual
+// считает равными два NaN, чтобы неизменное значение не попадало в изменения.
+func diffEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	fa, aOK := a.(float64)
+	fb, bOK := b.(float64)
+	return aOK && bOK && math.IsNaN(fa) && math.IsNaN(fb)
+}
+
+// stringMap приводит значение к словарю со строковыми ключами.
+// Поддерживает Fields и любые map[string]T.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case Fields:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	result := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		result[iter.Key().String()] = iter.Value().Interface()
+	}
+	return result, true
+}
+
+// diffValue ограничивает размер значения в результате DiffFields:
+// строки обрезаются до DiffMaxValueLen байт, остальные значения не изменяются.
+func diffValue(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || len(s) <= DiffMaxValueLen {
+		return v
+	}
+	return truncateUTF8(s, DiffMaxValueLen) + "..."
+}
+
+// truncateUTF8 обрезает строку до n байт, не разрывая многобайтовые символы.
+func truncateUTF8(s string, n int) string {
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
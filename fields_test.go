@@ -0,0 +1,52 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMutatingHookDoesNotChangeCallerFields(t *testing.T) {
+	// Хук нарушает договоренность Hook и изменяет поля на месте
+	mutate := func(ctx context.Context, e *Entry) {
+		e.Fields["error"] = "injected"
+		delete(e.Fields, "order")
+	}
+	provider := &recordingProvider{}
+	logger := NewLogger(LoggerConfig{Hooks: []Hook{mutate}}, NewFieldsHandler(), provider)
+
+	fields := Fields{"order": 42}
+	var nilCtx context.Context
+	for _, ctx := range []context.Context{nilCtx, context.Background(), nilCtx} {
+		logger.InfoWithFields(ctx, fields, "order created")
+		logger.ErrorErrWithFields(ctx, errors.New("declined"), fields, "order failed")
+	}
+
+	if want := (Fields{"order": 42}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("caller's fields = %v, want %v", fields, want)
+	}
+	if entries := provider.Entries(); len(entries) != 6 {
+		t.Fatalf("provider received %d entries, want 6", len(entries))
+	}
+}
+
+func TestFieldsHandlerDoesNotAlias(t *testing.T) {
+	h := NewFieldsHandler()
+	fields := Fields{"order": 42}
+
+	var nilCtx context.Context
+	extracted := h.ExtractFieldsFromContext(nilCtx, fields)
+	extracted["order"] = 0
+	merged := []Fields{h.MergeFields(nil, fields), h.MergeFields(fields, nil)}
+	for _, m := range merged {
+		m["order"] = 0
+	}
+
+	if fields["order"] != 42 {
+		t.Errorf("caller's fields changed through the result: %v", fields)
+	}
+	if got := h.ExtractFieldsFromContext(nilCtx, nil); got != nil {
+		t.Errorf("ExtractFieldsFromContext(nil, nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,229 @@
+package sglogger
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressedSuffix - суффикс сжатых ротированных файлов.
+const compressedSuffix = ".gz"
+
+// compressJob - ротированный файл, ожидающий сжатия.
+type compressJob struct {
+	path  string // путь файла при ротации по времени
+	index int    // номер копии при ротации по размеру
+	shift uint64 // значение fileProvider.shifts при постановке в очередь
+}
+
+// fileCompressor сжимает ротированные файлы провайдера в фоновом горутине,
+// по одному в порядке ротации, не задерживая Write.
+//
+// При ротации по размеру копия может сдвинуться (Path.1 в Path.2), пока она
+// сжимается: номер копии пересчитывается по количеству сдвигов с момента
+// постановки в очередь, а сжатый файл занимает место исходного под мьютексом
+// копий fileProvider.backupsMu. Файл, удаленный за это время по сроку
+// хранения, не сжимается.
+type fileCompressor struct {
+	mu    sync.Mutex
+	queue []compressJob
+	wake  chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// newFileCompressor создает очередь сжатия; горутин запускает fileProvider.
+func newFileCompressor() *fileCompressor {
+	return &fileCompressor{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// add ставит файл в очередь сжатия.
+func (c *fileCompressor) add(job compressJob) {
+	c.mu.Lock()
+	c.queue = append(c.queue, job)
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next извлекает следующий файл из очереди.
+func (c *fileCompressor) next() (compressJob, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.queue) == 0 {
+		return compressJob{}, false
+	}
+	job := c.queue[0]
+	c.queue = c.queue[1:]
+	return job, true
+}
+
+// close останавливает горутин после сжатия всех файлов очереди и ждет его
+// завершения, но не дольше, чем позволяет ctx.
+func (c *fileCompressor) close(ctx context.Context) error {
+	close(c.stop)
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("sglogger: wait for log compression: %w", ctx.Err())
+	}
+}
+
+// empty сообщает, пуста ли очередь.
+func (c *fileCompressor) empty() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.queue) == 0
+}
+
+// compressLoop сжимает файлы из очереди; после close завершается,
+// когда очередь опустеет.
+func (p *fileProvider) compressLoop() {
+	c := p.compressor
+	defer close(c.done)
+
+	for {
+		if job, ok := c.next(); ok {
+			if err := p.compressFile(job); err != nil {
+				p.diagnostics.reportf("file provider", "%v", err)
+			}
+			continue
+		}
+		select {
+		case <-c.wake:
+		case <-c.stop:
+			if c.empty() {
+				return
+			}
+		}
+	}
+}
+
+// compressFile сжимает ротированный файл во временный файл и заменяет им
+// исходный, сохраняя время изменения для MaxAgeDays.
+func (p *fileProvider) compressFile(job compressJob) error {
+	p.backupsMu.Lock()
+	source := p.compressSource(job)
+	src, err := os.Open(source)
+	p.backupsMu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("sglogger: open rotated log file: %w", err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("sglogger: stat rotated log file: %w", err)
+	}
+	tmp := p.compressTempPath()
+	if err := writeGzip(tmp, src, p.config.FileMode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+
+	p.backupsMu.Lock()
+	defer p.backupsMu.Unlock()
+
+	// Копия могла сдвинуться или быть удалена по сроку хранения
+	source = p.compressSource(job)
+	if current, err := os.Stat(source); err != nil || !os.SameFile(info, current) {
+		os.Remove(tmp)
+		return nil
+	}
+	if err := os.Rename(tmp, source+compressedSuffix); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("sglogger: rename compressed log file: %w", err)
+	}
+	if err := os.Remove(source); err != nil {
+		return fmt.Errorf("sglogger: remove compressed log file: %w", err)
+	}
+	return nil
+}
+
+// writeGzip записывает сжатое содержимое r в файл path.
+func writeGzip(path string, r io.Reader, mode os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("sglogger: create compressed log file: %w", err)
+	}
+	zw := gzip.NewWriter(file)
+	_, err = io.Copy(zw, r)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("sglogger: compress log file: %w", err)
+	}
+	return nil
+}
+
+// compressSource возвращает текущий путь файла задания.
+// Вызывается с захваченным backupsMu.
+func (p *fileProvider) compressSource(job compressJob) string {
+	if job.path != "" {
+		return job.path
+	}
+	return p.backupPath(job.index + int(p.shifts-job.shift))
+}
+
+// compressTempPath возвращает путь временного файла сжатия: скрытый файл
+// рядом с журналом, который не совпадает с именами ротированных файлов.
+func (p *fileProvider) compressTempPath() string {
+	dir, base := filepath.Split(p.config.Path)
+	return filepath.Join(dir, "."+base+compressedSuffix+".tmp")
+}
+
+// recoverCompression завершает сжатие, прерванное сбоем процесса: удаляет
+// временный файл с не до конца сжатыми данными и исходные файлы, уже
+// замененные сжатыми, и ставит в очередь несжатые ротированные файлы.
+// Вызывается при создании провайдера до запуска горутина сжатия.
+func (p *fileProvider) recoverCompression() {
+	os.Remove(p.compressTempPath())
+
+	p.backupsMu.Lock()
+	defer p.backupsMu.Unlock()
+
+	for _, path := range p.rotatedFiles() {
+		if strings.HasSuffix(path, compressedSuffix) {
+			continue
+		}
+		if _, err := os.Stat(path + compressedSuffix); err == nil {
+			// Сбой после переименования сжатого файла
+			os.Remove(path)
+			continue
+		}
+		job := compressJob{path: path}
+		if p.config.Rotation == RotateBySize {
+			index, _ := strconv.Atoi(strings.TrimPrefix(path, p.config.Path+"."))
+			job = compressJob{index: index, shift: p.shifts}
+		}
+		p.compressor.add(job)
+	}
+}
@@ -0,0 +1,785 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultFileMaxSize задает размер файла, при котором он ротируется, по умолчанию.
+	defaultFileMaxSize = 100 << 20
+
+	// defaultFileMaxBackups задает количество хранимых ротированных файлов по умолчанию.
+	defaultFileMaxBackups = 5
+
+	// defaultFileWriteTimeout ограничивает время одной записи в файл по умолчанию.
+	defaultFileWriteTimeout = 5 * time.Second
+
+	defaultFileMode = 0o644
+	defaultDirMode  = 0o755
+)
+
+// fileProviderNow возвращает текущее время для выбора периода при создании
+// провайдера и для MaxAgeDays. Подменяется в тестах.
+var fileProviderNow = time.Now
+
+// fileProvider дописывает строки в файл и ротирует его по размеру или по времени.
+//
+// Строка записывается целиком под мьютексом провайдера, а решение о ротации
+// принимается до ее записи, поэтому строки не перемешиваются и не разделяются
+// между файлами. Если ротация не удалась, запись продолжается в текущий файл,
+// а ошибка передается в диагностический канал.
+//
+// При ротации по времени период записи определяется ее временем (см.
+// ContextWithEntryTime), а не моментом вызова Write, поэтому запись,
+// сделанная в 23:59:59.999, попадает в файл своего дня, даже если Write
+// выполнился после полуночи. Провайдер не возвращается к прошедшим
+// периодам: запись с более ранним временем, пришедшая после переключения
+// (например, из спула), дописывается в текущий файл.
+type fileProvider struct {
+	closedState
+
+	config      FileProviderConfig
+	formatter   Formatter
+	mu          sync.Mutex
+	sink        *fileSink
+	buffer      *bufferedWriter
+	writes      *timeoutWorker
+	openFile    func(name string, flag int, perm os.FileMode) (logFile, error)
+	syncStop    chan struct{}
+	syncDone    chan struct{}
+	size        int64
+	period      time.Time
+	backupsMu   sync.Mutex
+	shifts      uint64
+	compressor  *fileCompressor
+	leakCheck   *leakCheck
+	sizes       sizeHistogram
+	diagnostics *diagnostics
+}
+
+// logFile - открытый файл лога. Интерфейс позволяет подменить файл в тестах.
+type logFile interface {
+	io.Writer
+	io.Closer
+	Sync() error
+	Stat() (os.FileInfo, error)
+}
+
+// openLogFile открывает файл лога в файловой системе.
+func openLogFile(name string, flag int, perm os.FileMode) (logFile, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// fileSink - открытый файл провайдера. Собственный мьютекс позволяет фоновому
+// сбросу буфера писать в файл, не захватывая мьютекс провайдера; провайдер
+// заменяет файл только после сброса буфера.
+type fileSink struct {
+	mu   sync.Mutex
+	file logFile
+}
+
+// Write записывает данные в текущий файл.
+func (s *fileSink) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return 0, os.ErrClosed
+	}
+	return s.file.Write(b)
+}
+
+// sync сбрасывает текущий файл на диск.
+func (s *fileSink) sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return os.ErrClosed
+	}
+	return s.file.Sync()
+}
+
+// isOpen сообщает, открыт ли файл.
+func (s *fileSink) isOpen() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file != nil
+}
+
+// swap заменяет текущий файл на file и возвращает предыдущий.
+func (s *fileSink) swap(file logFile) logFile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.file
+	s.file = file
+	return previous
+}
+
+// NewFileProvider создает провайдер, дописывающий записи в файл config.Path.
+// Каталоги пути создаются при необходимости. Когда следующая строка сделала бы
+// файл больше MaxSize, файл переименовывается в Path.1, прежние копии сдвигаются
+// (Path.1 в Path.2 и т. д.), копии сверх MaxBackups удаляются, и запись
+// продолжается в новый файл; строка больше MaxSize записывается в пустой файл
+// целиком. Строки формируются config.Formatter, по умолчанию - текстовым форматом.
+//
+// При RotateDaily и RotateHourly записи пишутся в файл с датой периода в имени
+// ("app-2024-05-01.log"), MaxSize не применяется, а при переходе к следующему
+// периоду удаляются самые старые файлы сверх MaxBackups. В обоих режимах
+// при ротации и при создании провайдера удаляются копии старше MaxAgeDays.
+//
+// При Compress ротированные файлы сжимаются gzip в фоновом горутине
+// (Path.1.gz, "app-2024-05-01.log.gz"), не задерживая Write; сжатый файл
+// сохраняет время изменения исходного. Несжатые файлы, оставшиеся после
+// сбоя процесса, сжимаются после создания провайдера, а не до конца сжатые
+// данные отбрасываются. Close ждет сжатия всех ротированных файлов, но не
+// дольше, чем позволяет его контекст.
+//
+// Запись в файл выполняется в отдельном горутине и ограничена WriteTimeout
+// (по умолчанию 5 секунд): если файловая система не отвечает, Write
+// возвращает ErrTimeout, а запись завершается в фоне, и логгер не блокируется.
+// Ожидающих записей не больше 256; записи выполняются в порядке вызова Write.
+//
+// SyncEveryWrite сбрасывает файл на диск (fsync) после каждой записи,
+// SyncInterval - из фонового горутина с заданным периодом; в обоих режимах
+// файл сбрасывается и при ротации и Close. Без них после записи данные
+// остаются в кэше операционной системы и могут быть потеряны при отключении
+// питания.
+//
+// Провайдер безопасен для одновременного использования. Он реализует Flusher,
+// Syncer, WriteTimeouter и Reopener: Reopen открывает текущий файл заново после того, как внешняя
+// утилита (например, logrotate) переместила файл, см. ReopenOnSignal.
+// Close сбрасывает буфер и закрывает файл.
+// Возвращает ошибку, если файл не удается открыть.
+func NewFileProvider(config FileProviderConfig) (LoggerProvider, error) {
+	p, err := newFileProvider(config, openLogFile)
+	if err != nil {
+		return nil, err
+	}
+	if !config.LeakCheck {
+		return p, nil
+	}
+	// Горутин сжатия ссылается на p, поэтому финализатор устанавливается
+	// на обертку, которую удерживает только вызывающий
+	h := &fileProviderHandle{p}
+	p.leakCheck = newLeakCheck(true, h, "file provider "+config.Path, p.diagnostics)
+	return h, nil
+}
+
+// fileProviderHandle - провайдер, возвращаемый NewFileProvider при LeakCheck.
+type fileProviderHandle struct{ *fileProvider }
+
+// newFileProvider создает файловый провайдер, открывающий файлы функцией openFile.
+func newFileProvider(config FileProviderConfig, openFile func(name string, flag int, perm os.FileMode) (logFile, error)) (*fileProvider, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("sglogger: log file path is not set")
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = defaultFileMaxSize
+	}
+	if config.MaxBackups == 0 {
+		config.MaxBackups = defaultFileMaxBackups
+	}
+	if config.FileMode == 0 {
+		config.FileMode = defaultFileMode
+	}
+	if config.DirMode == 0 {
+		config.DirMode = defaultDirMode
+	}
+	if config.WriteTimeout == 0 {
+		config.WriteTimeout = defaultFileWriteTimeout
+	}
+	switch config.Rotation {
+	case RotateBySize, RotateDaily, RotateHourly:
+	default:
+		return nil, fmt.Errorf("sglogger: invalid rotation interval %d", config.Rotation)
+	}
+	if config.MaxAgeDays < 0 {
+		return nil, fmt.Errorf("sglogger: negative log file max age %d", config.MaxAgeDays)
+	}
+	if config.SyncInterval < 0 {
+		return nil, fmt.Errorf("sglogger: negative log file sync interval %s", config.SyncInterval)
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+
+	formatter := config.Formatter
+	if formatter == nil {
+		// Последовательности ссылок не должны попадать в файлы.
+		textConfig := config.ProviderConfig
+		textConfig.Hyperlinks.Enabled = false
+		var err error
+		if formatter, err = NewTextFormatter(textConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.Path), config.DirMode); err != nil {
+		return nil, fmt.Errorf("sglogger: create log directory: %w", err)
+	}
+
+	p := &fileProvider{
+		config:      config,
+		formatter:   formatter,
+		sink:        &fileSink{},
+		openFile:    openFile,
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	if config.Rotation != RotateBySize {
+		p.period = p.periodStart(fileProviderNow())
+	}
+	if err := p.openLocked(); err != nil {
+		return nil, err
+	}
+	p.removeExpired()
+	if config.Compress {
+		p.compressor = newFileCompressor()
+		p.recoverCompression()
+		go p.compressLoop()
+	}
+	if config.BufferSize > 0 {
+		p.buffer = newBufferedWriter(p.sink, config.BufferSize, config.FlushInterval)
+	}
+	if config.WriteTimeout > 0 {
+		p.writes = newTimeoutWorker(config.WriteTimeout)
+	}
+	if config.SyncInterval > 0 {
+		p.syncStop = make(chan struct{})
+		p.syncDone = make(chan struct{})
+		go syncLoop(p.sink, p.buffer, config.SyncInterval, p.diagnostics, p.syncStop, p.syncDone)
+	}
+	return p, nil
+}
+
+// Write дописывает запись в файл, при необходимости ротируя его.
+func (p *fileProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+
+	t := entryTime(ctx)
+	bp, line := acquireLineBuffer()
+	line = p.formatter.AppendFormat(line, Entry{
+		Time:    t,
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+	if p.writes == nil {
+		defer func() { releaseLineBuffer(bp, line) }()
+		return p.writeLine(level, t, line)
+	}
+	// Запись в файл не зависит от отмены контекста вызывающего, а буфер строки
+	// освобождает рабочий горутин: после ErrTimeout запись еще выполняется.
+	return p.writes.do(context.Background(), func() error {
+		defer func() { releaseLineBuffer(bp, line) }()
+		return p.writeLine(level, t, line)
+	})
+}
+
+// writeLine записывает отформатированную строку, при необходимости ротируя файл.
+func (p *fileProvider) writeLine(level Level, t time.Time, line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	switch {
+	case p.config.Rotation != RotateBySize:
+		if start := p.periodStart(t); start.After(p.period) {
+			if err := p.switchPeriodLocked(start); err != nil {
+				return err
+			}
+		} else if !p.sink.isOpen() {
+			if err := p.openLocked(); err != nil {
+				return err
+			}
+		}
+	case !p.sink.isOpen():
+		// Предыдущее открытие файла при ротации или Reopen не удалось
+		if err := p.openLocked(); err != nil {
+			return err
+		}
+	case p.config.MaxSize > 0 && p.size > 0 && p.size+int64(len(line)) > p.config.MaxSize:
+		if err := p.rotateLocked(); err != nil {
+			p.diagnostics.reportf("file provider", "rotate %s: %v; writing to the current file", p.config.Path, err)
+		}
+	}
+
+	n, err := p.writer().Write(line)
+	p.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("sglogger: write log file: %w", err)
+	}
+	p.sizes.observe(len(line))
+
+	if p.config.SyncEveryWrite {
+		return syncFile(p.sink, p.buffer)
+	}
+	// Ошибки и критические сообщения не должны задерживаться в буфере
+	if p.buffer != nil && level >= LevelError {
+		return p.buffer.Flush()
+	}
+	return nil
+}
+
+// writer возвращает writer строк: буфер или сам файл.
+func (p *fileProvider) writer() io.Writer {
+	if p.buffer != nil {
+		return p.buffer
+	}
+	return p.sink
+}
+
+// Reopen закрывает файл и открывает текущий файл заново, создавая его при
+// отсутствии (см. Reopener). Буферизованные строки записываются в прежний файл.
+func (p *fileProvider) Reopen() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if err := p.closeFileLocked(); err != nil {
+		p.diagnostics.reportf("file provider", "%v", err)
+	}
+	return p.openLocked()
+}
+
+// WriteTimeout возвращает ограничение времени одной записи (см. WriteTimeouter);
+// 0, если ограничение отключено.
+func (p *fileProvider) WriteTimeout() time.Duration {
+	if p.writes == nil {
+		return 0
+	}
+	return p.config.WriteTimeout
+}
+
+// Sync записывает буферизованные строки в файл и сбрасывает файл на диск
+// (см. Syncer).
+func (p *fileProvider) Sync() error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	return syncFile(p.sink, p.buffer)
+}
+
+// syncFile сбрасывает буфер, если он есть, и файл на диск.
+func syncFile(sink *fileSink, buffer *bufferedWriter) error {
+	if buffer != nil {
+		if err := buffer.Flush(); err != nil {
+			return fmt.Errorf("sglogger: flush log file: %w", err)
+		}
+	}
+	if err := sink.sync(); err != nil {
+		return fmt.Errorf("sglogger: sync log file: %w", err)
+	}
+	return nil
+}
+
+// syncLoop сбрасывает файл на диск каждые interval до закрытия stop.
+// Не ссылается на провайдер, чтобы незакрытый провайдер мог быть собран
+// сборщиком мусора и обнаружен LeakCheck.
+func syncLoop(sink *fileSink, buffer *bufferedWriter, interval time.Duration, diag *diagnostics, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Файл может быть закрыт на время ротации
+			if err := syncFile(sink, buffer); err != nil && !errors.Is(err, os.ErrClosed) {
+				diag.reportf("file provider", "%v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Name возвращает имя провайдера из конфигурации или "file" по умолчанию.
+func (p *fileProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "file"
+}
+
+// Describe возвращает путь, параметры ротации, уровень и формат (см. Describer).
+func (p *fileProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["format"] = describeFormatter(p.formatter)
+	description["path"] = p.config.Path
+	description["max_backups"] = p.config.MaxBackups
+	switch p.config.Rotation {
+	case RotateDaily:
+		description["rotation"] = "daily"
+	case RotateHourly:
+		description["rotation"] = "hourly"
+	default:
+		description["rotation"] = "size"
+		description["max_size"] = p.config.MaxSize
+	}
+	if p.config.MaxAgeDays > 0 {
+		description["max_age_days"] = p.config.MaxAgeDays
+	}
+	if p.config.Compress {
+		description["compress"] = true
+	}
+	if p.writes != nil {
+		description["write_timeout"] = p.config.WriteTimeout.String()
+	}
+	switch {
+	case p.config.SyncEveryWrite:
+		description["sync"] = "every_write"
+	case p.config.SyncInterval > 0:
+		description["sync"] = p.config.SyncInterval.String()
+	}
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *fileProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *fileProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// EntrySizes возвращает гистограмму размеров записанных строк.
+func (p *fileProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// Flush сбрасывает буферизованный вывод, если буферизация включена.
+func (p *fileProvider) Flush(ctx context.Context) error {
+	if p.buffer == nil {
+		return nil
+	}
+	return p.buffer.Flush()
+}
+
+// Close сбрасывает буфер, закрывает файл и ждет сжатия ротированных файлов.
+// Фоновые горутины останавливаются в любом случае. Если ctx истек, пока
+// выполнялась зависшая запись или сброс на диск, Close возвращает ошибку ctx,
+// а файл закрывается в фоне, как только они завершатся.
+func (p *fileProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	if !p.markClosed() {
+		return nil
+	}
+	var ctxErr error
+	if p.syncStop != nil {
+		close(p.syncStop)
+		select {
+		case <-p.syncDone:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		}
+	}
+	if p.writes != nil {
+		// Записи из очереди завершаются с ErrProviderClosed; зависшая запись
+		// удерживает мьютекс, поэтому ее ожидание ограничено ctx.
+		if err := p.writes.stop(ctx); err != nil && ctxErr == nil {
+			ctxErr = err
+		}
+	}
+	if ctxErr != nil {
+		go func() {
+			if p.syncDone != nil {
+				<-p.syncDone
+			}
+			if p.writes != nil {
+				<-p.writes.stopped
+			}
+			if err := p.closeFile(context.Background()); err != nil {
+				p.diagnostics.reportf("file provider", "close %s: %v", p.config.Path, err)
+			}
+		}()
+		return ctxErr
+	}
+	return p.closeFile(ctx)
+}
+
+// closeFile сбрасывает буфер, закрывает файл и ждет сжатия в пределах ctx.
+func (p *fileProvider) closeFile(ctx context.Context) error {
+	p.mu.Lock()
+	var err error
+	if p.buffer != nil {
+		err = p.buffer.Close()
+	}
+	if closeErr := p.closeFileLocked(); err == nil {
+		err = closeErr
+	}
+	p.mu.Unlock()
+
+	if p.compressor != nil {
+		if closeErr := p.compressor.close(ctx); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// rotateLocked переименовывает текущий файл в Path.1, сдвигает прежние копии
+// и открывает новый файл. Если переименование не удалось, открывается прежний
+// файл, чтобы запись могла продолжиться. Вызывается с захваченным мьютексом.
+func (p *fileProvider) rotateLocked() error {
+	err := p.closeFileLocked()
+	if err == nil {
+		err = p.shiftBackups()
+	}
+	if openErr := p.openLocked(); err == nil {
+		err = openErr
+	}
+	p.removeExpired()
+	return err
+}
+
+// switchPeriodLocked закрывает файл прошедшего периода, открывает файл периода
+// start и удаляет файлы сверх MaxBackups и старше MaxAgeDays.
+// Вызывается с захваченным мьютексом.
+func (p *fileProvider) switchPeriodLocked(start time.Time) error {
+	if err := p.closeFileLocked(); err != nil {
+		p.diagnostics.reportf("file provider", "%v", err)
+	}
+	if p.compressor != nil {
+		p.compressor.add(compressJob{path: p.currentPath()})
+	}
+	p.period = start
+	if err := p.openLocked(); err != nil {
+		return err
+	}
+	p.removeExpired()
+	return nil
+}
+
+// periodStart возвращает начало периода ротации, которому принадлежит t,
+// в местном времени.
+func (p *fileProvider) periodStart(t time.Time) time.Time {
+	t = t.Local()
+	hour := 0
+	if p.config.Rotation == RotateHourly {
+		hour = t.Hour()
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, time.Local)
+}
+
+// periodLayout возвращает формат даты в именах файлов.
+func (p *fileProvider) periodLayout() string {
+	if p.config.Rotation == RotateHourly {
+		return "2006-01-02-15"
+	}
+	return "2006-01-02"
+}
+
+// currentPath возвращает путь файла, в который сейчас выполняется запись:
+// config.Path или, при ротации по времени, путь с датой текущего периода.
+func (p *fileProvider) currentPath() string {
+	if p.config.Rotation == RotateBySize {
+		return p.config.Path
+	}
+	ext := filepath.Ext(p.config.Path)
+	return strings.TrimSuffix(p.config.Path, ext) + "-" + p.period.Format(p.periodLayout()) + ext
+}
+
+// rotatedFiles возвращает ротированные файлы, сжатые и несжатые, от самого
+// нового к самому старому. Вызывается с захваченным backupsMu.
+func (p *fileProvider) rotatedFiles() []string {
+	if p.config.Rotation == RotateBySize {
+		var files []string
+		for i := 1; i <= p.config.MaxBackups; i++ {
+			for _, path := range []string{p.backupPath(i), p.backupPath(i) + compressedSuffix} {
+				if _, err := os.Stat(path); err == nil {
+					files = append(files, path)
+				}
+			}
+		}
+		return files
+	}
+
+	dir := filepath.Dir(p.config.Path)
+	ext := filepath.Ext(p.config.Path)
+	prefix := strings.TrimSuffix(filepath.Base(p.config.Path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	current := p.currentPath()
+	var files []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), compressedSuffix)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		period := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		path := filepath.Join(dir, entry.Name())
+		if _, err := time.ParseInLocation(p.periodLayout(), period, time.Local); err != nil || path == current {
+			continue
+		}
+		files = append(files, path)
+	}
+	// Даты в именах упорядочены так же, как строки
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files
+}
+
+// removeExpired удаляет ротированные файлы сверх MaxBackups и старше
+// MaxAgeDays. Ошибки передаются в диагностический канал.
+func (p *fileProvider) removeExpired() {
+	p.backupsMu.Lock()
+	defer p.backupsMu.Unlock()
+
+	keep := p.config.MaxBackups
+	if keep < 0 {
+		keep = 0
+	}
+	var cutoff time.Time
+	if p.config.MaxAgeDays > 0 {
+		cutoff = fileProviderNow().AddDate(0, 0, -p.config.MaxAgeDays)
+	}
+
+	for i, path := range p.rotatedFiles() {
+		if i < keep && !modifiedBefore(path, cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			p.diagnostics.reportf("file provider", "remove expired log file: %v", err)
+		}
+	}
+}
+
+// modifiedBefore сообщает, изменялся ли файл в последний раз раньше cutoff.
+// Для нулевого cutoff возвращает false.
+func modifiedBefore(path string, cutoff time.Time) bool {
+	if cutoff.IsZero() {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.ModTime().Before(cutoff)
+}
+
+// shiftBackups удаляет самую старую копию и сдвигает остальные на один номер,
+// сжатые вместе с несжатыми, освобождая Path.1 для текущего файла.
+// При отрицательном MaxBackups текущий файл удаляется.
+func (p *fileProvider) shiftBackups() error {
+	if p.config.MaxBackups < 0 {
+		if err := os.Remove(p.config.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("sglogger: remove log file: %w", err)
+		}
+		return nil
+	}
+
+	p.backupsMu.Lock()
+	defer p.backupsMu.Unlock()
+
+	for _, suffix := range []string{"", compressedSuffix} {
+		if err := os.Remove(p.backupPath(p.config.MaxBackups) + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("sglogger: remove log backup: %w", err)
+		}
+	}
+	// Задания сжатия, поставленные раньше, пересчитывают номер своей копии
+	p.shifts++
+	for i := p.config.MaxBackups - 1; i >= 1; i-- {
+		for _, suffix := range []string{"", compressedSuffix} {
+			if err := os.Rename(p.backupPath(i)+suffix, p.backupPath(i+1)+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("sglogger: rename log backup: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(p.config.Path, p.backupPath(1)); err != nil {
+		return fmt.Errorf("sglogger: rename log file: %w", err)
+	}
+	if p.compressor != nil {
+		p.compressor.add(compressJob{index: 1, shift: p.shifts})
+	}
+	return nil
+}
+
+// backupPath возвращает путь копии с номером n.
+func (p *fileProvider) backupPath(n int) string {
+	return p.config.Path + "." + strconv.Itoa(n)
+}
+
+// openLocked открывает текущий файл для дописывания и запоминает его размер.
+// Вызывается с захваченным мьютексом или при создании провайдера.
+func (p *fileProvider) openLocked() error {
+	file, err := p.openFile(p.currentPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, p.config.FileMode)
+	if err != nil {
+		return fmt.Errorf("sglogger: open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("sglogger: stat log file: %w", err)
+	}
+	p.sink.swap(file)
+	if p.buffer != nil {
+		p.buffer.reset(p.sink)
+	}
+	p.size = info.Size()
+	return nil
+}
+
+// closeFileLocked сбрасывает буфер в текущий файл и закрывает его; при
+// SyncEveryWrite или SyncInterval файл перед закрытием сбрасывается на диск.
+// Вызывается с захваченным мьютексом.
+func (p *fileProvider) closeFileLocked() error {
+	var err error
+	if p.buffer != nil {
+		if err = p.buffer.Flush(); err != nil {
+			err = fmt.Errorf("sglogger: flush log file: %w", err)
+		}
+	}
+	file := p.sink.swap(nil)
+	if file == nil {
+		return err
+	}
+	if p.config.SyncEveryWrite || p.config.SyncInterval > 0 {
+		if syncErr := file.Sync(); syncErr != nil && err == nil {
+			err = fmt.Errorf("sglogger: sync log file: %w", syncErr)
+		}
+	}
+	if closeErr := file.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("sglogger: close log file: %w", closeErr)
+	}
+	return err
+}
@@ -0,0 +1,630 @@
+package sglogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingFile считает вызовы Sync настоящего файла.
+type countingFile struct {
+	*os.File
+	syncs *int32
+}
+
+func (f countingFile) Sync() error {
+	atomic.AddInt32(f.syncs, 1)
+	return f.File.Sync()
+}
+
+// newCountingFileProvider создает файловый провайдер, считающий вызовы Sync.
+func newCountingFileProvider(t *testing.T, config FileProviderConfig) (*fileProvider, *int32) {
+	t.Helper()
+
+	syncs := new(int32)
+	p, err := newFileProvider(config, func(name string, flag int, perm os.FileMode) (logFile, error) {
+		file, err := os.OpenFile(name, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return countingFile{File: file, syncs: syncs}, nil
+	})
+	if err != nil {
+		t.Fatalf("newFileProvider: %v", err)
+	}
+	t.Cleanup(func() { p.Close(context.Background()) })
+	return p, syncs
+}
+
+func TestFileProviderModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file mode bits are not supported on windows")
+	}
+	dir := filepath.Join(t.TempDir(), "audit")
+	path := filepath.Join(dir, "audit.log")
+	provider, err := NewFileProvider(FileProviderConfig{Path: path, FileMode: 0o600, DirMode: 0o700})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	for name, want := range map[string]os.FileMode{path: 0o600, dir: 0o700} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %o, want %o", name, got, want)
+		}
+	}
+}
+
+func TestFileProviderSyncEveryWrite(t *testing.T) {
+	p, syncs := newCountingFileProvider(t, FileProviderConfig{
+		Path:           filepath.Join(t.TempDir(), "app.log"),
+		SyncEveryWrite: true,
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := p.Write(context.Background(), LevelInfo, "entry", nil); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(syncs); got != 3 {
+		t.Errorf("syncs after 3 writes = %d, want 3", got)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := atomic.LoadInt32(syncs); got != 4 {
+		t.Errorf("syncs after Close = %d, want 4", got)
+	}
+}
+
+func TestFileProviderSyncInterval(t *testing.T) {
+	p, syncs := newCountingFileProvider(t, FileProviderConfig{
+		ProviderConfig: ProviderConfig{BufferSize: 4096, FlushInterval: time.Hour},
+		Path:           filepath.Join(t.TempDir(), "app.log"),
+		SyncInterval:   10 * time.Millisecond,
+	})
+
+	if err := p.Write(context.Background(), LevelInfo, "entry", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := atomic.LoadInt32(syncs); got != 0 {
+		t.Errorf("syncs right after Write = %d, want 0", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(syncs) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("background sync did not run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Фоновый сброс записывает и буфер
+	data, err := os.ReadFile(p.config.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		t.Error("buffered entry was not written before sync")
+	}
+}
+
+func TestFileProviderSync(t *testing.T) {
+	p, syncs := newCountingFileProvider(t, FileProviderConfig{
+		ProviderConfig: ProviderConfig{BufferSize: 4096},
+		Path:           filepath.Join(t.TempDir(), "app.log"),
+	})
+
+	if err := p.Write(context.Background(), LevelInfo, "entry", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := p.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := atomic.LoadInt32(syncs); got != 1 {
+		t.Errorf("syncs = %d, want 1", got)
+	}
+	if info, err := os.Stat(p.config.Path); err != nil || info.Size() == 0 {
+		t.Errorf("Sync did not flush the buffer: %v", err)
+	}
+
+	p.Close(context.Background())
+	if err := p.Sync(); err != ErrProviderClosed {
+		t.Errorf("Sync after Close = %v, want ErrProviderClosed", err)
+	}
+}
+
+// setFileNow подменяет текущее время файлового провайдера до конца теста.
+func setFileNow(t *testing.T, now time.Time) {
+	t.Helper()
+	saved := fileProviderNow
+	fileProviderNow = func() time.Time { return now }
+	t.Cleanup(func() { fileProviderNow = saved })
+}
+
+// writeAt записывает запись со временем at.
+func writeAt(t *testing.T, p LoggerProvider, at time.Time, message string) {
+	t.Helper()
+	if err := p.Write(ContextWithEntryTime(context.Background(), at), LevelInfo, message, nil); err != nil {
+		t.Fatalf("Write(%s): %v", message, err)
+	}
+}
+
+// logFiles возвращает отсортированные имена файлов каталога dir.
+func logFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readLog возвращает содержимое файла name каталога dir.
+func readLog(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileProviderDailyRotation(t *testing.T) {
+	midnight := time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)
+	setFileNow(t, midnight.Add(-time.Minute))
+	dir := t.TempDir()
+	provider, err := NewFileProvider(FileProviderConfig{Path: filepath.Join(dir, "app.log"), Rotation: RotateDaily})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	writeAt(t, provider, midnight.Add(-time.Millisecond), "before midnight")
+	writeAt(t, provider, midnight.Add(time.Millisecond), "after midnight")
+	// К прошедшему дню провайдер не возвращается
+	writeAt(t, provider, midnight.Add(-time.Second), "late entry")
+
+	if got, want := logFiles(t, dir), []string{"app-2024-05-01.log", "app-2024-05-02.log"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	if got := readLog(t, dir, "app-2024-05-01.log"); !strings.Contains(got, "before midnight") || strings.Contains(got, "after midnight") {
+		t.Errorf("app-2024-05-01.log = %q", got)
+	}
+	if got := readLog(t, dir, "app-2024-05-02.log"); !strings.Contains(got, "after midnight") || !strings.Contains(got, "late entry") {
+		t.Errorf("app-2024-05-02.log = %q", got)
+	}
+}
+
+func TestFileProviderHourlyRotation(t *testing.T) {
+	start := time.Date(2024, 5, 1, 15, 30, 0, 0, time.Local)
+	setFileNow(t, start)
+	dir := t.TempDir()
+	provider, err := NewFileProvider(FileProviderConfig{Path: filepath.Join(dir, "app.log"), Rotation: RotateHourly})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	writeAt(t, provider, start, "15:30")
+	writeAt(t, provider, start.Add(29*time.Minute), "15:59")
+	writeAt(t, provider, start.Add(30*time.Minute), "16:00")
+
+	if got, want := logFiles(t, dir), []string{"app-2024-05-01-15.log", "app-2024-05-01-16.log"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	if got := readLog(t, dir, "app-2024-05-01-15.log"); strings.Count(got, "\n") != 2 {
+		t.Errorf("app-2024-05-01-15.log = %q, want 2 entries", got)
+	}
+}
+
+func TestFileProviderRotationConcurrentBoundary(t *testing.T) {
+	midnight := time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)
+	setFileNow(t, midnight.Add(-time.Minute))
+	dir := t.TempDir()
+	provider, err := NewFileProvider(FileProviderConfig{Path: filepath.Join(dir, "app.log"), Rotation: RotateDaily})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				// Время записей чередуется по обе стороны полуночи
+				at := midnight.Add(time.Duration(i%2*2-1) * time.Millisecond)
+				ctx := ContextWithEntryTime(context.Background(), at)
+				if err := provider.Write(ctx, LevelInfo, fmt.Sprintf("w%d-%d", w, i), nil); err != nil {
+					t.Errorf("Write: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := provider.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if got, want := logFiles(t, dir), []string{"app-2024-05-01.log", "app-2024-05-02.log"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	before, after := readLog(t, dir, "app-2024-05-01.log"), readLog(t, dir, "app-2024-05-02.log")
+	if total := strings.Count(before, "\n") + strings.Count(after, "\n"); total != writers*perWriter {
+		t.Errorf("files hold %d entries, want %d", total, writers*perWriter)
+	}
+	// Записи после полуночи не попадают в файл прошедшего дня
+	for w := 0; w < writers; w++ {
+		for i := 1; i < perWriter; i += 2 {
+			if strings.Contains(before, fmt.Sprintf("w%d-%d\n", w, i)) {
+				t.Fatalf("entry w%d-%d after midnight is in the previous day's file", w, i)
+			}
+		}
+	}
+}
+
+// createDatedLogs создает файлы лога за дни days с временем изменения
+// в полдень соответствующего дня, а также файлы с посторонними именами.
+func createDatedLogs(t *testing.T, dir string, days ...time.Time) {
+	t.Helper()
+	for _, name := range []string{"app-notes.log", "other-2024-04-01.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old entry\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, day := range days {
+		path := filepath.Join(dir, "app-"+day.Format("2006-01-02")+".log")
+		if err := os.WriteFile(path, []byte("old entry\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		noon := day.Add(12 * time.Hour)
+		if err := os.Chtimes(path, noon, noon); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileProviderRotationMaxBackups(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.Local) }
+	setFileNow(t, day(1).Add(12*time.Hour))
+	dir := t.TempDir()
+	createDatedLogs(t, dir, day(1).AddDate(0, 0, -5), day(1).AddDate(0, 0, -4), day(1).AddDate(0, 0, -3), day(1).AddDate(0, 0, -2), day(1).AddDate(0, 0, -1))
+
+	provider, err := NewFileProvider(FileProviderConfig{Path: filepath.Join(dir, "app.log"), Rotation: RotateDaily, MaxBackups: 3})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	// При создании остаются три самых новых файла
+	want := []string{"app-2024-04-28.log", "app-2024-04-29.log", "app-2024-04-30.log", "app-2024-05-01.log", "app-notes.log", "other-2024-04-01.log"}
+	if got := logFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Fatalf("files after start = %v, want %v", got, want)
+	}
+
+	writeAt(t, provider, day(2), "next day")
+	want = []string{"app-2024-04-29.log", "app-2024-04-30.log", "app-2024-05-01.log", "app-2024-05-02.log", "app-notes.log", "other-2024-04-01.log"}
+	if got := logFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files after switch = %v, want %v", got, want)
+	}
+}
+
+func TestFileProviderRotationMaxAge(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.Local) }
+	setFileNow(t, day(1).Add(12*time.Hour))
+	dir := t.TempDir()
+	createDatedLogs(t, dir, day(1).AddDate(0, 0, -3), day(1).AddDate(0, 0, -2), day(1).AddDate(0, 0, -1))
+
+	provider, err := NewFileProvider(FileProviderConfig{Path: filepath.Join(dir, "app.log"), Rotation: RotateDaily, MaxBackups: 10, MaxAgeDays: 2})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	// Файл, измененный 28 апреля в полдень, старше двух дней
+	want := []string{"app-2024-04-29.log", "app-2024-04-30.log", "app-2024-05-01.log", "app-notes.log", "other-2024-04-01.log"}
+	if got := logFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Fatalf("files after start = %v, want %v", got, want)
+	}
+
+	// Через сутки устаревает и следующий файл
+	setFileNow(t, day(2).Add(12*time.Hour))
+	writeAt(t, provider, day(2), "next day")
+	want = []string{"app-2024-04-30.log", "app-2024-05-01.log", "app-2024-05-02.log", "app-notes.log", "other-2024-04-01.log"}
+	if got := logFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files after switch = %v, want %v", got, want)
+	}
+}
+
+// messageFormatter выводит только сообщение записи.
+type messageFormatter struct{}
+
+func (messageFormatter) AppendFormat(buf []byte, e Entry) []byte {
+	return append(append(buf, e.Message...), '\n')
+}
+
+func TestFileProviderSizeRotation(t *testing.T) {
+	const (
+		maxSize   = 1000
+		writers   = 4
+		perWriter = 50
+	)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	provider, err := NewFileProvider(FileProviderConfig{
+		ProviderConfig: ProviderConfig{Formatter: messageFormatter{}},
+		Path:           path,
+		MaxSize:        maxSize,
+		MaxBackups:     100,
+	})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				message := fmt.Sprintf("writer %d entry %03d %s", w, i, strings.Repeat("x", 30))
+				if err := provider.Write(context.Background(), LevelInfo, message, nil); err != nil {
+					t.Errorf("Write: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := provider.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Каждая строка целиком попадает ровно в один файл не больше MaxSize
+	seen := make(map[string]int)
+	for _, name := range logFiles(t, dir) {
+		data := readLog(t, dir, name)
+		if len(data) > maxSize {
+			t.Errorf("%s is %d bytes, want at most %d", name, len(data), maxSize)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+			if !strings.HasPrefix(line, "writer ") || !strings.HasSuffix(line, strings.Repeat("x", 30)) {
+				t.Errorf("%s has a split line %q", name, line)
+			}
+			seen[line]++
+		}
+	}
+	if len(seen) != writers*perWriter {
+		t.Errorf("files hold %d distinct lines, want %d", len(seen), writers*perWriter)
+	}
+	for line, n := range seen {
+		if n != 1 {
+			t.Errorf("line %q written %d times", line, n)
+		}
+	}
+}
+
+func TestFileProviderSizeRotationMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	provider, err := NewFileProvider(FileProviderConfig{Path: filepath.Join(dir, "app.log"), MaxSize: 100, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		if err := provider.Write(context.Background(), LevelInfo, fmt.Sprintf("entry %d %s", i, strings.Repeat("x", 60)), nil); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if got, want := logFiles(t, dir), []string{"app.log", "app.log.1", "app.log.2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	// В app.log.1 - предпоследняя запись, в app.log.2 - запись перед ней
+	for name, want := range map[string]string{"app.log": "entry 9", "app.log.1": "entry 8", "app.log.2": "entry 7"} {
+		if got := readLog(t, dir, name); !strings.Contains(got, want) {
+			t.Errorf("%s = %q, want %s", name, got, want)
+		}
+	}
+}
+
+func TestFileProviderCompress(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string) []string // возвращает строки, оставшиеся от прошлого запуска
+	}{
+		{name: "size rotation", setup: func(t *testing.T, dir string) []string { return nil }},
+		{
+			name: "recovered after crash",
+			setup: func(t *testing.T, dir string) []string {
+				var compressed bytes.Buffer
+				zw := gzip.NewWriter(&compressed)
+				zw.Write([]byte("old 2\n"))
+				zw.Close()
+				// Несжатая копия, копия, сжатая до сбоя, и недописанный временный файл
+				files := map[string][]byte{
+					"app.log.1":       []byte("old 1\n"),
+					"app.log.2":       []byte("old 2\n"),
+					"app.log.2.gz":    compressed.Bytes(),
+					".app.log.gz.tmp": []byte("partial"),
+				}
+				for name, data := range files {
+					if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				return []string{"old 1", "old 2"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			want := tt.setup(t, dir)
+			provider, err := NewFileProvider(FileProviderConfig{
+				ProviderConfig: ProviderConfig{Formatter: messageFormatter{}},
+				Path:           filepath.Join(dir, "app.log"),
+				MaxSize:        200,
+				MaxBackups:     100,
+				Compress:       true,
+			})
+			if err != nil {
+				t.Fatalf("NewFileProvider: %v", err)
+			}
+			for i := 0; i < 20; i++ {
+				message := fmt.Sprintf("entry %02d %s", i, strings.Repeat("x", 40))
+				if err := provider.Write(context.Background(), LevelInfo, message, nil); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+				want = append(want, message)
+			}
+			// Close ждет завершения сжатия
+			if err := provider.Close(context.Background()); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			var got []string
+			compressed := 0
+			for _, name := range logFiles(t, dir) {
+				data := readLog(t, dir, name)
+				switch {
+				case name == "app.log":
+				case strings.HasSuffix(name, ".gz"):
+					compressed++
+					data = string(gunzip(t, []byte(data)))
+				default:
+					t.Errorf("%s is left uncompressed", name)
+				}
+				got = append(got, strings.Split(strings.TrimSuffix(data, "\n"), "\n")...)
+			}
+			if compressed == 0 {
+				t.Fatalf("files = %v, want rotated files compressed", logFiles(t, dir))
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("lines = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// hungFile - файл, запись в который ждет закрытия release; считает вызовы Close.
+type hungFile struct {
+	*os.File
+	release chan struct{}
+	closes  *int32
+}
+
+func (f hungFile) Write(b []byte) (int, error) {
+	<-f.release
+	return f.File.Write(b)
+}
+
+func (f hungFile) Close() error {
+	atomic.AddInt32(f.closes, 1)
+	return f.File.Close()
+}
+
+// newHungFileProvider создает файловый провайдер, записи которого зависают
+// до закрытия release.
+func newHungFileProvider(t *testing.T, release chan struct{}) (*fileProvider, *int32) {
+	t.Helper()
+
+	closes := new(int32)
+	p, err := newFileProvider(FileProviderConfig{
+		Path:         filepath.Join(t.TempDir(), "app.log"),
+		WriteTimeout: 50 * time.Millisecond,
+	}, func(name string, flag int, perm os.FileMode) (logFile, error) {
+		file, err := os.OpenFile(name, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return hungFile{File: file, release: release, closes: closes}, nil
+	})
+	if err != nil {
+		t.Fatalf("newFileProvider: %v", err)
+	}
+	return p, closes
+}
+
+// waitClosed ждет, пока файл провайдера не будет закрыт.
+func waitClosed(t *testing.T, closes *int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(closes) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("file was not closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFileProviderCloseExpiredContext(t *testing.T) {
+	release := make(chan struct{})
+	p, closes := newHungFileProvider(t, release)
+
+	if err := p.Write(context.Background(), LevelInfo, "hung", nil); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Write = %v, want ErrTimeout", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Close returned after %s, want about 50ms", elapsed)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+
+	// Файл закрывается, как только зависшая запись завершится
+	close(release)
+	waitClosed(t, closes)
+	if data, err := os.ReadFile(p.config.Path); err != nil || !strings.Contains(string(data), "hung") {
+		t.Errorf("hung entry was not written before close: %q, %v", data, err)
+	}
+}
+
+func TestFileProviderCloseCanceledContext(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	p, closes := newHungFileProvider(t, release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Close(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("Close = %v, want nil or context.Canceled", err)
+	}
+	waitClosed(t, closes)
+	if err := p.Write(context.Background(), LevelInfo, "late", nil); err != ErrProviderClosed {
+		t.Errorf("Write after Close = %v, want ErrProviderClosed", err)
+	}
+}
@@ -0,0 +1,149 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// filterProvider оборачивает LoggerProvider и отбрасывает записи по тексту сообщения.
+type filterProvider struct {
+	closedState
+
+	inner    LoggerProvider
+	name     string
+	allow    []*regexp.Regexp
+	deny     []*regexp.Regexp
+	filtered uint64
+}
+
+// NewFilterProvider создает обертку, которая передает внутреннему провайдеру
+// только записи, прошедшие фильтр сообщений (см. FilterConfig). Позволяет,
+// например, оставить шумные сообщения сторонней библиотеки в локальном файле,
+// но не отправлять их в Loki. Отброшенные записи учитываются в Stats (см. Filterer).
+// Шаблоны компилируются при создании; возвращает ошибку для некорректного шаблона
+// или если внутренний провайдер не задан.
+func NewFilterProvider(inner LoggerProvider, config FilterConfig) (LoggerProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("sglogger: filter provider requires an inner provider")
+	}
+
+	p := &filterProvider{inner: inner, name: config.Name}
+	for _, pattern := range config.AllowMessages {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: invalid allow pattern %q: %w", pattern, err)
+		}
+		p.allow = append(p.allow, re)
+	}
+	for _, pattern := range config.DenyMessages {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: invalid deny pattern %q: %w", pattern, err)
+		}
+		p.deny = append(p.deny, re)
+	}
+	for _, glob := range config.DenyGlobs {
+		if glob == "" {
+			return nil, fmt.Errorf("sglogger: empty deny glob")
+		}
+		p.deny = append(p.deny, regexp.MustCompile(globToRegexp(glob)))
+	}
+	return p, nil
+}
+
+// globToRegexp преобразует шаблон с * и ? в регулярное выражение,
+// совпадающее со всей строкой.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString(`^(?s:`)
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`)$`)
+	return b.String()
+}
+
+// allowed сообщает, проходит ли сообщение фильтр.
+func (p *filterProvider) allowed(message string) bool {
+	for _, re := range p.allow {
+		if re.MatchString(message) {
+			return true
+		}
+	}
+	for _, re := range p.deny {
+		if re.MatchString(message) {
+			return false
+		}
+	}
+	return true
+}
+
+// Write передает запись внутреннему провайдеру, если сообщение проходит фильтр.
+func (p *filterProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.allowed(message) {
+		atomic.AddUint64(&p.filtered, 1)
+		return nil
+	}
+	return p.inner.Write(ctx, level, message, fields)
+}
+
+// Filtered возвращает количество отброшенных записей.
+func (p *filterProvider) Filtered() uint64 {
+	return atomic.LoadUint64(&p.filtered)
+}
+
+// Name возвращает имя из конфигурации или имя внутреннего провайдера.
+func (p *filterProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return ProviderName(p.inner)
+}
+
+// Describe возвращает количество шаблонов и описание внутреннего провайдера (см. Describer).
+func (p *filterProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"allow_patterns": len(p.allow),
+		"deny_patterns":  len(p.deny),
+		"inner":          describeProvider(p.inner),
+	}
+}
+
+// Active делегирует проверку активности внутреннему провайдеру.
+func (p *filterProvider) Active() bool {
+	return providerActive(p.inner)
+}
+
+// ShouldLog делегирует проверку уровня внутреннему провайдеру.
+func (p *filterProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.inner.ShouldLog(ctx, level)
+}
+
+// Flush сбрасывает буферизованный вывод внутреннего провайдера.
+func (p *filterProvider) Flush(ctx context.Context) error {
+	if flusher, ok := p.inner.(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// Close прекращает прием записей и закрывает внутренний провайдер (см. ChainClose).
+func (p *filterProvider) Close(ctx context.Context) error {
+	if !p.markClosed() {
+		return nil
+	}
+	return ChainClose(ctx, p.inner, nil)
+}
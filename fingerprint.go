@@ -0,0 +1,35 @@
+package sglogger
+
+import (
+	"hash/fnv"
+	"regexp"
+	"strconv"
+)
+
+// FingerprintField - имя поля с отпечатком сообщения. Если вызывающий код
+// передает это поле явно, его значение не перезаписывается.
+const FingerprintField = "fingerprint"
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexPattern    = regexp.MustCompile(`\b(?:0[xX][0-9a-fA-F]+|[0-9a-fA-F]*[0-9][0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*|[0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*[0-9][0-9a-fA-F]*)\b`)
+	digitsPattern = regexp.MustCompile(`[0-9]+`)
+)
+
+// NormalizeMessage заменяет изменчивые части сообщения на заполнители:
+// UUID на <uuid>, шестнадцатеричные последовательности на <hex>, числа на <n>.
+// Например, "failed to process order 12345" превращается в "failed to process order <n>".
+func NormalizeMessage(message string) string {
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = hexPattern.ReplaceAllString(message, "<hex>")
+	return digitsPattern.ReplaceAllString(message, "<n>")
+}
+
+// Fingerprint вычисляет стабильный отпечаток шаблона сообщения для группировки ошибок.
+// Шаблон предварительно нормализуется, поэтому сообщения без параметров форматирования,
+// отличающиеся только идентификаторами, получают одинаковый отпечаток.
+func Fingerprint(template string) string {
+	h := fnv.New64a()
+	h.Write([]byte(NormalizeMessage(template)))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
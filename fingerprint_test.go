@@ -0,0 +1,77 @@
+package sglogger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	for _, tt := range []struct {
+		message string
+		want    string
+	}{
+		{"failed to process order 12345", "failed to process order <n>"},
+		{"user 550e8400-e29b-41d4-a716-446655440000 not found", "user <uuid> not found"},
+		{"commit 3f9a2c1 rejected", "commit <hex> rejected"},
+		{"bad pointer 0x7ffd5a3c", "bad pointer <hex>"},
+		{"retry 3 of 5 after 250ms", "retry <n> of <n> after <n>ms"},
+		{"connection refused", "connection refused"},
+		// Слова из букв a-f без цифр не считаются шестнадцатеричными
+		{"cafe added to feed", "cafe added to feed"},
+	} {
+		if got := NormalizeMessage(tt.message); got != tt.want {
+			t.Errorf("NormalizeMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint("failed to process order 12345")
+	if b := Fingerprint("failed to process order 67890"); a != b {
+		t.Errorf("messages differing by an id got fingerprints %s and %s", a, b)
+	}
+	if c := Fingerprint("failed to ship order 12345"); a == c {
+		t.Errorf("different messages share fingerprint %s", a)
+	}
+}
+
+func TestLoggerFingerprintField(t *testing.T) {
+	provider := &recordingProvider{}
+	logger := NewLogger(LoggerConfig{Fingerprint: true}, NewFieldsHandler(), provider)
+
+	ctx := context.Background()
+	logger.Error(ctx, "failed to process order %d", 12345)
+	logger.Error(ctx, "failed to process order %d", 67890)
+	logger.ErrorWithFields(ctx, Fields{FingerprintField: "orders"}, "failed to process order %d", 1)
+
+	entries := provider.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("provider received %d entries, want 3", len(entries))
+	}
+	// Отпечаток вычисляется по шаблону, а не по отформатированному сообщению
+	first, second := entries[0].Fields[FingerprintField], entries[1].Fields[FingerprintField]
+	if first == nil || first != second {
+		t.Errorf("fingerprints = %v and %v, want equal and set", first, second)
+	}
+	if got := entries[2].Fields[FingerprintField]; got != "orders" {
+		t.Errorf("explicit fingerprint = %v, want orders", got)
+	}
+}
+
+func TestSentryProviderFingerprint(t *testing.T) {
+	provider, err := NewSentryProvider("https://public@sentry.example.com/1")
+	if err != nil {
+		t.Fatalf("NewSentryProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	event := provider.(*sentryProvider).appendEvent(nil, newSentryEventID(), Entry{
+		Level:   LevelError,
+		Message: "failed to process order 12345",
+		Fields:  Fields{FingerprintField: "3c1f"},
+	})
+	if !strings.Contains(string(event), `"fingerprint":["3c1f"]`) {
+		t.Errorf("event = %s, want the fingerprint passed through", event)
+	}
+}
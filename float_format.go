@@ -0,0 +1,35 @@
+package sglogger
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// appendFloat добавляет число с плавающей точкой в текстовом виде.
+// Без заданной точности используется кратчайшее представление (как в %v),
+// иначе - фиксированное количество знаков после точки. Разделитель дробной
+// части всегда точка, независимо от локали.
+func appendFloat(buf []byte, f float64, bitSize int, format FloatFormat) []byte {
+	if format.Precision > 0 && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return strconv.AppendFloat(buf, f, 'f', format.Precision, bitSize)
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bitSize)
+}
+
+// jsonFloat возвращает значение для сериализации числа с плавающей точкой в JSON.
+// NaN и ±Inf не представимы в JSON: при NonFiniteAsString они заменяются строками
+// "NaN", "+Inf" и "-Inf", иначе возвращается ошибка.
+func jsonFloat(f float64, bitSize int, format FloatFormat) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		s := strconv.FormatFloat(f, 'g', -1, bitSize)
+		if !format.NonFiniteAsString {
+			return nil, &json.UnsupportedValueError{Str: s}
+		}
+		return s, nil
+	}
+	if format.Precision > 0 {
+		return json.Number(strconv.FormatFloat(f, 'f', format.Precision, bitSize)), nil
+	}
+	return f, nil
+}
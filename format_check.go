@@ -0,0 +1,36 @@
+package sglogger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// FormatErrorField добавляется со значением true к записям, в сообщении которых
+// найдены признаки ошибки форматирования (см. LoggerConfig.StrictFormat).
+const FormatErrorField = "format_error"
+
+// formatErrorMarker - общий префикс всех ошибок форматирования fmt:
+// "%!s(MISSING)", "%!(EXTRA int=1)", "%!d(string=x)", "%!(NOVERB)",
+// "%!(BADWIDTH)", "%!v(PANIC=String method: ...)" и других.
+const formatErrorMarker = "%!"
+
+// formatCallerSkip - количество кадров между formatCaller и вызовом
+// метода логгера в пользовательском коде: formatCaller, writeLog, метод Logger.
+const formatCallerSkip = 3
+
+// hasFormatError сообщает, содержит ли сообщение, полученное fmt.Sprintf,
+// признак ошибки форматирования. Значения аргументов, содержащие "%!",
+// тоже считаются ошибкой: проверка ограничена одним поиском подстроки.
+func hasFormatError(message string) bool {
+	return strings.Contains(message, formatErrorMarker)
+}
+
+// formatCaller возвращает место вызова метода логгера в виде "file.go:123".
+func formatCaller() string {
+	_, file, line, ok := runtime.Caller(formatCallerSkip)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
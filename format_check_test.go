@@ -0,0 +1,112 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// formatCase - формат и аргументы, которые fmt отображает с ошибкой или без нее.
+type formatCase struct {
+	name    string
+	format  string
+	args    []interface{}
+	wantErr bool
+}
+
+// formatCases покрывает все виды ошибок, которые выводит fmt.
+var formatCases = []formatCase{
+	{name: "valid", format: "user %s logged in %d times", args: []interface{}{"bob", 3}},
+	{name: "escaped percent", format: "disk 100%% full", args: nil},
+	{name: "percent in argument value", format: "progress %s", args: []interface{}{"50%"}},
+	{name: "missing argument", format: "user %s from %s", args: []interface{}{"bob"}, wantErr: true},
+	{name: "extra argument", format: "user %s", args: []interface{}{"bob", 42}, wantErr: true},
+	{name: "wrong type", format: "count %d", args: []interface{}{"many"}, wantErr: true},
+	{name: "bad index", format: "%[3]s", args: []interface{}{"a"}, wantErr: true},
+	{name: "no verb", format: "trailing %", args: []interface{}{1}, wantErr: true},
+	{name: "bad width", format: "%*d", args: []interface{}{"wide", 1}, wantErr: true},
+	{name: "bad precision", format: "%.*d", args: []interface{}{"precise", 1}, wantErr: true},
+	{name: "panicking String", format: "value %v", args: []interface{}{panickingStringer{}}, wantErr: true},
+	{name: "nil Stringer", format: "value %s", args: []interface{}{(*strings.Builder)(nil)}},
+}
+
+func TestHasFormatError(t *testing.T) {
+	for _, tt := range formatCases {
+		t.Run(tt.name, func(t *testing.T) {
+			message := fmt.Sprintf(tt.format, tt.args...)
+			if got := hasFormatError(message); got != tt.wantErr {
+				t.Errorf("hasFormatError(%q) = %v, want %v", message, got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStrictFormat(t *testing.T) {
+	for _, tt := range formatCases {
+		t.Run(tt.name, func(t *testing.T) {
+			diag := &syncBuffer{}
+			recorder := &recordingProvider{}
+			config := LoggerConfig{StrictFormat: true, Diagnostics: &DiagnosticsConfig{Output: diag}}
+			logger := NewLogger(config, NewFieldsHandler(), recorder)
+
+			logger.Info(context.Background(), tt.format, tt.args...)
+
+			entries := recorder.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1 (entries with format errors are still written)", len(entries))
+			}
+			if got := entries[0].Fields[FormatErrorField] == true; got != tt.wantErr {
+				t.Errorf("%s set = %v, want %v", FormatErrorField, got, tt.wantErr)
+			}
+			report := diag.String()
+			if tt.wantErr && !strings.Contains(report, "format_check_test.go:") {
+				t.Errorf("diagnostics = %q, want the caller of Info", report)
+			}
+			if !tt.wantErr && report != "" {
+				t.Errorf("unexpected diagnostics: %q", report)
+			}
+		})
+	}
+}
+
+func TestStrictFormatModes(t *testing.T) {
+	format, args := "user %s from %s", []interface{}{"bob"}
+
+	tests := []struct {
+		name      string
+		config    LoggerConfig
+		wantField bool
+		wantPanic bool
+	}{
+		{name: "disabled", config: LoggerConfig{}},
+		{name: "strict", config: LoggerConfig{StrictFormat: true}, wantField: true},
+		{name: "panic on format error", config: LoggerConfig{StrictFormat: true, PanicOnFormatError: true}, wantField: true, wantPanic: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingProvider{}
+			tt.config.Diagnostics = &DiagnosticsConfig{Disabled: true}
+			logger := NewLogger(tt.config, NewFieldsHandler(), recorder)
+
+			func() {
+				defer func() {
+					if r := recover(); (r != nil) != tt.wantPanic {
+						t.Errorf("panic = %v, want panic %v", r, tt.wantPanic)
+					}
+				}()
+				logger.Info(context.Background(), format, args...)
+			}()
+
+			// Запись выполняется до паники
+			entries := recorder.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if got := entries[0].Fields[FormatErrorField] == true; got != tt.wantField {
+				t.Errorf("%s set = %v, want %v", FormatErrorField, got, tt.wantField)
+			}
+		})
+	}
+}
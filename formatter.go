@@ -0,0 +1,41 @@
+package sglogger
+
+import "sync"
+
+// Formatter преобразует запись лога в строку вывода.
+// AppendFormat добавляет строку, включая завершающий перевод строки, к buf
+// и возвращает расширенный срез, что позволяет провайдерам переиспользовать
+// буферы из пула вместо выделения памяти на каждую запись.
+// Реализации должны быть безопасны для одновременного использования.
+type Formatter interface {
+	// AppendFormat добавляет отформатированную запись к buf
+	AppendFormat(buf []byte, e Entry) []byte
+}
+
+// maxPooledLineSize ограничивает размер буферов, возвращаемых в пул,
+// чтобы единичные огромные сообщения не удерживали память.
+const maxPooledLineSize = 64 << 10
+
+// lineBufferPool переиспользует буферы для формирования строк лога.
+var lineBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 512)
+		return &buf
+	},
+}
+
+// releaseLineBuffer возвращает буфер в пул, если он не слишком велик.
+func releaseLineBuffer(bp *[]byte, line []byte) {
+	if cap(line) > maxPooledLineSize {
+		return
+	}
+	*bp = line
+	lineBufferPool.Put(bp)
+}
+
+// acquireLineBuffer возвращает буфер из пула для формирования строки лога.
+// После записи буфер возвращается в пул через releaseLineBuffer.
+func acquireLineBuffer() (*[]byte, []byte) {
+	bp := lineBufferPool.Get().(*[]byte)
+	return bp, (*bp)[:0]
+}
@@ -0,0 +1,57 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+)
+
+const (
+	// GoroutineIDField - имя поля с идентификатором горутины.
+	GoroutineIDField = "goroutine_id"
+
+	// pprofLabelPrefix - префикс имен полей с метками pprof.
+	pprofLabelPrefix = "pprof."
+)
+
+// goroutineFields возвращает идентификатор текущей горутины и метки pprof из контекста.
+//
+// Получение идентификатора требует вызова runtime.Stack и разбора его первой строки
+// ("goroutine 42 [running]:"), что занимает порядка микросекунды и выделяет память,
+// поэтому функция вызывается один раз на сообщение и только если оно будет записано.
+func goroutineFields(ctx context.Context) Fields {
+	fields := make(Fields)
+
+	if id, ok := goroutineID(); ok {
+		fields[GoroutineIDField] = id
+	}
+
+	if ctx != nil {
+		pprof.ForLabels(ctx, func(key, value string) bool {
+			fields[pprofLabelPrefix+key] = value
+			return true
+		})
+	}
+
+	return fields
+}
+
+// goroutineID извлекает числовой идентификатор текущей горутины из runtime.Stack.
+func goroutineID() (uint64, bool) {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+
+	line := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	end := bytes.IndexByte(line, ' ')
+	if end <= 0 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(string(line[:end]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,82 @@
+package sglogger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recordingProvider запоминает записи, переданные логгером.
+type recordingProvider struct {
+	closedState
+
+	name    string
+	level   Level
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (p *recordingProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = append(p.entries, Entry{Level: level, Message: message, Fields: fields})
+	return nil
+}
+
+func (p *recordingProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && level >= p.level
+}
+
+func (p *recordingProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "recording"
+}
+
+func (p *recordingProvider) Close(ctx context.Context) error {
+	p.markClosed()
+	return nil
+}
+
+// Entries возвращает копию записанных записей.
+func (p *recordingProvider) Entries() []Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Entry(nil), p.entries...)
+}
+
+// scriptedProvider возвращает заданные ошибки из Flush и Close и считает
+// их вызовы; closeDelay задерживает Close без учета контекста.
+type scriptedProvider struct {
+	name       string
+	flushErr   error
+	closeErr   error
+	closeDelay time.Duration
+
+	flushes int32
+	closes  int32
+}
+
+func (p *scriptedProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	return nil
+}
+
+func (p *scriptedProvider) ShouldLog(ctx context.Context, level Level) bool { return true }
+
+func (p *scriptedProvider) Name() string { return p.name }
+
+func (p *scriptedProvider) Flush(ctx context.Context) error {
+	atomic.AddInt32(&p.flushes, 1)
+	return p.flushErr
+}
+
+func (p *scriptedProvider) Close(ctx context.Context) error {
+	atomic.AddInt32(&p.closes, 1)
+	time.Sleep(p.closeDelay)
+	return p.closeErr
+}
@@ -0,0 +1,260 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHoneycombAPIHost - адрес API Honeycomb по умолчанию.
+const defaultHoneycombAPIHost = "https://api.honeycomb.io"
+
+// honeycombProvider отправляет записи в Honeycomb через batch API
+// (POST /1/batch/<dataset>). Каждая запись становится событием с колонками
+// message, level и всеми полями записи.
+type honeycombProvider struct {
+	closedState
+
+	config      HoneycombConfig
+	client      *http.Client
+	url         string
+	batcher     *httpBatcher
+	diagnostics *diagnostics
+	sizes       sizeHistogram
+	leakCheck   *leakCheck
+}
+
+// NewHoneycombProvider создает провайдер событий Honeycomb.
+// Записи отправляются пакетами в фоне (см. HTTPBatchConfig).
+// Поля типа time.Duration передаются в миллисекундах в колонках с суффиксом "_ms"
+// (duration -> duration_ms). Поле SampleRateField, которое логгер добавляет
+// к записям, прошедшим семплирование, передается как samplerate события,
+// чтобы Honeycomb учитывал отброшенные записи.
+// Заголовок арендатора (HTTPBatchConfig.TenantHeader) по умолчанию не задан:
+// например, "X-Honeycomb-Team" позволяет отправлять события арендатора с его ключом API.
+// Возвращает ошибку, если не заданы ключ API или набор данных, либо некорректен HTTP-клиент.
+func NewHoneycombProvider(config HoneycombConfig) (LoggerProvider, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("sglogger: honeycomb API key is not set")
+	}
+	if config.Dataset == "" {
+		return nil, fmt.Errorf("sglogger: honeycomb dataset is not set")
+	}
+	if config.APIHost == "" {
+		config.APIHost = defaultHoneycombAPIHost
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Batch.validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+
+	client, err := NewHTTPClient(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &honeycombProvider{
+		config:      config,
+		client:      client,
+		url:         strings.TrimRight(config.APIHost, "/") + "/1/batch/" + url.PathEscape(config.Dataset),
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	p.batcher = newHTTPBatcher(config.Batch, p.send, nil, func(err error) {
+		p.diagnostics.reportf(p.Name(), "%v", err)
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(p.Name(), err)
+		}
+	})
+	if !config.LeakCheck {
+		return p, nil
+	}
+	// Фоновая отправка ссылается на p, поэтому финализатор устанавливается
+	// на обертку, которую удерживает только вызывающий
+	h := &honeycombProviderHandle{p}
+	p.leakCheck = newLeakCheck(true, h, "Honeycomb provider "+p.url, p.diagnostics)
+	return h, nil
+}
+
+// honeycombProviderHandle - провайдер, возвращаемый NewHoneycombProvider при LeakCheck.
+type honeycombProviderHandle struct{ *honeycombProvider }
+
+// Write ставит запись в очередь отправки.
+func (p *honeycombProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+	return p.batcher.add(Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+// WriteBatch отправляет записи синхронно, минуя очередь (см. BatchWriter).
+func (p *honeycombProvider) WriteBatch(ctx context.Context, entries []Entry) error {
+	return p.batcher.writeBatch(ctx, entries)
+}
+
+// EntrySizes возвращает гистограмму размеров отправленных событий без учета повторов.
+func (p *honeycombProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// DroppedEntries возвращает количество записей, потерянных при отправке
+// (см. DropRecorder).
+func (p *honeycombProvider) DroppedEntries() uint64 {
+	return p.batcher.droppedEntries()
+}
+
+// Name возвращает имя провайдера из конфигурации или "honeycomb" по умолчанию.
+func (p *honeycombProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "honeycomb"
+}
+
+// Describe возвращает уровень, адрес API, набор данных и параметры пакетов (см. Describer).
+func (p *honeycombProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["format"] = "honeycomb"
+	description["api_host"] = p.config.APIHost
+	description["dataset"] = p.config.Dataset
+	description["api_key"] = p.config.APIKey
+	description["batch"] = describeHTTPBatch(p.batcher.config)
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *honeycombProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *honeycombProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Flush отправляет записи из очереди.
+func (p *honeycombProvider) Flush(ctx context.Context) error {
+	return p.batcher.flush(ctx)
+}
+
+// Close отправляет оставшиеся записи и прекращает прием новых.
+func (p *honeycombProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	if !p.markClosed() {
+		return nil
+	}
+	return p.batcher.close(ctx)
+}
+
+// send отправляет пакет событий.
+func (p *honeycombProvider) send(ctx context.Context, tenant string, entries []Entry) error {
+	body := []byte{'['}
+	for i, e := range entries {
+		if i > 0 {
+			body = append(body, ',')
+		}
+		start := len(body)
+		body = p.appendEvent(body, e)
+		p.sizes.observe(len(body) - start)
+	}
+	body = append(body, ']')
+	body, encoding, err := compressHTTPBody(p.config.Batch, body)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, p.client, httpMaxRetries(p.config.Batch), p.diagnostics, p.Name(), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: create honeycomb request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		req.Header.Set("X-Honeycomb-Team", p.config.APIKey)
+		if tenant != "" && p.config.Batch.TenantHeader != "" {
+			req.Header.Set(p.config.Batch.TenantHeader, tenant)
+		}
+		return req, nil
+	})
+}
+
+// appendEvent добавляет событие batch API в формате
+// {"time":...,"samplerate":N,"data":{"message":...,"level":...,<поля>}}.
+func (p *honeycombProvider) appendEvent(buf []byte, e Entry) []byte {
+	buf = append(buf, `{"time":"`...)
+	buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, '"')
+
+	fields := e.Fields
+	if rate, ok := fields[SampleRateField].(int); ok && rate > 1 {
+		buf = append(buf, `,"samplerate":`...)
+		buf = strconv.AppendInt(buf, int64(rate), 10)
+	}
+
+	buf = append(buf, `,"data":{"message":`...)
+	buf = appendJSONString(buf, e.Message)
+	buf = append(buf, `,"level":`...)
+	buf = appendJSONString(buf, e.Level.String())
+	buf = appendJSONFields(buf, honeycombFields(fields), p.config.FloatFormat, "message", "level")
+	return append(buf, "}}"...)
+}
+
+// honeycombFields подготавливает поля к отправке: исключает SampleRateField
+// и заменяет значения time.Duration миллисекундами в колонке с суффиксом "_ms".
+// Если преобразования не нужны, возвращает исходные поля.
+func honeycombFields(fields Fields) Fields {
+	needsCopy := false
+	for k, v := range fields {
+		if _, ok := v.(time.Duration); ok || k == SampleRateField {
+			needsCopy = true
+			break
+		}
+	}
+	if !needsCopy {
+		return fields
+	}
+
+	result := make(Fields, len(fields))
+	for k, v := range fields {
+		switch val := v.(type) {
+		case time.Duration:
+			if !strings.HasSuffix(k, "_ms") {
+				k += "_ms"
+			}
+			result[k] = float64(val) / float64(time.Millisecond)
+		default:
+			if k != SampleRateField {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
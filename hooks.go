@@ -0,0 +1,112 @@
+package sglogger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Hook изменяет запись перед отправкой провайдерам (см. LoggerConfig.Hooks):
+// переписывает сообщение e.Message, заменяет поля e.Fields или делает и то,
+// и другое. e.Level передается для сведения, его изменение игнорируется;
+// e.Time не заполняется - время записи определяют провайдеры.
+// Набор полей может принадлежать вызывающему, поэтому изменять его на месте
+// нельзя: чтобы изменить поля, присвойте e.Fields копию (см. Entry.Clone).
+// Вызывается для каждой записи, поэтому должен выполняться быстро.
+type Hook func(ctx context.Context, e *Entry)
+
+// applyHooks выполняет хуки LoggerConfig.Hooks по порядку и возвращает
+// сообщение и поля, полученные от последнего хука.
+func (l *logger) applyHooks(ctx context.Context, level Level, message string, fields Fields) (string, Fields) {
+	e := Entry{Level: level, Message: message, Fields: fields}
+	for _, hook := range l.config.Hooks {
+		hook(ctx, &e)
+		e.Level = level
+	}
+	return e.Message, e.Fields
+}
+
+// PathSanitizer возвращает хук, заменяющий в сообщении и строковых значениях
+// полей, включая вложенные Fields, каталоги GOPATH на "$GOPATH", а домашний
+// каталог текущего пользователя - на "~": абсолютные пути в текстах ошибок
+// раскрывают имена пользователей. Каталоги определяются при вызове
+// PathSanitizer; GOPATH по умолчанию - ~/go, как у команды go.
+// Поля копируются, только если в них есть что заменить.
+func PathSanitizer() Hook {
+	home, _ := os.UserHomeDir()
+	gopath := filepath.SplitList(os.Getenv("GOPATH"))
+	if len(gopath) == 0 && home != "" {
+		gopath = []string{filepath.Join(home, "go")}
+	}
+
+	// GOPATH обычно находится внутри домашнего каталога, поэтому заменяется
+	// первым: strings.Replacer выбирает первую подходящую пару.
+	var pairs []string
+	for _, dir := range gopath {
+		if sanitizablePath(dir) {
+			pairs = append(pairs, filepath.Clean(dir), "$GOPATH")
+		}
+	}
+	if sanitizablePath(home) {
+		pairs = append(pairs, filepath.Clean(home), "~")
+	}
+	if len(pairs) == 0 {
+		return func(ctx context.Context, e *Entry) {}
+	}
+
+	replacer := strings.NewReplacer(pairs...)
+	return func(ctx context.Context, e *Entry) {
+		e.Message = replacer.Replace(e.Message)
+		if fields, ok := replaceFieldStrings(e.Fields, replacer); ok {
+			e.Fields = fields
+		}
+	}
+}
+
+// sanitizablePath сообщает, можно ли заменять каталог dir: пустой путь
+// и корень файловой системы встречаются в любом пути и не заменяются.
+func sanitizablePath(dir string) bool {
+	if dir == "" || !filepath.IsAbs(dir) {
+		return false
+	}
+	dir = filepath.Clean(dir)
+	return filepath.Dir(dir) != dir
+}
+
+// replaceFieldStrings возвращает копию полей, в строковых значениях которых
+// выполнены замены replacer, и true; если заменять нечего, возвращает
+// исходные поля и false.
+func replaceFieldStrings(fields Fields, replacer *strings.Replacer) (Fields, bool) {
+	var result Fields
+	for k, v := range fields {
+		var replaced interface{}
+		switch val := v.(type) {
+		case string:
+			s := replacer.Replace(val)
+			if s == val {
+				continue
+			}
+			replaced = s
+		case Fields:
+			nested, ok := replaceFieldStrings(val, replacer)
+			if !ok {
+				continue
+			}
+			replaced = nested
+		default:
+			continue
+		}
+		if result == nil {
+			result = make(Fields, len(fields))
+			for k, v := range fields {
+				result[k] = v
+			}
+		}
+		result[k] = replaced
+	}
+	if result == nil {
+		return fields, false
+	}
+	return result, true
+}
@@ -0,0 +1,171 @@
+package sglogger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// prefixHook добавляет к сообщению префикс prefix.
+func prefixHook(prefix string) Hook {
+	return func(ctx context.Context, e *Entry) {
+		e.Message = prefix + e.Message
+	}
+}
+
+func TestPathSanitizer(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	t.Setenv("GOPATH", "/home/alice/go")
+
+	tests := []struct {
+		name       string
+		message    string
+		fields     Fields
+		wantMsg    string
+		wantFields Fields
+	}{
+		{name: "nothing to replace", message: "open /etc/hosts", fields: Fields{"path": "/tmp/x"}, wantMsg: "open /etc/hosts", wantFields: Fields{"path": "/tmp/x"}},
+		{name: "home in message", message: "open /home/alice/.config/app.yaml: denied", wantMsg: "open ~/.config/app.yaml: denied"},
+		{name: "GOPATH before home", message: "panic in /home/alice/go/pkg/mod/x.go", wantMsg: "panic in $GOPATH/pkg/mod/x.go"},
+		{name: "other user untouched", message: "open /home/bob/app.log", wantMsg: "open /home/bob/app.log"},
+		{
+			name:       "string fields",
+			message:    "failed",
+			fields:     Fields{"file": "/home/alice/app.log", "count": 3},
+			wantMsg:    "failed",
+			wantFields: Fields{"file": "~/app.log", "count": 3},
+		},
+		{
+			name:       "nested fields",
+			message:    "failed",
+			fields:     Fields{"request": Fields{"body": "/home/alice/go/src/main.go"}},
+			wantMsg:    "failed",
+			wantFields: Fields{"request": Fields{"body": "$GOPATH/src/main.go"}},
+		},
+	}
+
+	hook := PathSanitizer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := cloneFields(tt.fields)
+			e := Entry{Level: LevelInfo, Message: tt.message, Fields: tt.fields}
+			hook(context.Background(), &e)
+
+			if e.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", e.Message, tt.wantMsg)
+			}
+			if !reflect.DeepEqual(e.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", e.Fields, tt.wantFields)
+			}
+			// Поля вызывающего не изменяются
+			if !reflect.DeepEqual(tt.fields, before) {
+				t.Errorf("caller's fields changed to %v", tt.fields)
+			}
+		})
+	}
+}
+
+func TestPathSanitizerWithoutDirectories(t *testing.T) {
+	t.Setenv("HOME", "/")
+	t.Setenv("GOPATH", "")
+
+	e := Entry{Message: "open /etc/hosts", Fields: Fields{"path": "/var/log"}}
+	PathSanitizer()(context.Background(), &e)
+	if e.Message != "open /etc/hosts" || e.Fields["path"] != "/var/log" {
+		t.Errorf("entry = %+v, want it unchanged", e)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		hooks      []Hook
+		level      Level
+		wantMsg    string
+		wantFields Fields
+	}{
+		{name: "no hooks", wantMsg: "user bob logged in", wantFields: Fields{"user": "bob"}},
+		{name: "rewrite message", hooks: []Hook{prefixHook("[api] ")}, wantMsg: "[api] user bob logged in", wantFields: Fields{"user": "bob"}},
+		{
+			name:       "hooks run in order",
+			hooks:      []Hook{prefixHook("b "), prefixHook("a ")},
+			wantMsg:    "a b user bob logged in",
+			wantFields: Fields{"user": "bob"},
+		},
+		{
+			name: "replace fields",
+			hooks: []Hook{func(ctx context.Context, e *Entry) {
+				fields := cloneFields(e.Fields)
+				fields["user"] = "[redacted]"
+				e.Fields = fields
+			}},
+			wantMsg:    "user bob logged in",
+			wantFields: Fields{"user": "[redacted]"},
+		},
+		{
+			name:       "level change ignored",
+			hooks:      []Hook{func(ctx context.Context, e *Entry) { e.Level = LevelDebug }},
+			wantMsg:    "user bob logged in",
+			wantFields: Fields{"user": "bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingProvider{}
+			logger := NewLogger(LoggerConfig{Hooks: tt.hooks}, NewFieldsHandler(), recorder)
+
+			fields := Fields{"user": "bob"}
+			logger.InfoWithFields(context.Background(), fields, "user %s logged in", "bob")
+
+			entries := recorder.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != LevelInfo || e.Message != tt.wantMsg {
+				t.Errorf("entry = %v %q, want info %q", e.Level, e.Message, tt.wantMsg)
+			}
+			if !reflect.DeepEqual(e.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", e.Fields, tt.wantFields)
+			}
+			if fields["user"] != "bob" {
+				t.Errorf("caller's fields changed to %v", fields)
+			}
+		})
+	}
+}
+
+func TestHooksKeepFingerprint(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	t.Setenv("GOPATH", "")
+
+	const format = "cannot open %s"
+	tests := []struct {
+		name  string
+		hooks []Hook
+	}{
+		{name: "no hooks"},
+		{name: "path sanitizer", hooks: []Hook{PathSanitizer()}},
+		{name: "sanitizer and prefix", hooks: []Hook{PathSanitizer(), prefixHook("[api] ")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingProvider{}
+			logger := NewLogger(LoggerConfig{Hooks: tt.hooks, Fingerprint: true}, NewFieldsHandler(), recorder)
+
+			// Хуки меняют только одно из сообщений, но отпечаток вычисляется
+			// по шаблону и остается общим
+			logger.Info(context.Background(), format, "/home/alice/app.log")
+			logger.Info(context.Background(), format, "/var/log/app.log")
+
+			want := Fingerprint(format)
+			for _, e := range recorder.Entries() {
+				if e.Fields[FingerprintField] != want {
+					t.Errorf("%q: %s = %v, want %s", e.Message, FingerprintField, e.Fields[FingerprintField], want)
+				}
+			}
+		})
+	}
+}
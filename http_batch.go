@@ -0,0 +1,542 @@
+package sglogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultHTTPBatchSize задает количество записей в одном запросе по умолчанию.
+	defaultHTTPBatchSize = 100
+
+	// defaultHTTPFlushInterval задает максимальную задержку отправки неполного пакета.
+	defaultHTTPFlushInterval = time.Second
+
+	// defaultHTTPMaxPending ограничивает очередь неотправленных записей по умолчанию.
+	defaultHTTPMaxPending = 10000
+
+	// defaultHTTPMaxRetries задает количество повторов запроса по умолчанию.
+	defaultHTTPMaxRetries = 3
+
+	// httpRetryBackoff и httpMaxRetryBackoff задают паузы между повторами запроса.
+	httpRetryBackoff    = 200 * time.Millisecond
+	httpMaxRetryBackoff = 5 * time.Second
+
+	// httpErrorBodyLimit ограничивает часть тела ответа, включаемую в HTTPStatusError.
+	httpErrorBodyLimit = 512
+)
+
+// gzipWriters - пулы gzip.Writer по уровню сжатия; индекс 0 соответствует
+// gzip.DefaultCompression.
+var gzipWriters [gzip.BestCompression + 1]sync.Pool
+
+// validate проверяет параметры сжатия и параллельной отправки.
+func (c HTTPBatchConfig) validate() error {
+	switch c.Compression {
+	case HTTPCompressionNone, HTTPCompressionGzip:
+	default:
+		return fmt.Errorf("sglogger: invalid HTTP compression %d", c.Compression)
+	}
+	if c.CompressionLevel < 0 || c.CompressionLevel > gzip.BestCompression {
+		return fmt.Errorf("sglogger: invalid gzip compression level %d", c.CompressionLevel)
+	}
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("sglogger: negative HTTP max in-flight requests %d", c.MaxInFlight)
+	}
+	return nil
+}
+
+// compressHTTPBody сжимает тело запроса согласно config.Compression.
+// Возвращает тело и значение заголовка Content-Encoding, пустое без сжатия.
+func compressHTTPBody(config HTTPBatchConfig, body []byte) ([]byte, string, error) {
+	if config.Compression != HTTPCompressionGzip {
+		return body, "", nil
+	}
+
+	var compressed bytes.Buffer
+	compressed.Grow(len(body) / 4)
+	pool := &gzipWriters[config.CompressionLevel]
+	zw, _ := pool.Get().(*gzip.Writer)
+	if zw == nil {
+		level := config.CompressionLevel
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		// Уровень проверен при создании провайдера
+		zw, _ = gzip.NewWriterLevel(&compressed, level)
+	} else {
+		zw.Reset(&compressed)
+	}
+	zw.Write(body)
+	err := zw.Close()
+	// Writer в пуле не должен удерживать буфер запроса
+	zw.Reset(io.Discard)
+	pool.Put(zw)
+	if err != nil {
+		return nil, "", fmt.Errorf("sglogger: compress request: %w", err)
+	}
+	return compressed.Bytes(), "gzip", nil
+}
+
+// HTTPStatusError возвращается HTTP-провайдерами, когда сервис ответил кодом ошибки.
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string // Начало тела ответа
+}
+
+// Error возвращает описание ошибки.
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("sglogger: HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable сообщает, имеет ли смысл повторить запрос: для 429 и кодов 5xx.
+func (e *HTTPStatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// postWithRetry выполняет запрос, повторяя его при сетевых ошибках и ответах,
+// для которых HTTPStatusError.Retryable возвращает true, с экспоненциальной паузой.
+// Каждый повтор сообщается в диагностический канал diag от имени source.
+// newRequest вызывается для каждой попытки, так как тело запроса читается один раз.
+func postWithRetry(ctx context.Context, client *http.Client, maxRetries int, diag *diagnostics, source string, newRequest func(ctx context.Context) (*http.Request, error)) error {
+	backoff := httpRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := doHTTPRequest(ctx, client, newRequest)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && !statusErr.Retryable() {
+			return err
+		}
+		if attempt >= maxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		diag.reportf(source, "retrying request in %s: %v", backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		if backoff *= 2; backoff > httpMaxRetryBackoff {
+			backoff = httpMaxRetryBackoff
+		}
+	}
+}
+
+// doHTTPRequest выполняет одну попытку запроса. Ответы с кодом вне 2xx
+// возвращаются как *HTTPStatusError.
+func doHTTPRequest(ctx context.Context, client *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) error {
+	req, err := newRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sglogger: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, httpErrorBodyLimit))
+	return &HTTPStatusError{StatusCode: resp.StatusCode, Body: string(body)}
+}
+
+// DropRecorder определяет интерфейс провайдеров, которые принимают записи
+// в очередь и могут потерять их при доставке: пакет, не отправленный после
+// всех повторов, или записи, оставшиеся в очереди, когда истек контекст
+// Close. Счетчик отображается в Stats; записи, отклоненные Write
+// с ErrQueueFull, учитываются как ошибки записи, а не как потерянные.
+type DropRecorder interface {
+	// DroppedEntries возвращает количество потерянных записей
+	DroppedEntries() uint64
+}
+
+// httpBatcher накапливает записи HTTP-провайдера и отправляет их пакетами
+// в фоне: по заполнении пакета, по FlushInterval, при Flush и при закрытии.
+// Одновременно выполняется не больше одной отправки очереди; внутри нее
+// до MaxInFlight запросов могут выполняться параллельно (см. streamGate).
+type httpBatcher struct {
+	// dropped - первое поле: атомарные операции с uint64 требуют
+	// выравнивания по 8 байтам на 32-битных платформах
+	dropped uint64
+
+	config    HTTPBatchConfig
+	send      func(ctx context.Context, tenant string, entries []Entry) error
+	streamKey func(e Entry) string
+	onError   func(err error)
+	pending   []Entry
+	closed    bool
+	mu        sync.Mutex
+	sendSem   chan struct{}
+	kick      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// newHTTPBatcher создает очередь с отправкой через send и запускает фоновую отправку.
+// send получает арендатора пакета (см. HTTPBatchConfig.TenantHeaderFromField),
+// пустого, если разделение по арендаторам не настроено. streamKey возвращает
+// поток записи у одного арендатора: пакеты с записями общего потока
+// отправляются по очереди. При nil streamKey записи независимы и пакеты
+// могут отправляться в любом порядке. Ошибки фоновой отправки передаются onError.
+func newHTTPBatcher(config HTTPBatchConfig, send func(ctx context.Context, tenant string, entries []Entry) error, streamKey func(e Entry) string, onError func(err error)) *httpBatcher {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultHTTPBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultHTTPFlushInterval
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = defaultHTTPMaxPending
+	}
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = 1
+	}
+
+	b := &httpBatcher{
+		config:    config,
+		send:      send,
+		streamKey: streamKey,
+		onError:   onError,
+		sendSem:   make(chan struct{}, 1),
+		kick:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// httpMaxRetries возвращает количество повторов запроса с учетом значения по умолчанию.
+func httpMaxRetries(config HTTPBatchConfig) int {
+	if config.MaxRetries == 0 {
+		return defaultHTTPMaxRetries
+	}
+	if config.MaxRetries < 0 {
+		return 0
+	}
+	return config.MaxRetries
+}
+
+// add ставит запись в очередь. Возвращает ErrQueueFull, если очередь заполнена,
+// и ErrProviderClosed после закрытия.
+func (b *httpBatcher) add(e Entry) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrProviderClosed
+	}
+	if len(b.pending) >= b.config.MaxPending {
+		b.mu.Unlock()
+		return ErrQueueFull
+	}
+	b.pending = append(b.pending, e)
+	full := len(b.pending) >= b.config.BatchSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// writeBatch отправляет записи синхронно, минуя очередь, пакетами по BatchSize.
+// Реализует семантику BatchWriter: при ошибке после успешной отправки части
+// пакетов возвращает *BatchError.
+func (b *httpBatcher) writeBatch(ctx context.Context, entries []Entry) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrProviderClosed
+	}
+
+	for written := 0; written < len(entries); {
+		end := written + b.config.BatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		if err := b.sendByTenant(ctx, entries[written:end]); err != nil {
+			if written > 0 {
+				return &BatchError{Written: written, Err: err}
+			}
+			return err
+		}
+		written = end
+	}
+	return nil
+}
+
+// flush отправляет все записи очереди. Пакет, отправка которого завершилась
+// ошибкой, отбрасывается; остальные записи остаются в очереди. При MaxInFlight
+// больше 1 запросы выполняются параллельно, и flush возвращается после
+// завершения всех начатых запросов. Если ctx истекает, пока выполняется
+// другая отправка, flush возвращает ошибку контекста, не дожидаясь ее.
+func (b *httpBatcher) flush(ctx context.Context) error {
+	select {
+	case b.sendSem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-b.sendSem }()
+
+	if b.config.MaxInFlight > 1 {
+		return b.flushParallel(ctx)
+	}
+	for {
+		batch := b.takeBatch()
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := b.sendByTenant(ctx, batch); err != nil {
+			atomic.AddUint64(&b.dropped, uint64(len(batch)))
+			return fmt.Errorf("sglogger: %d entries dropped: %w", len(batch), err)
+		}
+	}
+}
+
+// takeBatch извлекает из очереди до BatchSize записей.
+func (b *httpBatcher) takeBatch() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(b.pending)
+	if n > b.config.BatchSize {
+		n = b.config.BatchSize
+	}
+	batch := b.pending[:n:n]
+	b.pending = b.pending[n:]
+	return batch
+}
+
+// flushParallel отправляет записи очереди запросами по арендаторам, выполняя
+// до MaxInFlight запросов одновременно. Запрос начинается в порядке очереди
+// и только после завершения запросов с записями тех же потоков, поэтому
+// порядок записей потока сохраняется. После первой ошибки новые запросы
+// не начинаются. Вызывается с занятым sendSem.
+func (b *httpBatcher) flushParallel(ctx context.Context) error {
+	gate := newStreamGate(b.config.MaxInFlight)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		errMu.Lock()
+		defer errMu.Unlock()
+		return firstErr != nil
+	}
+
+	for !failed() {
+		batch := b.takeBatch()
+		if len(batch) == 0 {
+			break
+		}
+		batch = adjustSkew(batch, b.config.SkewAdjust)
+		for _, group := range splitByTenant(batch, b.config.TenantHeaderFromField) {
+			streams := b.streams(group)
+			gate.acquire(streams)
+			if failed() {
+				// Пакет, отправка которого не удалась, отбрасывается целиком,
+				// как и при последовательной отправке
+				gate.release(streams)
+				atomic.AddUint64(&b.dropped, uint64(len(group.entries)))
+				continue
+			}
+			wg.Add(1)
+			go func(group tenantGroup) {
+				defer wg.Done()
+				defer gate.release(streams)
+				if err := b.send(ctx, group.tenant, group.entries); err != nil {
+					atomic.AddUint64(&b.dropped, uint64(len(group.entries)))
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("sglogger: %d entries dropped: %w", len(group.entries), err)
+					}
+					errMu.Unlock()
+				}
+			}(group)
+		}
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// streams возвращает потоки записей группы; nil, если записи независимы.
+func (b *httpBatcher) streams(group tenantGroup) []string {
+	if b.streamKey == nil {
+		return nil
+	}
+	var streams []string
+	for _, e := range group.entries {
+		stream := group.tenant + "\x00" + b.streamKey(e)
+		if !containsString(streams, stream) {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// streamGate ограничивает количество одновременных запросов и не допускает
+// одновременной отправки записей одного потока.
+type streamGate struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	active  int
+	streams map[string]bool
+}
+
+// newStreamGate создает ограничитель на limit одновременных запросов.
+func newStreamGate(limit int) *streamGate {
+	g := &streamGate{limit: limit, streams: make(map[string]bool)}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+// acquire ждет свободного места и завершения запросов с теми же потоками.
+func (g *streamGate) acquire(streams []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for g.active >= g.limit || g.busy(streams) {
+		g.cond.Wait()
+	}
+	g.active++
+	for _, stream := range streams {
+		g.streams[stream] = true
+	}
+}
+
+// busy сообщает, отправляется ли один из потоков. Вызывается с захваченным mu.
+func (g *streamGate) busy(streams []string) bool {
+	for _, stream := range streams {
+		if g.streams[stream] {
+			return true
+		}
+	}
+	return false
+}
+
+// release освобождает место и потоки запроса.
+func (g *streamGate) release(streams []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.active--
+	for _, stream := range streams {
+		delete(g.streams, stream)
+	}
+	g.cond.Broadcast()
+}
+
+// sendByTenant отправляет пакет, разделяя его по арендаторам, если задано
+// TenantHeaderFromField. Группы отправляются в порядке первого появления
+// арендатора в пакете; порядок записей внутри группы сохраняется.
+func (b *httpBatcher) sendByTenant(ctx context.Context, entries []Entry) error {
+	entries = adjustSkew(entries, b.config.SkewAdjust)
+	for _, group := range splitByTenant(entries, b.config.TenantHeaderFromField) {
+		if err := b.send(ctx, group.tenant, group.entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantGroup - записи пакета одного арендатора.
+type tenantGroup struct {
+	tenant  string
+	entries []Entry
+}
+
+// splitByTenant разделяет записи по значению поля field в порядке первого
+// появления арендатора. При пустом field возвращает одну группу.
+func splitByTenant(entries []Entry, field string) []tenantGroup {
+	if field == "" {
+		return []tenantGroup{{entries: entries}}
+	}
+
+	var groups []tenantGroup
+	index := make(map[string]int)
+	for _, e := range entries {
+		tenant := fieldString(e.Fields[field])
+		i, ok := index[tenant]
+		if !ok {
+			i = len(groups)
+			index[tenant] = i
+			groups = append(groups, tenantGroup{tenant: tenant})
+		}
+		groups[i].entries = append(groups[i].entries, e)
+	}
+	return groups
+}
+
+// close прекращает прием записей, останавливает фоновую отправку
+// и отправляет оставшиеся записи в пределах ctx.
+func (b *httpBatcher) close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		b.mu.Lock()
+		atomic.AddUint64(&b.dropped, uint64(len(b.pending)))
+		b.pending = nil
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+	return b.flush(ctx)
+}
+
+// droppedEntries возвращает количество потерянных записей (см. DropRecorder).
+func (b *httpBatcher) droppedEntries() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// run отправляет записи по заполнении пакета и по FlushInterval до вызова close.
+func (b *httpBatcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.kick:
+		case <-b.stop:
+			return
+		}
+		if err := b.flush(context.Background()); err != nil && b.onError != nil {
+			b.onError(err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package sglogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompressHTTPBody(t *testing.T) {
+	body := bytes.Repeat([]byte(`{"msg":"request handled","status":200}`), 100)
+	for _, level := range []int{0, gzip.BestSpeed, gzip.BestCompression} {
+		// Повторное сжатие берет writer из пула
+		for i := 0; i < 2; i++ {
+			compressed, encoding, err := compressHTTPBody(HTTPBatchConfig{Compression: HTTPCompressionGzip, CompressionLevel: level}, body)
+			if err != nil {
+				t.Fatalf("level %d: %v", level, err)
+			}
+			if encoding != "gzip" {
+				t.Errorf("level %d: encoding = %q, want gzip", level, encoding)
+			}
+			if got := gunzip(t, compressed); !bytes.Equal(got, body) {
+				t.Errorf("level %d: decompressed body differs from the original", level)
+			}
+		}
+	}
+
+	plain, encoding, err := compressHTTPBody(HTTPBatchConfig{}, body)
+	if err != nil || encoding != "" || !bytes.Equal(plain, body) {
+		t.Errorf("uncompressed: body changed or encoding %q set, err %v", encoding, err)
+	}
+}
+
+func TestHTTPBatchConfigValidate(t *testing.T) {
+	for _, config := range []HTTPBatchConfig{
+		{Compression: 7},
+		{Compression: HTTPCompressionGzip, CompressionLevel: 10},
+		{Compression: HTTPCompressionGzip, CompressionLevel: -1},
+		{MaxInFlight: -1},
+	} {
+		if err := config.validate(); err == nil {
+			t.Errorf("validate(%+v) = nil, want an error", config)
+		}
+	}
+}
+
+func TestLokiProviderGzip(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip", got)
+		}
+		data, _ := io.ReadAll(r.Body)
+		bodies <- data
+	}))
+	defer server.Close()
+
+	provider, err := NewLokiProvider(LokiConfig{
+		URL:    server.URL,
+		Labels: map[string]string{"service": "billing"},
+		Batch:  HTTPBatchConfig{BatchSize: 2, Compression: HTTPCompressionGzip, CompressionLevel: gzip.BestSpeed},
+	})
+	if err != nil {
+		t.Fatalf("NewLokiProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ctx := ContextWithEntryTime(context.Background(), at)
+	entries := []Entry{
+		{Time: at, Level: LevelInfo, Message: "first", Fields: Fields{"order": 1}},
+		{Time: at, Level: LevelInfo, Message: "second", Fields: Fields{"order": 2}},
+	}
+	for _, e := range entries {
+		if err := provider.Write(ctx, e.Level, e.Message, e.Fields); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	var body []byte
+	select {
+	case body = <-bodies:
+	case <-time.After(5 * time.Second):
+		t.Fatal("push was not sent")
+	}
+	want := provider.(*lokiProvider).appendPush(nil, entries)
+	if got := gunzip(t, body); !bytes.Equal(got, want) {
+		t.Errorf("decompressed push = %s, want %s", got, want)
+	}
+}
+
+func TestHTTPBatcherMaxInFlight(t *testing.T) {
+	const inFlight = 3
+
+	var (
+		mu      sync.Mutex
+		active  int
+		peak    int
+		streams = make(map[string]bool)
+		order   = make(map[string][]int)
+	)
+	send := func(ctx context.Context, tenant string, entries []Entry) error {
+		stream := entries[0].Message
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		if streams[stream] {
+			t.Errorf("two batches of stream %s in flight at once", stream)
+		}
+		streams[stream] = true
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		delete(streams, stream)
+		for _, e := range entries {
+			order[stream] = append(order[stream], e.Fields["seq"].(int))
+		}
+		mu.Unlock()
+		return nil
+	}
+	// Поток записи - ее сообщение; в пакете по одной записи, а арендатор
+	// совпадает с потоком, поэтому в запросе записи одного потока
+	b := newHTTPBatcher(HTTPBatchConfig{
+		BatchSize:             1,
+		FlushInterval:         time.Hour,
+		MaxInFlight:           inFlight,
+		TenantHeaderFromField: "stream",
+	}, send, func(e Entry) string { return e.Message }, nil)
+	defer b.close(context.Background())
+
+	const perStream = 10
+	for seq := 0; seq < perStream; seq++ {
+		for s := 0; s < 4; s++ {
+			stream := fmt.Sprintf("s%d", s)
+			if err := b.add(Entry{Message: stream, Fields: Fields{"stream": stream, "seq": seq}}); err != nil {
+				t.Fatalf("add: %v", err)
+			}
+		}
+	}
+	if err := b.flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak < 2 || peak > inFlight {
+		t.Errorf("peak in-flight requests = %d, want 2..%d", peak, inFlight)
+	}
+	for stream, seqs := range order {
+		if len(seqs) != perStream {
+			t.Errorf("stream %s delivered %d entries, want %d", stream, len(seqs), perStream)
+		}
+		for i, seq := range seqs {
+			if seq != i {
+				t.Errorf("stream %s delivered out of order: %v", stream, seqs)
+				break
+			}
+		}
+	}
+}
+
+func TestHTTPBatcherFlushHonorsContext(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	// Фоновая отправка ждет release, так как ее контекст не истекает
+	send := func(ctx context.Context, tenant string, entries []Entry) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	b := newHTTPBatcher(HTTPBatchConfig{BatchSize: 1, FlushInterval: time.Hour}, send, nil, nil)
+	defer b.close(context.Background())
+	defer close(release)
+
+	if err := b.add(Entry{Message: "first"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	<-started
+	if err := b.add(Entry{Message: "second"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("flush = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flush returned after %s, want about 100ms", elapsed)
+	}
+}
+
+// gunzip распаковывает данные gzip.
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read gzip: %v", err)
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package sglogger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// defaultHTTPTimeout ограничивает время одного HTTP-запроса по умолчанию.
+	defaultHTTPTimeout = 10 * time.Second
+
+	// defaultHTTPMaxIdleConns задает размер пула простаивающих соединений по умолчанию.
+	defaultHTTPMaxIdleConns = 100
+)
+
+// NewHTTPClient создает *http.Client для HTTP-провайдеров по общей конфигурации:
+// TLS (собственные CA, клиентские сертификаты), прокси, аутентификация и таймауты.
+// Ошибки конфигурации (отсутствующие или некорректные файлы, неверный URL прокси)
+// возвращаются сразу, а не при первой записи.
+func NewHTTPClient(config HTTPClientConfig) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport.MaxIdleConns = config.MaxIdleConns
+	if transport.MaxIdleConns <= 0 {
+		transport.MaxIdleConns = defaultHTTPMaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = transport.MaxIdleConns
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if config.Auth != (HTTPAuth{}) {
+		roundTripper = &authRoundTripper{auth: config.Auth, next: transport}
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   timeout,
+	}, nil
+}
+
+// buildTLSConfig загружает сертификаты, указанные в конфигурации TLS.
+func buildTLSConfig(config TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("sglogger: no valid certificates in CA file %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// authRoundTripper добавляет заголовок аутентификации к каждому запросу.
+type authRoundTripper struct {
+	auth HTTPAuth
+	next http.RoundTripper
+}
+
+// RoundTrip выполняет запрос, дополненный данными аутентификации.
+// Исходный запрос не изменяется.
+func (t *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.auth.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.auth.BearerToken)
+	} else {
+		req.SetBasicAuth(t.auth.Username, t.auth.Password)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,215 @@
+package sglogger
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// Поля HTTP-запроса и ответа (см. HTTPRequestFields и HTTPResponseFields).
+const (
+	HTTPMethodField       = "http.method"
+	HTTPPathField         = "http.path"
+	HTTPUserAgentField    = "http.user_agent"
+	HTTPRemoteIPField     = "http.remote_ip"
+	HTTPStatusCodeField   = "http.status_code"
+	HTTPResponseSizeField = "http.response_size"
+	HTTPDurationField     = "http.duration_ms"
+
+	// HTTPHeaderFieldPrefix - префикс полей заголовков: http.header.<имя в нижнем регистре>.
+	HTTPHeaderFieldPrefix = "http.header."
+)
+
+// redactedHeaderValue заменяет значения заголовков с учетными данными.
+const redactedHeaderValue = "[REDACTED]"
+
+// sensitiveHeaders - заголовки, значения которых никогда не записываются.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// httpFieldsOptions содержит настройки HTTPRequestFields.
+type httpFieldsOptions struct {
+	trustedProxies []netip.Prefix
+	headers        []string
+}
+
+// HTTPFieldsOption настраивает HTTPRequestFields.
+type HTTPFieldsOption func(*httpFieldsOptions)
+
+// WithTrustedProxies задает адреса прокси, которым разрешено передавать адрес
+// клиента в X-Forwarded-For. Без этой настройки заголовок игнорируется.
+func WithTrustedProxies(proxies ...netip.Prefix) HTTPFieldsOption {
+	return func(o *httpFieldsOptions) {
+		o.trustedProxies = append(o.trustedProxies, proxies...)
+	}
+}
+
+// WithHeaders задает заголовки запроса, которые записываются в поля
+// http.header.<имя>. Значения Authorization, Proxy-Authorization, Cookie
+// и Set-Cookie всегда заменяются на "[REDACTED]".
+func WithHeaders(names ...string) HTTPFieldsOption {
+	return func(o *httpFieldsOptions) {
+		o.headers = append(o.headers, names...)
+	}
+}
+
+// HTTPRequestFields возвращает поля HTTP-запроса в едином формате:
+// http.method, http.path, http.user_agent, http.remote_ip и заголовки из WithHeaders.
+// Адрес клиента берется из X-Forwarded-For, только если запрос пришел от доверенного
+// прокси (см. WithTrustedProxies): адреса заголовка просматриваются справа налево,
+// и первый недоверенный адрес считается адресом клиента.
+func HTTPRequestFields(r *http.Request, opts ...HTTPFieldsOption) Fields {
+	var o httpFieldsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fields := Fields{
+		HTTPMethodField:   r.Method,
+		HTTPPathField:     r.URL.Path,
+		HTTPRemoteIPField: remoteIP(r, o.trustedProxies),
+	}
+	if ua := r.UserAgent(); ua != "" {
+		fields[HTTPUserAgentField] = ua
+	}
+
+	for _, name := range o.headers {
+		values := r.Header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		for _, sensitive := range sensitiveHeaders {
+			if strings.EqualFold(name, sensitive) {
+				value = redactedHeaderValue
+				break
+			}
+		}
+		fields[HTTPHeaderFieldPrefix+strings.ToLower(name)] = value
+	}
+	return fields
+}
+
+// HTTPResponseFields возвращает поля HTTP-ответа: http.status_code,
+// http.response_size и http.duration_ms (длительность в миллисекундах).
+func HTTPResponseFields(status int, size int64, dur time.Duration) Fields {
+	return Fields{
+		HTTPStatusCodeField:   status,
+		HTTPResponseSizeField: size,
+		HTTPDurationField:     float64(dur) / float64(time.Millisecond),
+	}
+}
+
+// remoteIP определяет адрес клиента с учетом доверенных прокси.
+func remoteIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if len(trusted) == 0 || !ipTrusted(host, trusted) {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !ipTrusted(hops[i], trusted) {
+			return hops[i]
+		}
+	}
+	if len(hops) > 0 {
+		return hops[0]
+	}
+	return host
+}
+
+// ipTrusted сообщает, входит ли адрес в один из доверенных диапазонов.
+func ipTrusted(s string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLogWriter запоминает код и размер ответа.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WriteHeader запоминает код ответа.
+func (w *accessLogWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write учитывает размер тела ответа.
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController.
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NewAccessLogMiddleware создает HTTP-middleware, которое после обработки
+// каждого запроса записывает сообщение "HTTP request" с полями HTTPRequestFields
+// и HTTPResponseFields. Ответы с кодом 5xx записываются уровнем Error,
+// 4xx - Warning, остальные - Info.
+func NewAccessLogMiddleware(logger Logger, opts ...HTTPFieldsOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			lw := &accessLogWriter{ResponseWriter: w}
+			next.ServeHTTP(lw, r)
+
+			status := lw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fields := HTTPRequestFields(r, opts...)
+			for k, v := range HTTPResponseFields(status, lw.size, time.Since(start)) {
+				fields[k] = v
+			}
+
+			logHTTP(r.Context(), logger, status, nil, fields, "HTTP request")
+		})
+	}
+}
+
+// logHTTP записывает сообщение о запросе уровнем, зависящим от кода ответа:
+// 5xx - Error, 4xx - Warning, остальные - Info.
+func logHTTP(ctx context.Context, logger Logger, status int, err error, fields Fields, message string) {
+	switch {
+	case status >= 500:
+		logger.ErrorErrWithFields(ctx, err, fields, message)
+	case status >= 400:
+		logger.WarningErrWithFields(ctx, err, fields, message)
+	default:
+		logger.InfoErrWithFields(ctx, err, fields, message)
+	}
+}
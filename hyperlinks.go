@@ -0,0 +1,119 @@
+package sglogger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultHyperlinkField - поле с местом вызова, выводимое ссылкой.
+	defaultHyperlinkField = "caller"
+
+	// defaultHyperlinkTemplate - шаблон ссылки по умолчанию.
+	defaultHyperlinkTemplate = "file://{abs}"
+)
+
+// hyperlinkText - значение поля, выводимое текстовым форматом как ссылка
+// OSC 8. Создается только textFormatter, поэтому в другие форматы не попадает.
+type hyperlinkText struct {
+	text string
+	url  string
+}
+
+// hyperlinks преобразует поле с местом вызова в ссылку по шаблону.
+type hyperlinks struct {
+	field    string
+	template string
+	revision string
+}
+
+// newHyperlinks создает преобразователь по конфигурации. Возвращает nil, если ссылки отключены.
+func newHyperlinks(config HyperlinkConfig) *hyperlinks {
+	if !config.Enabled {
+		return nil
+	}
+
+	h := &hyperlinks{field: config.Field, template: config.URLTemplate}
+	if h.field == "" {
+		h.field = defaultHyperlinkField
+	}
+	if h.template == "" {
+		h.template = defaultHyperlinkTemplate
+	}
+	if strings.Contains(h.template, "{rev}") {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					h.revision = setting.Value
+				}
+			}
+		}
+	}
+	return h
+}
+
+// fields возвращает поля, в которых строковое значение поля места вызова
+// заменено ссылкой. Исходная карта не изменяется; если поля нет, она
+// возвращается без копирования.
+func (h *hyperlinks) fields(fields Fields) Fields {
+	value, ok := fields[h.field].(string)
+	if !ok || value == "" {
+		return fields
+	}
+
+	result := make(Fields, len(fields))
+	for k, v := range fields {
+		result[k] = v
+	}
+	result[h.field] = hyperlinkText{text: value, url: h.url(value)}
+	return result
+}
+
+// url подставляет в шаблон части места вызова "файл:строка".
+func (h *hyperlinks) url(caller string) string {
+	file, line := caller, ""
+	if i := strings.LastIndexByte(caller, ':'); i > 0 {
+		if _, err := strconv.Atoi(caller[i+1:]); err == nil {
+			file, line = caller[:i], caller[i+1:]
+		}
+	}
+	abs := file
+	if !filepath.IsAbs(abs) {
+		if resolved, err := filepath.Abs(abs); err == nil {
+			abs = resolved
+		}
+	}
+
+	url := strings.NewReplacer(
+		"{file}", file,
+		"{abs}", filepath.ToSlash(abs),
+		"{line}", line,
+		"{rev}", h.revision,
+	).Replace(h.template)
+
+	// Управляющие символы в ссылке завершили бы последовательность OSC 8 раньше времени.
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+}
+
+// appendHyperlink добавляет значение в кавычках, обернутое в ссылку OSC 8.
+func appendHyperlink(buf []byte, link hyperlinkText) []byte {
+	buf = append(buf, "\x1b]8;;"...)
+	buf = append(buf, link.url...)
+	buf = append(buf, "\x1b\\"...)
+	buf = strconv.AppendQuote(buf, link.text)
+	return append(buf, "\x1b]8;;\x1b\\"...)
+}
+
+// isTerminal сообщает, связан ли файл с терминалом.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -1,6 +1,9 @@
 package sglogger
 
-import "context"
+import (
+    "context"
+    "io"
+)
 
 // Level представляет уровень логирования
 type Level int
@@ -15,19 +18,100 @@ const (
 
 // LoggerProvider определяет интерфейс для провайдеров логирования.
 // Провайдеры отвечают за запись логов в конкретные места назначения (консоль, файл, Loki и т.д.).
+// Конструкторы провайдеров возвращают (LoggerProvider, error) и проверяют конфигурацию
+// при создании; конструкторы без ошибки вместо паники возвращают NewFailedProvider.
 type LoggerProvider interface {
     // Write записывает лог-сообщение с указанным уровнем, текстом и дополнительными полями.
+    // Набор fields передается всем провайдерам логгера и не должен изменяться:
+    // для изменения используйте копию (см. Entry.Clone).
     // Возвращает ошибку в случае проблем при записи.
+    // После Close возвращает ErrProviderClosed и не паникует.
     Write(ctx context.Context, level Level, message string, fields Fields) error
     
     // ShouldLog проверяет, нужно ли логировать сообщение данного уровня.
     // Используется для фильтрации логов по уровню важности.
+    // После Close возвращает false.
     ShouldLog(ctx context.Context, level Level) bool
     
     // Close освобождает ресурсы провайдера. Должен вызываться при завершении работы приложения.
+    // Повторный вызов ничего не делает и возвращает nil.
     Close(ctx context.Context) error
 }
 
+// Flusher определяет интерфейс провайдеров, буферизующих вывод.
+type Flusher interface {
+    // Flush записывает буферизованные сообщения в место назначения
+    Flush(ctx context.Context) error
+}
+
+// Syncer определяет интерфейс провайдеров, записывающих в файл, который можно
+// сбросить на диск, чтобы записи пережили отключение питания.
+type Syncer interface {
+    // Sync записывает буферизованные сообщения и сбрасывает файл на диск (fsync)
+    Sync() error
+}
+
+// Named определяет интерфейс провайдеров, имеющих имя.
+// Имя используется логгером для идентификации провайдера в статистике,
+// проверках состояния, обработчике ошибок и при удалении провайдера.
+// При регистрации нескольких провайдеров с одинаковым именем к имени добавляется числовой суффикс.
+type Named interface {
+    // Name возвращает имя провайдера
+    Name() string
+}
+
+// Gated определяет интерфейс провайдеров, которые могут быть неактивны
+// (например, провайдер Sentry вне production). Логгер пропускает неактивные
+// провайдеры при записи, не проверяет их состояние в HealthCheck и отмечает
+// их в Stats; закрываются они вместе с остальными провайдерами.
+// Active вызывается для каждой записи, поэтому должен выполняться быстро.
+type Gated interface {
+    // Active сообщает, принимает ли провайдер записи в данный момент
+    Active() bool
+}
+
+// Filterer определяет интерфейс провайдеров, отбрасывающих часть записей
+// (см. NewFilterProvider). Количество отброшенных записей отображается в Stats.
+type Filterer interface {
+    // Filtered возвращает количество отброшенных записей
+    Filtered() uint64
+}
+
+// Pauser определяет интерфейс провайдеров консольного вывода, вывод которых
+// можно приостановить, например на время отрисовки индикатора прогресса
+// (см. NewFmtProvider). Пока вывод приостановлен, записи ниже LevelError
+// накапливаются в буфере ограниченного размера; записи LevelError и выше
+// выводятся сразу, а накопленные остаются в буфере до Resume.
+type Pauser interface {
+    // Pause приостанавливает вывод. Вызовы могут быть вложенными:
+    // вывод возобновляется после соответствующего количества вызовов Resume
+    Pause()
+    
+    // Resume возобновляет вывод и выводит накопленные записи
+    Resume() error
+    
+    // SynchronizedWriter возвращает writer, запись в который не чередуется
+    // со строками провайдера. Предназначен для библиотек индикаторов прогресса,
+    // выводящих в тот же терминал
+    SynchronizedWriter(w io.Writer) io.Writer
+}
+
+// HealthChecker определяет интерфейс провайдеров, умеющих проверять свое состояние
+// (например, доступность удаленного сервиса).
+type HealthChecker interface {
+    // HealthCheck возвращает ошибку, если провайдер не может записывать логи
+    HealthCheck(ctx context.Context) error
+}
+
+// Describer определяет интерфейс провайдеров, описывающих свою конфигурацию
+// для Logger.LogConfiguration. Значения ключей, похожих на секреты (api_key,
+// token, password и т.п.), и пароли в URL маскируются при записи, поэтому
+// описание может содержать параметры конфигурации как есть.
+type Describer interface {
+    // Describe возвращает параметры конфигурации провайдера
+    Describe() map[string]interface{}
+}
+
 // Logger определяет основной интерфейс для логирования в приложении.
 // Предоставляет методы для логирования с различными комбинациями параметров:
 // - интерполяция строк (форматирование)
@@ -93,4 +177,41 @@ type Logger interface {
     
     // FatalErrWithFields логирует критическую ошибку с дополнительной ошибкой, полями и завершает приложение
     FatalErrWithFields(ctx context.Context, err error, fields Fields, format string, args ...interface{})
+    
+    // SetLevel устанавливает минимальный уровень сообщений логгера во время работы.
+    // Возвращает ErrInvalidLevel для недопустимых значений
+    SetLevel(level Level) error
+    
+    // GetLevel возвращает текущий минимальный уровень сообщений логгера
+    GetLevel() Level
+    
+    // Silence отключает сообщения ниже указанного уровня и возвращает функцию восстановления.
+    // Вызовы с контекстом из ContextWithMinLevel продолжают использовать свой уровень
+    Silence(below Level) (restore func())
+    
+    // BeginOp записывает начало длительной операции и возвращает Operation
+    // для записи ее завершения (см. Operation)
+    BeginOp(ctx context.Context, name string, fields Fields) *Operation
+    
+    // LogConfiguration записывает уровнем LevelInfo описание действующей конфигурации
+    // логгера и его провайдеров (см. Describer)
+    LogConfiguration(ctx context.Context)
+    
+    // Stats возвращает счетчики работы логгера и его провайдеров (по именам провайдеров)
+    Stats() LoggerStats
+    
+    // HealthCheck возвращает результаты проверки состояния провайдеров по их именам
+    HealthCheck(ctx context.Context) map[string]error
+    
+    // AddProvider регистрирует дополнительный провайдер и возвращает присвоенное ему имя
+    AddProvider(provider LoggerProvider) string
+    
+    // RemoveProviderByName исключает провайдер с указанным именем из логгера и закрывает его
+    RemoveProviderByName(ctx context.Context, name string) error
+    
+    // Flush сбрасывает буферы всех провайдеров, поддерживающих буферизацию
+    Flush(ctx context.Context) error
+    
+    // Close закрывает все провайдеры. Должен вызываться при завершении работы приложения
+    Close(ctx context.Context) error
 }
\ No newline at end of file
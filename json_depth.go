@@ -0,0 +1,274 @@
+package sglogger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// jsonMaxDepth - наибольшая глубина вложенности составных значений полей
+	// в JSON: более глубокие значения заменяются на jsonMaxDepthValue.
+	jsonMaxDepth = 32
+
+	// jsonMaxDepthValue заменяет значения глубже jsonMaxDepth и повторные
+	// вхождения значения в самого себя (циклы).
+	jsonMaxDepthValue = "<max depth>"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// errJSONMaxDepth сообщается в FieldEncodeErrorField для усеченных значений.
+var errJSONMaxDepth = fmt.Errorf("value is cyclic or nested deeper than %d levels, truncated", jsonMaxDepth)
+
+// boundJSONDepth проверяет, что значение не содержит циклов и вложенности
+// глубже jsonMaxDepth. Такое значение возвращается без изменений; иначе
+// возвращается копия в обобщенном представлении (map[string]interface{}
+// и []interface{}), в которой слишком глубокие и циклические значения
+// заменены на jsonMaxDepthValue, и true. Без этого encoding/json прерывает
+// сериализацию циклической структуры только на глубине 1000, а запасной
+// вывод в формате %v рекурсивно обходит ее до переполнения стека.
+//
+// Значения, реализующие json.Marshaler или encoding.TextMarshaler,
+// не обходятся: их представление определяет сам тип.
+func boundJSONDepth(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid, reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v, false
+	}
+
+	w := &jsonDepthWalker{}
+	if !w.exceeds(rv, 0) {
+		return v, false
+	}
+	w.path = nil
+	return w.rewrite(rv, 0), true
+}
+
+// jsonRef идентифицирует указатель, отображение или срез на текущем пути обхода.
+type jsonRef struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// jsonDepthWalker обходит значение так же, как encoding/json: поля структур -
+// только экспортируемые и не исключенные тегом `json:"-"`.
+type jsonDepthWalker struct {
+	path map[jsonRef]struct{}
+}
+
+// enter добавляет ссылочное значение на путь обхода и возвращает false,
+// если оно уже на пути, то есть значение входит в самого себя.
+func (w *jsonDepthWalker) enter(rv reflect.Value) bool {
+	ref := jsonRef{ptr: rv.Pointer(), typ: rv.Type()}
+	if _, ok := w.path[ref]; ok {
+		return false
+	}
+	if w.path == nil {
+		w.path = make(map[jsonRef]struct{})
+	}
+	w.path[ref] = struct{}{}
+	return true
+}
+
+// leave убирает ссылочное значение с пути обхода.
+func (w *jsonDepthWalker) leave(rv reflect.Value) {
+	delete(w.path, jsonRef{ptr: rv.Pointer(), typ: rv.Type()})
+}
+
+// isJSONLeaf сообщает, сериализуется ли значение типа t без обхода вложенных
+// значений: типы с собственной сериализацией и []byte (строка base64).
+func isJSONLeaf(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// exceeds сообщает, содержит ли значение цикл или вложенность глубже jsonMaxDepth.
+func (w *jsonDepthWalker) exceeds(rv reflect.Value, depth int) bool {
+	if !rv.IsValid() || isJSONLeaf(rv.Type()) {
+		return false
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		return !rv.IsNil() && w.exceeds(rv.Elem(), depth)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return false
+		}
+		if !w.enter(rv) {
+			return true
+		}
+		defer w.leave(rv)
+		return w.exceeds(rv.Elem(), depth)
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() || rv.Len() == 0 {
+			return false
+		}
+		if depth >= jsonMaxDepth || !w.enter(rv) {
+			return true
+		}
+		defer w.leave(rv)
+		if rv.Kind() == reflect.Map {
+			iter := rv.MapRange()
+			for iter.Next() {
+				if w.exceeds(iter.Value(), depth+1) {
+					return true
+				}
+			}
+			return false
+		}
+		return w.exceedsElems(rv, depth)
+	case reflect.Array:
+		if rv.Len() == 0 {
+			return false
+		}
+		if depth >= jsonMaxDepth {
+			return true
+		}
+		return w.exceedsElems(rv, depth)
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if _, _, ok := jsonStructField(t.Field(i)); !ok {
+				continue
+			}
+			if depth >= jsonMaxDepth || w.exceeds(rv.Field(i), depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exceedsElems проверяет элементы среза или массива.
+func (w *jsonDepthWalker) exceedsElems(rv reflect.Value, depth int) bool {
+	for i := 0; i < rv.Len(); i++ {
+		if w.exceeds(rv.Index(i), depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite возвращает копию значения в обобщенном представлении, в которой
+// циклические значения и значения глубже jsonMaxDepth заменены на jsonMaxDepthValue.
+func (w *jsonDepthWalker) rewrite(rv reflect.Value, depth int) interface{} {
+	if !rv.IsValid() {
+		return nil
+	}
+	if isJSONLeaf(rv.Type()) {
+		return rv.Interface()
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return w.rewrite(rv.Elem(), depth)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		if !w.enter(rv) {
+			return jsonMaxDepthValue
+		}
+		defer w.leave(rv)
+		return w.rewrite(rv.Elem(), depth)
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		if depth >= jsonMaxDepth || !w.enter(rv) {
+			return jsonMaxDepthValue
+		}
+		defer w.leave(rv)
+		result := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			result[jsonMapKey(iter.Key())] = w.rewrite(iter.Value(), depth+1)
+		}
+		return result
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+		if depth >= jsonMaxDepth || !w.enter(rv) {
+			return jsonMaxDepthValue
+		}
+		defer w.leave(rv)
+		return w.rewriteElems(rv, depth)
+	case reflect.Array:
+		if depth >= jsonMaxDepth {
+			return jsonMaxDepthValue
+		}
+		return w.rewriteElems(rv, depth)
+	case reflect.Struct:
+		if depth >= jsonMaxDepth {
+			return jsonMaxDepthValue
+		}
+		t := rv.Type()
+		result := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, omitEmpty, ok := jsonStructField(t.Field(i))
+			if !ok || omitEmpty && rv.Field(i).IsZero() {
+				continue
+			}
+			result[name] = w.rewrite(rv.Field(i), depth+1)
+		}
+		return result
+	}
+	return rv.Interface()
+}
+
+// rewriteElems копирует элементы среза или массива.
+func (w *jsonDepthWalker) rewriteElems(rv reflect.Value, depth int) []interface{} {
+	result := make([]interface{}, rv.Len())
+	for i := range result {
+		result[i] = w.rewrite(rv.Index(i), depth+1)
+	}
+	return result
+}
+
+// jsonMapKey возвращает ключ отображения в виде строки, как encoding/json.
+func jsonMapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// jsonStructField возвращает имя поля структуры в JSON и признак omitempty
+// из тега json; ok равно false для полей, которые encoding/json не сериализует.
+// Встроенные структуры, в отличие от encoding/json, не раскрываются, а выводятся
+// как вложенный объект: точное представление усеченного значения не требуется.
+func jsonStructField(sf reflect.StructField) (name string, omitEmpty, ok bool) {
+	if sf.PkgPath != "" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "-" && opts == "" {
+		return "", false, false
+	}
+	if name == "" || name == "-" {
+		name = sf.Name
+	}
+	return name, strings.Contains(","+opts+",", ",omitempty,"), true
+}
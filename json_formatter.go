@@ -0,0 +1,368 @@
+package sglogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonReservedPrefix добавляется к полям, имена которых совпадают
+// с ключами записи ("ts", "level", "msg") или с FieldEncodeErrorField.
+const jsonReservedPrefix = "fields."
+
+// deterministicTimeLayout - формат времени детерминированного режима:
+// UTC с фиксированным количеством знаков дробной части.
+const deterministicTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// jsonFormatter формирует строки NDJSON вида
+// {"ts":"<RFC3339Nano>","level":"info","msg":"...","key":value,...}.
+type jsonFormatter struct {
+	floats        FloatFormat
+	deterministic bool
+	durations     DurationPolicy
+	reserved      []string
+}
+
+// NewJSONFormatter создает формат NDJSON: одна строка JSON на запись, поля
+// записываются на верхнем уровне объекта. Поле, имя которого совпадает с ключом
+// записи ("ts", "level", "msg") или с FieldEncodeErrorField, получает префикс
+// "fields." (например, "fields.msg"). Значения, которые не удается
+// сериализовать, заменяются строкой в формате %v, а ошибки записываются
+// в поле FieldEncodeErrorField, поэтому каждая строка остается корректным JSON.
+// Во вложенных значениях глубже 32 уровней и в циклических структурах
+// соответствующие значения заменяются строкой "<max depth>".
+// Числа с плавающей точкой выводятся согласно config.FloatFormat,
+// значения time.Duration - согласно config.Durations.
+//
+// При config.JSON.Deterministic одна и та же запись всегда дает одну и ту же
+// строку, что нужно для эталонных файлов в тестах: поля упорядочены по имени
+// на всех уровнях вложенности, HTML-символы не экранируются, время выводится
+// в UTC с девятью знаками дробной части. Режим медленнее из-за сортировки.
+func NewJSONFormatter(config ProviderConfig) Formatter {
+	return newJSONFormatter(config)
+}
+
+// newJSONFormatter создает формат NDJSON, в котором, помимо ключей записи,
+// зарезервированы ключи extra (например, служебные поля цепочки хешей).
+func newJSONFormatter(config ProviderConfig, extra ...string) *jsonFormatter {
+	return &jsonFormatter{
+		floats:        config.FloatFormat,
+		deterministic: config.JSON.Deterministic,
+		durations:     config.Durations,
+		reserved:      append([]string{"ts", "level", "msg"}, extra...),
+	}
+}
+
+// AppendFormat добавляет запись в формате JSON к buf. Если запись
+// не удается сформировать (паника при обходе значений полей), вместо нее
+// добавляется минимальная запись из времени, уровня, сообщения и поля
+// EncodeErrorField, чтобы запись не терялась целиком.
+func (f *jsonFormatter) AppendFormat(buf []byte, e Entry) (out []byte) {
+	start := len(buf)
+	defer func() {
+		if r := recover(); r != nil {
+			out = f.appendMinimal(buf[:start], e, fmt.Sprintf("encode panicked: %v", r))
+		}
+	}()
+
+	e.Fields = durationFields(e.Fields, f.durations)
+
+	buf = append(buf, `{"ts":"`...)
+	if f.deterministic {
+		buf = e.Time.UTC().AppendFormat(buf, deterministicTimeLayout)
+	} else {
+		buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+	}
+	buf = append(buf, `","level":`...)
+	buf = appendJSONString(buf, e.Level.String())
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, e.Message)
+
+	if f.deterministic {
+		buf = appendSortedJSONFields(buf, e.Fields, f.floats, f.reserved...)
+	} else {
+		buf = appendJSONFields(buf, e.Fields, f.floats, f.reserved...)
+	}
+	return append(buf, "}\n"...)
+}
+
+// appendMinimal добавляет запись только с ключами ts, level, msg
+// и EncodeErrorField с описанием ошибки.
+func (f *jsonFormatter) appendMinimal(buf []byte, e Entry, encodeError string) []byte {
+	buf = append(buf, `{"ts":"`...)
+	if f.deterministic {
+		buf = e.Time.UTC().AppendFormat(buf, deterministicTimeLayout)
+	} else {
+		buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+	}
+	buf = append(buf, `","level":`...)
+	buf = appendJSONString(buf, e.Level.String())
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, e.Message)
+	buf = append(buf, `,"`+EncodeErrorField+`":`...)
+	buf = appendJSONString(buf, encodeError)
+	return append(buf, "}\n"...)
+}
+
+// appendJSONFields добавляет поля к JSON-объекту в виде `,"key":value`.
+// Поля с именами из reserved и поле FieldEncodeErrorField получают префикс
+// jsonReservedPrefix. Значения, которые не удается сериализовать, заменяются
+// строкой в формате %v, а ошибки добавляются в поле FieldEncodeErrorField.
+func appendJSONFields(buf []byte, fields Fields, floats FloatFormat, reserved ...string) []byte {
+	var encodeErrors []string
+	for k, v := range fields {
+		buf, encodeErrors = appendJSONField(buf, encodeErrors, k, v, floats, false, reserved)
+	}
+	return appendJSONEncodeErrors(buf, encodeErrors)
+}
+
+// appendSortedJSONFields добавляет поля как appendJSONFields, но в порядке
+// имен и без экранирования HTML-символов во вложенных значениях.
+func appendSortedJSONFields(buf []byte, fields Fields, floats FloatFormat, reserved ...string) []byte {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return jsonFieldKey(keys[i], reserved) < jsonFieldKey(keys[j], reserved)
+	})
+
+	var encodeErrors []string
+	for _, k := range keys {
+		buf, encodeErrors = appendJSONField(buf, encodeErrors, k, fields[k], floats, true, reserved)
+	}
+	return appendJSONEncodeErrors(buf, encodeErrors)
+}
+
+// jsonFieldKey возвращает имя поля в JSON с учетом префикса jsonReservedPrefix.
+func jsonFieldKey(k string, reserved []string) string {
+	if k == FieldEncodeErrorField || containsString(reserved, k) {
+		return jsonReservedPrefix + k
+	}
+	return k
+}
+
+// appendJSONField добавляет одно поле и накапливает ошибки сериализации.
+func appendJSONField(buf []byte, encodeErrors []string, k string, v interface{}, floats FloatFormat, deterministic bool, reserved []string) ([]byte, []string) {
+	key := jsonFieldKey(k, reserved)
+	buf = append(buf, ',')
+	buf = appendJSONString(buf, key)
+	buf = append(buf, ':')
+
+	var err error
+	if deterministic {
+		buf, err = appendDeterministicJSONValue(buf, v, floats)
+	} else {
+		buf, err = appendJSONValue(buf, v, floats)
+	}
+	if err != nil {
+		encodeErrors = append(encodeErrors, key+": "+err.Error())
+	}
+	return buf, encodeErrors
+}
+
+// appendJSONEncodeErrors добавляет поле FieldEncodeErrorField, если были ошибки.
+func appendJSONEncodeErrors(buf []byte, encodeErrors []string) []byte {
+	if len(encodeErrors) > 0 {
+		sort.Strings(encodeErrors)
+		buf = append(buf, `,"`+FieldEncodeErrorField+`":`...)
+		buf = appendJSONString(buf, strings.Join(encodeErrors, "; "))
+	}
+	return buf
+}
+
+// containsString сообщает, содержит ли список строку s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// appendJSONValue добавляет значение поля в формате JSON. Если значение
+// не удается сериализовать, добавляет его строковое представление в формате %v
+// и возвращает ошибку сериализации.
+func appendJSONValue(buf []byte, v interface{}, floats FloatFormat) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case string:
+		return appendJSONString(buf, val), nil
+	case bool:
+		return strconv.AppendBool(buf, val), nil
+	case int:
+		return strconv.AppendInt(buf, int64(val), 10), nil
+	case int64:
+		return strconv.AppendInt(buf, val, 10), nil
+	case int32:
+		return strconv.AppendInt(buf, int64(val), 10), nil
+	case uint:
+		return strconv.AppendUint(buf, uint64(val), 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, val, 10), nil
+	case uint32:
+		return strconv.AppendUint(buf, uint64(val), 10), nil
+	case float64:
+		return appendJSONFloat(buf, val, 64, floats)
+	case float32:
+		return appendJSONFloat(buf, float64(val), 32, floats)
+	case json.RawMessage:
+		return appendRawPayload(buf, val)
+	}
+
+	v, truncated := boundJSONDepth(v)
+	data, err := safeMarshal(v)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprintf("%v", v)), err
+	}
+	buf = append(buf, data...)
+	if truncated {
+		return buf, errJSONMaxDepth
+	}
+	return buf, nil
+}
+
+// safeMarshal сериализует значение через json.Marshal, превращая панику
+// в методе MarshalJSON или MarshalText значения в ошибку: одно поле
+// не должно прерывать запись всей записи.
+func safeMarshal(v interface{}) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("marshal panicked: %v", r)
+		}
+	}()
+	return json.Marshal(v)
+}
+
+// appendDeterministicJSONValue добавляет значение как appendJSONValue, но без
+// экранирования HTML-символов и с ключами, упорядоченными на всех уровнях
+// вложенности, включая поля структур: составное значение сериализуется,
+// разбирается в обобщенное представление и сериализуется повторно
+// (карты encoding/json выводит в порядке ключей). Числа при этом сохраняют
+// исходную запись.
+func appendDeterministicJSONValue(buf []byte, v interface{}, floats FloatFormat) ([]byte, error) {
+	switch v.(type) {
+	case nil, string, bool, int, int64, int32, uint, uint64, uint32, float64, float32:
+		return appendJSONValue(buf, v, floats)
+	case json.RawMessage:
+		if !json.Valid(v.(json.RawMessage)) {
+			return appendJSONValue(buf, v, floats)
+		}
+	}
+
+	v, truncated := boundJSONDepth(v)
+	data, err := safeMarshal(v)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprintf("%v", v)), err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return appendJSONString(buf, fmt.Sprintf("%v", v)), err
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return appendJSONString(buf, fmt.Sprintf("%v", v)), err
+	}
+	buf = append(buf, bytes.TrimSuffix(out.Bytes(), []byte{'\n'})...)
+	if truncated {
+		return buf, errJSONMaxDepth
+	}
+	return buf, nil
+}
+
+// appendJSONFloat добавляет число с плавающей точкой в формате JSON
+// с учетом FloatFormat (см. jsonFloat).
+func appendJSONFloat(buf []byte, f float64, bitSize int, floats FloatFormat) ([]byte, error) {
+	value, err := jsonFloat(f, bitSize, floats)
+	if err != nil {
+		return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bitSize)), err
+	}
+
+	switch val := value.(type) {
+	case string:
+		return appendJSONString(buf, val), nil
+	case json.Number:
+		return append(buf, val...), nil
+	}
+
+	// Как в encoding/json: экспоненциальная запись только для очень малых и больших чисел
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bitSize)
+	if format == 'e' {
+		// e-09 -> e-9
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, nil
+}
+
+// appendJSONString добавляет строку в кавычках с экранированием по правилам JSON.
+// Некорректные последовательности UTF-8 заменяются на U+FFFD, а U+2028 и U+2029
+// экранируются, как в encoding/json.
+func appendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
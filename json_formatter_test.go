@@ -0,0 +1,224 @@
+package sglogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// panickingMarshaler паникует при сериализации в JSON.
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) { panic("marshal exploded") }
+
+// failingMarshaler возвращает ошибку при сериализации в JSON.
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("cannot marshal") }
+
+// panickingStringer паникует в String.
+type panickingStringer struct{}
+
+func (panickingStringer) String() string { panic("string exploded") }
+
+// panickingError паникует в Error.
+type panickingError struct{}
+
+func (panickingError) Error() string { panic("error exploded") }
+
+func TestJSONFormatterFieldFailures(t *testing.T) {
+	entry := Entry{
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "request handled",
+	}
+
+	tests := []struct {
+		name       string
+		fields     Fields
+		failed     string
+		wantErrors []string
+	}{
+		{name: "MarshalJSON panics", fields: Fields{"user": panickingMarshaler{}, "status": 200}, failed: "user", wantErrors: []string{"user: marshal panicked: marshal exploded"}},
+		{name: "MarshalJSON fails", fields: Fields{"user": failingMarshaler{}, "status": 200}, failed: "user", wantErrors: []string{"user: ", "cannot marshal"}},
+		{name: "unsupported value", fields: Fields{"callback": func() {}, "status": 200}, failed: "callback", wantErrors: []string{"callback: "}},
+	}
+
+	formatters := map[string]Formatter{
+		"json":          NewJSONFormatter(ProviderConfig{}),
+		"deterministic": NewJSONFormatter(ProviderConfig{JSON: JSONFormat{Deterministic: true}}),
+	}
+
+	for _, tt := range tests {
+		for name, f := range formatters {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				e := entry
+				e.Fields = tt.fields
+				line := f.AppendFormat(nil, e)
+
+				var decoded map[string]interface{}
+				if err := json.Unmarshal(line, &decoded); err != nil {
+					t.Fatalf("output is not valid JSON: %v: %s", err, line)
+				}
+				if decoded["msg"] != "request handled" || decoded["status"] != 200.0 {
+					t.Errorf("rest of the entry is missing: %s", line)
+				}
+				if _, ok := decoded[tt.failed].(string); !ok {
+					t.Errorf("%s = %v, want its %%v text", tt.failed, decoded[tt.failed])
+				}
+				encodeErr, _ := decoded[FieldEncodeErrorField].(string)
+				for _, want := range tt.wantErrors {
+					if !strings.Contains(encodeErr, want) {
+						t.Errorf("%s = %q, want it to contain %q", FieldEncodeErrorField, encodeErr, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestJSONSafeFieldsRecoversPanic(t *testing.T) {
+	fields := jsonSafeFields(Fields{"user": panickingMarshaler{}, "status": 200}, FloatFormat{})
+	if _, err := json.Marshal(fields); err != nil {
+		t.Fatalf("json.Marshal(jsonSafeFields) = %v", err)
+	}
+	if got, _ := fields[FieldEncodeErrorField].(string); !strings.Contains(got, "marshal panicked") {
+		t.Errorf("%s = %q, want the recovered panic", FieldEncodeErrorField, got)
+	}
+	if fields["status"] != 200 {
+		t.Errorf("status = %v, want 200", fields["status"])
+	}
+}
+
+func TestTextFormatterFieldPanics(t *testing.T) {
+	text, err := NewTextFormatter(ProviderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "String panics", value: panickingStringer{}, want: "PANIC=String method: string exploded"},
+		{name: "Error panics", value: panickingError{}, want: "PANIC=Error method: error exploded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := Entry{Time: time.Now(), Level: LevelInfo, Message: "request handled", Fields: Fields{"value": tt.value}}
+			line := string(text.AppendFormat(nil, entry))
+			if !strings.Contains(line, tt.want) || !strings.Contains(line, `"request handled"`) {
+				t.Errorf("line = %q, want it to contain %q and the message", line, tt.want)
+			}
+		})
+	}
+}
+
+// fieldShape строит значение поля произвольной формы из байтов фаззера:
+// каждый байт выбирает вид значения, а для составных значений - и число
+// вложенных элементов.
+type fieldShape struct {
+	data []byte
+	maps []map[string]interface{} // отображения на пути, для построения циклов
+}
+
+func (s *fieldShape) next() (byte, bool) {
+	if len(s.data) == 0 {
+		return 0, false
+	}
+	b := s.data[0]
+	s.data = s.data[1:]
+	return b, true
+}
+
+func (s *fieldShape) value() interface{} {
+	op, ok := s.next()
+	if !ok {
+		return "leaf"
+	}
+	n, _ := s.next()
+	switch op % 11 {
+	case 0:
+		m := make(map[string]interface{})
+		s.maps = append(s.maps, m)
+		for i := 0; i < int(n%4); i++ {
+			m["k"+strconv.Itoa(i)] = s.value()
+		}
+		s.maps = s.maps[:len(s.maps)-1]
+		return m
+	case 1:
+		var list []interface{}
+		for i := 0; i < int(n%4); i++ {
+			list = append(list, s.value())
+		}
+		return list
+	case 2:
+		v := s.value()
+		return &v
+	case 3:
+		return string([]byte{n, n ^ 0x80})
+	case 4:
+		return int(n) - 128
+	case 5:
+		return float64(n) / 3
+	case 6:
+		// Ссылка на отображение выше по пути создает цикл
+		if len(s.maps) == 0 {
+			return nil
+		}
+		return s.maps[int(n)%len(s.maps)]
+	case 7:
+		return panickingMarshaler{}
+	case 8:
+		return failingMarshaler{}
+	case 9:
+		return Fields{"nested": s.value()}
+	default:
+		return func() {}
+	}
+}
+
+func FuzzJSONFormatterFieldShapes(f *testing.F) {
+	// Затравка повторяет случаи TestJSONFormatterFieldFailures, циклы
+	// и вложенность глубже jsonMaxDepth
+	f.Add([]byte{7, 0})
+	f.Add([]byte{8, 0})
+	f.Add([]byte{10, 0})
+	f.Add([]byte{0, 1, 6, 0})
+	f.Add([]byte{0, 2, 2, 0, 6, 0, 4, 7})
+	f.Add([]byte{9, 0, 1, 2, 3, 'a', 5, 9})
+	f.Add(bytes.Repeat([]byte{1, 1}, jsonMaxDepth+8))
+	f.Add(bytes.Repeat([]byte{0, 1}, jsonMaxDepth+8))
+
+	formatters := map[string]Formatter{
+		"json":          NewJSONFormatter(ProviderConfig{}),
+		"deterministic": NewJSONFormatter(ProviderConfig{JSON: JSONFormat{Deterministic: true}}),
+	}
+
+	f.Fuzz(func(t *testing.T, shape []byte) {
+		s := &fieldShape{data: shape}
+		entry := Entry{
+			Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Level:   LevelInfo,
+			Message: "request handled",
+			Fields:  Fields{"value": s.value(), "status": 200},
+		}
+		for name, formatter := range formatters {
+			line := formatter.AppendFormat(nil, entry)
+
+			var decoded map[string]interface{}
+			if err := json.Unmarshal(line, &decoded); err != nil {
+				t.Fatalf("%s: output is not valid JSON: %v: %s", name, err, line)
+			}
+			if decoded["msg"] != "request handled" {
+				t.Errorf("%s: entry lost: %s", name, line)
+			}
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package sglogger
+
+import "io"
+
+// NewJSONProvider создает провайдер, записывающий в w по одной строке NDJSON
+// на запись: {"ts":...,"level":...,"msg":...} и все поля на верхнем уровне
+// объекта (см. NewJSONFormatter). Каждая строка - корректный JSON, даже если
+// значения содержат переводы строк и кавычки: такой вывод можно передавать
+// Filebeat, Fluent Bit и другим сборщикам без разбора текста.
+// Поле с именем "ts", "level" или "msg" записывается как "fields.ts",
+// "fields.level" или "fields.msg"; значения, которые не удается
+// сериализовать (каналы, функции), записываются строкой в формате %v
+// с описанием ошибки в поле FieldEncodeErrorField.
+//
+// Если w равен nil, записи выводятся в стандартный вывод. Вывод, буферизация
+// и пауза работают так же, как у NewFmtProviderWithWriter; config.Formatter
+// не используется. Имя провайдера по умолчанию - "json".
+func NewJSONProvider(config ProviderConfig, w io.Writer) LoggerProvider {
+	if config.Name == "" {
+		config.Name = "json"
+	}
+	config.Formatter = NewJSONFormatter(config)
+	return NewFmtProviderWithWriter(config, w)
+}
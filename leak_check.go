@@ -0,0 +1,71 @@
+package sglogger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// leakCheckDepth ограничивает количество кадров стека создания.
+const leakCheckDepth = 32
+
+// leakCheck сообщает в диагностический канал об объекте, который был собран
+// сборщиком мусора без вызова Close (см. LoggerConfig.LeakCheck).
+// Нулевой указатель ничего не проверяет.
+type leakCheck struct {
+	kind        string
+	pcs         []uintptr
+	closed      int32
+	diagnostics *diagnostics
+}
+
+// newLeakCheck запоминает стек создания owner и устанавливает для owner
+// финализатор. Возвращает nil, если проверка отключена. Финализатор ссылается
+// только на leakCheck, поэтому не удерживает owner от сборки.
+// Объект, на который ссылается фоновый горутин, не собирается, а финализатор
+// объекта из цикла ссылок может не выполниться, поэтому такие провайдеры
+// передают в owner обертку, которую возвращают вызывающему
+// (см. fileProviderHandle).
+func newLeakCheck(enabled bool, owner interface{}, kind string, diag *diagnostics) *leakCheck {
+	if !enabled {
+		return nil
+	}
+
+	pcs := make([]uintptr, leakCheckDepth)
+	// Пропускаем runtime.Callers и newLeakCheck: стек начинается с конструктора.
+	n := runtime.Callers(2, pcs)
+	c := &leakCheck{kind: kind, pcs: pcs[:n], diagnostics: diag}
+	runtime.SetFinalizer(owner, func(interface{}) { c.finalize() })
+	return c
+}
+
+// markClosed отмечает, что Close был вызван.
+func (c *leakCheck) markClosed() {
+	if c != nil {
+		atomic.StoreInt32(&c.closed, 1)
+	}
+}
+
+// finalize сообщает об объекте, собранном без вызова Close.
+func (c *leakCheck) finalize() {
+	if atomic.LoadInt32(&c.closed) != 0 {
+		return
+	}
+
+	var stack strings.Builder
+	frames := runtime.CallersFrames(c.pcs)
+	for {
+		frame, more := frames.Next()
+		stack.WriteString("\n\t")
+		stack.WriteString(frame.Function)
+		stack.WriteString("\n\t\t")
+		stack.WriteString(frame.File)
+		stack.WriteByte(':')
+		stack.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	c.diagnostics.reportf("leak check", "%s was garbage collected without Close; created at:%s", c.kind, stack.String())
+}
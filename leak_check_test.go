@@ -0,0 +1,108 @@
+package sglogger
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leakWarning запускает сборку мусора, пока в out не появится
+// предупреждение LeakCheck, и возвращает его; пустую строку, если
+// предупреждение не появилось за время ожидания.
+func leakWarning(out *syncBuffer, wait time.Duration) string {
+	deadline := time.Now().Add(wait)
+	for {
+		runtime.GC()
+		if s := out.String(); strings.Contains(s, "garbage collected without Close") {
+			return s
+		}
+		if time.Now().After(deadline) {
+			return ""
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLeakCheck(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		kind string
+		open func(t *testing.T, config LoggerConfig) (LoggerProvider, error)
+	}{
+		{"file", "file provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewFileProvider(FileProviderConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, Path: filepath.Join(t.TempDir(), "app.log")})
+		}},
+		// Горутин сжатия ссылается на провайдер
+		{"compressed file", "file provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewFileProvider(FileProviderConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, Path: filepath.Join(t.TempDir(), "app.log"), Compress: true})
+		}},
+		{"segment", "segment provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewSegmentProvider(SegmentConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, Dir: t.TempDir()})
+		}},
+		{"buffered fmt", "buffered provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewFmtProviderWithWriter(ProviderConfig{LoggerConfig: config, BufferSize: 4096}, io.Discard), nil
+		}},
+		{"syslog", "syslog provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewSyslogProvider(SyslogConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, Network: "udp", Address: listener.LocalAddr().String()})
+		}},
+		{"loki", "Loki provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewLokiProvider(LokiConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, URL: server.URL})
+		}},
+		{"sentry", "Sentry provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			dsn := strings.Replace(server.URL, "http://", "http://key@", 1) + "/1"
+			return NewSentryProvider(dsn, func(c *SentryConfig) { c.LoggerConfig = config })
+		}},
+		{"honeycomb", "Honeycomb provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewHoneycombProvider(HoneycombConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, APIKey: "key", Dataset: "logs", APIHost: server.URL})
+		}},
+		{"victorialogs", "VictoriaLogs provider", func(t *testing.T, config LoggerConfig) (LoggerProvider, error) {
+			return NewVictoriaLogsProvider(VictoriaLogsConfig{ProviderConfig: ProviderConfig{LoggerConfig: config}, Endpoint: server.URL})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var leaked, closed syncBuffer
+			// Провайдеры создаются в отдельной функции, чтобы на них
+			// не оставалось ссылок в стеке теста
+			func() {
+				if _, err := tt.open(t, LoggerConfig{LeakCheck: true, Diagnostics: &DiagnosticsConfig{Output: &leaked}}); err != nil {
+					t.Fatalf("open: %v", err)
+				}
+				p, err := tt.open(t, LoggerConfig{LeakCheck: true, Diagnostics: &DiagnosticsConfig{Output: &closed}})
+				if err != nil {
+					t.Fatalf("open: %v", err)
+				}
+				if err := p.Close(context.Background()); err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+			}()
+
+			warning := leakWarning(&leaked, 5*time.Second)
+			if warning == "" {
+				t.Fatal("no leak warning for an unclosed provider")
+			}
+			if !strings.Contains(warning, tt.kind) || !strings.Contains(warning, "leak_check_test.go") {
+				t.Errorf("warning does not name the provider and its construction site:\n%s", warning)
+			}
+			if warning := leakWarning(&closed, 100*time.Millisecond); warning != "" {
+				t.Errorf("closed provider reported as leaked:\n%s", warning)
+			}
+		})
+	}
+}
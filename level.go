@@ -0,0 +1,64 @@
+package sglogger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// String возвращает текстовое представление уровня логирования.
+// Для значений вне диапазона LevelDebug..LevelFatal возвращает "level(N)".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warning"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "critical"
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// IsValid сообщает, является ли значение одним из определенных уровней логирования.
+func (l Level) IsValid() bool {
+	return l >= LevelDebug && l <= LevelFatal
+}
+
+// clampLevel приводит значение уровня к диапазону LevelDebug..LevelFatal.
+func clampLevel(l Level) Level {
+	switch {
+	case l < LevelDebug:
+		return LevelDebug
+	case l > LevelFatal:
+		return LevelFatal
+	}
+	return l
+}
+
+// ParseLevel преобразует строку в уровень логирования без учета регистра.
+// Принимает имена уровней ("debug", "info", "warn"/"warning", "error", "fatal"/"critical")
+// и их числовые значения. Значения вне допустимого диапазона отклоняются с ErrInvalidLevel.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal", "critical":
+		return LevelFatal, nil
+	}
+
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && Level(n).IsValid() {
+		return Level(n), nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidLevel, s)
+}
@@ -0,0 +1,29 @@
+package sglogger
+
+import "context"
+
+// ContextWithMinLevel возвращает контекст, для вызовов с которым минимальный уровень
+// логгера заменяется указанным. Переопределение может как понижать порог
+// (включить Debug для отдельного запроса), так и повышать его.
+//
+// Порядок применения порогов:
+//   - если в контексте задан минимальный уровень, он заменяет и уровень логгера (SetLevel),
+//     и порог, установленный Silence;
+//   - иначе действует наибольший из уровня логгера и порога Silence;
+//   - после этого каждый провайдер применяет собственный уровень (ShouldLog).
+func ContextWithMinLevel(ctx context.Context, level Level) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, minLevelKey, level)
+}
+
+// MinLevelFromContext возвращает минимальный уровень, заданный в контексте
+// через ContextWithMinLevel.
+func MinLevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(minLevelKey).(Level)
+	return level, ok
+}
@@ -0,0 +1,69 @@
+package sglogger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSilenceAndContextLevelPrecedence(t *testing.T) {
+	all := &recordingProvider{name: "all"}
+	warn := &recordingProvider{name: "warn", level: LevelWarn}
+	logger := NewLogger(LoggerConfig{}, NewFieldsHandler(), all, warn)
+	if err := logger.SetLevel(LevelInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	background := context.Background()
+	debugCtx := ContextWithMinLevel(background, LevelDebug)
+	errorCtx := ContextWithMinLevel(background, LevelError)
+
+	restore := logger.Silence(LevelWarn)
+	logger.Info(background, "silenced info")
+	logger.Warning(background, "warning")
+	// Уровень из контекста заменяет и уровень логгера, и порог Silence
+	logger.Debug(debugCtx, "scoped debug")
+	logger.Warning(errorCtx, "raised warning")
+	restore()
+	restore()
+	logger.Info(background, "restored info")
+	logger.Debug(background, "debug below logger level")
+
+	check := func(p *recordingProvider, want ...string) {
+		t.Helper()
+		var got []string
+		for _, e := range p.Entries() {
+			got = append(got, e.Message)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s received %q, want %q", p.name, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s received %q, want %q", p.name, got, want)
+			}
+		}
+	}
+	check(all, "warning", "scoped debug", "restored info")
+	// Уровень провайдера применяется после порогов логгера
+	check(warn, "warning")
+}
+
+func TestNestedSilenceRestoresPreviousFloor(t *testing.T) {
+	provider := &recordingProvider{}
+	logger := NewLogger(LoggerConfig{}, NewFieldsHandler(), provider)
+	ctx := context.Background()
+
+	outer := logger.Silence(LevelWarn)
+	inner := logger.Silence(LevelError)
+	logger.Warning(ctx, "dropped by inner")
+	inner()
+	logger.Warning(ctx, "kept after inner restore")
+	logger.Info(ctx, "dropped by outer")
+	outer()
+	logger.Info(ctx, "kept after outer restore")
+
+	entries := provider.Entries()
+	if len(entries) != 2 || entries[0].Message != "kept after inner restore" || entries[1].Message != "kept after outer restore" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,66 @@
+package sglogger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// levelLabels содержит подготовленные подписи уровней для текстового вывода.
+// Регистр и выравнивание применяются один раз при создании провайдера.
+type levelLabels struct {
+	labels [LevelFatal + 1]string
+	format LevelFormat
+}
+
+// newLevelLabels подготавливает подписи уровней по настройкам.
+// Возвращает ошибку, если заданные подписи покрывают не все уровни.
+func newLevelLabels(format LevelFormat) (levelLabels, error) {
+	ll := levelLabels{format: format}
+
+	for level := LevelDebug; level <= LevelFatal; level++ {
+		label := level.String()
+		if format.Labels != nil {
+			custom, ok := format.Labels[level]
+			if !ok || custom == "" {
+				return levelLabels{}, fmt.Errorf("sglogger: no label for level %s", level)
+			}
+			label = custom
+		}
+		ll.labels[level] = ll.render(label)
+	}
+	return ll, nil
+}
+
+// maxWidth возвращает наибольшую ширину подписи уровня в колонках терминала.
+func (ll *levelLabels) maxWidth() int {
+	width := 0
+	for _, label := range ll.labels {
+		if w := displayWidth([]byte(label)); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// label возвращает подпись уровня. Для неизвестных уровней используется Level.String.
+func (ll *levelLabels) label(level Level) string {
+	if level.IsValid() {
+		return ll.labels[level]
+	}
+	return ll.render(level.String())
+}
+
+// render применяет к подписи регистр и выравнивание.
+func (ll *levelLabels) render(label string) string {
+	switch ll.format.Case {
+	case LabelCaseUpper:
+		label = strings.ToUpper(label)
+	case LabelCaseLower:
+		label = strings.ToLower(label)
+	}
+
+	if pad := ll.format.Width - displayWidth([]byte(label)); pad > 0 {
+		label += strings.Repeat(" ", pad)
+	}
+	return label
+}
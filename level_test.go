@@ -0,0 +1,93 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLevelString(t *testing.T) {
+	for level, want := range map[Level]string{
+		LevelDebug: "debug",
+		LevelFatal: "critical",
+		Level(7):   "level(7)",
+		Level(-3):  "level(-3)",
+	} {
+		if got := level.String(); got != want {
+			t.Errorf("Level(%d).String() = %q, want %q", int(level), got, want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]Level{
+		"debug":    LevelDebug,
+		" WARN ":   LevelWarn,
+		"warning":  LevelWarn,
+		"critical": LevelFatal,
+		"fatal":    LevelFatal,
+		"3":        LevelError,
+	} {
+		if got, err := ParseLevel(input); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "verbose", "7", "-1", "level(7)"} {
+		if _, err := ParseLevel(input); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("ParseLevel(%q) error = %v, want ErrInvalidLevel", input, err)
+		}
+	}
+}
+
+func TestSetLevelRejectsUnknownLevels(t *testing.T) {
+	logger := NewLogger(LoggerConfig{}, NewFieldsHandler(), &recordingProvider{})
+	if err := logger.SetLevel(LevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range []Level{Level(-1), Level(7)} {
+		if err := logger.SetLevel(level); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("SetLevel(%d) = %v, want ErrInvalidLevel", int(level), err)
+		}
+	}
+	if got := logger.GetLevel(); got != LevelWarn {
+		t.Errorf("level after rejected SetLevel = %s, want warning", got)
+	}
+}
+
+func TestUnknownLevelsThroughProviders(t *testing.T) {
+	ctx := context.Background()
+
+	// Уровень вне диапазона в конфигурации приводится к ближайшей границе
+	var text bytes.Buffer
+	fmtProvider := NewFmtProviderWithWriter(ProviderConfig{Level: Level(42)}, &text)
+	if fmtProvider.ShouldLog(ctx, LevelError) || !fmtProvider.ShouldLog(ctx, LevelFatal) {
+		t.Error("fmt provider level 42 was not clamped to fatal")
+	}
+
+	var out bytes.Buffer
+	jsonProvider := NewJSONProvider(ProviderConfig{Level: Level(-5)}, &out)
+	if !jsonProvider.ShouldLog(ctx, LevelDebug) {
+		t.Error("json provider level -5 was not clamped to debug")
+	}
+
+	// Записи с неизвестным уровнем выводятся как level(N), а не пустой строкой
+	if err := fmtProvider.Write(ctx, Level(9), "bogus", nil); err != nil {
+		t.Fatalf("fmt Write: %v", err)
+	}
+	if got := text.String(); !strings.Contains(got, "level(9)") {
+		t.Errorf("fmt output = %q, want level(9)", got)
+	}
+	if err := jsonProvider.Write(ctx, Level(7), "bogus", Fields{"k": "v"}); err != nil {
+		t.Fatalf("json Write: %v", err)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("json output %q: %v", out.String(), err)
+	}
+	if line["level"] != "level(7)" {
+		t.Errorf("json level = %v, want level(7)", line["level"])
+	}
+}
@@ -0,0 +1,242 @@
+package sglogger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Служебные поля цепочки хешей (см. SegmentConfig.HashChain).
+const (
+	// ChainSeqField - порядковый номер записи в цепочке, начиная с 1.
+	ChainSeqField = "seq"
+
+	// ChainField - SHA-256 от предыдущего звена и содержимого строки в hex.
+	ChainField = "chain"
+
+	// ChainPrevField - звено цепочки перед первой записью сегмента в hex.
+	// Позволяет проверить сегмент отдельно от предыдущих.
+	ChainPrevField = "chain_prev"
+)
+
+const (
+	// chainHeadFile - файл с последним звеном цепочки в каталоге сегментов.
+	chainHeadFile = "chain.head"
+
+	// chainSuffixSize - длина `,"chain":"<64 hex>"}` в конце строки.
+	chainSuffixSize = len(`,"chain":"`) + sha256.Size*2 + len(`"}`)
+
+	// chainRecordOverhead - наибольшее увеличение строки за счет полей seq и chain.
+	chainRecordOverhead = len(`,"seq":`) + 20 + chainSuffixSize - 1
+)
+
+// logChain ведет цепочку хешей строк сегментов: каждая строка получает поля
+// seq и chain = SHA-256(предыдущее звено || строка без поля chain). Последнее
+// звено сохраняется в файле chain.head, чтобы цепочка продолжалась после перезапуска.
+type logChain struct {
+	seq     uint64
+	head    []byte
+	sidecar *os.File
+	record  []byte
+}
+
+// openLogChain восстанавливает цепочку по файлу chain.head в каталоге dir
+// и по последней строке сегмента last (если он задан): предыдущий запуск мог
+// записать строку, но не успеть обновить chain.head.
+func openLogChain(dir, last string) (*logChain, error) {
+	sidecar, err := os.OpenFile(filepath.Join(dir, chainHeadFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("sglogger: open chain head: %w", err)
+	}
+	c := &logChain{sidecar: sidecar}
+
+	data, err := io.ReadAll(sidecar)
+	if err != nil {
+		sidecar.Close()
+		return nil, fmt.Errorf("sglogger: read chain head: %w", err)
+	}
+	if fields := bytes.Fields(data); len(fields) == 2 {
+		seq, seqErr := strconv.ParseUint(string(fields[0]), 10, 64)
+		head, headErr := hex.DecodeString(string(fields[1]))
+		if seqErr != nil || headErr != nil || len(head) != sha256.Size {
+			sidecar.Close()
+			return nil, fmt.Errorf("sglogger: chain head %s is corrupted", sidecar.Name())
+		}
+		c.seq, c.head = seq, head
+	}
+
+	if last != "" {
+		line, err := lastLine(last)
+		if err != nil {
+			sidecar.Close()
+			return nil, err
+		}
+		if seq, head, ok := parseChainLink(line); ok && seq > c.seq {
+			c.seq, c.head = seq, head
+		}
+	}
+	return c, nil
+}
+
+// seal добавляет к строке формата JSON поля цепочки и возвращает новую строку.
+// Строка line изменяется на месте. При first к строке добавляется ChainPrevField.
+func (c *logChain) seal(line []byte, first bool) []byte {
+	line = bytes.TrimSuffix(line, []byte("}\n"))
+	if first && c.head != nil {
+		line = append(line, `,"`+ChainPrevField+`":"`...)
+		line = hex.AppendEncode(line, c.head)
+		line = append(line, '"')
+	}
+	line = append(line, `,"`+ChainSeqField+`":`...)
+	line = strconv.AppendUint(line, c.seq+1, 10)
+	line = append(line, '}')
+
+	h := sha256.New()
+	h.Write(c.head)
+	h.Write(line)
+	sum := h.Sum(nil)
+
+	line = append(line[:len(line)-1], `,"`+ChainField+`":"`...)
+	line = hex.AppendEncode(line, sum)
+	line = append(line, "\"}\n"...)
+
+	c.seq++
+	c.head = sum
+	return line
+}
+
+// persist записывает последнее звено в chain.head.
+func (c *logChain) persist() error {
+	c.record = strconv.AppendUint(c.record[:0], c.seq, 10)
+	c.record = append(c.record, ' ')
+	c.record = hex.AppendEncode(c.record, c.head)
+	c.record = append(c.record, '\n')
+	if _, err := c.sidecar.WriteAt(c.record, 0); err != nil {
+		return fmt.Errorf("sglogger: write chain head: %w", err)
+	}
+	if err := c.sidecar.Truncate(int64(len(c.record))); err != nil {
+		return fmt.Errorf("sglogger: write chain head: %w", err)
+	}
+	return nil
+}
+
+// close закрывает файл chain.head.
+func (c *logChain) close() error {
+	if err := c.sidecar.Close(); err != nil {
+		return fmt.Errorf("sglogger: close chain head: %w", err)
+	}
+	return nil
+}
+
+// VerifyLogChain проверяет цепочку хешей сегмента, записанного с
+// SegmentConfig.HashChain. Возвращает номер первой строки (начиная с 1),
+// которая была изменена, удалена или вставлена, и ошибку ErrLogChainBroken;
+// для целой цепочки возвращает 0 и nil. Ошибки чтения файла возвращаются с brokenAt 0.
+//
+// Первая строка проверяется относительно своего ChainPrevField, поэтому удаление
+// начала файла обнаруживается только сравнением ChainPrevField с полем chain
+// последней строки предыдущего сегмента.
+func VerifyLogChain(path string) (brokenAt int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("sglogger: open log chain: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var head []byte
+	var seq uint64
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return 0, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("sglogger: read log chain: %w", err)
+		}
+
+		var ok bool
+		seq, head, ok = verifyChainLink(line, head, seq, n == 1)
+		if !ok {
+			return n, fmt.Errorf("%w at line %d", ErrLogChainBroken, n)
+		}
+	}
+}
+
+// verifyChainLink проверяет строку цепочки после звена head с номером seq
+// и возвращает номер и звено строки. Для первой строки файла предыдущее звено
+// берется из ChainPrevField, а номер не проверяется.
+func verifyChainLink(line, head []byte, seq uint64, first bool) (uint64, []byte, bool) {
+	if len(line) < chainSuffixSize+1 || line[len(line)-1] != '\n' {
+		return 0, nil, false
+	}
+	line = line[:len(line)-1]
+	suffix := line[len(line)-chainSuffixSize:]
+	if !bytes.HasPrefix(suffix, []byte(`,"`+ChainField+`":"`)) || !bytes.HasSuffix(suffix, []byte(`"}`)) {
+		return 0, nil, false
+	}
+	want, err := hex.DecodeString(string(suffix[len(`,"chain":"`) : len(suffix)-2]))
+	if err != nil {
+		return 0, nil, false
+	}
+
+	content := append(line[:len(line)-chainSuffixSize:len(line)-chainSuffixSize], '}')
+	var record struct {
+		Seq  *uint64 `json:"seq"`
+		Prev *string `json:"chain_prev"`
+	}
+	if json.Unmarshal(content, &record) != nil || record.Seq == nil {
+		return 0, nil, false
+	}
+	if record.Prev != nil {
+		prev, err := hex.DecodeString(*record.Prev)
+		if err != nil || (!first && !bytes.Equal(prev, head)) {
+			return 0, nil, false
+		}
+		head = prev
+	}
+	if !first && *record.Seq != seq+1 {
+		return 0, nil, false
+	}
+
+	h := sha256.New()
+	h.Write(head)
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), want) {
+		return 0, nil, false
+	}
+	return *record.Seq, want, true
+}
+
+// parseChainLink извлекает seq и chain из строки цепочки.
+func parseChainLink(line []byte) (uint64, []byte, bool) {
+	var record struct {
+		Seq   uint64 `json:"seq"`
+		Chain string `json:"chain"`
+	}
+	if json.Unmarshal(line, &record) != nil || record.Seq == 0 {
+		return 0, nil, false
+	}
+	head, err := hex.DecodeString(record.Chain)
+	if err != nil || len(head) != sha256.Size {
+		return 0, nil, false
+	}
+	return record.Seq, head, true
+}
+
+// lastLine возвращает последнюю строку файла.
+func lastLine(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("sglogger: read segment: %w", err)
+	}
+	data = bytes.TrimSuffix(data, []byte{'\n'})
+	return data[bytes.LastIndexByte(data, '\n')+1:], nil
+}
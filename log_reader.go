@@ -0,0 +1,368 @@
+package sglogger
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseErrorField - поле, в которое MergeReaders записывает ошибку разбора
+// строки, не соответствующей ни одному из форматов пакета.
+const ParseErrorField = "parse_error"
+
+// textTimeLayout - формат времени текстового формата (см. NewTextFormatter).
+const textTimeLayout = "2006-01-02 15:04:05"
+
+// ParseTextLine разбирает строку текстового формата (см. NewTextFormatter):
+// `[2006-01-02 15:04:05] <level> "<message>" {key1=value1 key2=value2}`.
+// Время интерпретируется в локальном часовом поясе. Уровень распознается
+// ParseLevel без учета регистра и выравнивания; колонка имени (AlignConfig.NameWidth)
+// возвращается в поле "component". Строковые значения полей раскавычиваются,
+// остальные распознаются как целые числа, числа с плавающей точкой и bool,
+// а если это не удается, сохраняются строкой. Текстовый формат не экранирует
+// сообщение, поэтому сообщение, содержащее `" {`, может быть разобрано неоднозначно.
+func ParseTextLine(line string) (Entry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "[") || len(line) < len(textTimeLayout)+2 || line[len(textTimeLayout)+1] != ']' {
+		return Entry{}, fmt.Errorf("sglogger: text line has no timestamp")
+	}
+	t, err := time.ParseInLocation(textTimeLayout, line[1:len(textTimeLayout)+1], time.Local)
+	if err != nil {
+		return Entry{}, fmt.Errorf("sglogger: parse text timestamp: %w", err)
+	}
+	rest := strings.TrimPrefix(line[len(textTimeLayout)+2:], " ")
+
+	open := strings.Index(rest, " \"")
+	if open < 0 {
+		return Entry{}, fmt.Errorf("sglogger: text line has no message")
+	}
+	head := strings.Fields(rest[:open])
+	if len(head) == 0 {
+		return Entry{}, fmt.Errorf("sglogger: text line has no level")
+	}
+	level, err := ParseLevel(head[0])
+	if err != nil {
+		return Entry{}, err
+	}
+
+	body := rest[open+2:]
+	for i := 0; i < len(body); i++ {
+		if body[i] != '"' {
+			continue
+		}
+		fields, ok := parseTextFields(body[i+1:])
+		if !ok {
+			continue
+		}
+		if len(head) > 1 {
+			if fields == nil {
+				fields = make(Fields, 1)
+			}
+			if _, exists := fields[defaultNameField]; !exists {
+				fields[defaultNameField] = strings.Join(head[1:], " ")
+			}
+		}
+		return Entry{Time: t, Level: level, Message: body[:i], Fields: fields}, nil
+	}
+	return Entry{}, fmt.Errorf("sglogger: text line has unterminated message")
+}
+
+// parseTextFields разбирает остаток строки после сообщения: пробелы и,
+// возможно, набор полей "{key=value ...}". Возвращает false, если остаток
+// не соответствует формату.
+func parseTextFields(s string) (Fields, bool) {
+	s = strings.TrimLeft(s, " ")
+	if s == "" {
+		return nil, true
+	}
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, false
+	}
+	s = s[1 : len(s)-1]
+
+	fields := make(Fields)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || strings.IndexByte(s[:eq], ' ') >= 0 {
+			return nil, false
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		if strings.HasPrefix(s, "\"") {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, false
+			}
+			value, _ := strconv.Unquote(quoted)
+			fields[key] = value
+			s = s[len(quoted):]
+			if s != "" && !strings.HasPrefix(s, " ") {
+				return nil, false
+			}
+			s = strings.TrimPrefix(s, " ")
+			continue
+		}
+
+		// Значение без кавычек (формат %v) может содержать пробелы:
+		// оно продолжается до следующего " key=".
+		end := len(s)
+		for i := 0; i < len(s); i++ {
+			if s[i] == ' ' && startsTextField(s[i+1:]) {
+				end = i
+				break
+			}
+		}
+		fields[key] = parseTextValue(s[:end])
+		s = strings.TrimPrefix(s[end:], " ")
+	}
+	return fields, true
+}
+
+// startsTextField сообщает, начинается ли s с "key=".
+func startsTextField(s string) bool {
+	eq := strings.IndexByte(s, '=')
+	return eq > 0 && strings.IndexAny(s[:eq], " \"{}[]()") < 0
+}
+
+// parseTextValue распознает значение без кавычек.
+func parseTextValue(s string) interface{} {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
+// ParseJSONLine разбирает строку формата NDJSON (см. NewJSONFormatter).
+// Поля с префиксом "fields." перед зарезервированными ключами получают исходные
+// имена. Целые числа возвращаются как int64, остальные числа - как float64.
+// Уровень может быть задан как именем, так и числом (формат дискового спула).
+func ParseJSONLine(line []byte) (Entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return Entry{}, fmt.Errorf("sglogger: parse JSON line: %w", err)
+	}
+
+	var e Entry
+	ts, _ := raw["ts"].(string)
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return Entry{}, fmt.Errorf("sglogger: parse JSON timestamp: %w", err)
+	}
+	e.Time = t
+
+	switch level := raw["level"].(type) {
+	case string:
+		if e.Level, err = ParseLevel(level); err != nil {
+			return Entry{}, err
+		}
+	case json.Number:
+		n, err := level.Int64()
+		if err != nil || !Level(n).IsValid() {
+			return Entry{}, fmt.Errorf("%w: %s", ErrInvalidLevel, level)
+		}
+		e.Level = Level(n)
+	default:
+		return Entry{}, fmt.Errorf("sglogger: JSON line has no level")
+	}
+
+	msg, ok := raw["msg"].(string)
+	if !ok {
+		return Entry{}, fmt.Errorf("sglogger: JSON line has no message")
+	}
+	e.Message = msg
+
+	for k, v := range raw {
+		switch k {
+		case "ts", "level", "msg":
+			continue
+		}
+		if e.Fields == nil {
+			e.Fields = make(Fields, len(raw)-3)
+		}
+		if name := strings.TrimPrefix(k, jsonReservedPrefix); name != k {
+			switch name {
+			case "ts", "level", "msg", FieldEncodeErrorField:
+				k = name
+			}
+		}
+		e.Fields[k] = jsonNumbers(v)
+	}
+	return e, nil
+}
+
+// jsonNumbers заменяет json.Number на int64 или float64, в том числе
+// во вложенных объектах и массивах.
+func jsonNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = jsonNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = jsonNumbers(item)
+		}
+	}
+	return v
+}
+
+// parseLine разбирает строку в формате JSON или текстовом формате.
+func parseLine(line []byte) (Entry, error) {
+	if trimmed := bytes.TrimLeft(line, " \t"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return ParseJSONLine(trimmed)
+	}
+	return ParseTextLine(string(line))
+}
+
+// EntryIterator последовательно возвращает записи, объединенные MergeReaders.
+//
+// Пример:
+//
+//	it := sglogger.MergeReaders(f1, f2)
+//	for it.Next() {
+//		e := it.Entry()
+//		...
+//	}
+//	if err := it.Err(); err != nil { ... }
+type EntryIterator struct {
+	sources mergeHeap
+	current Entry
+	errs    []error
+	started bool
+}
+
+// MergeReaders объединяет записи из нескольких источников в порядке времени
+// (k-way merge). Каждый источник должен быть упорядочен по времени, как файлы,
+// созданные провайдерами пакета. Формат определяется для каждой строки:
+// строки, начинающиеся с '{', разбираются ParseJSONLine, остальные - ParseTextLine.
+// Записи с одинаковым временем возвращаются в порядке источников.
+//
+// Строки, которые не удалось разобрать, не прерывают чтение: они возвращаются
+// как записи уровня Info с исходной строкой в сообщении и ошибкой в поле
+// ParseErrorField. Время такой записи берется из предыдущей записи того же
+// источника, чтобы она осталась на своем месте. Пустые строки пропускаются.
+// Ошибка чтения источника прекращает чтение только из него и возвращается Err.
+func MergeReaders(readers ...io.Reader) *EntryIterator {
+	it := &EntryIterator{}
+	for i, r := range readers {
+		it.sources = append(it.sources, &mergeSource{index: i, reader: bufio.NewReader(r)})
+	}
+	return it
+}
+
+// Next переходит к следующей записи. Возвращает false, когда записи закончились.
+func (it *EntryIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		sources := it.sources[:0]
+		for _, s := range it.sources {
+			if it.advance(s) {
+				sources = append(sources, s)
+			}
+		}
+		it.sources = sources
+		heap.Init(&it.sources)
+	}
+
+	if len(it.sources) == 0 {
+		return false
+	}
+	s := it.sources[0]
+	it.current = s.entry
+	if it.advance(s) {
+		heap.Fix(&it.sources, 0)
+	} else {
+		heap.Pop(&it.sources)
+	}
+	return true
+}
+
+// Entry возвращает текущую запись.
+func (it *EntryIterator) Entry() Entry {
+	return it.current
+}
+
+// Err возвращает ошибки чтения источников, объединенные через errors.Join.
+func (it *EntryIterator) Err() error {
+	return errors.Join(it.errs...)
+}
+
+// advance читает следующую запись источника. Возвращает false, если источник исчерпан.
+func (it *EntryIterator) advance(s *mergeSource) bool {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) > 0 {
+			e, parseErr := parseLine(line)
+			if parseErr != nil {
+				e = Entry{
+					Time:    s.entry.Time,
+					Level:   LevelInfo,
+					Message: string(line),
+					Fields:  Fields{ParseErrorField: parseErr.Error()},
+				}
+			}
+			s.entry = e
+			return true
+		}
+		if err != nil {
+			if err != io.EOF {
+				it.errs = append(it.errs, fmt.Errorf("sglogger: read source %d: %w", s.index, err))
+			}
+			return false
+		}
+	}
+}
+
+// mergeSource - источник записей MergeReaders с текущей записью.
+type mergeSource struct {
+	index  int
+	reader *bufio.Reader
+	entry  Entry
+}
+
+// mergeHeap упорядочивает источники по времени текущей записи и номеру источника.
+type mergeHeap []*mergeSource
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if !h[i].entry.Time.Equal(h[j].entry.Time) {
+		return h[i].entry.Time.Before(h[j].entry.Time)
+	}
+	return h[i].index < h[j].index
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(*mergeSource)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	s := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return s
+}
@@ -2,17 +2,36 @@ package sglogger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // logger является основной структурой для логирования, управляющей несколькими провайдерами.
 // Обеспечивает потокобезопасное логирование через multiple providers.
 type logger struct {
-	providers     []LoggerProvider
+	providers     []registeredProvider
 	config        LoggerConfig
 	fieldsHandler FieldsHandler
+	sampler       *sampler
+	escalation    *traceEscalation
+	sanitizer     *sanitizer
+	staticFields  Fields
+	fallback      *stderrFallback
+	fieldWarner   *fieldWarner
+	validator     *fieldValidator
+	enrichers     []Enricher
+	diagnostics   *diagnostics
+	leakCheck     *leakCheck
+	level         int32
+	silenced      int32
+	emptyWarning  int32
+	stats         loggerStats
 	mu            sync.RWMutex
 }
 
@@ -20,151 +39,510 @@ type logger struct {
 // Использует fmtProvider как единственный провайдер вывода.
 // Удобен для быстрого старта и разработки.
 func NewLoggerDefault(config ProviderConfig, fieldsHandler FieldsHandler) Logger {
-	return &logger{
-		providers: []LoggerProvider{
-			NewFmtProvider(config),
-		},
-		config:        config.LoggerConfig,
-		fieldsHandler: fieldsHandler,
-	}
+	return newLogger(config.LoggerConfig, fieldsHandler, []LoggerProvider{
+		NewFmtProvider(config),
+	})
 }
 
 // NewLogger создает кастомный логгер с указанными провайдерами.
 // Позволяет гибко настраивать вывод логов через multiple providers.
 // Пример: файловый провайдер + провайдер для Sentry + stdout провайдер.
+// Поведение без провайдеров задает config.EmptyProviders; по умолчанию записи
+// отбрасываются, NewLoggerWithOptions в этом случае возвращает ошибку.
 func NewLogger(config LoggerConfig, fieldsHandler FieldsHandler, providers ...LoggerProvider) Logger {
-	return &logger{
-		providers:     providers,
+	return newLogger(config, fieldsHandler, providers)
+}
+
+// NewLoggerWithOptions создает логгер, как NewLogger, но проверяет конфигурацию:
+// без провайдеров возвращает ErrNoProviders, если config.EmptyProviders
+// не разрешает этого явно (см. EmptyProvidersPolicy), для незарегистрированного
+// имени в config.Enrichers возвращает ErrUnknownEnricher, для некорректной
+// конфигурации - ошибку LoggerConfig.Validate.
+func NewLoggerWithOptions(config LoggerConfig, fieldsHandler FieldsHandler, providers ...LoggerProvider) (Logger, error) {
+	if len(providers) == 0 {
+		switch config.EmptyProviders {
+		case EmptyProvidersDefault, EmptyProvidersError:
+			return nil, ErrNoProviders
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := resolveEnrichers(config.Enrichers); err != nil {
+		return nil, err
+	}
+	return newLogger(config, fieldsHandler, providers), nil
+}
+
+// newLogger создает логгер и инициализирует компоненты, зависящие от конфигурации.
+func newLogger(config LoggerConfig, fieldsHandler FieldsHandler, providers []LoggerProvider) *logger {
+	config = config.clone()
+	var emptyWarning int32
+	if len(providers) == 0 {
+		switch config.EmptyProviders {
+		case EmptyProvidersStderr:
+			providers = []LoggerProvider{newFmtProvider(ProviderConfig{LoggerConfig: config, Name: "stderr"}, os.Stderr)}
+			emptyWarning = 1
+		case EmptyProvidersError:
+			newDiagnostics(config.Diagnostics).reportf("logger", "%v; entries are discarded", ErrNoProviders)
+		}
+	}
+
+	l := &logger{
+		providers:     registerProviders(nil, providers...),
 		config:        config,
 		fieldsHandler: fieldsHandler,
+		sampler:       newSampler(config.Sampling),
+		escalation:    newTraceEscalation(config.TraceEscalation),
+		sanitizer:     newSanitizer(config),
+		staticFields:  runtimeFields(config.EnrichRuntime),
+		fallback:      newStderrFallback(config.StderrFallback),
+		diagnostics:   newDiagnostics(config.Diagnostics),
+		emptyWarning:  emptyWarning,
+	}
+	if config.StrictFields {
+		l.fieldWarner = &fieldWarner{diagnostics: l.diagnostics}
+	}
+	l.validator = newFieldValidator(config.FieldValidation, l.diagnostics)
+	if err := config.Validate(); err != nil {
+		l.diagnostics.reportf("logger", "%v", err)
+	}
+	var err error
+	if l.enrichers, err = resolveEnrichers(config.Enrichers); err != nil {
+		l.diagnostics.reportf("logger", "%v; the enricher is skipped", err)
+	}
+	l.leakCheck = newLeakCheck(config.LeakCheck, l, "logger", l.diagnostics)
+	return l
+}
+
+// SetLevel устанавливает минимальный уровень сообщений логгера во время работы.
+// Сообщения ниже этого уровня отбрасываются до обращения к провайдерам,
+// уровни провайдеров при этом продолжают действовать.
+// Возвращает ErrInvalidLevel для значений вне диапазона LevelDebug..LevelFatal.
+func (l *logger) SetLevel(level Level) error {
+	if !level.IsValid() {
+		return fmt.Errorf("%w: %s", ErrInvalidLevel, level)
+	}
+	atomic.StoreInt32(&l.level, int32(level))
+	return nil
+}
+
+// GetLevel возвращает текущий минимальный уровень сообщений логгера.
+func (l *logger) GetLevel() Level {
+	return Level(atomic.LoadInt32(&l.level))
+}
+
+// Silence временно отключает сообщения с уровнем ниже below для всех вызовов,
+// кроме вызовов с контекстом, переопределяющим уровень (ContextWithMinLevel).
+// Возвращает функцию, восстанавливающую предыдущий порог.
+func (l *logger) Silence(below Level) (restore func()) {
+	previous := atomic.SwapInt32(&l.silenced, int32(below))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.StoreInt32(&l.silenced, previous)
+		})
+	}
+}
+
+// minLevel возвращает действующий минимальный уровень для вызова с указанным контекстом.
+func (l *logger) minLevel(ctx context.Context) Level {
+	if level, ok := MinLevelFromContext(ctx); ok {
+		return level
+	}
+
+	level := l.GetLevel()
+	if silenced := Level(atomic.LoadInt32(&l.silenced)); silenced > level {
+		level = silenced
 	}
+	return level
+}
+
+// Flush сбрасывает буферы всех провайдеров, реализующих Flusher.
+// Сбрасываются все провайдеры, даже если некоторые из них завершились ошибкой;
+// ошибки объединяются через errors.Join и содержат имя провайдера.
+func (l *logger) Flush(ctx context.Context) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var errs []error
+	for i := range l.providers {
+		rp := &l.providers[i]
+		if err := l.flushProvider(ctx, rp); err != nil {
+			errs = append(errs, fmt.Errorf("sglogger: flush provider %q: %w", rp.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close закрывает все провайдеры логгера одновременно, каждый с контекстом ctx,
+// поэтому медленный провайдер не расходует время остальных. Close возвращается
+// не позже отмены ctx, даже если провайдер ее не учитывает. Если хотя бы один
+// провайдер не закрылся, возвращается *MultiCloseError со списками провайдеров,
+// завершившихся ошибкой и не уложившихся в ctx; они же с длительностью
+// закрытия сообщаются через диагностический канал.
+func (l *logger) Close(ctx context.Context) error {
+	l.leakCheck.markClosed()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	providers := make([]*registeredProvider, len(l.providers))
+	for i := range l.providers {
+		providers[i] = &l.providers[i]
+	}
+	return l.closeProviders(ctx, providers)
+}
+
+// Stats возвращает текущие счетчики логгера и его провайдеров.
+func (l *logger) Stats() LoggerStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := l.stats.snapshot()
+	stats.Providers = make(map[string]ProviderStats, len(l.providers))
+	for _, rp := range l.providers {
+		ps := rp.stats.snapshot()
+		ps.Inactive = !providerActive(rp.provider)
+		if filterer, ok := rp.provider.(Filterer); ok {
+			ps.Filtered = filterer.Filtered()
+		}
+		if recorder, ok := rp.provider.(OrderRecorder); ok {
+			order := recorder.OrderStats()
+			ps.Order = &order
+		}
+		if recorder, ok := rp.provider.(SizeRecorder); ok {
+			sizes := recorder.EntrySizes()
+			ps.Sizes = &sizes
+		}
+		if recorder, ok := rp.provider.(DropRecorder); ok {
+			ps.Dropped = recorder.DroppedEntries()
+		}
+		stats.Providers[rp.name] = ps
+	}
+	return stats
+}
+
+// HealthCheck проверяет состояние провайдеров, реализующих HealthChecker.
+// Возвращает результаты по именам провайдеров; для остальных и неактивных (см. Gated) провайдеров значение равно nil.
+func (l *logger) HealthCheck(ctx context.Context) map[string]error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make(map[string]error, len(l.providers))
+	for _, rp := range l.providers {
+		var err error
+		if checker, ok := rp.provider.(HealthChecker); ok && providerActive(rp.provider) {
+			err = checker.HealthCheck(ctx)
+		}
+		result[rp.name] = err
+	}
+	return result
+}
+
+// AddProvider регистрирует дополнительный провайдер и возвращает присвоенное ему имя.
+// При совпадении имени с уже зарегистрированным провайдером добавляется числовой суффикс.
+func (l *logger) AddProvider(provider LoggerProvider) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.providers = registerProviders(l.providers, provider)
+	return l.providers[len(l.providers)-1].name
+}
+
+// RemoveProviderByName исключает провайдер из логгера и закрывает его.
+// Возвращает ErrProviderNotFound, если провайдер с таким именем не зарегистрирован.
+func (l *logger) RemoveProviderByName(ctx context.Context, name string) error {
+	l.mu.Lock()
+	var removed *registeredProvider
+	providers := make([]registeredProvider, 0, len(l.providers))
+	for i, rp := range l.providers {
+		if rp.name == name && removed == nil {
+			removed = &l.providers[i]
+			continue
+		}
+		providers = append(providers, rp)
+	}
+	l.providers = providers
+	l.mu.Unlock()
+
+	if removed == nil {
+		return ErrProviderNotFound
+	}
+	return l.closeProvider(ctx, removed)
 }
 
 func (l *logger) Debug(ctx context.Context, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelDebug, message, nil)
+    l.writeLog(ctx, LevelDebug, nil, nil, format, args)
 }
 
 func (l *logger) Info(ctx context.Context, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelInfo, message, nil)
+    l.writeLog(ctx, LevelInfo, nil, nil, format, args)
 }
 
 func (l *logger) Warning(ctx context.Context, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelWarn, message, nil)
+    l.writeLog(ctx, LevelWarn, nil, nil, format, args)
 }
 
 func (l *logger) Error(ctx context.Context, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelError, message, nil)
+    l.writeLog(ctx, LevelError, nil, nil, format, args)
 }
 
 func (l *logger) Fatal(ctx context.Context, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelFatal, message, nil)
-    log.Fatal(message)
+    l.writeLog(ctx, LevelFatal, nil, nil, format, args)
+    l.flushForExit(ctx)
+    log.Fatal(fmt.Sprintf(format, args...))
 }
 
 func (l *logger) DebugErr(ctx context.Context, err error, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    fields := Fields{"error": err.Error()}
-    l.writeLog(ctx, LevelDebug, message, fields)
+    l.writeLog(ctx, LevelDebug, err, nil, format, args)
 }
 
 func (l *logger) InfoErr(ctx context.Context, err error, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    fields := Fields{"error": err.Error()}
-    l.writeLog(ctx, LevelInfo, message, fields)
+    l.writeLog(ctx, LevelInfo, err, nil, format, args)
 }
 
 func (l *logger) WarningErr(ctx context.Context, err error, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    fields := Fields{"error": err.Error()}
-    l.writeLog(ctx, LevelWarn, message, fields)
+    l.writeLog(ctx, LevelWarn, err, nil, format, args)
 }
 
 func (l *logger) ErrorErr(ctx context.Context, err error, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    fields := Fields{"error": err.Error()}
-    l.writeLog(ctx, LevelError, message, fields)
+    l.writeLog(ctx, LevelError, err, nil, format, args)
 }
 
 func (l *logger) FatalErr(ctx context.Context, err error, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    fields := Fields{"error": err.Error()}
-    l.writeLog(ctx, LevelFatal, message, fields)
-    log.Fatalf("%s: %v", message, err)
+    l.writeLog(ctx, LevelFatal, err, nil, format, args)
+    l.flushForExit(ctx)
+    log.Fatalf("%s: %v", fmt.Sprintf(format, args...), err)
 }
 
 func (l *logger) DebugWithFields(ctx context.Context, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelDebug, message, fields)
+    l.writeLog(ctx, LevelDebug, nil, fields, format, args)
 }
 
 func (l *logger) InfoWithFields(ctx context.Context, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelInfo, message, fields)
+    l.writeLog(ctx, LevelInfo, nil, fields, format, args)
 }
 
 func (l *logger) WarningWithFields(ctx context.Context, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelWarn, message, fields)
+    l.writeLog(ctx, LevelWarn, nil, fields, format, args)
 }
 
 func (l *logger) ErrorWithFields(ctx context.Context, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelError, message, fields)
+    l.writeLog(ctx, LevelError, nil, fields, format, args)
 }
 
 func (l *logger) FatalWithFields(ctx context.Context, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    l.writeLog(ctx, LevelFatal, message, fields)
-    log.Fatal(message)
+    l.writeLog(ctx, LevelFatal, nil, fields, format, args)
+    l.flushForExit(ctx)
+    log.Fatal(fmt.Sprintf(format, args...))
 }
 
 func (l *logger) DebugErrWithFields(ctx context.Context, err error, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    allFields := l.mergeFields(fields, Fields{"error": err.Error()})
-    l.writeLog(ctx, LevelDebug, message, allFields)
+    l.writeLog(ctx, LevelDebug, err, fields, format, args)
 }
 
 func (l *logger) InfoErrWithFields(ctx context.Context, err error, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    allFields := l.mergeFields(fields, Fields{"error": err.Error()})
-    l.writeLog(ctx, LevelInfo, message, allFields)
+    l.writeLog(ctx, LevelInfo, err, fields, format, args)
 }
 
 func (l *logger) WarningErrWithFields(ctx context.Context, err error, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    allFields := l.mergeFields(fields, Fields{"error": err.Error()})
-    l.writeLog(ctx, LevelWarn, message, allFields)
+    l.writeLog(ctx, LevelWarn, err, fields, format, args)
 }
 
 func (l *logger) ErrorErrWithFields(ctx context.Context, err error, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    allFields := l.mergeFields(fields, Fields{"error": err.Error()})
-    l.writeLog(ctx, LevelError, message, allFields)
+    l.writeLog(ctx, LevelError, err, fields, format, args)
 }
 
 func (l *logger) FatalErrWithFields(ctx context.Context, err error, fields Fields, format string, args ...interface{}) {
-    message := fmt.Sprintf(format, args...)
-    allFields := l.mergeFields(fields, Fields{"error": err.Error()})
-    l.writeLog(ctx, LevelFatal, message, allFields)
-    log.Fatalf("%s: %v", message, err)
+    l.writeLog(ctx, LevelFatal, err, fields, format, args)
+    l.flushForExit(ctx)
+    log.Fatalf("%s: %v", fmt.Sprintf(format, args...), err)
 }
 
-func (l *logger) writeLog(ctx context.Context, level Level, message string, fields Fields) {
+// writeLog формирует сообщение и передает его провайдерам.
+// Проверки уровня и семплирования выполняются до форматирования сообщения
+// и создания полей, поэтому вызовы с отключенным уровнем не выделяют память.
+func (l *logger) writeLog(ctx context.Context, level Level, err error, fields Fields, format string, args []interface{}) {
+    ctx = l.escalation.context(ctx)
+    if level < l.minLevel(ctx) {
+        return
+    }
+    l.escalation.observe(ctx, level)
+
     l.mu.RLock()
     defer l.mu.RUnlock()
 
+    if atomic.LoadInt32(&l.emptyWarning) != 0 && atomic.CompareAndSwapInt32(&l.emptyWarning, 1, 0) {
+        l.diagnostics.reportf("logger", "no providers configured, writing entries to stderr")
+    }
+
+    if !l.enabled(ctx, level) {
+        return
+    }
+
+    sampleRate := 1
+    if l.sampler != nil {
+        var keep bool
+        if keep, sampleRate = l.sampler.allow(level, format); !keep {
+            atomic.AddUint64(&l.stats.sampled, 1)
+            return
+        }
+    }
+
+    ctx = l.assignSequences(ctx, level)
+
+    var start time.Time
+    if l.config.SlowWriteBudget > 0 {
+        start = time.Now()
+    }
+
+    message := format
+    formatErr := false
+    if len(args) > 0 || strings.IndexByte(format, '%') >= 0 {
+        message = fmt.Sprintf(format, args...)
+        if l.config.StrictFormat && hasFormatError(message) {
+            formatErr = true
+            l.diagnostics.reportf("strict format", "format error in %q at %s: %s", format, formatCaller(), message)
+            fields = l.mergeFields(fields, Fields{FormatErrorField: true})
+        }
+    }
+
+    if err != nil {
+        if l.config.ContextDiagnostics {
+            if diag := contextDiagnosticFields(ctx, err); diag != nil {
+                fields = l.mergeFields(diag, fields)
+            }
+        }
+        fields = l.mergeFields(fields, Fields{"error": err.Error()})
+    }
+
     allFields := l.extractFieldsFromContext(ctx, fields)
+    allFields = resolveVerboseFields(level, allFields)
+
+    if len(l.staticFields) > 0 {
+        allFields = l.mergeFields(l.staticFields, allFields)
+    }
+
+    allFields = l.enrich(ctx, level, message, allFields)
+
+    allFields = l.config.FieldRules.apply(level, allFields)
+    allFields = payloadFields(allFields, l.config.ConvertValue, l.config.MaxPayloadSize)
+
+    if l.config.GoroutineInfo {
+        allFields = l.mergeFields(goroutineFields(ctx), allFields)
+    }
+
+    // Хуки переписывают сообщение до вычисления ключа дедупликации и проверки
+    // UTF-8; отпечаток и ключ выборки берутся из шаблона format и от хуков
+    // не зависят.
+    if len(l.config.Hooks) > 0 {
+        message, allFields = l.applyHooks(ctx, level, message, allFields)
+    }
+
+    allFields = l.config.Redaction.apply(allFields)
+
+    if l.config.Fingerprint {
+        if _, ok := allFields[FingerprintField]; !ok {
+            allFields = l.mergeFields(allFields, Fields{FingerprintField: Fingerprint(format)})
+        }
+    }
+
+    if sampleRate > 1 {
+        allFields = l.mergeFields(allFields, Fields{SampleRateField: sampleRate})
+    }
+
+    if l.config.DedupKey != nil {
+        if _, ok := allFields[DedupKeyField]; !ok {
+            key := DedupKey(level, message, allFields, l.config.DedupKey.Fields)
+            allFields = l.mergeFields(allFields, Fields{DedupKeyField: key})
+        }
+    }
+
+    if l.sanitizer != nil {
+        var sanitized bool
+        if message, allFields, sanitized = l.sanitizer.entry(message, allFields); sanitized {
+            atomic.AddUint64(&l.stats.sanitized, 1)
+        }
+    }
+
+    if l.fieldWarner != nil {
+        l.fieldWarner.check(allFields)
+    }
+
+    if l.validator != nil {
+        if violations := l.validator.violations(level, allFields); len(violations) > 0 {
+            l.validator.report(violations, formatCaller())
+        }
+    }
+
+    // Для учета медленных записей момент окончания каждой записи служит началом
+    // следующей, поэтому измерение стоит одного вызова time.Now на провайдер;
+    // время первого провайдера включает подготовку сообщения и полей.
+    var slowest *registeredProvider
+    var slowestTime time.Duration
+    mark := start
+
+    attempted, failed := 0, 0
+    for i := range l.providers {
+        rp := &l.providers[i]
+        if !l.providerAccepts(ctx, rp, level) {
+            continue
+        }
+        attempted++
+        writeCtx, cancel := providerContext(ctx, level, rp.provider)
+        guard := guardSharedFields(allFields)
+        err := l.writeProvider(writeCtx, rp, level, message, allFields)
+        cancel()
+        guard.check(rp.name)
+        if !start.IsZero() {
+            now := time.Now()
+            if d := now.Sub(mark); slowest == nil || d > slowestTime {
+                slowest, slowestTime = rp, d
+            }
+            mark = now
+        }
+        if err != nil {
+            failed++
+            atomic.AddUint64(&rp.stats.errors, 1)
+            if l.config.ErrorHandler != nil {
+                l.config.ErrorHandler(rp.name, err)
+            }
+            continue
+        }
+        atomic.AddUint64(&rp.stats.written, 1)
+    }
+
+    if !start.IsZero() {
+        elapsed := mark.Sub(start)
+        if slowest == nil {
+            elapsed = time.Since(start)
+        }
+        l.checkWriteBudget(message, elapsed, slowest, slowestTime)
+    }
+
+    if l.fallback != nil && level >= LevelWarn && attempted > 0 && failed == attempted {
+        if l.fallback.write(level, message, allFields) {
+            atomic.AddUint64(&l.stats.fallback, 1)
+        } else {
+            atomic.AddUint64(&l.stats.fallbackSuppressed, 1)
+        }
+    }
+
+    if formatErr && l.config.PanicOnFormatError {
+        panic(fmt.Sprintf("sglogger: format error in %q: %s", format, message))
+    }
+}
 
-    for _, provider := range l.providers {
-        if provider.ShouldLog(ctx, level) {
-            provider.Write(ctx, level, message, allFields)
+// enabled проверяет, запишет ли сообщение данного уровня хотя бы один провайдер.
+func (l *logger) enabled(ctx context.Context, level Level) bool {
+    for i := range l.providers {
+        if l.providerAccepts(ctx, &l.providers[i], level) {
+            return true
         }
     }
+    return false
 }
 
 func (l *logger) extractFieldsFromContext(ctx context.Context, fields Fields) Fields {
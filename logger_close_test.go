@@ -0,0 +1,77 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newScriptedLogger создает логгер из пяти провайдеров, два из которых
+// завершают Flush и Close ошибкой.
+func newScriptedLogger(errA, errB error) (Logger, []*scriptedProvider) {
+	providers := []*scriptedProvider{
+		{name: "console"},
+		{name: "file", flushErr: errA, closeErr: errA},
+		{name: "loki"},
+		{name: "sentry", flushErr: errB, closeErr: errB},
+		{name: "syslog"},
+	}
+	list := make([]LoggerProvider, len(providers))
+	for i, p := range providers {
+		list[i] = p
+	}
+	config := LoggerConfig{Diagnostics: &DiagnosticsConfig{Disabled: true}}
+	return NewLogger(config, NewFieldsHandler(), list...), providers
+}
+
+func TestLoggerCloseAggregatesErrors(t *testing.T) {
+	errDisk := errors.New("disk full")
+	errNetwork := errors.New("network unreachable")
+	logger, providers := newScriptedLogger(errDisk, errNetwork)
+
+	err := logger.Close(context.Background())
+	if !errors.Is(err, errDisk) || !errors.Is(err, errNetwork) {
+		t.Fatalf("Close = %v, want both provider errors", err)
+	}
+	for _, name := range []string{`"file"`, `"sentry"`} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Close error %q does not name provider %s", err, name)
+		}
+	}
+	for _, p := range providers {
+		if n := atomic.LoadInt32(&p.closes); n != 1 {
+			t.Errorf("provider %s closed %d times, want 1", p.name, n)
+		}
+	}
+}
+
+func TestLoggerFlushAggregatesErrors(t *testing.T) {
+	errDisk := errors.New("disk full")
+	errNetwork := errors.New("network unreachable")
+	logger, providers := newScriptedLogger(errDisk, errNetwork)
+	defer logger.Close(context.Background())
+
+	err := logger.Flush(context.Background())
+	if !errors.Is(err, errDisk) || !errors.Is(err, errNetwork) {
+		t.Fatalf("Flush = %v, want both provider errors", err)
+	}
+	for _, name := range []string{`"file"`, `"sentry"`} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Flush error %q does not name provider %s", err, name)
+		}
+	}
+	for _, p := range providers {
+		if n := atomic.LoadInt32(&p.flushes); n != 1 {
+			t.Errorf("provider %s flushed %d times, want 1", p.name, n)
+		}
+	}
+}
+
+func TestLoggerCloseWithoutErrors(t *testing.T) {
+	logger, _ := newScriptedLogger(nil, nil)
+	if err := logger.Close(context.Background()); err != nil {
+		t.Errorf("Close = %v, want nil", err)
+	}
+}
@@ -0,0 +1,341 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lokiPushPath - путь API приема записей Loki.
+const lokiPushPath = "/loki/api/v1/push"
+
+// lokiProvider отправляет записи в Grafana Loki через /loki/api/v1/push.
+// Поток определяется статическими метками и, если не отключено, уровнем;
+// сообщение и поля записи передаются в строке лога в формате JSON.
+type lokiProvider struct {
+	closedState
+
+	config      LokiConfig
+	client      *http.Client
+	url         string
+	labels      []byte
+	reserved    []string
+	batcher     *httpBatcher
+	diagnostics *diagnostics
+	sizes       sizeHistogram
+	leakCheck   *leakCheck
+}
+
+// NewLokiProvider создает провайдер Grafana Loki.
+// Записи отправляются пакетами в фоне: при заполнении пакета
+// (Batch.BatchSize) или по истечении Batch.FlushInterval. Неудачная отправка
+// повторяется с экспоненциальной паузой; пакет, не отправленный после всех
+// повторов, отбрасывается и учитывается в счетчике DroppedEntries
+// (см. DropRecorder). Close отправляет последний пакет.
+//
+// Метки Labels задают поток и не должны содержать значений с большим
+// количеством вариантов (идентификаторов запросов, пользователей): каждое
+// сочетание меток - отдельный поток Loki. Поля записи передаются в строке
+// {"msg":...,"key":value,...}; поля с именами msg и level получают префикс
+// "fields.". При заданном Batch.TenantHeaderFromField значение поля
+// передается в заголовке Batch.TenantHeader, по умолчанию X-Scope-OrgID.
+// Возвращает ошибку, если адрес не задан или некорректен либо имя метки
+// не соответствует требованиям Loki.
+func NewLokiProvider(config LokiConfig) (LoggerProvider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("sglogger: loki URL is not set")
+	}
+	push, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("sglogger: invalid loki URL: %w", err)
+	}
+	for name := range config.Labels {
+		if !validLokiLabel(name) {
+			return nil, fmt.Errorf("sglogger: invalid loki label name %q", name)
+		}
+		if name == "level" && !config.DisableLevelLabel {
+			return nil, fmt.Errorf("sglogger: loki label \"level\" is set from the entry level unless DisableLevelLabel is true")
+		}
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Batch.validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Labels = cloneStrings(config.Labels)
+	config.Level = clampLevel(config.Level)
+	if config.Batch.TenantHeader == "" {
+		config.Batch.TenantHeader = "X-Scope-OrgID"
+	}
+
+	client, err := NewHTTPClient(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(push.Path, lokiPushPath) {
+		push.Path = strings.TrimRight(push.Path, "/") + lokiPushPath
+	}
+
+	p := &lokiProvider{
+		config:      config,
+		client:      client,
+		url:         push.String(),
+		labels:      appendLokiLabels(nil, config.Labels),
+		reserved:    []string{"msg"},
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	if config.DisableLevelLabel {
+		p.reserved = append(p.reserved, "level")
+	}
+	p.batcher = newHTTPBatcher(config.Batch, p.send, p.streamKey, func(err error) {
+		p.diagnostics.reportf(p.Name(), "%v", err)
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(p.Name(), err)
+		}
+	})
+	if !config.LeakCheck {
+		return p, nil
+	}
+	// Фоновая отправка ссылается на p, поэтому финализатор устанавливается
+	// на обертку, которую удерживает только вызывающий
+	h := &lokiProviderHandle{p}
+	p.leakCheck = newLeakCheck(true, h, "Loki provider "+p.url, p.diagnostics)
+	return h, nil
+}
+
+// lokiProviderHandle - провайдер, возвращаемый NewLokiProvider при LeakCheck.
+type lokiProviderHandle struct{ *lokiProvider }
+
+// validLokiLabel сообщает, соответствует ли имя метки [a-zA-Z_][a-zA-Z0-9_]*.
+func validLokiLabel(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// appendLokiLabels добавляет метки, отсортированные по имени, как пары JSON
+// без фигурных скобок.
+func appendLokiLabels(buf []byte, labels map[string]string) []byte {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, name)
+		buf = append(buf, ':')
+		buf = appendJSONString(buf, labels[name])
+	}
+	return buf
+}
+
+// Write ставит запись в очередь отправки.
+func (p *lokiProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+	return p.batcher.add(Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+// WriteBatch отправляет записи синхронно, минуя очередь (см. BatchWriter).
+func (p *lokiProvider) WriteBatch(ctx context.Context, entries []Entry) error {
+	return p.batcher.writeBatch(ctx, entries)
+}
+
+// EntrySizes возвращает гистограмму размеров отправленных строк
+// (без учета повторов).
+func (p *lokiProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// DroppedEntries возвращает количество записей, потерянных при отправке
+// (см. DropRecorder).
+func (p *lokiProvider) DroppedEntries() uint64 {
+	return p.batcher.droppedEntries()
+}
+
+// Name возвращает имя провайдера из конфигурации или "loki" по умолчанию.
+func (p *lokiProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "loki"
+}
+
+// Describe возвращает уровень, адрес, метки и параметры пакетов (см. Describer).
+func (p *lokiProvider) Describe() map[string]interface{} {
+	labels := make(map[string]interface{}, len(p.config.Labels))
+	for k, v := range p.config.Labels {
+		labels[k] = v
+	}
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["format"] = "json"
+	description["url"] = p.url
+	description["labels"] = labels
+	description["level_label"] = !p.config.DisableLevelLabel
+	description["tenant_id"] = p.config.TenantID
+	description["batch"] = describeHTTPBatch(p.batcher.config)
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *lokiProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *lokiProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Flush отправляет записи из очереди.
+func (p *lokiProvider) Flush(ctx context.Context) error {
+	return p.batcher.flush(ctx)
+}
+
+// Close отправляет оставшиеся записи и прекращает прием новых.
+func (p *lokiProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	if !p.markClosed() {
+		return nil
+	}
+	return p.batcher.close(ctx)
+}
+
+// send отправляет пакет записей, сгруппированных в потоки.
+func (p *lokiProvider) send(ctx context.Context, tenant string, entries []Entry) error {
+	body := p.appendPush(make([]byte, 0, 256*len(entries)), entries)
+	body, encoding, err := compressHTTPBody(p.config.Batch, body)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, p.client, httpMaxRetries(p.config.Batch), p.diagnostics, p.Name(), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: create loki request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		if p.config.TenantID != "" {
+			req.Header.Set("X-Scope-OrgID", p.config.TenantID)
+		}
+		if tenant != "" {
+			req.Header.Set(p.config.Batch.TenantHeader, tenant)
+		}
+		return req, nil
+	})
+}
+
+// streamKey возвращает поток записи у одного арендатора: уровень, если он
+// передается меткой, иначе - единственный поток статических меток.
+func (p *lokiProvider) streamKey(e Entry) string {
+	if p.config.DisableLevelLabel {
+		return ""
+	}
+	return e.Level.String()
+}
+
+// appendPush добавляет тело запроса push:
+// {"streams":[{"stream":{...},"values":[["<ns>","<line>"],...]},...]}.
+// Потоки следуют в порядке первого появления, порядок записей внутри потока
+// сохраняется.
+func (p *lokiProvider) appendPush(buf []byte, entries []Entry) []byte {
+	var streams []Level
+	groups := make(map[Level][]Entry)
+	for _, e := range entries {
+		key := e.Level
+		if p.config.DisableLevelLabel {
+			key = 0
+		}
+		if _, ok := groups[key]; !ok {
+			streams = append(streams, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	buf = append(buf, `{"streams":[`...)
+	for i, level := range streams {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, `{"stream":{`...)
+		buf = append(buf, p.labels...)
+		if !p.config.DisableLevelLabel {
+			if len(p.labels) > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, `"level":`...)
+			buf = appendJSONString(buf, level.String())
+		}
+		buf = append(buf, `},"values":[`...)
+		for j, e := range groups[level] {
+			if j > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, `["`...)
+			buf = strconv.AppendInt(buf, e.Time.UnixNano(), 10)
+			buf = append(buf, `",`...)
+			line := p.appendLine(nil, e)
+			p.sizes.observe(len(line))
+			buf = appendJSONString(buf, string(line))
+			buf = append(buf, ']')
+		}
+		buf = append(buf, "]}"...)
+	}
+	return append(buf, "]}"...)
+}
+
+// appendLine добавляет строку лога: JSON с сообщением, уровнем (если он
+// не передается меткой) и полями записи.
+func (p *lokiProvider) appendLine(buf []byte, e Entry) []byte {
+	buf = append(buf, `{"msg":`...)
+	buf = appendJSONString(buf, e.Message)
+	if p.config.DisableLevelLabel {
+		buf = append(buf, `,"level":`...)
+		buf = appendJSONString(buf, e.Level.String())
+	}
+	buf = appendJSONFields(buf, durationFields(e.Fields, p.config.Durations), p.config.FloatFormat, p.reserved...)
+	return append(buf, '}')
+}
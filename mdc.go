@@ -0,0 +1,83 @@
+package sglogger
+
+import "context"
+
+// MDC предоставляет помощники в стиле Mapped Diagnostic Context (Java) поверх
+// ContextWithFields: значение, добавленное один раз, попадает во все записи
+// с этим контекстом до очистки. Явно переданные поля имеют приоритет над значениями MDC.
+//
+// В отличие от Java, MDC привязан к контексту, а не к горутине: значения видны
+// только в записях с возвращенным контекстом (и производными от него), а
+// горутины, получившие другой контекст, их не видят. Для фоновой работы,
+// которая переживает запрос, используйте MDC.WrapGoroutine.
+//
+// Пример:
+//
+//	ctx = sglogger.MDC.Put(ctx, "user_id", userID)
+//	logger.Info(ctx, "профиль обновлен") // содержит user_id
+var MDC mdc
+
+// mdc реализует помощники MDC.
+type mdc struct{}
+
+// Put возвращает контекст, в котором значение key равно value.
+func (mdc) Put(ctx context.Context, key string, value interface{}) context.Context {
+	return ContextWithFields(ctx, Fields{key: value})
+}
+
+// Get возвращает значение key из контекста.
+func (mdc) Get(ctx context.Context, key string) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	fields, _ := ctx.Value(fieldsKey).(Fields)
+	value, ok := fields[key]
+	return value, ok
+}
+
+// Remove возвращает контекст без значения key.
+func (mdc) Remove(ctx context.Context, key string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	existing, _ := ctx.Value(fieldsKey).(Fields)
+	if _, ok := existing[key]; !ok {
+		return ctx
+	}
+
+	result := make(Fields, len(existing)-1)
+	for k, v := range existing {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return context.WithValue(ctx, fieldsKey, result)
+}
+
+// Clear возвращает контекст без значений MDC (и других полей ContextWithFields).
+func (mdc) Clear(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	if _, ok := ctx.Value(fieldsKey).(Fields); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldsKey, Fields(nil))
+}
+
+// WrapGoroutine возвращает функцию, вызывающую fn с отсоединенным контекстом
+// (см. DetachFields): в него скопированы значения MDC, trace_id и другие значения
+// логгера из ctx, но не его отмена и дедлайн, поэтому фоновая работа продолжает
+// логировать с полями запроса после его завершения.
+//
+// Пример:
+//
+//	go sglogger.MDC.WrapGoroutine(ctx, func(ctx context.Context) {
+//	    logger.Info(ctx, "отчет сформирован") // содержит поля MDC из запроса
+//	})()
+func (m mdc) WrapGoroutine(ctx context.Context, fn func(ctx context.Context)) func() {
+	detached := DetachFields(ctx)
+	return func() {
+		fn(detached)
+	}
+}
@@ -0,0 +1,120 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProviderCloseResult описывает закрытие одного провайдера.
+type ProviderCloseResult struct {
+	Provider string        // Имя провайдера
+	Duration time.Duration // Время закрытия; для истекших - время до истечения контекста
+	Err      error         // Ошибка Close или ошибка контекста для истекших
+}
+
+// MultiCloseError возвращается Logger.Close, если хотя бы один провайдер
+// не закрылся: Failed содержит провайдеры, Close которых вернул ошибку,
+// TimedOut - провайдеры, не завершившие Close до отмены контекста
+// или вернувшие ошибку контекста.
+type MultiCloseError struct {
+	Failed   []ProviderCloseResult
+	TimedOut []ProviderCloseResult
+}
+
+// Error возвращает описание ошибки.
+func (e *MultiCloseError) Error() string {
+	parts := make([]string, 0, len(e.Failed)+len(e.TimedOut))
+	for _, r := range e.Failed {
+		parts = append(parts, fmt.Sprintf("close provider %q: %v", r.Provider, r.Err))
+	}
+	for _, r := range e.TimedOut {
+		parts = append(parts, fmt.Sprintf("close provider %q timed out after %s: %v", r.Provider, r.Duration, r.Err))
+	}
+	return "sglogger: " + strings.Join(parts, "; ")
+}
+
+// Unwrap возвращает ошибки провайдеров.
+func (e *MultiCloseError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed)+len(e.TimedOut))
+	for _, r := range e.Failed {
+		errs = append(errs, r.Err)
+	}
+	for _, r := range e.TimedOut {
+		errs = append(errs, r.Err)
+	}
+	return errs
+}
+
+// closeProviders закрывает провайдеры одновременно, каждый с контекстом ctx,
+// и ожидает их не дольше, чем до отмены ctx: провайдер, не учитывающий контекст,
+// не задерживает возврат, его Close продолжается в фоне. Ошибки и истечения
+// сообщаются через диагностический канал с длительностью закрытия.
+// Возвращает *MultiCloseError или nil.
+func (l *logger) closeProviders(ctx context.Context, providers []*registeredProvider) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
+	results := make(chan ProviderCloseResult, len(providers))
+	for _, rp := range providers {
+		go func(rp *registeredProvider) {
+			err := l.closeProvider(ctx, rp)
+			results <- ProviderCloseResult{Provider: rp.name, Duration: time.Since(start), Err: err}
+		}(rp)
+	}
+
+	pending := make(map[string]bool, len(providers))
+	for _, rp := range providers {
+		pending[rp.name] = true
+	}
+
+	var closeErr MultiCloseError
+	record := func(r ProviderCloseResult) {
+		delete(pending, r.Provider)
+		switch {
+		case r.Err == nil:
+		case errors.Is(r.Err, context.DeadlineExceeded) || errors.Is(r.Err, context.Canceled):
+			closeErr.TimedOut = append(closeErr.TimedOut, r)
+			l.diagnostics.reportf(r.Provider, "close timed out after %s: %v", r.Duration, r.Err)
+		default:
+			closeErr.Failed = append(closeErr.Failed, r)
+			l.diagnostics.reportf(r.Provider, "close failed after %s: %v", r.Duration, r.Err)
+		}
+	}
+
+	expired := false
+	for len(pending) > 0 && !expired {
+		select {
+		case r := <-results:
+			record(r)
+		case <-ctx.Done():
+			expired = true
+		}
+	}
+	// Результаты, готовые к моменту отмены, учитываются до признания
+	// оставшихся провайдеров истекшими.
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			record(r)
+			continue
+		default:
+		}
+		elapsed := time.Since(start)
+		for _, rp := range providers {
+			if pending[rp.name] {
+				closeErr.TimedOut = append(closeErr.TimedOut, ProviderCloseResult{Provider: rp.name, Duration: elapsed, Err: ctx.Err()})
+				l.diagnostics.reportf(rp.name, "close still running after %s: %v", elapsed, ctx.Err())
+			}
+		}
+		break
+	}
+
+	if len(closeErr.Failed) == 0 && len(closeErr.TimedOut) == 0 {
+		return nil
+	}
+	return &closeErr
+}
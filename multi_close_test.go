@@ -0,0 +1,74 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer - bytes.Buffer, безопасный для одновременной записи.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLoggerCloseReportsSlowAndFailingProviders(t *testing.T) {
+	errDisk := errors.New("disk full")
+	fast := &scriptedProvider{name: "fast"}
+	slow := &scriptedProvider{name: "slow", closeDelay: time.Second}
+	failing := &scriptedProvider{name: "failing", closeErr: errDisk}
+
+	var diag syncBuffer
+	config := LoggerConfig{Diagnostics: &DiagnosticsConfig{Output: &diag, Rate: 100}}
+	logger := NewLogger(config, NewFieldsHandler(), fast, slow, failing)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := logger.Close(ctx)
+	// Медленный провайдер не задерживает Close дольше контекста
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Close took %s with a 100ms deadline", elapsed)
+	}
+
+	var closeErr *MultiCloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("Close = %v, want *MultiCloseError", err)
+	}
+	if len(closeErr.Failed) != 1 || closeErr.Failed[0].Provider != "failing" || !errors.Is(closeErr.Failed[0].Err, errDisk) {
+		t.Errorf("Failed = %+v, want the failing provider with its error", closeErr.Failed)
+	}
+	if len(closeErr.TimedOut) != 1 || closeErr.TimedOut[0].Provider != "slow" || !errors.Is(closeErr.TimedOut[0].Err, context.DeadlineExceeded) {
+		t.Errorf("TimedOut = %+v, want the slow provider", closeErr.TimedOut)
+	}
+	if !errors.Is(err, errDisk) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close error %v does not unwrap to the provider errors", err)
+	}
+
+	// Закрытие каждого провайдера с ошибкой описывается в диагностике
+	output := diag.String()
+	for _, want := range []string{"failing", "close failed after", "slow", "close still running after"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("diagnostics %q do not mention %q", output, want)
+		}
+	}
+	if strings.Contains(output, "fast") {
+		t.Errorf("diagnostics mention the provider that closed cleanly: %q", output)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !race
+
+package sglogger
+
+// raceEnabled сообщает, что тесты собраны с детектором гонок.
+const raceEnabled = false
@@ -0,0 +1,135 @@
+package sglogger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Поля записей операции (см. Logger.BeginOp).
+const (
+	// OpIDField содержит идентификатор, общий для записей начала и завершения операции.
+	OpIDField = "op_id"
+
+	// OpNameField содержит название операции.
+	OpNameField = "op"
+
+	// OpElapsedField содержит длительность операции (time.Duration, см. DurationPolicy).
+	OpElapsedField = "elapsed"
+
+	// OpPanicField содержит значение паники, прервавшей операцию.
+	OpPanicField = "panic"
+)
+
+// Operation - длительная операция, для которой записываются начало и ровно
+// одно завершение: Success, Fail или, если ни один из них не был вызван,
+// Finish. Методы безопасны для вызова из нескольких горутин: завершение
+// записывает только первый из них, остальные ничего не делают.
+type Operation struct {
+	logger Logger
+	ctx    context.Context
+	name   string
+	id     string
+	fields Fields
+	start  time.Time
+	done   int32
+}
+
+// BeginOp записывает сообщение "<name> started" уровнем LevelInfo и возвращает
+// операцию. Записи начала и завершения содержат поля fields, OpNameField
+// и общий OpIDField, записи завершения - также OpElapsedField.
+// Типичное использование:
+//
+//	op := logger.BeginOp(ctx, "rebuild index", sglogger.Fields{"index": name})
+//	defer op.Finish()
+//	...
+//	op.Success(sglogger.Fields{"documents": n})
+func (l *logger) BeginOp(ctx context.Context, name string, fields Fields) *Operation {
+	op := &Operation{
+		logger: l,
+		ctx:    ctx,
+		name:   name,
+		id:     newOpID(),
+		fields: fields,
+		start:  time.Now(),
+	}
+	l.InfoWithFields(ctx, op.entryFields(nil, false), "%s started", name)
+	return op
+}
+
+// ID возвращает идентификатор операции.
+func (op *Operation) ID() string {
+	return op.id
+}
+
+// Success записывает сообщение "<name> finished" уровнем LevelInfo с полями fields.
+func (op *Operation) Success(fields Fields) {
+	if !op.complete() {
+		return
+	}
+	op.logger.InfoWithFields(op.ctx, op.entryFields(fields, true), "%s finished", op.name)
+}
+
+// Fail записывает сообщение "<name> failed" уровнем LevelError с ошибкой err и полями fields.
+func (op *Operation) Fail(err error, fields Fields) {
+	if !op.complete() {
+		return
+	}
+	op.logger.ErrorErrWithFields(op.ctx, err, op.entryFields(fields, true), "%s failed", op.name)
+}
+
+// Finish предназначен для вызова через defer. Если операция уже завершена
+// Success или Fail, ничего не делает. Иначе записывает сообщение
+// "<name> failed" уровнем LevelError: при панике - со значением паники
+// в OpPanicField, после чего паника продолжается, без паники - с ошибкой
+// ErrOpNotCompleted. Перехват паники возможен, только если Finish вызывается
+// непосредственно через defer.
+func (op *Operation) Finish() {
+	r := recover()
+	if op.complete() {
+		if r != nil {
+			op.logger.ErrorErrWithFields(op.ctx, fmt.Errorf("panic: %v", r),
+				op.entryFields(Fields{OpPanicField: fmt.Sprint(r)}, true), "%s failed", op.name)
+		} else {
+			op.logger.ErrorErrWithFields(op.ctx, ErrOpNotCompleted, op.entryFields(nil, true), "%s failed", op.name)
+		}
+	}
+	if r != nil {
+		panic(r)
+	}
+}
+
+// complete отмечает операцию завершенной и сообщает, сделал ли это текущий вызов.
+func (op *Operation) complete() bool {
+	return atomic.CompareAndSwapInt32(&op.done, 0, 1)
+}
+
+// entryFields собирает поля записи: поля BeginOp, поля extra, имя,
+// идентификатор и, для завершения, длительность.
+func (op *Operation) entryFields(extra Fields, finished bool) Fields {
+	fields := make(Fields, len(op.fields)+len(extra)+3)
+	for k, v := range op.fields {
+		fields[k] = v
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	fields[OpNameField] = op.name
+	fields[OpIDField] = op.id
+	if finished {
+		fields[OpElapsedField] = time.Since(op.start)
+	}
+	return fields
+}
+
+// newOpID возвращает случайный идентификатор операции из 16 шестнадцатеричных символов.
+func newOpID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,324 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultOrderedMaxPending ограничивает количество записей, ожидающих
+	// предшествующих записей, по умолчанию.
+	defaultOrderedMaxPending = 1024
+
+	// defaultOrderedMaxDelay задает время ожидания пропущенной записи по умолчанию.
+	defaultOrderedMaxDelay = 100 * time.Millisecond
+)
+
+// OrderGapField содержит количество записей, пропущенных в последовательности
+// упорядочивающего провайдера (см. NewOrderedProvider).
+const OrderGapField = "order_gap"
+
+// OrderStats содержит счетчики упорядочивающего провайдера.
+type OrderStats struct {
+	Reordered  uint64 // Записи, пришедшие раньше предшествующих и задержанные до их прихода
+	Gaps       uint64 // Записи, которые не дождались и пропустили с отметкой о разрыве
+	Late       uint64 // Записи, пришедшие после разрыва на их месте и записанные не по порядку
+	Pending    int    // Записи, ожидающие предшествующих в данный момент
+	MaxPending int    // Наибольшее количество одновременно ожидавших записей
+}
+
+// OrderRecorder определяет интерфейс провайдеров, упорядочивающих записи
+// (см. NewOrderedProvider). Счетчики отображаются в Stats.
+type OrderRecorder interface {
+	// OrderStats возвращает текущие счетчики упорядочивания
+	OrderStats() OrderStats
+}
+
+// sequenceKey - ключ контекста с порядковым номером записи для провайдера p.
+type sequenceKey struct {
+	p *orderedProvider
+}
+
+// orderedEntry - запись, ожидающая предшествующих записей.
+type orderedEntry struct {
+	ctx     context.Context
+	level   Level
+	message string
+	fields  Fields
+}
+
+// orderedProvider оборачивает LoggerProvider и передает ему записи в порядке
+// их порядковых номеров, назначенных логгером.
+type orderedProvider struct {
+	closedState
+
+	inner        LoggerProvider
+	config       OrderedConfig
+	diagnostics  *diagnostics
+	mu           sync.Mutex
+	seq          uint64 // последний назначенный номер
+	expected     uint64 // номер следующей записи для внутреннего провайдера
+	pending      map[uint64]orderedEntry
+	waitingSince time.Time
+	timer        *time.Timer
+	stats        OrderStats
+}
+
+// NewOrderedProvider создает обертку, гарантирующую, что внутренний провайдер
+// получает записи в том порядке, в котором они были переданы логгеру, даже
+// если записи из разных горутин доходят до провайдера в другом порядке.
+// По умолчанию порядок не гарантируется (см. раздел README о порядке записей).
+//
+// Логгер назначает записи порядковый номер в начале записи, поэтому обертка
+// должна быть зарегистрирована в логгере непосредственно; записи без номера
+// (например, при вызове Write напрямую) передаются сразу. Запись, пришедшая
+// раньше предшествующих, задерживается - Write возвращает nil, а ошибки ее
+// последующей записи сообщаются в Diagnostics. Если пропущенная запись не
+// пришла за MaxDelay или задержано больше MaxPending записей, задержанные
+// записи передаются с отметкой о разрыве - записью уровня LevelWarn с полем
+// OrderGapField. Записи передаются внутреннему провайдеру последовательно,
+// что ограничивает пропускную способность. Счетчики отображаются в Stats
+// (см. OrderRecorder).
+//
+// Возвращает ошибку, если внутренний провайдер не задан.
+func NewOrderedProvider(inner LoggerProvider, config OrderedConfig) (LoggerProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("sglogger: ordered provider requires an inner provider")
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = defaultOrderedMaxPending
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultOrderedMaxDelay
+	}
+
+	return &orderedProvider{
+		inner:       inner,
+		config:      config,
+		diagnostics: newDiagnostics(config.Diagnostics),
+		expected:    1,
+		pending:     make(map[uint64]orderedEntry),
+	}, nil
+}
+
+// sequence назначает записи следующий порядковый номер и возвращает
+// контекст с ним.
+func (p *orderedProvider) sequence(ctx context.Context) context.Context {
+	p.mu.Lock()
+	p.seq++
+	seq := p.seq
+	p.mu.Unlock()
+	return context.WithValue(ctx, sequenceKey{p}, seq)
+}
+
+// assignSequences назначает порядковые номера записи упорядочивающим
+// провайдерам, которые ее примут (см. NewOrderedProvider).
+func (l *logger) assignSequences(ctx context.Context, level Level) context.Context {
+	for i := range l.providers {
+		rp := &l.providers[i]
+		if rp.ordered != nil && l.providerAccepts(ctx, rp, level) {
+			ctx = rp.ordered.sequence(ctx)
+		}
+	}
+	return ctx
+}
+
+// Write передает запись внутреннему провайдеру в порядке номеров: если
+// предшествующие записи еще не пришли, запись задерживается.
+func (p *orderedProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	seq, ok := ctx.Value(sequenceKey{p}).(uint64)
+	if !ok {
+		return p.inner.Write(ctx, level, message, fields)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	switch {
+	case seq < p.expected:
+		p.stats.Late++
+		return p.inner.Write(ctx, level, message, fields)
+	case seq > p.expected:
+		p.stats.Reordered++
+		if len(p.pending) == 0 {
+			p.waitingSince = time.Now()
+		}
+		p.pending[seq] = orderedEntry{
+			ctx:     ContextWithEntryTime(DetachFields(ctx), entryTime(ctx)),
+			level:   level,
+			message: message,
+			fields:  fields,
+		}
+		if len(p.pending) > p.stats.MaxPending {
+			p.stats.MaxPending = len(p.pending)
+		}
+		if len(p.pending) > p.config.MaxPending {
+			p.releaseGap()
+		} else {
+			p.scheduleExpiry()
+		}
+		return nil
+	}
+
+	err := p.inner.Write(ctx, level, message, fields)
+	p.expected++
+	p.drain()
+	return err
+}
+
+// drain передает задержанные записи, следующие по порядку.
+// Вызывается с удерживаемой блокировкой.
+func (p *orderedProvider) drain() {
+	drained := false
+	for {
+		e, ok := p.pending[p.expected]
+		if !ok {
+			break
+		}
+		delete(p.pending, p.expected)
+		p.expected++
+		drained = true
+		if err := p.inner.Write(e.ctx, e.level, e.message, e.fields); err != nil {
+			p.diagnostics.reportf(p.Name(), "delayed write failed: %v", err)
+		}
+	}
+	if drained && len(p.pending) > 0 {
+		p.waitingSince = time.Now()
+	}
+}
+
+// releaseGap пропускает недостающие записи до первой задержанной, записывает
+// отметку о разрыве и передает задержанные записи, следующие по порядку.
+// Вызывается с удерживаемой блокировкой.
+func (p *orderedProvider) releaseGap() {
+	if len(p.pending) == 0 {
+		return
+	}
+	first := uint64(0)
+	for seq := range p.pending {
+		if first == 0 || seq < first {
+			first = seq
+		}
+	}
+
+	missing := first - p.expected
+	p.stats.Gaps += missing
+	p.expected = first
+	if p.inner.ShouldLog(context.Background(), LevelWarn) {
+		err := p.inner.Write(context.Background(), LevelWarn,
+			fmt.Sprintf("ordered: %d entries missing", missing), Fields{OrderGapField: missing})
+		if err != nil {
+			p.diagnostics.reportf(p.Name(), "gap marker write failed: %v", err)
+		}
+	}
+	p.waitingSince = time.Now()
+	p.drain()
+}
+
+// scheduleExpiry запускает ожидание пропущенной записи, если оно еще не запущено.
+// Вызывается с удерживаемой блокировкой.
+func (p *orderedProvider) scheduleExpiry() {
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.config.MaxDelay, p.expire)
+	}
+}
+
+// expire передает задержанные записи с отметкой о разрыве, если пропущенная
+// запись не пришла за MaxDelay, и при необходимости продолжает ожидание.
+func (p *orderedProvider) expire() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.timer = nil
+	if p.isClosed() || len(p.pending) == 0 {
+		return
+	}
+	if wait := p.config.MaxDelay - time.Since(p.waitingSince); wait > 0 {
+		p.timer = time.AfterFunc(wait, p.expire)
+		return
+	}
+	p.releaseGap()
+	if len(p.pending) > 0 {
+		p.scheduleExpiry()
+	}
+}
+
+// OrderStats возвращает текущие счетчики упорядочивания.
+func (p *orderedProvider) OrderStats() OrderStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Pending = len(p.pending)
+	return stats
+}
+
+// Name возвращает имя из конфигурации или имя внутреннего провайдера.
+func (p *orderedProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return ProviderName(p.inner)
+}
+
+// Describe возвращает параметры упорядочивания и описание внутреннего провайдера (см. Describer).
+func (p *orderedProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"max_pending": p.config.MaxPending,
+		"max_delay":   p.config.MaxDelay.String(),
+		"inner":       describeProvider(p.inner),
+	}
+}
+
+// Active делегирует проверку активности внутреннему провайдеру.
+func (p *orderedProvider) Active() bool {
+	return providerActive(p.inner)
+}
+
+// ShouldLog делегирует проверку уровня внутреннему провайдеру.
+func (p *orderedProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.inner.ShouldLog(ctx, level)
+}
+
+// Flush сбрасывает буферизованный вывод внутреннего провайдера.
+// Задержанные записи не передаются: они ожидают предшествующих.
+func (p *orderedProvider) Flush(ctx context.Context) error {
+	if flusher, ok := p.inner.(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// Close прекращает прием записей, передает задержанные записи с отметками
+// о разрывах и закрывает внутренний провайдер (см. ChainClose).
+func (p *orderedProvider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.markClosed() {
+		p.mu.Unlock()
+		return nil
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.mu.Unlock()
+
+	return ChainClose(ctx, p.inner, func(ctx context.Context) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for len(p.pending) > 0 {
+			p.releaseGap()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,148 @@
+package sglogger
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OversizeDroppedField - поле со списком полей, удаленных из записи, чтобы
+// она поместилась в датаграмму (см. OversizeTruncate).
+const OversizeDroppedField = "dropped_fields"
+
+var (
+	// defaultOversizeDropFirst - поля, удаляемые первыми по умолчанию.
+	defaultOversizeDropFirst = []string{"stack", "stacktrace"}
+
+	// defaultOversizeKeep - поля, которые по умолчанию не удаляются.
+	defaultOversizeKeep = []string{"error", "trace_id"}
+)
+
+// datagramFitter приводит закодированные записи к размеру датаграммы
+// согласно OversizeConfig. Используется провайдерами датаграмм: encode
+// кодирует запись по правилам протокола, chunk разбивает закодированную
+// запись на датаграммы (nil, если протокол этого не поддерживает).
+type datagramFitter struct {
+	config OversizeConfig
+	encode func(Entry) []byte
+	chunk  func(data []byte) [][]byte
+}
+
+// newDatagramFitter создает datagramFitter; maxSize - ограничение протокола,
+// используемое, если config.MaxSize не задан.
+func newDatagramFitter(config OversizeConfig, maxSize int, encode func(Entry) []byte, chunk func([]byte) [][]byte) (*datagramFitter, error) {
+	switch config.Policy {
+	case OversizeTruncate, OversizeSplit, OversizeReject:
+	default:
+		return nil, fmt.Errorf("sglogger: invalid oversize policy %d", config.Policy)
+	}
+	if config.MaxSize < 0 {
+		return nil, fmt.Errorf("sglogger: negative datagram size limit %d", config.MaxSize)
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = maxSize
+	}
+	if config.DropFirst == nil {
+		config.DropFirst = defaultOversizeDropFirst
+	}
+	if config.Keep == nil {
+		config.Keep = defaultOversizeKeep
+	}
+	config.DropFirst = append([]string(nil), config.DropFirst...)
+	config.Keep = append([]string(nil), config.Keep...)
+	return &datagramFitter{config: config, encode: encode, chunk: chunk}, nil
+}
+
+// fit возвращает датаграммы записи. Запись, превышающая ограничение,
+// обрабатывается согласно политике; если привести ее к размеру не удалось,
+// возвращается ErrEntryTooLarge.
+func (f *datagramFitter) fit(e Entry) ([][]byte, error) {
+	data := f.encode(e)
+	if len(data) <= f.config.MaxSize {
+		return [][]byte{data}, nil
+	}
+
+	switch f.config.Policy {
+	case OversizeReject:
+		return nil, f.tooLarge(len(data))
+	case OversizeSplit:
+		if f.chunk != nil {
+			return f.chunk(data), nil
+		}
+	}
+	return f.truncate(e, data)
+}
+
+// truncate удаляет поля в порядке dropOrder, пока запись не поместится,
+// а затем при необходимости укорачивает сообщение.
+func (f *datagramFitter) truncate(e Entry, data []byte) ([][]byte, error) {
+	var dropped []string
+	for _, k := range f.dropOrder(e.Fields) {
+		if len(data) <= f.config.MaxSize {
+			break
+		}
+		if dropped == nil {
+			e.Fields = cloneFields(e.Fields)
+		}
+		delete(e.Fields, k)
+		dropped = append(dropped, k)
+		e.Fields[OversizeDroppedField] = dropped
+		data = f.encode(e)
+	}
+
+	// Каждый шаг уменьшает длину сообщения как минимум на превышение,
+	// поэтому цикл завершается.
+	message := e.Message
+	limit := len(message) + len(payloadTruncatedMarker)
+	for len(data) > f.config.MaxSize && limit > len(payloadTruncatedMarker) {
+		limit -= len(data) - f.config.MaxSize
+		n := limit - len(payloadTruncatedMarker)
+		if n < 0 {
+			n = 0
+		}
+		if n > len(message) {
+			n = len(message)
+		}
+		e.Message = truncateUTF8(message, n) + payloadTruncatedMarker
+		data = f.encode(e)
+	}
+
+	if len(data) > f.config.MaxSize {
+		return nil, f.tooLarge(len(data))
+	}
+	return [][]byte{data}, nil
+}
+
+// dropOrder возвращает поля записи в порядке удаления: сначала DropFirst
+// в заданном порядке, затем остальные от наибольшего значения к наименьшему.
+// Поля Keep не удаляются.
+func (f *datagramFitter) dropOrder(fields Fields) []string {
+	order := make([]string, 0, len(fields))
+	for _, k := range f.config.DropFirst {
+		if _, ok := fields[k]; ok && !containsString(f.config.Keep, k) {
+			order = append(order, k)
+		}
+	}
+
+	first := len(order)
+	sizes := make(map[string]int, len(fields))
+	for k, v := range fields {
+		if containsString(f.config.Keep, k) || containsString(f.config.DropFirst, k) || k == OversizeDroppedField {
+			continue
+		}
+		order = append(order, k)
+		sizes[k] = len(fmt.Sprint(v))
+	}
+	rest := order[first:]
+	sort.Slice(rest, func(i, j int) bool {
+		if sizes[rest[i]] != sizes[rest[j]] {
+			return sizes[rest[i]] > sizes[rest[j]]
+		}
+		return rest[i] < rest[j]
+	})
+	return order
+}
+
+// tooLarge возвращает ошибку ErrEntryTooLarge с размером записи.
+func (f *datagramFitter) tooLarge(size int) error {
+	return fmt.Errorf("%w: %d bytes, limit %d", ErrEntryTooLarge, size, f.config.MaxSize)
+}
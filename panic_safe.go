@@ -0,0 +1,100 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// defaultMaxProviderPanics - количество паник подряд, после которого
+// провайдер отключается, по умолчанию.
+const defaultMaxProviderPanics = 3
+
+// maxProviderPanics возвращает порог отключения провайдера или 0, если
+// провайдеры не отключаются.
+func (l *logger) maxProviderPanics() int32 {
+	switch {
+	case l.config.MaxProviderPanics == 0:
+		return defaultMaxProviderPanics
+	case l.config.MaxProviderPanics < 0:
+		return 0
+	}
+	return int32(l.config.MaxProviderPanics)
+}
+
+// providerPanicked преобразует панику провайдера в ошибку, учитывает ее
+// и отключает провайдер после MaxProviderPanics паник подряд.
+func (l *logger) providerPanicked(rp *registeredProvider, method string, r interface{}) error {
+	err := fmt.Errorf("%w: %s: %v", ErrProviderPanicked, method, r)
+	atomic.AddUint64(&rp.stats.panics, 1)
+	l.diagnostics.reportf(rp.name, "%v", err)
+
+	n := atomic.AddInt32(&rp.stats.consecutivePanics, 1)
+	if max := l.maxProviderPanics(); max > 0 && n >= max && atomic.CompareAndSwapInt32(&rp.stats.disabled, 0, 1) {
+		l.diagnostics.reportf(rp.name, "provider disabled after %d consecutive panics", n)
+	}
+	return err
+}
+
+// providerDisabled сообщает, отключен ли провайдер из-за паник.
+func providerDisabled(rp *registeredProvider) bool {
+	return atomic.LoadInt32(&rp.stats.disabled) != 0
+}
+
+// providerAccepts сообщает, нужно ли передавать провайдеру запись уровня level:
+// провайдер активен, не отключен и его ShouldLog вернул true. Паника в ShouldLog
+// передается ErrorHandler, а запись провайдеру не передается.
+func (l *logger) providerAccepts(ctx context.Context, rp *registeredProvider, level Level) (accepts bool) {
+	if providerDisabled(rp) {
+		return false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			accepts = false
+			if err := l.providerPanicked(rp, "ShouldLog", r); l.config.ErrorHandler != nil {
+				l.config.ErrorHandler(rp.name, err)
+			}
+		}
+	}()
+	return providerActive(rp.provider) && rp.provider.ShouldLog(ctx, level)
+}
+
+// writeProvider вызывает Write провайдера, преобразуя панику в ошибку.
+// Успешная запись сбрасывает счетчик паник подряд.
+func (l *logger) writeProvider(ctx context.Context, rp *registeredProvider, level Level, message string, fields Fields) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = l.providerPanicked(rp, "Write", r)
+		}
+	}()
+	if err = rp.provider.Write(ctx, level, message, fields); err == nil {
+		atomic.StoreInt32(&rp.stats.consecutivePanics, 0)
+	}
+	return err
+}
+
+// flushProvider вызывает Flush провайдера, если он реализует Flusher,
+// преобразуя панику в ошибку.
+func (l *logger) flushProvider(ctx context.Context, rp *registeredProvider) (err error) {
+	flusher, ok := rp.provider.(Flusher)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = l.providerPanicked(rp, "Flush", r)
+		}
+	}()
+	return flusher.Flush(ctx)
+}
+
+// closeProvider вызывает Close провайдера, преобразуя панику в ошибку.
+// Отключенные провайдеры также закрываются.
+func (l *logger) closeProvider(ctx context.Context, rp *registeredProvider) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = l.providerPanicked(rp, "Close", r)
+		}
+	}()
+	return rp.provider.Close(ctx)
+}
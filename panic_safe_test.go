@@ -0,0 +1,119 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// panickingProvider паникует в методе method; при panicFirst > 0 паникует
+// только в первых panicFirst вызовах Write.
+type panickingProvider struct {
+	method     string
+	panicFirst int
+	writes     int
+}
+
+func (p *panickingProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	p.writes++
+	if p.method == "Write" && (p.panicFirst == 0 || p.writes <= p.panicFirst) {
+		panic("write exploded")
+	}
+	return nil
+}
+
+func (p *panickingProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.method == "ShouldLog" {
+		panic("should log exploded")
+	}
+	return true
+}
+
+func (p *panickingProvider) Name() string { return "panicky" }
+
+func (p *panickingProvider) Close(ctx context.Context) error {
+	if p.method == "Close" {
+		panic("close exploded")
+	}
+	return nil
+}
+
+func TestProviderPanics(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		panicFirst   int
+		maxPanics    int
+		writes       int
+		wantPanics   uint64
+		wantDisabled bool
+		wantHandled  int
+	}{
+		{name: "write panics", method: "Write", writes: 2, wantPanics: 2, wantHandled: 2},
+		{name: "write disabled after default threshold", method: "Write", writes: 5, wantPanics: 3, wantDisabled: true, wantHandled: 3},
+		{name: "write custom threshold", method: "Write", maxPanics: 1, writes: 3, wantPanics: 1, wantDisabled: true, wantHandled: 1},
+		{name: "never disabled", method: "Write", maxPanics: -1, writes: 5, wantPanics: 5, wantHandled: 5},
+		{name: "success resets consecutive panics", method: "Write", panicFirst: 2, writes: 6, wantPanics: 2, wantHandled: 2},
+		{name: "should log panics", method: "ShouldLog", writes: 4, wantPanics: 3, wantDisabled: true, wantHandled: 3},
+		{name: "close panics", method: "Close", writes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				handled []error
+			)
+			config := LoggerConfig{
+				MaxProviderPanics: tt.maxPanics,
+				Diagnostics:       &DiagnosticsConfig{Disabled: true},
+				ErrorHandler: func(provider string, err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if provider != "panicky" {
+						t.Errorf("ErrorHandler provider = %q, want panicky", provider)
+					}
+					handled = append(handled, err)
+				},
+			}
+			healthy := &recordingProvider{}
+			logger := NewLogger(config, NewFieldsHandler(), &panickingProvider{method: tt.method, panicFirst: tt.panicFirst}, healthy)
+
+			for i := 0; i < tt.writes; i++ {
+				logger.Info(context.Background(), "entry %d", i)
+			}
+			if got := len(healthy.Entries()); got != tt.writes {
+				t.Errorf("healthy provider received %d entries, want %d", got, tt.writes)
+			}
+
+			stats := logger.Stats().Providers["panicky"]
+			if stats.Panics != tt.wantPanics {
+				t.Errorf("Panics = %d, want %d", stats.Panics, tt.wantPanics)
+			}
+			if stats.Disabled != tt.wantDisabled {
+				t.Errorf("Disabled = %v, want %v", stats.Disabled, tt.wantDisabled)
+			}
+
+			err := logger.Close(context.Background())
+			if tt.method == "Close" {
+				if !errors.Is(err, ErrProviderPanicked) {
+					t.Errorf("Close = %v, want ErrProviderPanicked", err)
+				}
+			} else if err != nil {
+				t.Errorf("Close = %v, want nil", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(handled) != tt.wantHandled {
+				t.Errorf("ErrorHandler called %d times, want %d", len(handled), tt.wantHandled)
+			}
+			for _, err := range handled {
+				if !errors.Is(err, ErrProviderPanicked) {
+					t.Errorf("ErrorHandler error = %v, want ErrProviderPanicked", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package sglogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxPayloadSize - наибольший размер значения json.RawMessage по умолчанию.
+	defaultMaxPayloadSize = 64 << 10
+
+	// PayloadBytesSuffix - суффикс поля с исходным размером усеченного значения:
+	// для поля "req" размер записывается в "req.bytes".
+	PayloadBytesSuffix = ".bytes"
+
+	// payloadTruncatedMarker завершает усеченное значение.
+	payloadTruncatedMarker = "...(truncated)"
+)
+
+// errInvalidPayload - ошибка сериализации значения json.RawMessage, не являющегося JSON.
+var errInvalidPayload = errors.New("invalid JSON payload")
+
+// payloadFields применяет ConvertValue к значениям полей и ограничивает
+// размер значений json.RawMessage: значение длиннее предела заменяется
+// строкой из его начала с отметкой усечения, а исходный размер записывается
+// в поле с суффиксом PayloadBytesSuffix. Карта полей копируется только при изменении.
+func payloadFields(fields Fields, convert func(key string, value interface{}) (interface{}, bool), maxSize int) Fields {
+	if maxSize == 0 {
+		maxSize = defaultMaxPayloadSize
+	}
+
+	copied := false
+	set := func(k string, v interface{}) {
+		if !copied {
+			fields = cloneFields(fields)
+			copied = true
+		}
+		fields[k] = v
+	}
+
+	for k, v := range fields {
+		if convert != nil {
+			if converted, ok := convert(k, v); ok {
+				v = converted
+				set(k, v)
+			}
+		}
+		raw, ok := v.(json.RawMessage)
+		if !ok || maxSize < 0 || len(raw) <= maxSize {
+			continue
+		}
+		set(k, truncatePayload(raw, maxSize))
+		set(k+PayloadBytesSuffix, len(raw))
+	}
+	return fields
+}
+
+// truncatePayload возвращает начало значения длиной не более maxSize байт,
+// не разрывая символы UTF-8, с отметкой усечения.
+func truncatePayload(raw json.RawMessage, maxSize int) string {
+	n := maxSize
+	for n > 0 && n < len(raw) && !utf8.RuneStart(raw[n]) {
+		n--
+	}
+	return string(raw[:n]) + payloadTruncatedMarker
+}
+
+// appendRawPayload добавляет значение json.RawMessage в JSON без повторной
+// сериализации, удаляя пробельные символы, чтобы запись осталась одной строкой.
+// Значение, не являющееся корректным JSON, добавляется строкой с ошибкой.
+func appendRawPayload(buf []byte, raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 {
+		return append(buf, "null"...), nil
+	}
+	out := bytes.NewBuffer(buf)
+	if err := json.Compact(out, raw); err != nil {
+		return appendJSONString(buf, string(raw)), errInvalidPayload
+	}
+	return out.Bytes(), nil
+}
+
+// appendQuotedPayload добавляет значение json.RawMessage в текстовый формат строкой в кавычках.
+func appendQuotedPayload(buf []byte, raw json.RawMessage) []byte {
+	return strconv.AppendQuote(buf, string(raw))
+}
@@ -0,0 +1,387 @@
+package sglogger
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Поля запросов к базам данных.
+const (
+	// DBStatementField содержит текст запроса (см. QueryFields).
+	DBStatementField = "db.statement"
+
+	// DBFingerprintField содержит отпечаток запроса (см. QueryFingerprint).
+	DBFingerprintField = "db.fingerprint"
+)
+
+// queryPlaceholder заменяет литералы и параметры запроса, queryList - список
+// значений IN и строк VALUES.
+const (
+	queryPlaceholder = "?"
+	queryList        = "?+"
+)
+
+// QueryFields возвращает поля для записи о запросе к базе данных:
+// DBFingerprintField с отпечатком запроса и DBStatementField с текстом запроса
+// или, если redact равен true, с нормализованным текстом (см. NormalizeQuery),
+// в котором литералы заменены заполнителями.
+// Функция предназначена для адаптеров драйверов и ORM.
+func QueryFields(query string, redact bool) Fields {
+	normalized := NormalizeQuery(query)
+	statement := query
+	if redact {
+		statement = normalized
+	}
+	return Fields{
+		DBStatementField:   statement,
+		DBFingerprintField: fingerprintHash(normalized),
+	}
+}
+
+// QueryFingerprint вычисляет стабильный отпечаток запроса: запросы,
+// отличающиеся только литералами, параметрами, пробелами, комментариями,
+// регистром ключевых слов и длиной списков IN и VALUES, получают одинаковый отпечаток.
+func QueryFingerprint(query string) string {
+	return fingerprintHash(NormalizeQuery(query))
+}
+
+// NormalizeQuery приводит SQL-запрос к каноническому виду:
+//   - комментарии удаляются, пробельные символы сводятся к одному пробелу;
+//   - слова вне кавычек приводятся к нижнему регистру, идентификаторы
+//     в двойных и обратных кавычках сохраняются;
+//   - строковые литералы ('...', E'...', $$...$$), числа и параметры
+//     ($1, :name, @p1) заменяются на "?";
+//   - списки IN (?, ?, ...) сворачиваются в "in (?+)", строки VALUES
+//     из одних заполнителей - в "values (?+)".
+//
+// Например, "SELECT * FROM t WHERE name = 'x -- y' AND id IN (1, 2, 3)"
+// превращается в "select * from t where name = ? and id in (?+)".
+// Разбор не проверяет синтаксис: незакрытая строка или комментарий
+// продолжаются до конца запроса.
+func NormalizeQuery(query string) string {
+	tokens := collapseQueryLists(scanQuery(query))
+
+	var b strings.Builder
+	b.Grow(len(query))
+	for i, tok := range tokens {
+		if i > 0 && querySpaceBetween(tokens[i-1], tok) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(tok)
+	}
+	return b.String()
+}
+
+// fingerprintHash возвращает шестнадцатеричный хэш FNV-1a строки, как Fingerprint.
+func fingerprintHash(s string) string {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// scanQuery разбивает запрос на лексемы, заменяя литералы и параметры на "?".
+func scanQuery(q string) []string {
+	var tokens []string
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			end := strings.IndexByte(q[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			end := strings.Index(q[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += 2 + end + 2
+
+		case c == '\'':
+			i = skipQueryString(q, i)
+			tokens = append(tokens, queryPlaceholder)
+
+		case (c == 'e' || c == 'E' || c == 'n' || c == 'N' || c == 'x' || c == 'X' || c == 'b' || c == 'B') &&
+			i+1 < len(q) && q[i+1] == '\'' && !queryWordByte(prevByte(q, i)):
+			// Префиксные строки: E'...', N'...', X'...', B'...'.
+			i = skipQueryString(q, i+1)
+			tokens = append(tokens, queryPlaceholder)
+
+		case c == '"' || c == '`':
+			end := i + 1
+			for end < len(q) {
+				if q[end] == c {
+					if end+1 < len(q) && q[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end < len(q) {
+				end++
+			}
+			tokens = append(tokens, q[i:end])
+			i = end
+
+		case c == '$':
+			if tag, ok := dollarQuoteTag(q[i:]); ok {
+				end := strings.Index(q[i+len(tag):], tag)
+				if end < 0 {
+					i = len(q)
+				} else {
+					i += len(tag) + end + len(tag)
+				}
+				tokens = append(tokens, queryPlaceholder)
+				break
+			}
+			end := i + 1
+			for end < len(q) && isDigit(q[end]) {
+				end++
+			}
+			if end == i+1 {
+				tokens = append(tokens, "$")
+			} else {
+				tokens = append(tokens, queryPlaceholder)
+			}
+			i = end
+
+		case c == '?':
+			tokens = append(tokens, queryPlaceholder)
+			i++
+
+		case (c == ':' || c == '@') && i+1 < len(q) && isQueryWordStart(q[i+1]) && prevByte(q, i) != ':':
+			// Именованные параметры :name и @p1; приведение типа "::" разбирается как оператор.
+			end := i + 1
+			for end < len(q) && queryWordByte(q[end]) {
+				end++
+			}
+			tokens = append(tokens, queryPlaceholder)
+			i = end
+
+		case isDigit(c) || (c == '.' && i+1 < len(q) && isDigit(q[i+1])):
+			i = skipQueryNumber(q, i)
+			tokens = appendQueryValue(tokens, queryPlaceholder)
+
+		case isQueryWordStart(c):
+			end := i + 1
+			for end < len(q) && queryWordByte(q[end]) {
+				end++
+			}
+			tokens = append(tokens, strings.ToLower(q[i:end]))
+			i = end
+
+		default:
+			op := queryOperator(q[i:])
+			tokens = append(tokens, op)
+			i += len(op)
+		}
+	}
+	return tokens
+}
+
+// skipQueryString возвращает позицию после строкового литерала, начинающегося
+// с кавычки в позиции i. Удвоенная кавычка и кавычка после обратной косой
+// черты не завершают строку.
+func skipQueryString(q string, i int) int {
+	for i++; i < len(q); i++ {
+		switch q[i] {
+		case '\\':
+			i++
+		case '\'':
+			if i+1 < len(q) && q[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(q)
+}
+
+// skipQueryNumber возвращает позицию после числа: целого, десятичного,
+// с экспонентой или шестнадцатеричного (0x...).
+func skipQueryNumber(q string, i int) int {
+	if strings.HasPrefix(q[i:], "0x") || strings.HasPrefix(q[i:], "0X") {
+		i += 2
+		for i < len(q) && isHexDigit(q[i]) {
+			i++
+		}
+		return i
+	}
+	for i < len(q) && (isDigit(q[i]) || q[i] == '.') {
+		i++
+	}
+	if i < len(q) && (q[i] == 'e' || q[i] == 'E') {
+		j := i + 1
+		if j < len(q) && (q[j] == '+' || q[j] == '-') {
+			j++
+		}
+		if j < len(q) && isDigit(q[j]) {
+			i = j
+			for i < len(q) && isDigit(q[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+// appendQueryValue добавляет заполнитель числа, поглощая предшествующий
+// унарный знак: "= -1" и "= 1" дают одинаковый результат.
+func appendQueryValue(tokens []string, value string) []string {
+	if n := len(tokens); n > 0 && (tokens[n-1] == "-" || tokens[n-1] == "+") {
+		if n == 1 || !queryValueToken(tokens[n-2]) {
+			tokens = tokens[:n-1]
+		}
+	}
+	return append(tokens, value)
+}
+
+// queryValueToken сообщает, может ли лексема быть левым операндом,
+// то есть является ли знак после нее бинарным.
+func queryValueToken(tok string) bool {
+	switch tok {
+	case ")", queryPlaceholder, queryList:
+		return true
+	}
+	c := tok[0]
+	return c == '"' || c == '`' || (isQueryWordStart(c) && !queryKeywords[tok])
+}
+
+// queryKeywords - ключевые слова, после которых знак числа является унарным.
+var queryKeywords = map[string]bool{
+	"select": true, "where": true, "and": true, "or": true, "not": true,
+	"then": true, "else": true, "when": true, "return": true, "by": true,
+	"set": true, "values": true, "limit": true, "offset": true, "between": true,
+	"in": true, "is": true, "like": true, "case": true, "having": true, "on": true,
+}
+
+// collapseQueryLists сворачивает списки заполнителей после IN и VALUES.
+func collapseQueryLists(tokens []string) []string {
+	out := tokens[:0]
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		out = append(out, tok)
+		if tok != "in" && tok != "values" {
+			continue
+		}
+
+		// Для VALUES сворачиваются все строки "(...), (...)" подряд.
+		j := i + 1
+		rows := 0
+		for {
+			end, ok := placeholderTuple(tokens, j)
+			if !ok {
+				break
+			}
+			rows++
+			j = end
+			if tok == "in" || j >= len(tokens) || tokens[j] != "," {
+				break
+			}
+			if _, ok := placeholderTuple(tokens, j+1); !ok {
+				break
+			}
+			j++
+		}
+		if rows > 0 {
+			out = append(out, "(", queryList, ")")
+			i = j - 1
+		}
+	}
+	return out
+}
+
+// placeholderTuple проверяет, начинается ли в позиции i список "(?, ?, ...)"
+// из одних заполнителей (а также null и default), и возвращает позицию после него.
+func placeholderTuple(tokens []string, i int) (int, bool) {
+	if i >= len(tokens) || tokens[i] != "(" {
+		return 0, false
+	}
+	expectValue := true
+	for j := i + 1; j < len(tokens); j++ {
+		switch tok := tokens[j]; {
+		case expectValue && (tok == queryPlaceholder || tok == queryList || tok == "null" || tok == "default"):
+			expectValue = false
+		case !expectValue && tok == ",":
+			expectValue = true
+		case !expectValue && tok == ")":
+			return j + 1, true
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// querySpaceBetween определяет, разделяются ли лексемы пробелом в нормализованном запросе.
+func querySpaceBetween(prev, next string) bool {
+	switch next {
+	case ")", ",", ".", ";", "::":
+		return false
+	}
+	switch prev {
+	case "(", ".", "::":
+		return false
+	}
+	return true
+}
+
+// queryOperator возвращает оператор или знак препинания в начале s.
+func queryOperator(s string) string {
+	for _, op := range []string{"->>", "#>>", "<=>", "::", "<=", ">=", "<>", "!=", "||", "->", "#>", "@>", "<@", "&&", "<<", ">>"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return s[:1]
+}
+
+// dollarQuoteTag возвращает открывающий тег строки в долларовых кавычках
+// PostgreSQL ($$ или $tag$), если s начинается с него.
+func dollarQuoteTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1], true
+		case i == 1 && isDigit(c):
+			return "", false
+		case !queryWordByte(c):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// prevByte возвращает байт перед позицией i или 0.
+func prevByte(s string, i int) byte {
+	if i == 0 {
+		return 0
+	}
+	return s[i-1]
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isQueryWordStart сообщает, может ли байт начинать слово запроса.
+// Байты не-ASCII символов считаются частью слова.
+func isQueryWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// queryWordByte сообщает, может ли байт продолжать слово запроса.
+func queryWordByte(c byte) bool {
+	return isQueryWordStart(c) || isDigit(c) || c == '$'
+}
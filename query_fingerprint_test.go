@@ -0,0 +1,83 @@
+package sglogger
+
+import "testing"
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "numeric literal", query: "SELECT * FROM users WHERE id = 42", want: "select * from users where id = ?"},
+		{name: "whitespace and case", query: "select  *\n from users\twhere id=7", want: "select * from users where id = ?"},
+		{name: "keywords inside string", query: "SELECT * FROM t WHERE name = 'DROP TABLE users; -- '", want: "select * from t where name = ?"},
+		{name: "in list", query: "SELECT * FROM t WHERE a IN (1, 2, 3)", want: "select * from t where a in (?+)"},
+		{name: "single element in list", query: "SELECT * FROM t WHERE a IN (1)", want: "select * from t where a in (?+)"},
+		{name: "multi-row values", query: "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')", want: "insert into t (a, b) values (?+)"},
+		{name: "escaped quotes", query: `SELECT 'it''s', 'a\'b' FROM t`, want: "select ?, ? from t"},
+		{name: "dollar quoting", query: "SELECT $tag$ select 1 $tag$, $$x$$", want: "select ?, ?"},
+		{name: "bind parameters", query: "SELECT * FROM t WHERE id = $1 AND name = :name AND x = @p1", want: "select * from t where id = ? and name = ? and x = ?"},
+		{name: "comments and unary minus", query: "SELECT * FROM t /* comment 'quote */ WHERE a = -5 -- trailing", want: "select * from t where a = ?"},
+		{name: "quoted identifiers keep case", query: `SELECT "Mixed".Col FROM "Mixed" WHERE x = E'\n'`, want: `select "Mixed".col from "Mixed" where x = ?`},
+		{name: "json operator and cast", query: "SELECT data->>'key', x::int FROM t", want: "select data ->> ?, x::int from t"},
+		{name: "nested subquery", query: "SELECT * FROM t WHERE a IN (SELECT b FROM u WHERE c IN (1,2))", want: "select * from t where a in (select b from u where c in (?+))"},
+		{name: "unterminated string", query: "SELECT * FROM t WHERE s = 'unterminated", want: "select * from t where s = ?"},
+		{name: "number forms", query: "SELECT 1.5e10, 0x1F, .5", want: "select ?, ?, ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeQuery(tt.query); got != tt.want {
+				t.Errorf("NormalizeQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "spacing and case", a: "SELECT * FROM t WHERE id = 1", b: "select *\n  from T where ID=2", same: true},
+		{name: "list length", a: "SELECT * FROM t WHERE a IN (1, 2)", b: "SELECT * FROM t WHERE a IN (7, 8, 9, 10)", same: true},
+		{name: "comments", a: "SELECT 1 -- first", b: "/* hint */ SELECT 2", same: true},
+		{name: "different column", a: "SELECT a FROM t", b: "SELECT b FROM t"},
+		{name: "string is not an identifier", a: "SELECT * FROM t WHERE a = 'b'", b: "SELECT * FROM t WHERE a = b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := QueryFingerprint(tt.a), QueryFingerprint(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("fingerprints %s and %s: equal = %v, want %v", a, b, a == b, tt.same)
+			}
+		})
+	}
+}
+
+func TestQueryFields(t *testing.T) {
+	const query = "SELECT * FROM t WHERE a = 1"
+
+	tests := []struct {
+		name   string
+		redact bool
+		want   string
+	}{
+		{name: "raw statement", want: query},
+		{name: "redacted statement", redact: true, want: "select * from t where a = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := QueryFields(query, tt.redact)
+			if fields[DBStatementField] != tt.want {
+				t.Errorf("%s = %v, want %q", DBStatementField, fields[DBStatementField], tt.want)
+			}
+			if fields[DBFingerprintField] != QueryFingerprint(query) {
+				t.Errorf("%s = %v, want %s", DBFingerprintField, fields[DBFingerprintField], QueryFingerprint(query))
+			}
+		})
+	}
+}
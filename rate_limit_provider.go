@@ -0,0 +1,296 @@
+package sglogger
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultRateLimitMaxKeys ограничивает количество отслеживаемых ключей по умолчанию.
+const defaultRateLimitMaxKeys = 10000
+
+// Поля сводок об отброшенных записях (см. NewRateLimitProvider).
+const (
+	// RateLimitKeyField содержит ключ, записи которого были отброшены.
+	RateLimitKeyField = "rate_limit_key"
+
+	// RateLimitSuppressedField содержит количество отброшенных записей ключа.
+	RateLimitSuppressedField = "suppressed"
+)
+
+// tokenBucket - корзина токенов: пополняется со скоростью rate в секунду
+// до burst, каждая запись расходует один токен.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill пополняет корзину на момент now.
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	}
+	b.last = now
+}
+
+// rateLimitKey - корзина ключа и количество отброшенных записей ключа,
+// о которых еще не записана сводка.
+type rateLimitKey struct {
+	key        string
+	bucket     tokenBucket
+	suppressed uint64
+}
+
+// rateLimitSummary - сводка об отброшенных записях ключа.
+type rateLimitSummary struct {
+	key        string
+	suppressed uint64
+}
+
+// rateLimitProvider оборачивает LoggerProvider и ограничивает частоту записей
+// по ключам и в целом.
+type rateLimitProvider struct {
+	closedState
+
+	inner    LoggerProvider
+	config   RateLimitConfig
+	mu       sync.Mutex
+	keys     map[string]*list.Element
+	lru      *list.List // *rateLimitKey, недавно использованные в начале
+	global   tokenBucket
+	filtered uint64
+}
+
+// NewRateLimitProvider создает обертку, ограничивающую частоту записей
+// во внутренний провайдер корзинами токенов: отдельной для каждого ключа
+// KeyFunc (например, арендатора) и общей (GlobalRate), чтобы один шумный
+// источник не расходовал весь лимит. Запись проходит, только если токен есть
+// и в корзине ключа, и в общей корзине.
+//
+// Количество отброшенных записей ключа записывается сводкой - записью уровня
+// LevelWarn с полями RateLimitKeyField и RateLimitSuppressedField - перед
+// следующей прошедшей записью ключа, при вытеснении ключа и при Close.
+// Отслеживается не более MaxKeys ключей; при превышении вытесняется ключ,
+// дольше всех не использовавшийся, и его корзина при следующей записи
+// создается заново заполненной. Отброшенные записи учитываются в Stats
+// (см. Filterer).
+//
+// Возвращает ошибку, если внутренний провайдер не задан или не задан ни один лимит.
+func NewRateLimitProvider(inner LoggerProvider, config RateLimitConfig) (LoggerProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("sglogger: rate limit provider requires an inner provider")
+	}
+	if config.Rate < 0 || config.GlobalRate < 0 {
+		return nil, fmt.Errorf("sglogger: invalid rate limit %g/%g", config.Rate, config.GlobalRate)
+	}
+	if config.Rate == 0 && config.GlobalRate == 0 {
+		return nil, fmt.Errorf("sglogger: rate limit provider requires Rate or GlobalRate")
+	}
+
+	config.Burst = defaultBurst(config.Burst, config.Rate)
+	config.GlobalBurst = defaultBurst(config.GlobalBurst, config.GlobalRate)
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaultRateLimitMaxKeys
+	}
+
+	return &rateLimitProvider{
+		inner:  inner,
+		config: config,
+		keys:   make(map[string]*list.Element),
+		lru:    list.New(),
+		global: tokenBucket{tokens: float64(config.GlobalBurst), last: time.Now()},
+	}, nil
+}
+
+// defaultBurst возвращает емкость корзины: заданную или, по умолчанию,
+// количество записей за одну секунду, но не меньше одной.
+func defaultBurst(burst int, rate float64) int {
+	if burst > 0 {
+		return burst
+	}
+	if n := int(math.Ceil(rate)); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// Write передает запись внутреннему провайдеру, если лимиты ее ключа
+// и общий лимит не исчерпаны; иначе отбрасывает запись и возвращает nil.
+func (p *rateLimitProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+
+	key := ""
+	if p.config.KeyFunc != nil {
+		key = p.config.KeyFunc(level, message, fields)
+	}
+
+	allowed, summaries := p.allow(key, time.Now())
+	for _, s := range summaries {
+		p.writeSummary(ctx, s)
+	}
+	if !allowed {
+		atomic.AddUint64(&p.filtered, 1)
+		return nil
+	}
+	return p.inner.Write(ctx, level, message, fields)
+}
+
+// allow расходует токены ключа и общей корзины. Возвращает, прошла ли запись,
+// и сводки, которые нужно записать: ключа, вытесненного из-за MaxKeys,
+// и, если запись прошла, ее собственного ключа.
+func (p *rateLimitProvider) allow(key string, now time.Time) (bool, []rateLimitSummary) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var summaries []rateLimitSummary
+	k := p.lookup(key, now, &summaries)
+
+	keyOK := true
+	if p.config.Rate > 0 {
+		k.bucket.refill(now, p.config.Rate, p.config.Burst)
+		keyOK = k.bucket.tokens >= 1
+	}
+	globalOK := true
+	if p.config.GlobalRate > 0 {
+		p.global.refill(now, p.config.GlobalRate, p.config.GlobalBurst)
+		globalOK = p.global.tokens >= 1
+	}
+
+	if !keyOK || !globalOK {
+		k.suppressed++
+		return false, summaries
+	}
+
+	if p.config.Rate > 0 {
+		k.bucket.tokens--
+	}
+	if p.config.GlobalRate > 0 {
+		p.global.tokens--
+	}
+	if k.suppressed > 0 {
+		summaries = append(summaries, rateLimitSummary{key: key, suppressed: k.suppressed})
+		k.suppressed = 0
+	}
+	return true, summaries
+}
+
+// lookup возвращает состояние ключа, создавая его при необходимости
+// и вытесняя ключ, дольше всех не использовавшийся, при превышении MaxKeys.
+// Сводка вытесненного ключа добавляется в summaries.
+func (p *rateLimitProvider) lookup(key string, now time.Time, summaries *[]rateLimitSummary) *rateLimitKey {
+	if el, ok := p.keys[key]; ok {
+		p.lru.MoveToFront(el)
+		return el.Value.(*rateLimitKey)
+	}
+
+	if p.lru.Len() >= p.config.MaxKeys {
+		oldest := p.lru.Back()
+		evicted := oldest.Value.(*rateLimitKey)
+		p.lru.Remove(oldest)
+		delete(p.keys, evicted.key)
+		if evicted.suppressed > 0 {
+			*summaries = append(*summaries, rateLimitSummary{key: evicted.key, suppressed: evicted.suppressed})
+		}
+	}
+
+	k := &rateLimitKey{
+		key:    key,
+		bucket: tokenBucket{tokens: float64(p.config.Burst), last: now},
+	}
+	p.keys[key] = p.lru.PushFront(k)
+	return k
+}
+
+// writeSummary записывает сводку об отброшенных записях ключа во внутренний
+// провайдер в обход лимитов.
+func (p *rateLimitProvider) writeSummary(ctx context.Context, s rateLimitSummary) {
+	if !p.inner.ShouldLog(ctx, LevelWarn) {
+		return
+	}
+	p.inner.Write(ctx, LevelWarn, fmt.Sprintf("rate limit: %d entries suppressed", s.suppressed), Fields{
+		RateLimitKeyField:        s.key,
+		RateLimitSuppressedField: s.suppressed,
+	})
+}
+
+// Keys возвращает количество отслеживаемых ключей.
+func (p *rateLimitProvider) Keys() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lru.Len()
+}
+
+// Filtered возвращает количество отброшенных записей.
+func (p *rateLimitProvider) Filtered() uint64 {
+	return atomic.LoadUint64(&p.filtered)
+}
+
+// Name возвращает имя из конфигурации или имя внутреннего провайдера.
+func (p *rateLimitProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return ProviderName(p.inner)
+}
+
+// Describe возвращает лимиты и описание внутреннего провайдера (см. Describer).
+func (p *rateLimitProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"rate":         p.config.Rate,
+		"burst":        p.config.Burst,
+		"global_rate":  p.config.GlobalRate,
+		"global_burst": p.config.GlobalBurst,
+		"max_keys":     p.config.MaxKeys,
+		"keyed":        p.config.KeyFunc != nil,
+		"inner":        describeProvider(p.inner),
+	}
+}
+
+// Active делегирует проверку активности внутреннему провайдеру.
+func (p *rateLimitProvider) Active() bool {
+	return providerActive(p.inner)
+}
+
+// ShouldLog делегирует проверку уровня внутреннему провайдеру.
+func (p *rateLimitProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.inner.ShouldLog(ctx, level)
+}
+
+// Flush сбрасывает буферизованный вывод внутреннего провайдера.
+func (p *rateLimitProvider) Flush(ctx context.Context) error {
+	if flusher, ok := p.inner.(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// Close записывает сводки ключей с отброшенными записями и закрывает
+// внутренний провайдер (см. ChainClose).
+func (p *rateLimitProvider) Close(ctx context.Context) error {
+	if !p.markClosed() {
+		return nil
+	}
+	return ChainClose(ctx, p.inner, func(ctx context.Context) error {
+		p.mu.Lock()
+		var summaries []rateLimitSummary
+		for el := p.lru.Back(); el != nil; el = el.Prev() {
+			k := el.Value.(*rateLimitKey)
+			if k.suppressed > 0 {
+				summaries = append(summaries, rateLimitSummary{key: k.key, suppressed: k.suppressed})
+				k.suppressed = 0
+			}
+		}
+		p.mu.Unlock()
+
+		for _, s := range summaries {
+			p.writeSummary(ctx, s)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,179 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// tenantKey выбирает корзину по полю tenant_id.
+func tenantKey(level Level, message string, fields Fields) string {
+	tenant, _ := fields["tenant_id"].(string)
+	return tenant
+}
+
+// rateLimitStep - запись ключа key через offset после начала теста.
+type rateLimitStep struct {
+	key    string
+	offset time.Duration
+	want   bool
+}
+
+func TestRateLimitAllow(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RateLimitConfig
+		steps  []rateLimitStep
+	}{
+		{
+			name:   "per key burst",
+			config: RateLimitConfig{Rate: 1, Burst: 2},
+			steps:  []rateLimitStep{{"a", 0, true}, {"a", 0, true}, {"a", 0, false}, {"b", 0, true}},
+		},
+		{
+			name:   "refill",
+			config: RateLimitConfig{Rate: 2, Burst: 1},
+			steps:  []rateLimitStep{{"a", 0, true}, {"a", 100 * time.Millisecond, false}, {"a", 500 * time.Millisecond, true}},
+		},
+		{
+			name:   "refill capped at burst",
+			config: RateLimitConfig{Rate: 10, Burst: 2},
+			steps:  []rateLimitStep{{"a", time.Hour, true}, {"a", time.Hour, true}, {"a", time.Hour, false}},
+		},
+		{
+			name:   "global ceiling across keys",
+			config: RateLimitConfig{Rate: 10, Burst: 10, GlobalRate: 1, GlobalBurst: 2},
+			steps:  []rateLimitStep{{"a", 0, true}, {"b", 0, true}, {"c", 0, false}, {"c", time.Second, true}},
+		},
+		{
+			name:   "global only",
+			config: RateLimitConfig{GlobalRate: 1, GlobalBurst: 1},
+			steps:  []rateLimitStep{{"a", 0, true}, {"b", 0, false}},
+		},
+		{
+			name:   "suppressed entry spends no global token",
+			config: RateLimitConfig{Rate: 1, Burst: 1, GlobalRate: 1, GlobalBurst: 2},
+			steps:  []rateLimitStep{{"a", 0, true}, {"a", 0, false}, {"b", 0, true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewRateLimitProvider(&recordingProvider{}, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := provider.(*rateLimitProvider)
+
+			start := time.Now()
+			for i, step := range tt.steps {
+				if got, _ := p.allow(step.key, start.Add(step.offset)); got != step.want {
+					t.Errorf("step %d (%s at +%s): allowed = %v, want %v", i, step.key, step.offset, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitSummaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxKeys int
+		writes  []string // арендаторы записей по порядку
+		close   bool
+		want    map[string]uint64
+	}{
+		{name: "on close", writes: []string{"a", "a", "a", "b", "b"}, close: true, want: map[string]uint64{"a": 2, "b": 1}},
+		{name: "on eviction", maxKeys: 1, writes: []string{"a", "a", "b"}, want: map[string]uint64{"a": 1}},
+		{name: "no suppression", writes: []string{"a", "b", "c"}, close: true, want: map[string]uint64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingProvider{}
+			// Корзины практически не пополняются за время теста
+			p, err := NewRateLimitProvider(inner, RateLimitConfig{KeyFunc: tenantKey, Rate: 0.001, Burst: 1, MaxKeys: tt.maxKeys})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tenant := range tt.writes {
+				if err := p.Write(context.Background(), LevelInfo, "request", Fields{"tenant_id": tenant}); err != nil {
+					t.Fatalf("Write = %v", err)
+				}
+			}
+			if tt.close {
+				p.Close(context.Background())
+			}
+
+			got := make(map[string]uint64)
+			for _, e := range inner.Entries() {
+				if e.Level != LevelWarn {
+					continue
+				}
+				got[e.Fields[RateLimitKeyField].(string)] += e.Fields[RateLimitSuppressedField].(uint64)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("summaries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitKeyChurn(t *testing.T) {
+	const (
+		maxKeys = 100
+		keys    = 20000
+	)
+
+	tests := []struct {
+		name           string
+		writesPerKey   int
+		wantSuppressed uint64
+	}{
+		{name: "distinct keys", writesPerKey: 1},
+		{name: "suppressed keys evicted", writesPerKey: 3, wantSuppressed: keys * 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingProvider{}
+			provider, err := NewRateLimitProvider(inner, RateLimitConfig{KeyFunc: tenantKey, Rate: 0.001, Burst: 1, MaxKeys: maxKeys})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := provider.(*rateLimitProvider)
+
+			for i := 0; i < keys; i++ {
+				fields := Fields{"tenant_id": fmt.Sprintf("tenant-%d", i)}
+				for j := 0; j < tt.writesPerKey; j++ {
+					p.Write(context.Background(), LevelInfo, "request", fields)
+				}
+				if n := p.Keys(); n > maxKeys {
+					t.Fatalf("tracking %d keys, want at most %d", n, maxKeys)
+				}
+			}
+			if n := p.Keys(); n != maxKeys {
+				t.Errorf("Keys = %d, want %d", n, maxKeys)
+			}
+			p.Close(context.Background())
+
+			// Каждая отброшенная запись попадает в сводку ровно один раз:
+			// при вытеснении ключа или при Close
+			var written, summarized uint64
+			for _, e := range inner.Entries() {
+				if e.Level == LevelWarn {
+					summarized += e.Fields[RateLimitSuppressedField].(uint64)
+					continue
+				}
+				written++
+			}
+			if written != keys {
+				t.Errorf("written = %d, want %d (one per key)", written, keys)
+			}
+			if summarized != tt.wantSuppressed || p.Filtered() != tt.wantSuppressed {
+				t.Errorf("summarized %d, filtered %d, want %d", summarized, p.Filtered(), tt.wantSuppressed)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package sglogger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// WouldRedactField - имя поля с отсортированными ключами полей, которые
+// Redaction заменил бы в режиме RedactEnforce (см. RedactDryRun).
+const WouldRedactField = "would_redact"
+
+// redactedValue заменяет значения полей в режиме RedactEnforce.
+const redactedValue = "[REDACTED]"
+
+// Redaction заменяет значения полей, перечисленных в правилах RedactionRule,
+// или в режиме RedactDryRun только сообщает о них (см. LoggerConfig.Redaction).
+// Режим можно переключать во время работы логгера, как уровень через SetLevel.
+type Redaction struct {
+	mode   int32
+	rules  []RedactionRule
+	keys   map[string]int // ключ поля -> индекс первого правила с этим ключом
+	counts []uint64
+}
+
+// NewRedaction создает набор правил в режиме mode. Правила применяются после
+// извлечения полей из контекста, правил FieldRules и хуков, до передачи записи
+// провайдерам; проверяются только поля верхнего уровня. Для каждого правила
+// подсчитывается количество совпавших значений в обоих режимах (см. Counts),
+// поэтому режим RedactDryRun позволяет оценить правила до их включения.
+func NewRedaction(mode RedactionMode, rules ...RedactionRule) *Redaction {
+	r := &Redaction{
+		mode:   int32(mode),
+		keys:   make(map[string]int),
+		counts: make([]uint64, len(rules)),
+	}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = strings.Join(rule.Keys, ",")
+		}
+		rule.Keys = append([]string(nil), rule.Keys...)
+		r.rules = append(r.rules, rule)
+		for _, key := range rule.Keys {
+			if _, ok := r.keys[key]; !ok {
+				r.keys[key] = i
+			}
+		}
+	}
+	return r
+}
+
+// SetMode переключает режим. Записи, обрабатываемые в момент переключения,
+// используют старый или новый режим целиком.
+// Возвращает ErrInvalidRedactionMode для неизвестных значений.
+func (r *Redaction) SetMode(mode RedactionMode) error {
+	if !mode.valid() {
+		return fmt.Errorf("%w: %d", ErrInvalidRedactionMode, mode)
+	}
+	atomic.StoreInt32(&r.mode, int32(mode))
+	return nil
+}
+
+// Mode возвращает текущий режим.
+func (r *Redaction) Mode() RedactionMode {
+	return RedactionMode(atomic.LoadInt32(&r.mode))
+}
+
+// Counts возвращает количество значений, совпавших с каждым правилом,
+// по именам правил; значения правил с одинаковыми именами суммируются.
+func (r *Redaction) Counts() map[string]uint64 {
+	counts := make(map[string]uint64, len(r.rules))
+	for i, rule := range r.rules {
+		counts[rule.Name] += atomic.LoadUint64(&r.counts[i])
+	}
+	return counts
+}
+
+// apply возвращает поля записи с учетом правил. Если ни одно поле
+// не совпало, возвращает fields без копирования.
+func (r *Redaction) apply(fields Fields) Fields {
+	if r == nil || len(fields) == 0 {
+		return fields
+	}
+
+	var matched []string
+	for k := range fields {
+		if i, ok := r.keys[k]; ok {
+			atomic.AddUint64(&r.counts[i], 1)
+			matched = append(matched, k)
+		}
+	}
+	if matched == nil {
+		return fields
+	}
+
+	result := make(Fields, len(fields)+1)
+	for k, v := range fields {
+		result[k] = v
+	}
+	if r.Mode() == RedactDryRun {
+		sort.Strings(matched)
+		result[WouldRedactField] = matched
+		return result
+	}
+	for _, k := range matched {
+		result[k] = redactedValue
+	}
+	return result
+}
+
+// valid сообщает, является ли режим одним из объявленных.
+func (m RedactionMode) valid() bool {
+	return m == RedactEnforce || m == RedactDryRun
+}
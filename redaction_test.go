@@ -0,0 +1,105 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	rules := []RedactionRule{
+		{Name: "credentials", Keys: []string{"password", "token"}},
+		{Keys: []string{"authorization"}},
+	}
+
+	tests := []struct {
+		name       string
+		mode       RedactionMode
+		fields     Fields
+		wantFields Fields
+		wantCounts map[string]uint64
+	}{
+		{
+			name:       "no match",
+			fields:     Fields{"user": "bob"},
+			wantFields: Fields{"user": "bob"},
+			wantCounts: map[string]uint64{"credentials": 0, "authorization": 0},
+		},
+		{
+			name:       "enforce",
+			fields:     Fields{"user": "bob", "password": "hunter2", "authorization": "Bearer x"},
+			wantFields: Fields{"user": "bob", "password": "[REDACTED]", "authorization": "[REDACTED]"},
+			wantCounts: map[string]uint64{"credentials": 1, "authorization": 1},
+		},
+		{
+			name:       "dry run",
+			mode:       RedactDryRun,
+			fields:     Fields{"user": "bob", "token": "abc", "password": "hunter2"},
+			wantFields: Fields{"user": "bob", "token": "abc", "password": "hunter2", WouldRedactField: []string{"password", "token"}},
+			wantCounts: map[string]uint64{"credentials": 2, "authorization": 0},
+		},
+		{
+			name:       "nested fields are not checked",
+			fields:     Fields{"request": Fields{"password": "hunter2"}},
+			wantFields: Fields{"request": Fields{"password": "hunter2"}},
+			wantCounts: map[string]uint64{"credentials": 0, "authorization": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingProvider{}
+			redaction := NewRedaction(tt.mode, rules...)
+			logger := NewLogger(LoggerConfig{Redaction: redaction}, NewFieldsHandler(), recorder)
+
+			before := cloneFields(tt.fields)
+			logger.InfoWithFields(context.Background(), tt.fields, "login")
+
+			entries := recorder.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if !reflect.DeepEqual(entries[0].Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", entries[0].Fields, tt.wantFields)
+			}
+			if got := redaction.Counts(); !reflect.DeepEqual(got, tt.wantCounts) {
+				t.Errorf("Counts = %v, want %v", got, tt.wantCounts)
+			}
+			// Поля вызывающего не изменяются
+			if !reflect.DeepEqual(tt.fields, before) {
+				t.Errorf("caller's fields changed to %v", tt.fields)
+			}
+		})
+	}
+}
+
+func TestRedactionSetMode(t *testing.T) {
+	recorder := &recordingProvider{}
+	redaction := NewRedaction(RedactDryRun, RedactionRule{Keys: []string{"password"}})
+	logger := NewLogger(LoggerConfig{Redaction: redaction}, NewFieldsHandler(), recorder)
+
+	logger.InfoWithFields(context.Background(), Fields{"password": "hunter2"}, "login")
+	if err := redaction.SetMode(RedactEnforce); err != nil {
+		t.Fatalf("SetMode = %v", err)
+	}
+	logger.InfoWithFields(context.Background(), Fields{"password": "hunter2"}, "login")
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Fields["password"] != "hunter2" || entries[0].Fields[WouldRedactField] == nil {
+		t.Errorf("dry run entry = %v, want the value intact and %s", entries[0].Fields, WouldRedactField)
+	}
+	if _, ok := entries[1].Fields[WouldRedactField]; ok || entries[1].Fields["password"] != "[REDACTED]" {
+		t.Errorf("enforced entry = %v, want the value replaced", entries[1].Fields)
+	}
+
+	if err := redaction.SetMode(RedactionMode(42)); !errors.Is(err, ErrInvalidRedactionMode) {
+		t.Errorf("SetMode(42) = %v, want ErrInvalidRedactionMode", err)
+	}
+	if mode := redaction.Mode(); mode != RedactEnforce {
+		t.Errorf("Mode = %d after an invalid SetMode, want RedactEnforce", mode)
+	}
+}
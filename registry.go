@@ -0,0 +1,72 @@
+package sglogger
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// registeredProvider связывает провайдер логгера с присвоенным ему именем и счетчиками.
+type registeredProvider struct {
+	name     string
+	provider LoggerProvider
+	stats    *providerStats
+	ordered  *orderedProvider // провайдер, которому логгер назначает порядковые номера записей
+}
+
+// ProviderName возвращает имя провайдера: результат Name() для провайдеров,
+// реализующих Named, или имя типа провайдера в остальных случаях.
+func ProviderName(provider LoggerProvider) string {
+	if named, ok := provider.(Named); ok {
+		if name := named.Name(); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", provider)
+}
+
+// providerActive сообщает, активен ли провайдер (см. Gated).
+func providerActive(provider LoggerProvider) bool {
+	gated, ok := provider.(Gated)
+	return !ok || gated.Active()
+}
+
+// registerProviders присваивает провайдерам уникальные имена в порядке регистрации.
+func registerProviders(existing []registeredProvider, providers ...LoggerProvider) []registeredProvider {
+	result := make([]registeredProvider, len(existing), len(existing)+len(providers))
+	copy(result, existing)
+
+	for _, provider := range providers {
+		result = append(result, registeredProvider{
+			name:     uniqueProviderName(result, ProviderName(provider)),
+			provider: provider,
+			stats:    &providerStats{},
+		})
+		if ordered, ok := provider.(*orderedProvider); ok {
+			result[len(result)-1].ordered = ordered
+		}
+	}
+	return result
+}
+
+// uniqueProviderName добавляет к имени числовой суффикс ("file-2", "file-3"),
+// если провайдер с таким именем уже зарегистрирован.
+func uniqueProviderName(registered []registeredProvider, name string) string {
+	taken := func(candidate string) bool {
+		for _, r := range registered {
+			if r.name == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + "-" + strconv.Itoa(i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package sglogger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Reopener определяет интерфейс провайдеров, умеющих переоткрывать свой файл назначения.
+// Используется для совместной работы с logrotate и аналогичными утилитами,
+// которые перемещают файл лога и ожидают, что процесс откроет его заново.
+type Reopener interface {
+	// Reopen закрывает текущий файл и открывает заново настроенный путь,
+	// создавая файл при его отсутствии.
+	Reopen() error
+}
+
+// ReopenOnSignal вызывает Reopen у провайдера при получении любого из указанных сигналов.
+// Если сигналы не переданы, используются сигналы по умолчанию для платформы
+// (SIGUSR1 и SIGHUP на Unix-системах). Ошибки переоткрытия передаются в onError,
+// если он задан. Возвращает функцию, прекращающую обработку сигналов.
+func ReopenOnSignal(r Reopener, onError func(error), signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = defaultReopenSignals
+	}
+	if len(signals) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := r.Reopen(); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
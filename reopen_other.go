@@ -0,0 +1,9 @@
+//go:build !unix
+
+package sglogger
+
+import "os"
+
+// defaultReopenSignals пуст на платформах без сигналов SIGUSR1/SIGHUP:
+// сигналы для ReopenOnSignal необходимо передавать явно.
+var defaultReopenSignals []os.Signal
@@ -0,0 +1,133 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileProviderReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	provider, err := NewFileProvider(FileProviderConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer provider.Close(context.Background())
+
+	ctx := context.Background()
+	if err := provider.Write(ctx, LevelInfo, "before rotation", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// logrotate перемещает файл и просит процесс открыть его заново
+	rotated := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.(Reopener).Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	if err := provider.Write(ctx, LevelInfo, "after rotation", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	for name, want := range map[string]string{rotated: "before rotation", path: "after rotation"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 1 || !strings.Contains(string(data), want) {
+			t.Errorf("%s = %q, want one line with %q", filepath.Base(name), data, want)
+		}
+	}
+
+	provider.Close(ctx)
+	if err := provider.(Reopener).Reopen(); err != ErrProviderClosed {
+		t.Errorf("Reopen after Close = %v, want ErrProviderClosed", err)
+	}
+}
+
+func TestFileProviderReopenConcurrentWrites(t *testing.T) {
+	const (
+		writers = 8
+		writes  = 200
+	)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	provider, err := NewFileProvider(FileProviderConfig{
+		ProviderConfig: ProviderConfig{BufferSize: 512},
+		Path:           path,
+	})
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	stop := make(chan struct{})
+	rotations := make(chan error, 1)
+	go func() {
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				rotations <- nil
+				return
+			default:
+			}
+			if err := os.Rename(path, fmt.Sprintf("%s.%d", path, i)); err != nil {
+				rotations <- err
+				return
+			}
+			if err := provider.(Reopener).Reopen(); err != nil {
+				rotations <- err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				if err := provider.Write(ctx, LevelInfo, fmt.Sprintf("writer %d entry %d", w, i), nil); err != nil {
+					t.Errorf("Write: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(stop)
+	if err := <-rotations; err != nil {
+		t.Fatalf("rotation: %v", err)
+	}
+	if err := provider.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Ни одна запись не потеряна и не разорвана между файлами
+	files, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []byte
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			t.Errorf("%s ends with a partial line", filepath.Base(name))
+		}
+		all = append(all, data...)
+	}
+	if lines := bytes.Count(all, []byte("\n")); lines != writers*writes {
+		t.Errorf("%d lines in %d files, want %d", lines, len(files), writers*writes)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package sglogger
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReopenSignals содержит сигналы, которые logrotate и аналогичные утилиты
+// традиционно используют для запроса переоткрытия файлов логов.
+var defaultReopenSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGHUP}
+
+// Сигналы переоткрытия доступны только на Unix (см. Features).
+func init() {
+	RegisterFeature("reopen_signals")
+}
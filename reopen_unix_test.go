@@ -0,0 +1,40 @@
+//go:build unix
+
+package sglogger
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// reopenFunc реализует Reopener функцией.
+type reopenFunc func() error
+
+func (f reopenFunc) Reopen() error { return f() }
+
+func TestReopenOnSignal(t *testing.T) {
+	reopened := make(chan struct{}, 1)
+	failed := errors.New("reopen failed")
+	errs := make(chan error, 1)
+	stop := ReopenOnSignal(reopenFunc(func() error {
+		reopened <- struct{}{}
+		return failed
+	}), func(err error) { errs <- err }, syscall.SIGUSR1)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reopened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reopen was not called on SIGUSR1")
+	}
+	if err := <-errs; err != failed {
+		t.Errorf("onError received %v, want %v", err, failed)
+	}
+
+	stop()
+	stop()
+}
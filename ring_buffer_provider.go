@@ -0,0 +1,191 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultRingBufferSize задает количество хранимых записей по умолчанию.
+const defaultRingBufferSize = 1000
+
+// RingBufferProvider хранит последние записи в памяти и передает новые записи
+// подписчикам (см. TailHandler).
+type RingBufferProvider struct {
+	closedState
+
+	config      RingBufferConfig
+	entries     []Entry
+	next        int
+	full        bool
+	subscribers map[*ringSubscriber]struct{}
+	mu          sync.Mutex
+}
+
+// ringSubscriber получает новые записи через канал ограниченного размера.
+// Записи, не поместившиеся в канал, отбрасываются и учитываются в dropped.
+type ringSubscriber struct {
+	ch      chan Entry
+	dropped uint64
+}
+
+// NewRingBufferProvider создает провайдер, хранящий config.Size последних записей.
+// В отличие от других конструкторов возвращает конкретный тип, так как
+// он нужен TailHandler. Записи копируются (см. Entry.Clone), поэтому
+// последующее изменение полей вызывающим на них не влияет.
+// Возвращает ошибку, если размер отрицателен.
+func NewRingBufferProvider(config RingBufferConfig) (*RingBufferProvider, error) {
+	if config.Size < 0 {
+		return nil, fmt.Errorf("sglogger: negative ring buffer size %d", config.Size)
+	}
+	if config.Size == 0 {
+		config.Size = defaultRingBufferSize
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+
+	return &RingBufferProvider{
+		config:      config,
+		entries:     make([]Entry, config.Size),
+		subscribers: make(map[*ringSubscriber]struct{}),
+	}, nil
+}
+
+// Write сохраняет запись и передает ее подписчикам без ожидания.
+func (p *RingBufferProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+	e := Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  cloneFields(fields),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	p.entries[p.next] = e
+	p.next++
+	if p.next == len(p.entries) {
+		p.next = 0
+		p.full = true
+	}
+
+	for sub := range p.subscribers {
+		select {
+		case sub.ch <- e:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	}
+	return nil
+}
+
+// Recent возвращает сохраненные записи от старых к новым.
+func (p *RingBufferProvider) Recent() []Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.recentLocked()
+}
+
+// recentLocked возвращает копию сохраненных записей. Вызывается с захваченным мьютексом.
+func (p *RingBufferProvider) recentLocked() []Entry {
+	if !p.full {
+		return append([]Entry(nil), p.entries[:p.next]...)
+	}
+	result := make([]Entry, 0, len(p.entries))
+	result = append(result, p.entries[p.next:]...)
+	return append(result, p.entries[:p.next]...)
+}
+
+// subscribe возвращает сохраненные записи и подписку на новые записи.
+// Снимок и подписка создаются атомарно, поэтому записи не теряются
+// и не повторяются. Для закрытого провайдера канал подписки закрыт.
+func (p *RingBufferProvider) subscribe(buffer int) ([]Entry, *ringSubscriber) {
+	sub := &ringSubscriber{ch: make(chan Entry, buffer)}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() {
+		close(sub.ch)
+	} else {
+		p.subscribers[sub] = struct{}{}
+	}
+	return p.recentLocked(), sub
+}
+
+// unsubscribe прекращает передачу записей подписчику.
+func (p *RingBufferProvider) unsubscribe(sub *ringSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.subscribers[sub]; ok {
+		delete(p.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// Name возвращает имя провайдера из конфигурации или "ring" по умолчанию.
+func (p *RingBufferProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "ring"
+}
+
+// Describe возвращает уровень и емкость буфера (см. Describer).
+func (p *RingBufferProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"level": p.config.Level.String(),
+		"size":  len(p.entries),
+	}
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *RingBufferProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *RingBufferProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Close прекращает прием записей и завершает подписки.
+// Сохраненные записи остаются доступны через Recent.
+func (p *RingBufferProvider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.markClosed() {
+		return nil
+	}
+	for sub := range p.subscribers {
+		delete(p.subscribers, sub)
+		close(sub.ch)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sglogger
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// Имена полей метаданных сборки и окружения по умолчанию.
+// Могут быть переименованы через RuntimeEnrichment.Keys.
+const (
+	GoVersionField    = "go_version"
+	VCSRevisionField  = "vcs.revision"
+	VCSTimeField      = "vcs.time"
+	HostnameField     = "hostname"
+	K8sPodField       = "k8s.pod"
+	K8sNamespaceField = "k8s.namespace"
+)
+
+// Переменные окружения, в которые Kubernetes downward API обычно передает
+// имя пода и пространства имен.
+const (
+	podNameEnv      = "POD_NAME"
+	podNamespaceEnv = "POD_NAMESPACE"
+)
+
+// runtimeFields собирает метаданные сборки и окружения согласно настройкам.
+// Вызывается один раз при создании логгера; результат добавляется
+// ко всем сообщениям как статические поля.
+func runtimeFields(config *RuntimeEnrichment) Fields {
+	fields := make(Fields)
+	if config == nil {
+		return fields
+	}
+
+	set := func(key string, value string) {
+		if value == "" {
+			return
+		}
+		if renamed, ok := config.Keys[key]; ok {
+			key = renamed
+		}
+		fields[key] = value
+	}
+
+	if config.GoVersion {
+		set(GoVersionField, runtime.Version())
+	}
+
+	if config.VCS {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					set(VCSRevisionField, setting.Value)
+				case "vcs.time":
+					set(VCSTimeField, setting.Value)
+				}
+			}
+		}
+	}
+
+	if config.Hostname {
+		if hostname, err := os.Hostname(); err == nil {
+			set(HostnameField, hostname)
+		}
+	}
+
+	if config.Kubernetes {
+		set(K8sPodField, os.Getenv(podNameEnv))
+		set(K8sNamespaceField, os.Getenv(podNamespaceEnv))
+	}
+
+	return fields
+}
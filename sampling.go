@@ -0,0 +1,94 @@
+package sglogger
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSamplingTick задает длительность окна подсчета по умолчанию.
+	defaultSamplingTick = time.Second
+
+	// defaultSamplingMaxKeys ограничивает число различных сообщений,
+	// отслеживаемых в одном окне.
+	defaultSamplingMaxKeys = 1024
+)
+
+// SampleRateField - имя поля с эффективной частотой семплирования записи:
+// значение N означает, что записана одна из N подобных записей. Поле добавляется
+// только к записям, прошедшим семплирование с частотой больше 1, чтобы получатели
+// (например, Honeycomb) могли восстановить исходное количество событий.
+const SampleRateField = "sample_rate"
+
+// samplingKey идентифицирует счетчик сообщений одного уровня и шаблона.
+type samplingKey struct {
+	level    Level
+	template string
+}
+
+// sampler реализует семплирование "первые N, затем каждое M-е" для повторяющихся
+// сообщений в пределах окна. Сообщения группируются по уровню и шаблону (строке формата),
+// поэтому "order %d failed" с разными аргументами считается одним сообщением.
+type sampler struct {
+	mu        sync.Mutex
+	config    SamplingConfig
+	windowEnd time.Time
+	counts    map[samplingKey]int
+}
+
+// newSampler создает семплер по конфигурации. Возвращает nil, если семплирование не настроено.
+func newSampler(config *SamplingConfig) *sampler {
+	if config == nil || len(config.Levels) == 0 {
+		return nil
+	}
+
+	s := &sampler{
+		config: *config,
+		counts: make(map[samplingKey]int),
+	}
+	if s.config.Tick <= 0 {
+		s.config.Tick = defaultSamplingTick
+	}
+	if s.config.MaxKeys <= 0 {
+		s.config.MaxKeys = defaultSamplingMaxKeys
+	}
+	return s
+}
+
+// allow определяет, нужно ли записать очередное сообщение с указанным уровнем и шаблоном,
+// и возвращает эффективную частоту семплирования записанного сообщения (1 - без семплирования).
+// Счетчики сбрасываются в начале каждого окна. Когда число отслеживаемых шаблонов
+// достигает MaxKeys, новые шаблоны окна используют общий счетчик.
+func (s *sampler) allow(level Level, template string) (bool, int) {
+	rule, ok := s.config.Levels[level]
+	if !ok {
+		return true, 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(s.windowEnd) {
+		for k := range s.counts {
+			delete(s.counts, k)
+		}
+		s.windowEnd = now.Add(s.config.Tick)
+	}
+
+	key := samplingKey{level: level, template: template}
+	if _, tracked := s.counts[key]; !tracked && len(s.counts) >= s.config.MaxKeys {
+		key.template = ""
+	}
+
+	s.counts[key]++
+	n := s.counts[key]
+
+	if n <= rule.First {
+		return true, 1
+	}
+	if rule.Thereafter <= 0 {
+		return false, 0
+	}
+	return (n-rule.First)%rule.Thereafter == 0, rule.Thereafter
+}
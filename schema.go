@@ -0,0 +1,181 @@
+package sglogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FieldType описывает тип значения поля в JSON Schema.
+type FieldType string
+
+const (
+	FieldTypeString  FieldType = "string"
+	FieldTypeInteger FieldType = "integer"
+	FieldTypeNumber  FieldType = "number"
+	FieldTypeBoolean FieldType = "boolean"
+	FieldTypeObject  FieldType = "object"
+	FieldTypeArray   FieldType = "array"
+)
+
+// FieldSchema описывает зарегистрированное поле записи.
+type FieldSchema struct {
+	Name        string
+	Type        FieldType
+	Description string
+}
+
+// fieldRegistry хранит описания полей, объявленных через RegisterField.
+var fieldRegistry = struct {
+	mu     sync.RWMutex
+	fields map[string]FieldSchema
+}{fields: make(map[string]FieldSchema)}
+
+// Поля, которые добавляет сам пакет.
+func init() {
+	for _, f := range []FieldSchema{
+		{"error", FieldTypeString, "Error text passed to the *Err methods"},
+		{"trace_id", FieldTypeString, "Trace identifier from the context"},
+		{defaultTenantField, FieldTypeString, "Tenant from the context"},
+		{FingerprintField, FieldTypeString, "Message template fingerprint for error grouping"},
+		{DedupKeyField, FieldTypeString, "Entry key for idempotent downstream processing"},
+		{SampleRateField, FieldTypeInteger, "Effective sample rate of the entry"},
+		{GoroutineIDField, FieldTypeInteger, "Identifier of the logging goroutine"},
+		{FieldEncodeErrorField, FieldTypeString, "Fields that could not be encoded and why"},
+		{OversizeDroppedField, FieldTypeArray, "Fields removed so the entry fits in a datagram"},
+		{WouldRedactField, FieldTypeArray, "Fields that redaction would replace, in dry-run mode"},
+		{FailoverProbeField, FieldTypeBoolean, "Entry written by the failover provider to probe its primary"},
+		{EncodeErrorField, FieldTypeString, "Why the entry could not be encoded and was reduced to ts, level and msg"},
+		{CtxDeadlineField, FieldTypeString, "Context deadline (RFC 3339)"},
+		{CtxRemainingField, FieldTypeString, "Time left until the context deadline at log time"},
+		{CtxErrField, FieldTypeString, "Context error at log time"},
+		{GoVersionField, FieldTypeString, "Go version of the binary"},
+		{VCSRevisionField, FieldTypeString, "VCS revision of the binary"},
+		{VCSTimeField, FieldTypeString, "VCS commit time of the binary"},
+		{HostnameField, FieldTypeString, "Host name"},
+		{K8sPodField, FieldTypeString, "Kubernetes pod name"},
+		{K8sNamespaceField, FieldTypeString, "Kubernetes namespace"},
+		{HTTPMethodField, FieldTypeString, "HTTP request method"},
+		{HTTPPathField, FieldTypeString, "HTTP request path"},
+		{HTTPUserAgentField, FieldTypeString, "HTTP client User-Agent"},
+		{HTTPRemoteIPField, FieldTypeString, "HTTP client address, X-Forwarded-For from trusted proxies only"},
+		{HTTPStatusCodeField, FieldTypeInteger, "HTTP response status code"},
+		{HTTPResponseSizeField, FieldTypeInteger, "HTTP response body size in bytes"},
+		{HTTPDurationField, FieldTypeNumber, "HTTP request duration in milliseconds"},
+		{DBStatementField, FieldTypeString, "Database query text, normalized when redacted"},
+		{DBFingerprintField, FieldTypeString, "Database query fingerprint"},
+		{OpIDField, FieldTypeString, "Operation identifier shared by its start and completion entries"},
+		{OpNameField, FieldTypeString, "Operation name"},
+		{OpElapsedField, FieldTypeNumber, "Operation duration"},
+		{OpPanicField, FieldTypeString, "Panic value that interrupted the operation"},
+		{OriginalTimeField, FieldTypeString, "Original entry time replaced because of clock skew adjustment (RFC 3339)"},
+		{FormatErrorField, FieldTypeBoolean, "Set when the formatted message contains fmt error markers"},
+		{RateLimitKeyField, FieldTypeString, "Rate limit key of suppressed entries"},
+		{RateLimitSuppressedField, FieldTypeInteger, "Entries suppressed by the rate limit since the previous summary"},
+		{OrderGapField, FieldTypeInteger, "Entries skipped in the sequence of an ordered provider"},
+		{CanonicalDroppedField, FieldTypeInteger, "Canonical line fields dropped over the size cap"},
+	} {
+		fieldRegistry.fields[f.Name] = f
+	}
+}
+
+// RegisterField объявляет поле записи для ExportSchema и строгого режима
+// (LoggerConfig.StrictFields). Повторная регистрация с тем же типом обновляет
+// описание; регистрация с другим типом возвращает ошибку.
+func RegisterField(name string, typ FieldType, description string) error {
+	if name == "" {
+		return fmt.Errorf("sglogger: field name is empty")
+	}
+	switch typ {
+	case FieldTypeString, FieldTypeInteger, FieldTypeNumber, FieldTypeBoolean, FieldTypeObject, FieldTypeArray:
+	default:
+		return fmt.Errorf("sglogger: invalid type %q for field %q", typ, name)
+	}
+
+	fieldRegistry.mu.Lock()
+	defer fieldRegistry.mu.Unlock()
+
+	if existing, ok := fieldRegistry.fields[name]; ok && existing.Type != typ {
+		return fmt.Errorf("sglogger: field %q is already registered as %s", name, existing.Type)
+	}
+	fieldRegistry.fields[name] = FieldSchema{Name: name, Type: typ, Description: description}
+	return nil
+}
+
+// RegisteredFields возвращает зарегистрированные поля, отсортированные по имени.
+func RegisteredFields() []FieldSchema {
+	fieldRegistry.mu.RLock()
+	defer fieldRegistry.mu.RUnlock()
+
+	result := make([]FieldSchema, 0, len(fieldRegistry.fields))
+	for _, f := range fieldRegistry.fields {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// fieldRegistered сообщает, зарегистрировано ли поле. Поля pprof-меток
+// (см. LoggerConfig.GoroutineInfo) считаются зарегистрированными.
+func fieldRegistered(name string) bool {
+	if strings.HasPrefix(name, pprofLabelPrefix) {
+		return true
+	}
+	fieldRegistry.mu.RLock()
+	_, ok := fieldRegistry.fields[name]
+	fieldRegistry.mu.RUnlock()
+	return ok
+}
+
+// ExportSchema возвращает JSON Schema записи в формате NewJSONFormatter:
+// обязательные ключи ts, level и msg и зарегистрированные поля.
+// Незарегистрированные поля допускаются схемой.
+func ExportSchema() ([]byte, error) {
+	levels := make([]string, 0, LevelFatal-LevelDebug+1)
+	for level := LevelDebug; level <= LevelFatal; level++ {
+		levels = append(levels, level.String())
+	}
+
+	properties := map[string]interface{}{
+		"ts":    map[string]interface{}{"type": "string", "format": "date-time", "description": "Entry time"},
+		"level": map[string]interface{}{"type": "string", "enum": levels, "description": "Entry level"},
+		"msg":   map[string]interface{}{"type": "string", "description": "Message"},
+	}
+	for _, f := range RegisteredFields() {
+		property := map[string]interface{}{"type": string(f.Type)}
+		if f.Description != "" {
+			property["description"] = f.Description
+		}
+		properties[f.Name] = property
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "sglogger entry",
+		"type":                 "object",
+		"required":             []string{"ts", "level", "msg"},
+		"properties":           properties,
+		"additionalProperties": true,
+	}, "", "  ")
+}
+
+// fieldWarner предупреждает через диагностический канал логгера об использовании
+// незарегистрированных полей, не более одного раза для каждого ключа.
+type fieldWarner struct {
+	diagnostics *diagnostics
+	warned      sync.Map
+}
+
+// check проверяет ключи полей записи.
+func (w *fieldWarner) check(fields Fields) {
+	for k := range fields {
+		if fieldRegistered(k) {
+			continue
+		}
+		if _, loaded := w.warned.LoadOrStore(k, struct{}{}); !loaded {
+			w.diagnostics.reportf("strict fields", "field %q is not registered, see RegisterField", k)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultSegmentMaxBytes задает размер сегмента по умолчанию.
+	defaultSegmentMaxBytes = 8 << 20
+
+	// defaultMaxSegments задает количество хранимых сегментов по умолчанию.
+	defaultMaxSegments = 16
+
+	segmentPrefix = "segment-"
+	segmentSuffix = ".ndjson"
+)
+
+// segmentProvider записывает записи в формате NDJSON в последовательность
+// файлов-сегментов ограниченного размера, которые затем выгружает другой процесс.
+// Имена сегментов "segment-<номер>.ndjson" уникальны и при сортировке
+// по имени упорядочены по времени создания, в том числе между перезапусками.
+type segmentProvider struct {
+	closedState
+
+	config    SegmentConfig
+	formatter Formatter
+	segments  []string
+	active    *os.File
+	size      int64
+	nextSeq   uint64
+	mu        sync.Mutex
+	leakCheck *leakCheck
+	sizes     sizeHistogram
+	chain     *logChain
+}
+
+// NewSegmentProvider создает провайдер, пишущий NDJSON в сегменты в каталоге config.Dir.
+// Сегмент завершается, когда следующая запись превысила бы MaxBytes (запись
+// больше MaxBytes занимает отдельный сегмент); при превышении MaxSegments
+// удаляются самые старые сегменты. Строки не разделяются между сегментами.
+//
+// При запуске оборванная последняя строка сегмента, оставшегося от предыдущего
+// запуска, отбрасывается, а сам сегмент считается завершенным.
+// При HashChain строки образуют цепочку хешей (см. VerifyLogChain), которая
+// продолжается в следующих сегментах и после перезапуска.
+// Возвращает ошибку, если каталог недоступен.
+func NewSegmentProvider(config SegmentConfig) (LoggerProvider, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("sglogger: segment directory is not set")
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultSegmentMaxBytes
+	}
+	if config.MaxSegments <= 0 {
+		config.MaxSegments = defaultMaxSegments
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("sglogger: create segment directory: %w", err)
+	}
+
+	p := &segmentProvider{
+		config:    config,
+		formatter: NewJSONFormatter(config.ProviderConfig),
+	}
+	if config.HashChain {
+		p.formatter = newJSONFormatter(config.ProviderConfig, ChainSeqField, ChainField, ChainPrevField)
+	}
+	recovered, err := p.loadSegments()
+	if err != nil {
+		return nil, err
+	}
+	if config.HashChain {
+		var last string
+		if len(p.segments) > 0 {
+			last = p.segments[len(p.segments)-1]
+		}
+		if p.chain, err = openLogChain(config.Dir, last); err != nil {
+			return nil, err
+		}
+	}
+	p.notify(recovered)
+	p.leakCheck = newLeakCheck(config.LeakCheck, p, "segment provider "+config.Dir, newDiagnostics(config.Diagnostics))
+	return p, nil
+}
+
+// Write дописывает запись в текущий сегмент, при необходимости начиная новый.
+func (p *segmentProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+
+	bp, line := acquireLineBuffer()
+	line = p.formatter.AppendFormat(line, Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+	defer func() { releaseLineBuffer(bp, line) }()
+
+	p.mu.Lock()
+	if p.isClosed() {
+		p.mu.Unlock()
+		return ErrProviderClosed
+	}
+
+	size := int64(len(line))
+	if p.chain != nil {
+		size += int64(chainRecordOverhead)
+	}
+	var completed string
+	if p.active != nil && p.size > 0 && p.size+size > p.config.MaxBytes {
+		completed = p.active.Name()
+		if err := p.closeActiveLocked(); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+	}
+
+	var err error
+	if p.chain != nil {
+		err = p.writeChainedLocked(&line)
+	} else {
+		err = p.writeLocked(line)
+	}
+	p.mu.Unlock()
+
+	p.notify(completed)
+	return err
+}
+
+// writeChainedLocked дополняет строку полями цепочки хешей, записывает ее
+// и сохраняет новое звено. Вызывается с захваченным мьютексом.
+func (p *segmentProvider) writeChainedLocked(line *[]byte) error {
+	if p.active == nil {
+		if err := p.openSegmentLocked(); err != nil {
+			return err
+		}
+	}
+	*line = p.chain.seal(*line, p.size == 0)
+	if err := p.writeLocked(*line); err != nil {
+		return err
+	}
+	return p.chain.persist()
+}
+
+// Name возвращает имя провайдера из конфигурации или "segment" по умолчанию.
+func (p *segmentProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "segment"
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *segmentProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *segmentProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Close закрывает текущий сегмент и сообщает о его завершении.
+func (p *segmentProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	p.mu.Lock()
+	if !p.markClosed() {
+		p.mu.Unlock()
+		return nil
+	}
+
+	var completed string
+	var err error
+	if p.active != nil {
+		completed = p.active.Name()
+		err = p.closeActiveLocked()
+	}
+	if p.chain != nil {
+		if chainErr := p.chain.close(); err == nil {
+			err = chainErr
+		}
+	}
+	p.mu.Unlock()
+
+	p.notify(completed)
+	return err
+}
+
+// writeLocked записывает строку в текущий сегмент, открывая новый при необходимости.
+// Вызывается с захваченным мьютексом.
+func (p *segmentProvider) writeLocked(line []byte) error {
+	if p.active == nil {
+		if err := p.openSegmentLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := p.active.Write(line)
+	p.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("sglogger: write segment: %w", err)
+	}
+	p.sizes.observe(len(line))
+	return nil
+}
+
+// EntrySizes возвращает гистограмму размеров записанных строк.
+func (p *segmentProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// openSegmentLocked создает новый сегмент и удаляет самые старые сегменты
+// сверх MaxSegments. Вызывается с захваченным мьютексом.
+func (p *segmentProvider) openSegmentLocked() error {
+	path := filepath.Join(p.config.Dir, fmt.Sprintf("%s%020d%s", segmentPrefix, p.nextSeq, segmentSuffix))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("sglogger: create segment: %w", err)
+	}
+
+	p.nextSeq++
+	p.active = file
+	p.size = 0
+	p.segments = append(p.segments, path)
+
+	for len(p.segments) > p.config.MaxSegments {
+		os.Remove(p.segments[0])
+		p.segments = p.segments[1:]
+	}
+	return nil
+}
+
+// closeActiveLocked закрывает текущий сегмент. Вызывается с захваченным мьютексом.
+func (p *segmentProvider) closeActiveLocked() error {
+	err := p.active.Close()
+	p.active = nil
+	p.size = 0
+	if err != nil {
+		return fmt.Errorf("sglogger: close segment: %w", err)
+	}
+	return nil
+}
+
+// notify вызывает OnSegmentComplete для завершенного сегмента, если он задан.
+func (p *segmentProvider) notify(path string) {
+	if path != "" && p.config.OnSegmentComplete != nil {
+		p.config.OnSegmentComplete(path)
+	}
+}
+
+// loadSegments находит сегменты, оставшиеся от предыдущего запуска,
+// и отбрасывает оборванную последнюю строку в самом новом из них.
+// Возвращает путь самого нового сегмента, если он не пуст: предыдущий запуск
+// не успел сообщить о его завершении.
+func (p *segmentProvider) loadSegments() (string, error) {
+	matches, err := filepath.Glob(filepath.Join(p.config.Dir, segmentPrefix+"*"+segmentSuffix))
+	if err != nil {
+		return "", fmt.Errorf("sglogger: list segments: %w", err)
+	}
+	sort.Strings(matches)
+
+	var recovered string
+	for i, path := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), segmentPrefix), segmentSuffix)
+		seq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq >= p.nextSeq {
+			p.nextSeq = seq + 1
+		}
+
+		if i == len(matches)-1 {
+			if err := truncatePartialRecord(path); err != nil {
+				return "", err
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return "", fmt.Errorf("sglogger: stat segment: %w", err)
+			}
+			if info.Size() == 0 {
+				os.Remove(path)
+				continue
+			}
+			recovered = path
+		}
+		p.segments = append(p.segments, path)
+	}
+	return recovered, nil
+}
@@ -0,0 +1,414 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSentryFlushTimeout ограничивает Close и сброс перед Fatal.
+	defaultSentryFlushTimeout = 2 * time.Second
+
+	// Ограничения Sentry на теги: длиннее ключи отклоняются, значения обрезаются.
+	sentryMaxTagKey   = 32
+	sentryMaxTagValue = 200
+)
+
+// SentryOption настраивает NewSentryProvider. Кроме функций With* подходит
+// любая функция func(*SentryConfig), например для HTTP или Batch.
+type SentryOption func(*SentryConfig)
+
+// WithSentryLevel задает минимальный уровень событий вместо LevelError.
+func WithSentryLevel(level Level) SentryOption {
+	return func(c *SentryConfig) {
+		c.Level = level
+	}
+}
+
+// WithSentryEnvironment задает окружение событий (production, staging).
+func WithSentryEnvironment(environment string) SentryOption {
+	return func(c *SentryConfig) {
+		c.Environment = environment
+	}
+}
+
+// WithSentryRelease задает версию приложения, с которой связываются события.
+func WithSentryRelease(release string) SentryOption {
+	return func(c *SentryConfig) {
+		c.Release = release
+	}
+}
+
+// WithSentryTagFields задает поля, которые передаются тегами события;
+// остальные поля передаются в extra.
+func WithSentryTagFields(keys ...string) SentryOption {
+	return func(c *SentryConfig) {
+		c.TagFields = append(c.TagFields, keys...)
+	}
+}
+
+// WithSentryFlushTimeout задает, сколько Close и сброс перед Fatal ждут
+// отправки событий из очереди.
+func WithSentryFlushTimeout(timeout time.Duration) SentryOption {
+	return func(c *SentryConfig) {
+		c.FlushTimeout = timeout
+	}
+}
+
+// sentryProvider отправляет записи в Sentry как события через envelope API
+// (POST /api/<project>/envelope/), по одному событию на запрос.
+type sentryProvider struct {
+	closedState
+
+	config      SentryConfig
+	client      *http.Client
+	url         string
+	auth        string
+	batcher     *httpBatcher
+	diagnostics *diagnostics
+	sizes       sizeHistogram
+	leakCheck   *leakCheck
+}
+
+// NewSentryProvider создает провайдер событий Sentry с DSN вида
+// https://<key>@<host>/<project>. По умолчанию отправляются только записи
+// уровня LevelError и выше, независимо от уровня логгера.
+//
+// Поле "error" (его добавляют ErrorErr и FatalErr) становится исключением
+// события, поле FingerprintField - отпечатком группировки, поля из TagFields -
+// тегами (значения приводятся к строке и обрезаются до 200 байт), остальные
+// поля - данными extra. События отправляются в фоне (см. HTTPBatchConfig) с
+// повторами; потерянные события учитываются в DroppedEntries (см. DropRecorder).
+// Close и сброс буферов перед завершением Fatal ждут отправки очереди не дольше
+// FlushTimeout. Возвращает ошибку, если DSN некорректен.
+func NewSentryProvider(dsn string, opts ...SentryOption) (LoggerProvider, error) {
+	endpoint, auth, err := parseSentryDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	config := SentryConfig{ProviderConfig: ProviderConfig{Level: LevelError}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Batch.validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.TagFields = append([]string(nil), config.TagFields...)
+	config.Level = clampLevel(config.Level)
+	if config.ServerName == "" {
+		config.ServerName, _ = os.Hostname()
+	}
+	if config.FlushTimeout <= 0 {
+		config.FlushTimeout = defaultSentryFlushTimeout
+	}
+	if config.Batch.BatchSize <= 0 {
+		config.Batch.BatchSize = 1
+	}
+
+	client, err := NewHTTPClient(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &sentryProvider{
+		config:      config,
+		client:      client,
+		url:         endpoint,
+		auth:        auth,
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	p.batcher = newHTTPBatcher(config.Batch, p.send, nil, func(err error) {
+		p.diagnostics.reportf(p.Name(), "%v", err)
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(p.Name(), err)
+		}
+	})
+	if !config.LeakCheck {
+		return p, nil
+	}
+	// Фоновая отправка ссылается на p, поэтому финализатор устанавливается
+	// на обертку, которую удерживает только вызывающий
+	h := &sentryProviderHandle{p}
+	p.leakCheck = newLeakCheck(true, h, "Sentry provider "+p.url, p.diagnostics)
+	return h, nil
+}
+
+// sentryProviderHandle - провайдер, возвращаемый NewSentryProvider при LeakCheck.
+type sentryProviderHandle struct{ *sentryProvider }
+
+// parseSentryDSN возвращает адрес envelope API и заголовок X-Sentry-Auth для DSN.
+func parseSentryDSN(dsn string) (endpoint, auth string, err error) {
+	if dsn == "" {
+		return "", "", fmt.Errorf("sglogger: sentry DSN is not set")
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("sglogger: invalid sentry DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("sglogger: invalid sentry DSN: unsupported scheme %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("sglogger: invalid sentry DSN: public key is not set")
+	}
+	path := strings.TrimRight(u.Path, "/")
+	slash := strings.LastIndexByte(path, '/')
+	project := path[slash+1:]
+	if project == "" {
+		return "", "", fmt.Errorf("sglogger: invalid sentry DSN: project ID is not set")
+	}
+
+	auth = "Sentry sentry_version=7, sentry_client=sglogger/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok && secret != "" {
+		auth += ", sentry_secret=" + secret
+	}
+	endpoint = u.Scheme + "://" + u.Host + path[:slash+1] + "api/" + project + "/envelope/"
+	return endpoint, auth, nil
+}
+
+// Write ставит запись в очередь отправки.
+func (p *sentryProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+	return p.batcher.add(Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+// WriteBatch отправляет записи синхронно, минуя очередь (см. BatchWriter).
+func (p *sentryProvider) WriteBatch(ctx context.Context, entries []Entry) error {
+	return p.batcher.writeBatch(ctx, entries)
+}
+
+// EntrySizes возвращает гистограмму размеров отправленных событий без учета повторов.
+func (p *sentryProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// DroppedEntries возвращает количество событий, потерянных при отправке
+// (см. DropRecorder).
+func (p *sentryProvider) DroppedEntries() uint64 {
+	return p.batcher.droppedEntries()
+}
+
+// WriteTimeout возвращает FlushTimeout: он ограничивает сброс очереди перед
+// завершением Fatal (см. WriteTimeouter).
+func (p *sentryProvider) WriteTimeout() time.Duration {
+	return p.config.FlushTimeout
+}
+
+// Name возвращает имя провайдера из конфигурации или "sentry" по умолчанию.
+func (p *sentryProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "sentry"
+}
+
+// Describe возвращает уровень, адрес, окружение и параметры пакетов (см. Describer).
+// Ключ DSN не раскрывается.
+func (p *sentryProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["format"] = "sentry"
+	description["url"] = p.url
+	description["environment"] = p.config.Environment
+	description["release"] = p.config.Release
+	description["tag_fields"] = append([]string(nil), p.config.TagFields...)
+	description["flush_timeout"] = p.config.FlushTimeout.String()
+	description["batch"] = describeHTTPBatch(p.batcher.config)
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *sentryProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *sentryProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Flush отправляет события из очереди.
+func (p *sentryProvider) Flush(ctx context.Context) error {
+	return p.batcher.flush(ctx)
+}
+
+// Close отправляет оставшиеся события, ожидая не дольше FlushTimeout,
+// и прекращает прием новых.
+func (p *sentryProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	if !p.markClosed() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.config.FlushTimeout)
+	defer cancel()
+	return p.batcher.close(ctx)
+}
+
+// send отправляет записи пакета по одной: envelope содержит одно событие.
+func (p *sentryProvider) send(ctx context.Context, tenant string, entries []Entry) error {
+	for _, e := range entries {
+		if err := p.sendEvent(ctx, tenant, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendEvent отправляет одно событие.
+func (p *sentryProvider) sendEvent(ctx context.Context, tenant string, e Entry) error {
+	eventID := newSentryEventID()
+	event := p.appendEvent(nil, eventID, e)
+	p.sizes.observe(len(event))
+
+	body := make([]byte, 0, len(event)+128)
+	body = append(body, `{"event_id":"`...)
+	body = append(body, eventID...)
+	body = append(body, `","sent_at":"`...)
+	body = time.Now().UTC().AppendFormat(body, time.RFC3339Nano)
+	body = append(body, "\"}\n"...)
+	body = append(body, `{"type":"event","length":`...)
+	body = strconv.AppendInt(body, int64(len(event)), 10)
+	body = append(body, "}\n"...)
+	body = append(body, event...)
+	body = append(body, '\n')
+	body, encoding, err := compressHTTPBody(p.config.Batch, body)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, p.client, httpMaxRetries(p.config.Batch), p.diagnostics, p.Name(), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: create sentry request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/x-sentry-envelope")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		req.Header.Set("X-Sentry-Auth", p.auth)
+		if tenant != "" && p.config.Batch.TenantHeader != "" {
+			req.Header.Set(p.config.Batch.TenantHeader, tenant)
+		}
+		return req, nil
+	})
+}
+
+// appendEvent добавляет событие Sentry в формате JSON.
+func (p *sentryProvider) appendEvent(buf []byte, eventID string, e Entry) []byte {
+	buf = append(buf, `{"event_id":"`...)
+	buf = append(buf, eventID...)
+	buf = append(buf, `","timestamp":"`...)
+	buf = e.Time.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, `","platform":"go","logger":"sglogger","level":`...)
+	buf = appendJSONString(buf, sentryLevel(e.Level))
+	buf = append(buf, `,"message":{"formatted":`...)
+	buf = appendJSONString(buf, e.Message)
+	buf = append(buf, '}')
+	if p.config.ServerName != "" {
+		buf = append(buf, `,"server_name":`...)
+		buf = appendJSONString(buf, p.config.ServerName)
+	}
+	if p.config.Environment != "" {
+		buf = append(buf, `,"environment":`...)
+		buf = appendJSONString(buf, p.config.Environment)
+	}
+	if p.config.Release != "" {
+		buf = append(buf, `,"release":`...)
+		buf = appendJSONString(buf, p.config.Release)
+	}
+
+	var tags []byte
+	extra := make(Fields, len(e.Fields))
+	for k, v := range e.Fields {
+		switch {
+		case k == "error":
+			buf = append(buf, `,"exception":{"values":[{"type":"error","value":`...)
+			buf = appendJSONString(buf, fmt.Sprint(v))
+			buf = append(buf, "}]}"...)
+		case k == FingerprintField:
+			buf = append(buf, `,"fingerprint":[`...)
+			buf = appendJSONString(buf, fmt.Sprint(v))
+			buf = append(buf, ']')
+		case len(k) <= sentryMaxTagKey && containsString(p.config.TagFields, k):
+			tags = append(tags, ',')
+			tags = appendJSONString(tags, k)
+			tags = append(tags, ':')
+			value := fmt.Sprint(v)
+			if len(value) > sentryMaxTagValue {
+				value = truncateUTF8(value, sentryMaxTagValue)
+			}
+			tags = appendJSONString(tags, value)
+		default:
+			extra[k] = v
+		}
+	}
+	if len(tags) > 0 {
+		buf = append(buf, `,"tags":{`...)
+		buf = append(buf, tags[1:]...)
+		buf = append(buf, '}')
+	}
+	if fields := appendJSONFields(nil, durationFields(extra, p.config.Durations), p.config.FloatFormat); len(fields) > 0 {
+		buf = append(buf, `,"extra":{`...)
+		buf = append(buf, fields[1:]...)
+		buf = append(buf, '}')
+	}
+	return append(buf, '}')
+}
+
+// sentryLevel возвращает уровень события Sentry.
+func sentryLevel(level Level) string {
+	switch {
+	case level >= LevelFatal:
+		return "fatal"
+	case level >= LevelError:
+		return "error"
+	case level >= LevelWarn:
+		return "warning"
+	case level >= LevelInfo:
+		return "info"
+	}
+	return "debug"
+}
+
+// newSentryEventID возвращает случайный идентификатор события из 32
+// шестнадцатеричных символов.
+func newSentryEventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
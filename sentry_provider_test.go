@@ -0,0 +1,74 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newBlockedSentry создает провайдер Sentry, сервер которого не отвечает,
+// пока не истечет контекст запроса или не завершится тест.
+func newBlockedSentry(t *testing.T, flushTimeout time.Duration) LoggerProvider {
+	t.Helper()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dsn := strings.Replace(server.URL, "http://", "http://key@", 1) + "/1"
+	provider, err := NewSentryProvider(dsn, WithSentryFlushTimeout(flushTimeout))
+	if err != nil {
+		t.Fatalf("NewSentryProvider: %v", err)
+	}
+	t.Cleanup(func() {
+		close(release)
+		provider.Close(context.Background())
+	})
+	return provider
+}
+
+func TestSentryFlushBlockedEndpoint(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+	provider := newBlockedSentry(t, timeout)
+	if err := provider.Write(context.Background(), LevelError, "payment failed", nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.(WriteTimeouter).WriteTimeout())
+	defer cancel()
+	start := time.Now()
+	err := provider.(Flusher).Flush(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > timeout+time.Second {
+		t.Errorf("Flush returned after %s, want about %s", elapsed, timeout)
+	}
+}
+
+func TestSentryFlushForExitBlockedEndpoint(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+	provider := newBlockedSentry(t, timeout)
+	var handled error
+	l := NewLogger(LoggerConfig{ErrorHandler: func(name string, err error) { handled = err }}, NewFieldsHandler(), provider).(*logger)
+
+	l.writeLog(context.Background(), LevelError, nil, nil, "payment failed", nil)
+	// Сброс перед Fatal ограничен FlushTimeout, а не расписанием повторов
+	start := time.Now()
+	l.flushForExit(context.Background())
+	if elapsed := time.Since(start); elapsed > timeout+time.Second {
+		t.Errorf("flushForExit returned after %s, want about %s", elapsed, timeout)
+	}
+	if !errors.Is(handled, context.DeadlineExceeded) {
+		t.Errorf("ErrorHandler got %v, want context.DeadlineExceeded", handled)
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/SergeiKhanlarov/seri-go-logger/sgkafka
+
+go 1.21
+
+require (
+	github.com/SergeiKhanlarov/seri-go-logger v0.0.0
+	github.com/twmb/franz-go v1.18.1
+	github.com/twmb/franz-go/pkg/kfake v0.0.0-20250320172111-35ab5e5f5327
+)
+
+require (
+	github.com/klauspost/compress v1.17.11 // indirect
+	github.com/pierrec/lz4/v4 v4.1.22 // indirect
+	github.com/twmb/franz-go/pkg/kmsg v1.9.0 // indirect
+	golang.org/x/crypto v0.32.0 // indirect
+)
+
+replace github.com/SergeiKhanlarov/seri-go-logger => ../
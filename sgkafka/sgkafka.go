@@ -0,0 +1,483 @@
+// Package sgkafka публикует записи sglogger в тему Kafka.
+//
+// Пакет вынесен в отдельный модуль, чтобы основной модуль sglogger
+// не зависел от клиента Kafka. Отправкой, повторами, выбором раздела
+// и сжатием занимается клиент franz-go (github.com/twmb/franz-go).
+//
+// Пример:
+//
+//	provider, err := sgkafka.New([]string{"kafka-1:9092", "kafka-2:9092"}, "logs",
+//		sgkafka.WithBuffer(50000, sgkafka.BufferDrop),
+//		sgkafka.WithErrorHandler(func(provider string, err error) {
+//			fmt.Fprintln(os.Stderr, provider, err)
+//		}),
+//	)
+package sgkafka
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Значения по умолчанию подобраны для журналов: небольшая задержка пакета
+// и ограниченный буфер, чтобы недоступность брокеров не расходовала память
+// без предела.
+const (
+	// DefaultMaxBuffered - размер буфера неотправленных записей.
+	DefaultMaxBuffered = 10000
+
+	// DefaultLinger - сколько пакет раздела ждет новых записей перед отправкой.
+	DefaultLinger = 100 * time.Millisecond
+
+	// DefaultBatchMaxBytes - наибольший размер пакета одного раздела.
+	DefaultBatchMaxBytes = 1 << 20
+
+	// DefaultDeliveryTimeout - сколько запись может ждать доставки с учетом
+	// повторов, прежде чем будет потеряна.
+	DefaultDeliveryTimeout = 30 * time.Second
+
+	// DefaultTimeout ограничивает подключение к брокеру и ожидание ответа
+	// сверх таймаута самого запроса.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Адаптер отмечается в sglogger.Features, чтобы построители логгера
+// по конфигурации знали о его наличии в сборке.
+func init() {
+	sglogger.RegisterFeature("provider.kafka")
+}
+
+// BufferPolicy определяет поведение Write при заполненном буфере,
+// например пока брокеры недоступны.
+type BufferPolicy int
+
+const (
+	// BufferDrop - Write сразу возвращает sglogger.ErrQueueFull.
+	BufferDrop BufferPolicy = iota
+	// BufferBlock - Write ждет места в буфере, пока не отменен его контекст.
+	BufferBlock
+)
+
+// Config определяет провайдер Kafka (см. New и функции With*).
+// Каждая запись публикуется одной записью Kafka в формате JSON.
+type Config struct {
+	sglogger.ProviderConfig // Уровень, имя, параметры JSON и вывода чисел
+
+	// KeyField - поле, значение которого становится ключом записи, поэтому
+	// записи с одинаковым значением попадают в один раздел; по умолчанию
+	// "trace_id". Записи без него распределяются по разделам пакетами.
+	KeyField        string
+	ClientID        string              // Идентификатор клиента Kafka, по умолчанию "sglogger"
+	AllReplicas     bool                // Ждать подтверждения всех синхронных реплик (acks=all) вместо лидера
+	TLS             *sglogger.TLSConfig // Параметры TLS, nil - соединения без шифрования
+	MaxBuffered     int                 // Размер буфера записей, по умолчанию DefaultMaxBuffered
+	BufferPolicy    BufferPolicy        // Поведение Write при заполненном буфере, по умолчанию BufferDrop
+	Linger          time.Duration       // Задержка пакета, по умолчанию DefaultLinger
+	BatchMaxBytes   int32               // Размер пакета, по умолчанию DefaultBatchMaxBytes
+	DeliveryTimeout time.Duration       // Срок доставки записи, по умолчанию DefaultDeliveryTimeout
+	DialTimeout     time.Duration       // Таймаут подключения, по умолчанию DefaultTimeout
+	RequestTimeout  time.Duration       // Ожидание ответа сверх таймаута запроса, по умолчанию DefaultTimeout
+	ClientOptions   []kgo.Opt           // Дополнительные параметры клиента franz-go, например SASL
+}
+
+// Option настраивает New. Кроме функций With* подходит любая функция
+// func(*Config).
+type Option func(*Config)
+
+// WithKeyField задает поле, значение которого становится ключом записи
+// вместо trace_id.
+func WithKeyField(field string) Option {
+	return func(c *Config) {
+		c.KeyField = field
+	}
+}
+
+// WithBuffer задает размер буфера записей и поведение Write при его
+// заполнении.
+func WithBuffer(size int, policy BufferPolicy) Option {
+	return func(c *Config) {
+		c.MaxBuffered = size
+		c.BufferPolicy = policy
+	}
+}
+
+// WithErrorHandler задает обработчик ошибок доставки: ему передаются
+// ошибки записей, не доставленных за DeliveryTimeout.
+func WithErrorHandler(handler func(provider string, err error)) Option {
+	return func(c *Config) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithTLS включает TLS для соединений с брокерами.
+func WithTLS(config sglogger.TLSConfig) Option {
+	return func(c *Config) {
+		c.TLS = &config
+	}
+}
+
+// provider публикует записи в тему Kafka через клиент franz-go.
+type provider struct {
+	dropped uint64 // Первое поле: атомарный доступ требует выравнивания на 32-битных платформах
+	closed  int32
+
+	config    Config
+	brokers   []string
+	topic     string
+	formatter sglogger.Formatter
+	client    *kgo.Client
+
+	// mu захватывается Write на чтение на время передачи записи клиенту,
+	// Close - на запись, чтобы после него pending больше не увеличивался.
+	// pending считает записи, результат которых еще не передан в promise.
+	mu      sync.RWMutex
+	pending sync.WaitGroup
+
+	// ctx - контекст записей в буфере клиента: его отмена, если Close
+	// не уложился в свой контекст, снимает неотправленные записи.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New создает провайдер, публикующий записи в тему topic.
+// Каждая запись сериализуется в JSON (см. sglogger.NewJSONFormatter)
+// и отправляется асинхронно; значение поля KeyField (по умолчанию trace_id)
+// становится ключом записи, поэтому записи одной трассировки попадают в один
+// раздел; раздел по ключу выбирается так же, как в клиенте Kafka для Java.
+//
+// Записи ждут отправки в буфере на MaxBuffered записей; когда он заполнен,
+// Write согласно BufferPolicy сразу возвращает sglogger.ErrQueueFull или ждет
+// места. Клиент повторяет неудачную отправку; записи, не доставленные
+// за DeliveryTimeout, учитываются в DroppedEntries (см. sglogger.DropRecorder),
+// а ошибка передается ErrorHandler. Close отправляет оставшиеся записи
+// в пределах своего контекста.
+//
+// Подключение к брокерам выполняется при первой отправке. Возвращает ошибку,
+// если не заданы брокеры или тема либо конфигурация некорректна.
+func New(brokers []string, topic string, opts ...Option) (sglogger.LoggerProvider, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("sgkafka: brokers are not set")
+	}
+	if topic == "" {
+		return nil, errors.New("sgkafka: topic is not set")
+	}
+
+	var config Config
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.Level = clampLevel(config.Level)
+	if config.KeyField == "" {
+		config.KeyField = "trace_id"
+	}
+	if config.ClientID == "" {
+		config.ClientID = "sglogger"
+	}
+	if config.MaxBuffered <= 0 {
+		config.MaxBuffered = DefaultMaxBuffered
+	}
+	if config.Linger <= 0 {
+		config.Linger = DefaultLinger
+	}
+	if config.BatchMaxBytes <= 0 {
+		config.BatchMaxBytes = DefaultBatchMaxBytes
+	}
+	if config.DeliveryTimeout <= 0 {
+		config.DeliveryTimeout = DefaultDeliveryTimeout
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = DefaultTimeout
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = DefaultTimeout
+	}
+
+	clientOpts := []kgo.Opt{
+		kgo.SeedBrokers(brokers...),
+		kgo.DefaultProduceTopic(topic),
+		kgo.ClientID(config.ClientID),
+		kgo.MaxBufferedRecords(config.MaxBuffered),
+		kgo.ProducerLinger(config.Linger),
+		kgo.ProducerBatchMaxBytes(config.BatchMaxBytes),
+		kgo.RecordDeliveryTimeout(config.DeliveryTimeout),
+		kgo.DialTimeout(config.DialTimeout),
+		kgo.RequestTimeoutOverhead(config.RequestTimeout),
+	}
+	if config.AllReplicas {
+		clientOpts = append(clientOpts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	} else {
+		// Идемпотентная отправка требует acks=all
+		clientOpts = append(clientOpts, kgo.RequiredAcks(kgo.LeaderAck()), kgo.DisableIdempotentWrite())
+	}
+	if config.TLS != nil {
+		tlsConfig, err := buildTLSConfig(*config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts, kgo.DialTLSConfig(tlsConfig))
+	}
+	clientOpts = append(clientOpts, config.ClientOptions...)
+
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("sgkafka: create client: %w", err)
+	}
+
+	formatter := config.Formatter
+	if formatter == nil {
+		formatter = sglogger.NewJSONFormatter(config.ProviderConfig)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &provider{
+		config:    config,
+		brokers:   append([]string(nil), brokers...),
+		topic:     topic,
+		formatter: formatter,
+		client:    client,
+		ctx:       ctx,
+		cancel:    cancel,
+	}, nil
+}
+
+// Write сериализует запись и передает ее клиенту Kafka.
+func (p *provider) Write(ctx context.Context, level sglogger.Level, message string, fields sglogger.Fields) error {
+	if p.isClosed() {
+		return sglogger.ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+
+	at, ok := sglogger.EntryTimeFromContext(ctx)
+	if !ok {
+		at = time.Now()
+	}
+	e := sglogger.Entry{
+		Time:    at,
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	}
+	record := &kgo.Record{
+		Value:     bytes.TrimSuffix(p.formatter.AppendFormat(nil, e), []byte("\n")),
+		Timestamp: at,
+		// Записи в буфере не зависят от контекста вызова, который обычно
+		// отменяется вместе с запросом раньше, чем запись отправлена
+		Context: p.ctx,
+	}
+	if key := fieldString(fields[p.config.KeyField]); key != "" {
+		record.Key = []byte(key)
+	}
+
+	return p.produce(ctx, record)
+}
+
+// produce передает запись клиенту согласно BufferPolicy.
+func (p *provider) produce(ctx context.Context, record *kgo.Record) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.isClosed() {
+		return sglogger.ErrProviderClosed
+	}
+	if p.config.BufferPolicy == BufferBlock {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("sgkafka: wait for buffer: %w", err)
+		}
+		// Produce ждет места в буфере до отмены ctx или закрытия клиента;
+		// прерванное ожидание передается в promise как потерянная запись
+		p.pending.Add(1)
+		p.client.Produce(ctx, record, p.promise)
+		return nil
+	}
+	if p.client.BufferedProduceRecords() >= int64(p.config.MaxBuffered) {
+		return sglogger.ErrQueueFull
+	}
+	p.pending.Add(1)
+	p.client.TryProduce(ctx, record, p.promise)
+	return nil
+}
+
+// promise учитывает записи, которые клиент не смог доставить.
+func (p *provider) promise(_ *kgo.Record, err error) {
+	defer p.pending.Done()
+	if err == nil {
+		return
+	}
+	atomic.AddUint64(&p.dropped, 1)
+	if errors.Is(err, kgo.ErrMaxBuffered) {
+		err = sglogger.ErrQueueFull
+	}
+	if p.config.ErrorHandler != nil {
+		p.config.ErrorHandler(p.Name(), fmt.Errorf("sgkafka: produce to %s: %w", p.topic, err))
+	}
+}
+
+// DroppedEntries возвращает количество записей, потерянных при отправке
+// (см. sglogger.DropRecorder).
+func (p *provider) DroppedEntries() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Name возвращает имя провайдера из конфигурации или "kafka" по умолчанию.
+func (p *provider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "kafka"
+}
+
+// Describe возвращает уровень, брокеры, тему и параметры буфера
+// (см. sglogger.Describer).
+func (p *provider) Describe() map[string]interface{} {
+	policy := "drop"
+	if p.config.BufferPolicy == BufferBlock {
+		policy = "block"
+	}
+	acks := "leader"
+	if p.config.AllReplicas {
+		acks = "all"
+	}
+	return map[string]interface{}{
+		"level":            p.config.Level.String(),
+		"format":           "json",
+		"brokers":          append([]string(nil), p.brokers...),
+		"topic":            p.topic,
+		"key_field":        p.config.KeyField,
+		"acks":             acks,
+		"tls":              p.config.TLS != nil,
+		"max_buffered":     p.config.MaxBuffered,
+		"buffer_policy":    policy,
+		"linger":           p.config.Linger.String(),
+		"batch_max_bytes":  p.config.BatchMaxBytes,
+		"delivery_timeout": p.config.DeliveryTimeout.String(),
+	}
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *provider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из sglogger.ContextWithMinLevel
+// заменяет уровень провайдера.
+func (p *provider) ShouldLog(ctx context.Context, level sglogger.Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := sglogger.MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Flush ждет доставки записей, находящихся в буфере, не дольше,
+// чем позволяет ctx.
+func (p *provider) Flush(ctx context.Context) error {
+	if p.isClosed() {
+		return nil
+	}
+	if err := p.client.Flush(ctx); err != nil {
+		return fmt.Errorf("sgkafka: flush: %w", err)
+	}
+	return nil
+}
+
+// Close прекращает прием записей и отправляет оставшиеся в пределах ctx.
+// Если ctx истекает раньше, неотправленные записи снимаются и учитываются
+// в DroppedEntries. К возврату из Close все записи учтены и переданы
+// ErrorHandler.
+func (p *provider) Close(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		return nil
+	}
+	err := p.client.Flush(ctx)
+	p.cancel()
+	// Закрытие клиента завершает и ожидание места в Produce
+	p.client.Close()
+	p.mu.Lock()
+	p.mu.Unlock()
+	p.pending.Wait()
+	if err != nil {
+		return fmt.Errorf("sgkafka: drain producer: %w", err)
+	}
+	return nil
+}
+
+// isClosed сообщает, был ли вызван Close.
+func (p *provider) isClosed() bool {
+	return atomic.LoadInt32(&p.closed) != 0
+}
+
+// clampLevel приводит уровень к диапазону LevelDebug..LevelFatal.
+func clampLevel(l sglogger.Level) sglogger.Level {
+	switch {
+	case l < sglogger.LevelDebug:
+		return sglogger.LevelDebug
+	case l > sglogger.LevelFatal:
+		return sglogger.LevelFatal
+	}
+	return l
+}
+
+// fieldString возвращает значение поля в виде строки для ключа записи.
+func fieldString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.RawMessage:
+		return string(val)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// buildTLSConfig загружает сертификаты, указанные в конфигурации TLS.
+func buildTLSConfig(config sglogger.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("sgkafka: read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("sgkafka: no valid certificates in CA file %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("sgkafka: load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
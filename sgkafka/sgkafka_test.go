@@ -0,0 +1,113 @@
+package sgkafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// newCluster запускает встроенный кластер Kafka с темой topic.
+func newCluster(t *testing.T, topic string) []string {
+	t.Helper()
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(2, topic))
+	if err != nil {
+		t.Fatalf("kfake.NewCluster: %v", err)
+	}
+	t.Cleanup(cluster.Close)
+	return cluster.ListenAddrs()
+}
+
+func TestProviderProduces(t *testing.T) {
+	brokers := newCluster(t, "logs")
+	provider, err := New(brokers, "logs", WithKeyField("request_id"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := provider.Write(ctx, sglogger.LevelInfo, "request handled", sglogger.Fields{"request_id": "r-1", "status": 200}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := provider.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := provider.Write(ctx, sglogger.LevelInfo, "late", nil); err != sglogger.ErrProviderClosed {
+		t.Errorf("Write after Close = %v, want ErrProviderClosed", err)
+	}
+
+	consumer, err := kgo.NewClient(kgo.SeedBrokers(brokers...), kgo.ConsumeTopics("logs"))
+	if err != nil {
+		t.Fatalf("kgo.NewClient: %v", err)
+	}
+	defer consumer.Close()
+	fetches := consumer.PollFetches(ctx)
+	if err := fetches.Err(); err != nil {
+		t.Fatalf("PollFetches: %v", err)
+	}
+	records := fetches.Records()
+	if len(records) != 1 {
+		t.Fatalf("consumed %d records, want 1", len(records))
+	}
+	if got := string(records[0].Key); got != "r-1" {
+		t.Errorf("key = %q, want r-1", got)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(records[0].Value, &entry); err != nil {
+		t.Fatalf("value is not JSON: %v: %s", err, records[0].Value)
+	}
+	if entry["msg"] != "request handled" || entry["status"] != float64(200) {
+		t.Errorf("value = %s", records[0].Value)
+	}
+	if dropped := provider.(sglogger.DropRecorder).DroppedEntries(); dropped != 0 {
+		t.Errorf("DroppedEntries = %d, want 0", dropped)
+	}
+}
+
+func TestProviderBufferFull(t *testing.T) {
+	// Брокер недоступен, поэтому записи остаются в буфере клиента
+	provider, err := New([]string{"127.0.0.1:1"}, "logs",
+		WithBuffer(1, BufferDrop),
+		func(c *Config) { c.DeliveryTimeout = time.Hour },
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := provider.Write(ctx, sglogger.LevelInfo, "first", nil); err != nil {
+		t.Fatalf("first Write: %v", err)
+	}
+	if err := provider.Write(ctx, sglogger.LevelInfo, "second", nil); !errors.Is(err, sglogger.ErrQueueFull) {
+		t.Errorf("Write to a full buffer = %v, want ErrQueueFull", err)
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := provider.Close(closeCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want a deadline error", err)
+	}
+	if dropped := provider.(sglogger.DropRecorder).DroppedEntries(); dropped != 1 {
+		t.Errorf("DroppedEntries = %d, want 1", dropped)
+	}
+}
+
+func TestNewValidates(t *testing.T) {
+	if _, err := New(nil, "logs"); err == nil {
+		t.Error("New without brokers succeeded")
+	}
+	if _, err := New([]string{"127.0.0.1:9092"}, ""); err == nil {
+		t.Error("New without a topic succeeded")
+	}
+	tls := sglogger.TLSConfig{CAFile: "/nonexistent/ca.pem"}
+	if _, err := New([]string{"127.0.0.1:9092"}, "logs", WithTLS(tls)); err == nil {
+		t.Error("New with a missing CA file succeeded")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/SergeiKhanlarov/seri-go-logger/sglogr
+
+go 1.19
+
+require (
+	github.com/SergeiKhanlarov/seri-go-logger v0.0.0
+	github.com/go-logr/logr v1.4.2
+)
+
+replace github.com/SergeiKhanlarov/seri-go-logger => ../
@@ -0,0 +1,218 @@
+// Package sglogr подключает sglogger к библиотекам, использующим logr,
+// в том числе к klog и client-go Kubernetes.
+//
+// Пакет вынесен в отдельный модуль, чтобы основной модуль sglogger
+// не зависел от logr.
+//
+// Пример для кода, использующего klog:
+//
+//	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+//	klog.InitFlags(fs)
+//	if err := sglogr.SuppressKlogOutput(fs); err != nil {
+//		return err
+//	}
+//	klog.SetLogger(sglogr.New(logger, sglogr.Options{Caller: true}))
+//	defer klog.Flush()
+//
+// После этого записи client-go (klog.Info, klog.V(4).Info, klog.Error)
+// передаются провайдерам logger.
+package sglogr
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+	"github.com/go-logr/logr"
+)
+
+// Поля, которые добавляет адаптер.
+const (
+	// NameField содержит имя логгера из WithName; имена вложенных логгеров разделяются "/".
+	NameField = "logger"
+
+	// VerbosityField содержит V-уровень записи, если он больше нуля.
+	VerbosityField = "v"
+
+	// CallerField содержит место вызова в виде "файл:строка" (см. Options.Caller).
+	CallerField = "caller"
+)
+
+// Адаптер отмечается в sglogger.Features, чтобы построители логгера
+// по конфигурации знали о его наличии в сборке.
+func init() {
+	sglogger.RegisterFeature("logr")
+}
+
+// Options определяет отображение записей logr на уровни sglogger.
+type Options struct {
+	// InfoMaxV - наибольший V-уровень, записываемый уровнем LevelInfo;
+	// записи с большим V-уровнем записываются уровнем LevelDebug.
+	// По умолчанию LevelInfo получают только записи V(0).
+	InfoMaxV int
+	// MaxV - наибольший записываемый V-уровень; записи с большим V-уровнем
+	// отбрасываются. Ноль снимает ограничение.
+	MaxV int
+	// Caller добавляет поле CallerField с местом вызова с учетом глубины
+	// вызова, переданной через logr.WithCallDepth (klog передает глубину
+	// своих оберток). Стоит одного вызова runtime.Caller на запись.
+	Caller bool
+}
+
+// sink реализует logr.LogSink поверх sglogger.Logger.
+type sink struct {
+	logger  sglogger.Logger
+	options Options
+	name    string
+	values  sglogger.Fields
+	depth   int
+}
+
+// New возвращает logr.Logger, записывающий через logger. Записи Info
+// получают уровень LevelInfo или LevelDebug по V-уровню (см. Options),
+// записи Error - LevelError с ошибкой. Пары ключ-значение становятся полями;
+// ключи, не являющиеся строками, приводятся к строке.
+func New(logger sglogger.Logger, options Options) logr.Logger {
+	return logr.New(&sink{logger: logger, options: options})
+}
+
+// Init запоминает глубину вызова оберток logr.
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+// Enabled сообщает, будут ли записаны сообщения V-уровня level.
+func (s *sink) Enabled(level int) bool {
+	if s.options.MaxV > 0 && level > s.options.MaxV {
+		return false
+	}
+	return s.level(level) >= s.logger.GetLevel()
+}
+
+// Info записывает сообщение уровнем, соответствующим V-уровню level.
+func (s *sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	fields := s.fields(keysAndValues)
+	if level > 0 {
+		fields[VerbosityField] = level
+	}
+	ctx := context.Background()
+	if s.level(level) == sglogger.LevelDebug {
+		s.logger.DebugWithFields(ctx, fields, "%s", msg)
+	} else {
+		s.logger.InfoWithFields(ctx, fields, "%s", msg)
+	}
+}
+
+// Error записывает сообщение уровнем LevelError.
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.logger.ErrorErrWithFields(context.Background(), err, s.fields(keysAndValues), "%s", msg)
+}
+
+// WithValues возвращает sink с дополнительными полями.
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	clone := *s
+	clone.values = make(sglogger.Fields, len(s.values)+len(keysAndValues)/2)
+	for k, v := range s.values {
+		clone.values[k] = v
+	}
+	addPairs(clone.values, keysAndValues)
+	return &clone
+}
+
+// WithName возвращает sink с добавленным к имени сегментом name.
+func (s *sink) WithName(name string) logr.LogSink {
+	clone := *s
+	if clone.name != "" {
+		clone.name += "/"
+	}
+	clone.name += name
+	return &clone
+}
+
+// WithCallDepth возвращает sink, пропускающий дополнительные depth кадров
+// при определении места вызова.
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	clone := *s
+	clone.depth += depth
+	return &clone
+}
+
+// level возвращает уровень sglogger для V-уровня.
+func (s *sink) level(v int) sglogger.Level {
+	if v > s.options.InfoMaxV {
+		return sglogger.LevelDebug
+	}
+	return sglogger.LevelInfo
+}
+
+// fields собирает поля записи: значения WithValues, имя, место вызова
+// и пары ключ-значение вызова.
+func (s *sink) fields(keysAndValues []interface{}) sglogger.Fields {
+	fields := make(sglogger.Fields, len(s.values)+len(keysAndValues)/2+2)
+	for k, v := range s.values {
+		fields[k] = v
+	}
+	if s.name != "" {
+		fields[NameField] = s.name
+	}
+	if s.options.Caller {
+		// Пропускаются fields и метод sink; кадры logr.Logger учтены в depth (см. Init).
+		if _, file, line, ok := runtime.Caller(2 + s.depth); ok {
+			fields[CallerField] = shortFile(file) + ":" + strconv.Itoa(line)
+		}
+	}
+	addPairs(fields, keysAndValues)
+	return fields
+}
+
+// addPairs добавляет пары ключ-значение к полям. Значение без пары
+// записывается как "(MISSING)", как в logr.
+func addPairs(fields sglogger.Fields, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = "(MISSING)"
+		}
+	}
+}
+
+// shortFile возвращает имя файла с каталогом пакета.
+func shortFile(file string) string {
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			return file[j+1:]
+		}
+	}
+	return file
+}
+
+// SuppressKlogOutput настраивает флаги klog, зарегистрированные в fs через
+// klog.InitFlags, так, чтобы klog не создавал собственных файлов и не дублировал
+// записи в stderr: весь вывод идет через логгер из klog.SetLogger.
+// Флаги, отсутствующие в используемой версии klog, пропускаются.
+func SuppressKlogOutput(fs *flag.FlagSet) error {
+	for _, setting := range [][2]string{
+		{"logtostderr", "true"},
+		{"alsologtostderr", "false"},
+		{"log_dir", ""},
+		{"log_file", ""},
+		{"skip_log_headers", "true"},
+	} {
+		if fs.Lookup(setting[0]) == nil {
+			continue
+		}
+		if err := fs.Set(setting[0], setting[1]); err != nil {
+			return fmt.Errorf("sglogr: set klog flag %s: %w", setting[0], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package sglogtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+// nearbyEntries - количество ближайших записей в сообщении о неудаче.
+const nearbyEntries = 5
+
+// ValueMatcher проверяет значение поля. Ему соответствуют матчеры gomega
+// (types.GomegaMatcher), поэтому их можно передавать в ожидаемых полях;
+// если матчер реализует FailureMessage(actual), как матчеры gomega,
+// его текст используется в сообщении о неудаче:
+//
+//	sglogtest.ContainsEntry(t, o, sglogger.LevelWarn, "retrying",
+//		sglogger.Fields{"attempt": gomega.BeNumerically(">=", 3)})
+type ValueMatcher interface {
+	Match(actual interface{}) (success bool, err error)
+}
+
+// failureExplainer - матчер, объясняющий неудачу, как матчеры gomega.
+type failureExplainer interface {
+	FailureMessage(actual interface{}) string
+}
+
+// ContainsEntry проверяет, что o содержит запись уровня level, сообщение
+// которой содержит message, а поля включают fields. Поля сравниваются
+// частично: лишние поля записи не учитываются, вложенные Fields тоже
+// сравниваются частично. Ожидаемое значение может быть ValueMatcher;
+// числа разных типов с одинаковым значением считаются равными.
+// При неудаче тест завершается ошибкой с перечнем наиболее похожих записей
+// и расхождений в их полях. Возвращает результат проверки.
+func ContainsEntry(t testing.TB, o *Observer, level sglogger.Level, message string, fields sglogger.Fields) bool {
+	t.Helper()
+
+	entries := o.Entries()
+	type candidate struct {
+		entry sglogger.Entry
+		diffs []string
+		score int
+	}
+	candidates := make([]candidate, 0, len(entries))
+	for _, e := range entries {
+		var diffs []string
+		score := 0
+		if e.Level != level {
+			diffs = append(diffs, fmt.Sprintf("level: got %s, want %s", e.Level, level))
+		} else {
+			score += 2
+		}
+		if !strings.Contains(e.Message, message) {
+			diffs = append(diffs, fmt.Sprintf("message: %q does not contain %q", e.Message, message))
+		} else {
+			score += 2
+		}
+		fieldDiffs := diffFields("", e.Fields, fields)
+		score += len(fields) - len(fieldDiffs)
+		diffs = append(diffs, fieldDiffs...)
+
+		if len(diffs) == 0 {
+			return true
+		}
+		candidates = append(candidates, candidate{entry: e, diffs: diffs, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "sglogtest: no %s entry containing %q", level, message)
+	if len(fields) > 0 {
+		fmt.Fprintf(&b, " with fields %s", formatFields(fields))
+	}
+	fmt.Fprintf(&b, " among %d entries", len(entries))
+	if len(candidates) > nearbyEntries {
+		candidates = candidates[:nearbyEntries]
+	}
+	if len(candidates) > 0 {
+		b.WriteString("; closest:")
+	}
+	for _, c := range candidates {
+		fmt.Fprintf(&b, "\n  %s", formatEntry(c.entry))
+		for _, d := range c.diffs {
+			fmt.Fprintf(&b, "\n      %s", d)
+		}
+	}
+	t.Errorf("%s", b.String())
+	return false
+}
+
+// NotContainsLevel проверяет, что o не содержит записей уровня level.
+// При неудаче тест завершается ошибкой с перечнем таких записей.
+func NotContainsLevel(t testing.TB, o *Observer, level sglogger.Level) bool {
+	t.Helper()
+
+	var found []sglogger.Entry
+	for _, e := range o.Entries() {
+		if e.Level == level {
+			found = append(found, e)
+		}
+	}
+	if len(found) == 0 {
+		return true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "sglogtest: want no %s entries, got %d:", level, len(found))
+	for i, e := range found {
+		if i == nearbyEntries {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(found)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s", formatEntry(e))
+	}
+	t.Errorf("%s", b.String())
+	return false
+}
+
+// CountByLevel возвращает количество записей o по уровням.
+func CountByLevel(o *Observer) map[sglogger.Level]int {
+	counts := make(map[sglogger.Level]int)
+	for _, e := range o.Entries() {
+		counts[e.Level]++
+	}
+	return counts
+}
+
+// FieldValue возвращает значение поля key последней записи, сообщение
+// которой содержит message. Если такой записи или поля нет, тест
+// завершается ошибкой с перечнем записей с этим сообщением, а результат
+// равен nil и false.
+func FieldValue(t testing.TB, o *Observer, message, key string) (interface{}, bool) {
+	t.Helper()
+
+	entries := o.Entries()
+	var matched []sglogger.Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !strings.Contains(e.Message, message) {
+			continue
+		}
+		if v, ok := e.Fields[key]; ok {
+			return v, true
+		}
+		matched = append(matched, e)
+	}
+
+	if len(matched) == 0 {
+		t.Errorf("sglogtest: no entry containing %q among %d entries", message, len(entries))
+		return nil, false
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "sglogtest: no field %q in entries containing %q:", key, message)
+	for i, e := range matched {
+		if i == nearbyEntries {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(matched)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s", formatEntry(e))
+	}
+	t.Errorf("%s", b.String())
+	return nil, false
+}
+
+// diffFields возвращает расхождения полей got с ожидаемыми полями want.
+// prefix - путь вложенного набора полей.
+func diffFields(prefix string, got, want sglogger.Fields) []string {
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var diffs []string
+	for _, k := range keys {
+		path := prefix + k
+		actual, ok := got[k]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing", path))
+			continue
+		}
+		expected := want[k]
+
+		if matcher, ok := expected.(ValueMatcher); ok {
+			success, err := matcher.Match(actual)
+			switch {
+			case err != nil:
+				diffs = append(diffs, fmt.Sprintf("%s: matcher error: %v", path, err))
+			case !success:
+				if explainer, ok := matcher.(failureExplainer); ok {
+					diffs = append(diffs, fmt.Sprintf("%s: %s", path, explainer.FailureMessage(actual)))
+				} else {
+					diffs = append(diffs, fmt.Sprintf("%s: %#v does not match %s", path, actual, describeMatcher(matcher)))
+				}
+			}
+			continue
+		}
+
+		if nested, ok := expected.(sglogger.Fields); ok {
+			if actualNested, ok := asFields(actual); ok {
+				diffs = append(diffs, diffFields(path+".", actualNested, nested)...)
+				continue
+			}
+		}
+		if !valuesEqual(actual, expected) {
+			diffs = append(diffs, fmt.Sprintf("%s: got %#v, want %#v", path, actual, expected))
+		}
+	}
+	return diffs
+}
+
+// describeMatcher возвращает описание матчера: String, если матчер его
+// реализует, иначе представление %+v.
+func describeMatcher(m ValueMatcher) string {
+	if s, ok := m.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%+v", m)
+}
+
+// asFields приводит вложенный набор полей к Fields.
+func asFields(v interface{}) (sglogger.Fields, bool) {
+	switch val := v.(type) {
+	case sglogger.Fields:
+		return val, true
+	case map[string]interface{}:
+		return sglogger.Fields(val), true
+	}
+	return nil, false
+}
+
+// valuesEqual сравнивает значения, считая равными числа разных типов
+// с одинаковым значением.
+func valuesEqual(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	a, aok := number(actual)
+	e, eok := number(expected)
+	return aok && eok && a == e
+}
+
+// number возвращает числовое значение для целых и чисел с плавающей точкой.
+func number(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// formatEntry возвращает запись в виде `<level> "<message>" {k=v ...}` с полями по порядку имен.
+func formatEntry(e sglogger.Entry) string {
+	s := fmt.Sprintf("%s %q", e.Level, e.Message)
+	if len(e.Fields) > 0 {
+		s += " " + formatFields(e.Fields)
+	}
+	return s
+}
+
+// formatFields возвращает поля в виде {k=v ...} по порядку имен.
+func formatFields(fields sglogger.Fields) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		v := fields[k]
+		if m, ok := v.(ValueMatcher); ok {
+			parts[i] = k + "=" + describeMatcher(m)
+			continue
+		}
+		parts[i] = fmt.Sprintf("%s=%#v", k, v)
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
@@ -0,0 +1,137 @@
+package sglogtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+// recordingT запоминает сообщения об ошибках вместо завершения теста.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// atLeast - матчер, принимающий числа не меньше min.
+type atLeast struct{ min int }
+
+func (m atLeast) Match(actual interface{}) (bool, error) {
+	n, ok := actual.(int)
+	if !ok {
+		return false, fmt.Errorf("%T is not an int", actual)
+	}
+	return n >= m.min, nil
+}
+
+func (m atLeast) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("expected %v to be at least %d", actual, m.min)
+}
+
+// newObservedLogger создает Observer с записями типичного сценария повторов.
+func newObservedLogger() *Observer {
+	o := NewObserver()
+	logger := sglogger.NewLogger(sglogger.LoggerConfig{}, sglogger.NewFieldsHandler(), o)
+	ctx := context.Background()
+	logger.InfoWithFields(ctx, sglogger.Fields{"order": 42}, "order created")
+	logger.WarningWithFields(ctx, sglogger.Fields{"attempt": 2, "http": sglogger.Fields{"status": 503, "method": "POST"}}, "retrying request")
+	logger.WarningWithFields(ctx, sglogger.Fields{"attempt": 3, "http": sglogger.Fields{"status": 503, "method": "POST"}}, "retrying request")
+	return o
+}
+
+func TestContainsEntryPasses(t *testing.T) {
+	o := newObservedLogger()
+	rt := &recordingT{}
+
+	// Частичное совпадение полей, вложенные поля, числа разных типов и матчер
+	ContainsEntry(rt, o, sglogger.LevelWarn, "retrying", sglogger.Fields{"attempt": 3})
+	ContainsEntry(rt, o, sglogger.LevelWarn, "retrying", sglogger.Fields{"http": sglogger.Fields{"status": 503}})
+	ContainsEntry(rt, o, sglogger.LevelInfo, "order", sglogger.Fields{"order": 42.0})
+	ContainsEntry(rt, o, sglogger.LevelWarn, "retrying", sglogger.Fields{"attempt": atLeast{3}})
+	ContainsEntry(rt, o, sglogger.LevelInfo, "created", nil)
+	if len(rt.errors) != 0 {
+		t.Errorf("matching entries reported failures: %q", rt.errors)
+	}
+}
+
+func TestContainsEntryFailureListsClosestEntries(t *testing.T) {
+	o := newObservedLogger()
+	rt := &recordingT{}
+
+	if ContainsEntry(rt, o, sglogger.LevelWarn, "retrying", sglogger.Fields{"attempt": atLeast{5}, "http": sglogger.Fields{"status": 500}}) {
+		t.Fatal("ContainsEntry matched an entry with different fields")
+	}
+	if len(rt.errors) != 1 {
+		t.Fatalf("reported %d failures, want 1", len(rt.errors))
+	}
+	message := rt.errors[0]
+	for _, want := range []string{
+		`no warning entry containing "retrying"`,
+		"among 3 entries; closest:",
+		"attempt: expected 3 to be at least 5",
+		"http.status: got 503, want 500",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("failure message is missing %q:\n%s", want, message)
+		}
+	}
+	// Записи с совпадающим уровнем и сообщением идут первыми
+	if first := strings.Index(message, `warning "retrying request"`); first < 0 || first > strings.Index(message, `info "order created"`) {
+		t.Errorf("closest entries are not ordered by similarity:\n%s", message)
+	}
+}
+
+func TestNotContainsLevel(t *testing.T) {
+	o := newObservedLogger()
+	rt := &recordingT{}
+
+	if !NotContainsLevel(rt, o, sglogger.LevelError) {
+		t.Errorf("NotContainsLevel(error) failed: %q", rt.errors)
+	}
+	if NotContainsLevel(rt, o, sglogger.LevelWarn) {
+		t.Fatal("NotContainsLevel(warning) passed with warning entries")
+	}
+	if message := rt.errors[0]; !strings.Contains(message, "want no warning entries, got 2") || !strings.Contains(message, "attempt=3") {
+		t.Errorf("failure message = %q", message)
+	}
+}
+
+func TestCountByLevel(t *testing.T) {
+	counts := CountByLevel(newObservedLogger())
+	if counts[sglogger.LevelInfo] != 1 || counts[sglogger.LevelWarn] != 2 || counts[sglogger.LevelError] != 0 {
+		t.Errorf("CountByLevel = %v", counts)
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	o := newObservedLogger()
+	rt := &recordingT{}
+
+	// Возвращается значение последней подходящей записи
+	if v, ok := FieldValue(rt, o, "retrying", "attempt"); !ok || v != 3 {
+		t.Errorf("FieldValue(attempt) = %v, %v, want 3", v, ok)
+	}
+	if _, ok := FieldValue(rt, o, "retrying", "tenant"); ok {
+		t.Error("FieldValue found a missing field")
+	}
+	if _, ok := FieldValue(rt, o, "shutdown", "attempt"); ok {
+		t.Error("FieldValue found a missing entry")
+	}
+	if len(rt.errors) != 2 {
+		t.Fatalf("reported %d failures, want 2: %q", len(rt.errors), rt.errors)
+	}
+	if !strings.Contains(rt.errors[0], `no field "tenant" in entries containing "retrying"`) {
+		t.Errorf("missing field message = %q", rt.errors[0])
+	}
+	if !strings.Contains(rt.errors[1], `no entry containing "shutdown" among 3 entries`) {
+		t.Errorf("missing entry message = %q", rt.errors[1])
+	}
+}
@@ -0,0 +1,368 @@
+package sglogtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+// conformanceTimeout ограничивает каждый вызов провайдера в проверках соответствия.
+const conformanceTimeout = 5 * time.Second
+
+// conformanceLevels - уровни, для которых проверяется ShouldLog.
+var conformanceLevels = []sglogger.Level{
+	sglogger.LevelDebug,
+	sglogger.LevelInfo,
+	sglogger.LevelWarn,
+	sglogger.LevelError,
+	sglogger.LevelFatal,
+}
+
+// conformanceWriters и conformanceWrites задают нагрузку проверок
+// одновременной записи: количество горутин и записей в каждой.
+const (
+	conformanceWriters = 8
+	conformanceWrites  = 50
+)
+
+// TestProviderConformance проверяет, что провайдеры, создаваемые factory,
+// соблюдают ожидания логгера от LoggerProvider:
+//   - Write принимает записи всех уровней, в том числе без полей, и не
+//     возвращает ошибку для записей, отсеянных собственным уровнем провайдера:
+//     фильтрация - задача ShouldLog, а ошибка Write означает сбой записи;
+//   - Write не изменяет переданные поля, включая вложенные;
+//   - Write с отмененным контекстом завершается без ожидания и возвращает
+//     nil или ошибку контекста;
+//   - Write, ShouldLog и Flush безопасны для одновременного вызова,
+//     в том числе одновременно с Close;
+//   - Flush, если провайдер реализует Flusher, завершается без ошибки;
+//   - поведение после Close соответствует RunProviderConformance.
+//
+// Гонки обнаруживаются только при запуске тестов с флагом -race.
+// factory должен создавать независимые работоспособные провайдеры:
+// место назначения должно принимать записи (например, io.Discard или
+// httptest.Server), иначе ошибки записи или закрытия будут считаться
+// нарушением. Каждый подтест закрывает свой провайдер.
+func TestProviderConformance(t *testing.T, factory func() sglogger.LoggerProvider) {
+	t.Helper()
+
+	t.Run("WriteAllLevels", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		for _, level := range conformanceLevels {
+			err := callProvider(func(ctx context.Context) error {
+				return p.Write(ctx, level, "conformance", sglogger.Fields{"level_name": level.String()})
+			})
+			if err != nil {
+				t.Errorf("Write(%s) returned %v, want nil", level, err)
+			}
+		}
+	})
+
+	t.Run("NilFields", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		for _, message := range []string{"conformance", ""} {
+			err := callProvider(func(ctx context.Context) error {
+				return p.Write(ctx, sglogger.LevelError, message, nil)
+			})
+			if err != nil {
+				t.Errorf("Write(%q, nil fields) returned %v, want nil", message, err)
+			}
+		}
+	})
+
+	t.Run("FieldsNotModified", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		fields := conformanceFields()
+		for _, level := range conformanceLevels {
+			err := callProvider(func(ctx context.Context) error {
+				return p.Write(ctx, level, "conformance", fields)
+			})
+			if err != nil {
+				t.Errorf("Write(%s) returned %v, want nil", level, err)
+			}
+		}
+		if err := flushProvider(p); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+		if want := conformanceFields(); !reflect.DeepEqual(fields, want) {
+			t.Errorf("Write modified fields:\n got: %v\nwant: %v", fields, want)
+		}
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for _, level := range conformanceLevels {
+			err := callProviderContext(ctx, func(ctx context.Context) error {
+				return p.Write(ctx, level, "conformance", nil)
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("Write(%s) with a canceled context returned %v, want nil or %v", level, err, context.Canceled)
+			}
+		}
+	})
+
+	t.Run("ConcurrentWrite", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		errs := writeConcurrently(p, nil)
+		for err := range errs {
+			t.Error(err)
+		}
+		if err := flushProvider(p); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	})
+
+	t.Run("CloseDuringWrite", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		start := make(chan struct{})
+		closeErrs := make(chan error, 2)
+		for i := 0; i < cap(closeErrs); i++ {
+			go func() {
+				<-start
+				closeErrs <- closeProvider(p)
+			}()
+		}
+		errs := writeConcurrently(p, start)
+		for err := range errs {
+			if !errors.Is(err, sglogger.ErrProviderClosed) {
+				t.Error(err)
+			}
+		}
+		for i := 0; i < cap(closeErrs); i++ {
+			if err := <-closeErrs; err != nil {
+				t.Errorf("Close during Write: %v", err)
+			}
+		}
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		p := newConformanceProvider(t, factory)
+		if _, ok := p.(sglogger.Flusher); !ok {
+			t.Skip("provider does not implement Flusher")
+		}
+		if err := flushProvider(p); err != nil {
+			t.Errorf("Flush before any Write: %v", err)
+		}
+		err := callProvider(func(ctx context.Context) error {
+			return p.Write(ctx, sglogger.LevelFatal, "conformance", nil)
+		})
+		if err != nil {
+			t.Errorf("Write: %v", err)
+		}
+		if err := flushProvider(p); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		RunProviderConformance(t, factory)
+	})
+}
+
+// newConformanceProvider создает провайдер и закрывает его по завершении теста.
+func newConformanceProvider(t *testing.T, factory func() sglogger.LoggerProvider) sglogger.LoggerProvider {
+	t.Helper()
+	p := factory()
+	if p == nil {
+		t.Fatal("factory returned a nil provider")
+	}
+	t.Cleanup(func() {
+		if err := closeProvider(p); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	return p
+}
+
+// conformanceFields возвращает поля с вложенными значениями основных видов.
+// Каждый вызов возвращает новый, равный предыдущим набор.
+func conformanceFields() sglogger.Fields {
+	return sglogger.Fields{
+		"string":   "value",
+		"int":      42,
+		"float":    1.5,
+		"bool":     true,
+		"duration": 1500 * time.Millisecond,
+		"time":     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"error":    errors.New("conformance error"),
+		"nil":      nil,
+		"slice":    []string{"a", "b"},
+		"map":      map[string]interface{}{"k": "v", "n": 1},
+		"nested":   sglogger.Fields{"inner": sglogger.Fields{"key": "value"}},
+	}
+}
+
+// writeConcurrently выполняет записи и проверки уровня из conformanceWriters
+// горутин; если start не nil, закрывает его после запуска горутин.
+// Возвращает закрытый канал ошибок: по одной для каждой неудачной записи.
+func writeConcurrently(p sglogger.LoggerProvider, start chan struct{}) <-chan error {
+	errs := make(chan error, conformanceWriters*conformanceWrites)
+	var wg sync.WaitGroup
+	for w := 0; w < conformanceWriters; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < conformanceWrites; i++ {
+				level := conformanceLevels[(w+i)%len(conformanceLevels)]
+				err := callProvider(func(ctx context.Context) error {
+					if !p.ShouldLog(ctx, level) {
+						return nil
+					}
+					return p.Write(ctx, level, "concurrent", sglogger.Fields{"writer": w, "seq": i})
+				})
+				if err != nil {
+					errs <- fmt.Errorf("writer %d, write %d (%s): %w", w, i, level, err)
+				}
+			}
+		}(w)
+	}
+	if start != nil {
+		close(start)
+	}
+	wg.Wait()
+	close(errs)
+	return errs
+}
+
+// flushProvider сбрасывает буферизованные записи провайдера, реализующего Flusher.
+func flushProvider(p sglogger.LoggerProvider) error {
+	flusher, ok := p.(sglogger.Flusher)
+	if !ok {
+		return nil
+	}
+	return callProvider(flusher.Flush)
+}
+
+// RunProviderConformance проверяет, что провайдеры, создаваемые newProvider,
+// соблюдают поведение LoggerProvider после Close:
+//   - повторный Close возвращает nil;
+//   - Write после Close возвращает sglogger.ErrProviderClosed и не паникует;
+//   - ShouldLog после Close возвращает false для всех уровней;
+//   - Close с отмененным контекстом возвращает nil или ошибку контекста,
+//     после чего провайдер считается закрытым, а повторный Close возвращает nil.
+//
+// Каждая проверка выполняется в отдельном подтесте с новым провайдером,
+// поэтому newProvider должен создавать независимые провайдеры, которые
+// закрываются без ошибок. Входит в TestProviderConformance, который
+// проверяет и остальные ожидания логгера. Предназначена для тестов
+// сторонних провайдеров:
+//
+//	func TestConformance(t *testing.T) {
+//		sglogtest.RunProviderConformance(t, func() sglogger.LoggerProvider {
+//			return mypkg.NewProvider(mypkg.Config{Out: io.Discard})
+//		})
+//	}
+func RunProviderConformance(t *testing.T, newProvider func() sglogger.LoggerProvider) {
+	t.Helper()
+
+	t.Run("CloseIdempotent", func(t *testing.T) {
+		p := newProvider()
+		if err := closeProvider(p); err != nil {
+			t.Fatalf("first Close: %v", err)
+		}
+		if err := closeProvider(p); err != nil {
+			t.Errorf("second Close returned %v, want nil", err)
+		}
+	})
+
+	t.Run("WriteAfterClose", func(t *testing.T) {
+		p := newProvider()
+		if err := closeProvider(p); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		for _, level := range conformanceLevels {
+			err := callProvider(func(ctx context.Context) error {
+				return p.Write(ctx, level, "after close", sglogger.Fields{"key": "value"})
+			})
+			if !errors.Is(err, sglogger.ErrProviderClosed) {
+				t.Errorf("Write(%s) after Close returned %v, want %v", level, err, sglogger.ErrProviderClosed)
+			}
+		}
+	})
+
+	t.Run("CloseCanceledContext", func(t *testing.T) {
+		p := newProvider()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := callProviderContext(ctx, p.Close)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("Close with a canceled context returned %v, want nil or %v", err, context.Canceled)
+		}
+		if err := closeProvider(p); err != nil {
+			t.Errorf("Close after a canceled Close returned %v, want nil", err)
+		}
+		err = callProvider(func(ctx context.Context) error {
+			return p.Write(ctx, sglogger.LevelError, "after close", nil)
+		})
+		if !errors.Is(err, sglogger.ErrProviderClosed) {
+			t.Errorf("Write after a canceled Close returned %v, want %v", err, sglogger.ErrProviderClosed)
+		}
+	})
+
+	t.Run("ShouldLogAfterClose", func(t *testing.T) {
+		p := newProvider()
+		if err := closeProvider(p); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		for _, level := range conformanceLevels {
+			var should bool
+			err := callProvider(func(ctx context.Context) error {
+				should = p.ShouldLog(ctx, level)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("ShouldLog(%s) after Close: %v", level, err)
+			} else if should {
+				t.Errorf("ShouldLog(%s) after Close returned true, want false", level)
+			}
+		}
+	})
+}
+
+// closeProvider закрывает провайдер, ограничивая время закрытия conformanceTimeout.
+func closeProvider(p sglogger.LoggerProvider) error {
+	return callProvider(p.Close)
+}
+
+// errConformanceTimeout возвращается callProvider, если вызов не завершился
+// за conformanceTimeout.
+var errConformanceTimeout = fmt.Errorf("call did not return within %s", conformanceTimeout)
+
+// callProvider вызывает f с контекстом, ограниченным conformanceTimeout,
+// и превращает панику в ошибку. Если f не завершается за conformanceTimeout,
+// возвращает errConformanceTimeout, оставляя f выполняться в фоне.
+func callProvider(f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	return callProviderContext(ctx, f)
+}
+
+// callProviderContext вызывает f с контекстом ctx, как callProvider.
+func callProviderContext(ctx context.Context, f func(ctx context.Context) error) error {
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		result <- f(ctx)
+	}()
+
+	timer := time.NewTimer(conformanceTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return errConformanceTimeout
+	}
+}
@@ -0,0 +1,176 @@
+package sglogtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+// mustProvider завершает тест, если провайдер не создан.
+func mustProvider(t *testing.T, p sglogger.LoggerProvider, err error) sglogger.LoggerProvider {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	return p
+}
+
+// builtinProviders возвращает фабрики встроенных провайдеров и оберток по имени.
+// Syslog пишет в локальный сокет UDP, HTTP-провайдеры - серверу,
+// принимающему любые запросы; оба закрываются по завершении теста.
+func builtinProviders(t *testing.T) map[string]func(t *testing.T) sglogger.LoggerProvider {
+	t.Helper()
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	t.Cleanup(server.Close)
+
+	inner := func() sglogger.LoggerProvider {
+		return sglogger.NewFmtProviderWithWriter(sglogger.ProviderConfig{}, io.Discard)
+	}
+	return map[string]func(t *testing.T) sglogger.LoggerProvider{
+		"fmt": func(t *testing.T) sglogger.LoggerProvider { return inner() },
+		"json": func(t *testing.T) sglogger.LoggerProvider {
+			return sglogger.NewJSONProvider(sglogger.ProviderConfig{}, io.Discard)
+		},
+		"observer": func(t *testing.T) sglogger.LoggerProvider { return NewObserver() },
+		"file": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewFileProvider(sglogger.FileProviderConfig{Path: filepath.Join(t.TempDir(), "app.log")})
+			return mustProvider(t, p, err)
+		},
+		"ring_buffer": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewRingBufferProvider(sglogger.RingBufferConfig{})
+			return mustProvider(t, p, err)
+		},
+		"syslog": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewSyslogProvider(sglogger.SyslogConfig{Network: "udp", Address: listener.LocalAddr().String()})
+			return mustProvider(t, p, err)
+		},
+		"filter": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewFilterProvider(inner(), sglogger.FilterConfig{DenyGlobs: []string{"health *"}})
+			return mustProvider(t, p, err)
+		},
+		"rate_limit": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewRateLimitProvider(inner(), sglogger.RateLimitConfig{Rate: 1000})
+			return mustProvider(t, p, err)
+		},
+		"timeout": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewTimeoutProvider(inner(), conformanceTimeout)
+			return mustProvider(t, p, err)
+		},
+		"ordered": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewOrderedProvider(inner(), sglogger.OrderedConfig{})
+			return mustProvider(t, p, err)
+		},
+		"encrypting": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewEncryptingProvider(inner(), sglogger.EncryptionConfig{KeyID: "k1", Key: make([]byte, 32)})
+			return mustProvider(t, p, err)
+		},
+		"spool": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewSpoolProvider(inner(), sglogger.SpoolConfig{Dir: t.TempDir()})
+			return mustProvider(t, p, err)
+		},
+		"loki": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewLokiProvider(sglogger.LokiConfig{URL: server.URL})
+			return mustProvider(t, p, err)
+		},
+		"sentry": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewSentryProvider(strings.Replace(server.URL, "http://", "http://key@", 1) + "/1")
+			return mustProvider(t, p, err)
+		},
+		"honeycomb": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewHoneycombProvider(sglogger.HoneycombConfig{APIKey: "key", Dataset: "logs", APIHost: server.URL})
+			return mustProvider(t, p, err)
+		},
+		"victorialogs": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewVictoriaLogsProvider(sglogger.VictoriaLogsConfig{Endpoint: server.URL})
+			return mustProvider(t, p, err)
+		},
+		"segment": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewSegmentProvider(sglogger.SegmentConfig{Dir: t.TempDir()})
+			return mustProvider(t, p, err)
+		},
+		"dual_format": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewDualFormatProvider(sglogger.DualFormatConfig{Legacy: io.Discard, Primary: io.Discard})
+			return mustProvider(t, p, err)
+		},
+		"console_file": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewConsoleFileProvider(sglogger.ConsoleFileConfig{
+				InfoWriter:  io.Discard,
+				ErrorWriter: io.Discard,
+				FilePath:    filepath.Join(t.TempDir(), "app.log"),
+			})
+			return mustProvider(t, p, err)
+		},
+		"error_budget": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewErrorBudgetProvider(sglogger.ErrorBudgetConfig{})
+			return mustProvider(t, p, err)
+		},
+		"tenant_router": func(t *testing.T) sglogger.LoggerProvider {
+			p, err := sglogger.NewTenantRouter(sglogger.TenantRouterConfig{
+				Routes:  map[string]sglogger.LoggerProvider{"acme": inner()},
+				Default: inner(),
+			})
+			return mustProvider(t, p, err)
+		},
+	}
+}
+
+func TestBuiltinProvidersConformance(t *testing.T) {
+	for name, factory := range builtinProviders(t) {
+		factory := factory
+		t.Run(name, func(t *testing.T) {
+			TestProviderConformance(t, func() sglogger.LoggerProvider { return factory(t) })
+		})
+	}
+}
+
+// openFiles возвращает количество открытых дескрипторов процесса.
+func openFiles(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("open descriptors are not available: %v", err)
+	}
+	return len(entries)
+}
+
+func TestBuiltinProvidersReleaseOnCanceledClose(t *testing.T) {
+	for name, factory := range builtinProviders(t) {
+		factory := factory
+		t.Run(name, func(t *testing.T) {
+			before := openFiles(t)
+			p := factory(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if err := p.Close(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("Close = %v, want nil or %v", err, context.Canceled)
+			}
+
+			// Провайдер может освободить ресурсы в фоне вскоре после Close
+			deadline := time.Now().Add(conformanceTimeout)
+			for openFiles(t) > before {
+				if time.Now().After(deadline) {
+					t.Fatalf("%d descriptors still open after Close with a canceled context", openFiles(t)-before)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
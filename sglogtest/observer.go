@@ -0,0 +1,72 @@
+package sglogtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+// Observer - провайдер, сохраняющий записи в памяти для проверок в тестах
+// (см. ContainsEntry, NotContainsLevel, CountByLevel, FieldValue).
+// Принимает записи всех уровней до Close; методы безопасны для одновременного
+// вызова.
+type Observer struct {
+	mu      sync.Mutex
+	entries []sglogger.Entry
+	closed  bool
+}
+
+// NewObserver создает пустой Observer.
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// Write сохраняет копию записи. После Close возвращает sglogger.ErrProviderClosed.
+func (o *Observer) Write(ctx context.Context, level sglogger.Level, message string, fields sglogger.Fields) error {
+	e := sglogger.Entry{Time: time.Now(), Level: level, Message: message, Fields: fields}.Clone()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return sglogger.ErrProviderClosed
+	}
+	o.entries = append(o.entries, e)
+	return nil
+}
+
+// ShouldLog принимает записи всех уровней до Close.
+func (o *Observer) ShouldLog(ctx context.Context, level sglogger.Level) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return !o.closed
+}
+
+// Name возвращает "observer".
+func (o *Observer) Name() string {
+	return "observer"
+}
+
+// Close прекращает прием записей; сохраненные записи остаются доступны
+// после закрытия логгера.
+func (o *Observer) Close(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	return nil
+}
+
+// Entries возвращает копию сохраненных записей в порядке записи.
+func (o *Observer) Entries() []sglogger.Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]sglogger.Entry(nil), o.entries...)
+}
+
+// Reset удаляет сохраненные записи.
+func (o *Observer) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = nil
+}
@@ -0,0 +1,58 @@
+// Package sglogtest содержит вспомогательные функции для тестов,
+// использующих пакет sglogger.
+package sglogtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+var (
+	mu sync.Mutex
+	// owners - тесты, заменившие логгер по умолчанию, от внешнего к вложенному.
+	owners []string
+)
+
+// WithDefault делает l логгером по умолчанию на время теста и восстанавливает
+// предыдущий логгер в t.Cleanup.
+//
+// Логгер по умолчанию глобален, поэтому тесты, заменяющие его, не могут
+// выполняться параллельно. WithDefault завершает тест ошибкой, если логгер
+// по умолчанию уже заменен другим тестом, который не является родителем t
+// (например, параллельным соседним подтестом). Вложенные вызовы из
+// последовательных подтестов допускаются.
+func WithDefault(t testing.TB, l sglogger.Logger) {
+	t.Helper()
+
+	name := t.Name()
+	mu.Lock()
+	if n := len(owners); n > 0 {
+		owner := owners[n-1]
+		if owner != name && !strings.HasPrefix(name, owner+"/") {
+			mu.Unlock()
+			t.Fatalf("sglogtest: %s replaces the default logger while %s holds it; tests using WithDefault must not run in parallel", name, owner)
+			return
+		}
+	}
+	owners = append(owners, name)
+	mu.Unlock()
+
+	previous := sglogger.Default()
+	sglogger.SetDefault(l)
+
+	t.Cleanup(func() {
+		sglogger.SetDefault(previous)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i := len(owners) - 1; i >= 0; i-- {
+			if owners[i] == name {
+				owners = append(owners[:i], owners[i+1:]...)
+				break
+			}
+		}
+	})
+}
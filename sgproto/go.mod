@@ -0,0 +1,5 @@
+module github.com/SergeiKhanlarov/seri-go-logger/sgproto
+
+go 1.19
+
+require google.golang.org/protobuf v1.33.0
@@ -0,0 +1,129 @@
+// Package sgproto позволяет записывать сообщения protobuf в поля sglogger
+// без предварительной сериализации.
+//
+// Пакет вынесен в отдельный модуль, чтобы основной модуль sglogger
+// не зависел от protobuf.
+//
+// Пример:
+//
+//	logger := sglogger.NewLogger(sglogger.LoggerConfig{
+//		ConvertValue: sgproto.Converter(sgproto.Options{Redact: []string{"password", "token"}}),
+//	}, sglogger.NewFieldsHandler(), provider)
+//
+//	logger.DebugWithFields(ctx, sglogger.Fields{"req": req}, "request")
+//
+// Сообщение записывается как json.RawMessage: в JSON-выводе - вложенным
+// объектом, в текстовом - строкой. Размер ограничивается
+// LoggerConfig.MaxPayloadSize.
+package sgproto
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// redactedValue заменяет значения скрываемых строковых полей.
+const redactedValue = "[REDACTED]"
+
+// Options определяет сериализацию сообщений.
+type Options struct {
+	// Redact - имена скрываемых полей на любом уровне вложенности, в виде
+	// из .proto-файла ("api_key") или JSON ("apiKey"). Поля с опцией
+	// debug_redact скрываются всегда. Строковые поля получают значение
+	// "[REDACTED]", остальные очищаются.
+	Redact []string
+	// UseProtoNames записывает имена полей как в .proto-файле, а не в JSON-виде.
+	UseProtoNames bool
+}
+
+// Converter возвращает функцию для LoggerConfig.ConvertValue, заменяющую
+// значения proto.Message результатом Value.
+func Converter(options Options) func(key string, value interface{}) (interface{}, bool) {
+	redact := redactSet(options.Redact)
+	return func(_ string, value interface{}) (interface{}, bool) {
+		m, ok := value.(proto.Message)
+		if !ok {
+			return nil, false
+		}
+		return marshal(m, options, redact), true
+	}
+}
+
+// Value сериализует сообщение в JSON через protojson, скрывая поля
+// согласно options. Ошибка сериализации записывается строкой JSON
+// с ее текстом, чтобы запись не терялась.
+func Value(m proto.Message, options Options) json.RawMessage {
+	return marshal(m, options, redactSet(options.Redact))
+}
+
+// marshal сериализует копию сообщения со скрытыми полями.
+func marshal(m proto.Message, options Options, redact map[string]bool) json.RawMessage {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return json.RawMessage("null")
+	}
+
+	m = proto.Clone(m)
+	redactMessage(m.ProtoReflect(), redact)
+
+	data, err := protojson.MarshalOptions{UseProtoNames: options.UseProtoNames}.Marshal(m)
+	if err != nil {
+		data, _ = json.Marshal("sgproto: " + err.Error())
+	}
+	return data
+}
+
+// redactSet строит множество имен скрываемых полей.
+func redactSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// redactMessage скрывает поля сообщения и рекурсивно обходит вложенные сообщения.
+func redactMessage(m protoreflect.Message, redact map[string]bool) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if sensitive(fd, redact) {
+			if fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated {
+				m.Set(fd, protoreflect.ValueOfString(redactedValue))
+			} else {
+				m.Clear(fd)
+			}
+			return true
+		}
+
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactMessage(mv.Message(), redact)
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				list := v.List()
+				for i := 0; i < list.Len(); i++ {
+					redactMessage(list.Get(i).Message(), redact)
+				}
+			}
+		case fd.Message() != nil:
+			redactMessage(v.Message(), redact)
+		}
+		return true
+	})
+}
+
+// sensitive сообщает, нужно ли скрыть поле.
+func sensitive(fd protoreflect.FieldDescriptor, redact map[string]bool) bool {
+	if redact[string(fd.Name())] || redact[fd.JSONName()] {
+		return true
+	}
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && opts.GetDebugRedact()
+}
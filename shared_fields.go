@@ -0,0 +1,15 @@
+//go:build !sglogger_debug
+
+package sglogger
+
+// sharedFieldsGuard проверяет, что провайдер не изменил общий набор полей.
+// Без тега сборки sglogger_debug проверка отключена и ничего не стоит.
+type sharedFieldsGuard struct{}
+
+// guardSharedFields запоминает состояние набора полей перед записью.
+func guardSharedFields(Fields) sharedFieldsGuard {
+	return sharedFieldsGuard{}
+}
+
+// check сообщает об изменении набора полей провайдером.
+func (sharedFieldsGuard) check(string) {}
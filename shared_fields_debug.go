@@ -0,0 +1,88 @@
+//go:build sglogger_debug
+
+package sglogger
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// sharedFieldsGuard проверяет, что провайдер не изменил общий набор полей
+// (см. Entry.Clone). Включается тегом сборки sglogger_debug, например
+// `go test -tags sglogger_debug -race ./...`: провайдер, изменивший поля
+// без копирования, вызывает панику с его именем.
+type sharedFieldsGuard struct {
+	fields   Fields
+	snapshot Fields
+}
+
+// guardSharedFields запоминает состояние набора полей перед записью.
+func guardSharedFields(fields Fields) sharedFieldsGuard {
+	return sharedFieldsGuard{fields: fields, snapshot: cloneFields(fields)}
+}
+
+// check паникует, если набор полей изменился после записи провайдером.
+func (g sharedFieldsGuard) check(provider string) {
+	if !sameFields(g.fields, g.snapshot) {
+		panic(fmt.Sprintf("sglogger: provider %q modified shared fields; clone them first (see Entry.Clone)", provider))
+	}
+}
+
+// sameFields сравнивает наборы полей, включая вложенные наборы Fields.
+// Остальные значения сравниваются как есть, без обхода их содержимого.
+func sameFields(a, b Fields) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, va := range a {
+		vb, ok := b[k]
+		if !ok {
+			return false
+		}
+		if na, ok := va.(Fields); ok {
+			nb, ok := vb.(Fields)
+			if !ok || !sameFields(na, nb) {
+				return false
+			}
+			continue
+		}
+		if !sameValue(va, vb) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValue сравнивает значения: числа с плавающей точкой - побитово (NaN равен
+// самому себе), ссылочные типы - по адресу данных, остальные - оператором ==.
+func sameValue(a, b interface{}) (same bool) {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	if ta == nil {
+		return true
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.Float64bits(va.Float()) == math.Float64bits(vb.Float())
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	case reflect.Map, reflect.Func, reflect.Chan, reflect.Pointer, reflect.UnsafePointer:
+		return va.Pointer() == vb.Pointer()
+	}
+	if !ta.Comparable() {
+		return true
+	}
+
+	// Структуры с интерфейсными полями могут содержать несравнимые значения.
+	defer func() {
+		if recover() != nil {
+			same = true
+		}
+	}()
+	return a == b
+}
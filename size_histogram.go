@@ -0,0 +1,65 @@
+package sglogger
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// SizeHistogramBuckets - количество корзин гистограммы размеров записей.
+const SizeHistogramBuckets = 32
+
+// SizeHistogram - приближенное распределение размеров записей в байтах
+// с корзинами по степеням двойки: Buckets[i] содержит количество записей
+// размером от 2^(i-1)+1 до 2^i байт, Buckets[0] - записей не больше 1 байта,
+// последняя корзина - также всех записей большего размера.
+type SizeHistogram struct {
+	Buckets [SizeHistogramBuckets]uint64
+	Count   uint64 // Общее количество записей
+	Sum     uint64 // Суммарный размер записей в байтах
+}
+
+// BucketUpperBound возвращает верхнюю границу корзины i в байтах (2^i).
+func (SizeHistogram) BucketUpperBound(i int) uint64 {
+	return 1 << uint(i)
+}
+
+// SizeRecorder определяет интерфейс провайдеров, учитывающих размеры
+// записываемых записей. Гистограмма отображается в Stats.
+type SizeRecorder interface {
+	// EntrySizes возвращает текущую гистограмму размеров записей
+	EntrySizes() SizeHistogram
+}
+
+// sizeHistogram накапливает гистограмму размеров без блокировок.
+type sizeHistogram struct {
+	buckets [SizeHistogramBuckets]uint64
+	count   uint64
+	sum     uint64
+}
+
+// observe учитывает запись размером n байт.
+func (h *sizeHistogram) observe(n int) {
+	i := 0
+	if n > 1 {
+		i = bits.Len(uint(n - 1))
+	}
+	if i >= SizeHistogramBuckets {
+		i = SizeHistogramBuckets - 1
+	}
+	atomic.AddUint64(&h.buckets[i], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddUint64(&h.sum, uint64(n))
+}
+
+// snapshot возвращает текущие значения гистограммы. Корзины читаются
+// по отдельности, поэтому при одновременной записи сумма корзин может
+// незначительно расходиться с Count.
+func (h *sizeHistogram) snapshot() SizeHistogram {
+	var s SizeHistogram
+	for i := range h.buckets {
+		s.Buckets[i] = atomic.LoadUint64(&h.buckets[i])
+	}
+	s.Count = atomic.LoadUint64(&h.count)
+	s.Sum = atomic.LoadUint64(&h.sum)
+	return s
+}
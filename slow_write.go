@@ -0,0 +1,25 @@
+package sglogger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// checkWriteBudget учитывает запись, занявшую больше LoggerConfig.SlowWriteBudget:
+// увеличивает счетчики логгера и самого медленного провайдера и сообщает
+// о ней в диагностический канал. slowest равен nil, если запись не передавалась
+// ни одному провайдеру.
+func (l *logger) checkWriteBudget(message string, elapsed time.Duration, slowest *registeredProvider, slowestTime time.Duration) {
+	if elapsed <= l.config.SlowWriteBudget {
+		return
+	}
+
+	atomic.AddUint64(&l.stats.slowWrites, 1)
+	if slowest == nil {
+		l.diagnostics.reportf("slow write", "entry %q took %s (budget %s)", message, elapsed, l.config.SlowWriteBudget)
+		return
+	}
+	atomic.AddUint64(&slowest.stats.slowWrites, 1)
+	l.diagnostics.reportf("slow write", "entry %q took %s (budget %s), slowest provider %q took %s",
+		message, elapsed, l.config.SlowWriteBudget, slowest.name, slowestTime)
+}
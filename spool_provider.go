@@ -0,0 +1,453 @@
+package sglogger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSpoolMaxBytes ограничивает суммарный размер спула по умолчанию.
+	defaultSpoolMaxBytes = 100 << 20
+
+	// defaultSpoolSegmentBytes задает размер сегмента спула по умолчанию.
+	defaultSpoolSegmentBytes = 8 << 20
+
+	// defaultSpoolRetryInterval задает паузу между попытками воспроизведения спула.
+	defaultSpoolRetryInterval = 5 * time.Second
+
+	// spoolReplayBatch ограничивает количество записей, читаемых из спула за один раз.
+	spoolReplayBatch = 128
+
+	spoolSegmentPrefix = "spool-"
+	spoolSegmentSuffix = ".ndjson"
+)
+
+// spoolSegment описывает один файл сегмента спула.
+type spoolSegment struct {
+	path string
+	size int64
+}
+
+// spoolRecord - запись, прочитанная из сегмента, и смещение сразу после нее.
+type spoolRecord struct {
+	entry Entry
+	end   int64
+}
+
+// spoolProvider оборачивает LoggerProvider (как правило, удаленный) и сохраняет
+// на диск записи, которые не удалось передать. Фоновый горутин воспроизводит
+// спул по порядку, как только внутренний провайдер снова принимает записи.
+//
+// Пока спул не пуст, новые записи также добавляются в его конец, поэтому
+// внутренний провайдер получает записи в порядке их поступления. Исключение -
+// записи, переданные напрямую одновременно с первой неудачной записью: они могут
+// опередить уже сохраненные. Доставка выполняется по принципу "хотя бы один раз":
+// после перезапуска процесса частично воспроизведенный сегмент передается заново.
+// Записи сохраняют исходное время: при воспроизведении оно передается внутреннему
+// провайдеру через ContextWithEntryTime, а не заменяется временем доставки.
+//
+// Сегменты хранятся в формате NDJSON. При достижении MaxBytes удаляются самые
+// старые сегменты. Оборванная последняя запись (например, после сбоя процесса)
+// отбрасывается при запуске.
+type spoolProvider struct {
+	closedState
+
+	inner       LoggerProvider
+	config      SpoolConfig
+	segments    []spoolSegment
+	active      *os.File
+	readOffset  int64
+	totalBytes  int64
+	nextSeq     uint64
+	mu          sync.Mutex
+	stop        chan struct{}
+	done        chan struct{}
+	diagnostics *diagnostics
+}
+
+// NewSpoolProvider создает обертку с дисковым спулом вокруг внутреннего провайдера.
+// Создает каталог спула при необходимости и подхватывает сегменты, оставшиеся
+// от предыдущего запуска. Возвращает ошибку, если каталог недоступен.
+func NewSpoolProvider(inner LoggerProvider, config SpoolConfig) (LoggerProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("sglogger: spool provider requires an inner provider")
+	}
+	if config.Dir == "" {
+		return nil, fmt.Errorf("sglogger: spool directory is not set")
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultSpoolMaxBytes
+	}
+	if config.SegmentBytes <= 0 {
+		config.SegmentBytes = defaultSpoolSegmentBytes
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultSpoolRetryInterval
+	}
+
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("sglogger: create spool directory: %w", err)
+	}
+
+	p := &spoolProvider{
+		inner:       inner,
+		config:      config,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	if err := p.loadSegments(); err != nil {
+		return nil, err
+	}
+
+	go p.run()
+	return p, nil
+}
+
+// Write передает запись внутреннему провайдеру. Если спул не пуст или запись
+// завершилась ошибкой, запись сохраняется в спул и метод возвращает nil.
+// Ошибка возвращается только если запись не удалось сохранить на диск.
+func (p *spoolProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	entry := Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	}
+
+	p.mu.Lock()
+	if p.isClosed() {
+		p.mu.Unlock()
+		return ErrProviderClosed
+	}
+
+	if len(p.segments) == 0 {
+		p.mu.Unlock()
+		if err := p.inner.Write(ctx, level, message, fields); err == nil {
+			return nil
+		}
+		p.mu.Lock()
+	}
+	defer p.mu.Unlock()
+
+	return p.appendLocked(entry)
+}
+
+// Name возвращает имя из конфигурации или имя внутреннего провайдера.
+func (p *spoolProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return ProviderName(p.inner)
+}
+
+// Describe возвращает параметры спула и описание внутреннего провайдера (см. Describer).
+func (p *spoolProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"dir":       p.config.Dir,
+		"max_bytes": p.config.MaxBytes,
+		"inner":     describeProvider(p.inner),
+	}
+}
+
+// Active делегирует проверку активности внутреннему провайдеру.
+func (p *spoolProvider) Active() bool {
+	return providerActive(p.inner)
+}
+
+// ShouldLog делегирует проверку уровня внутреннему провайдеру.
+func (p *spoolProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.inner.ShouldLog(ctx, level)
+}
+
+// Close останавливает воспроизведение, закрывает текущий сегмент и внутренний провайдер
+// (см. ChainClose).
+// Невоспроизведенные записи остаются на диске и будут переданы после следующего запуска.
+func (p *spoolProvider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.markClosed() {
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	close(p.stop)
+	return ChainClose(ctx, p.inner, func(ctx context.Context) error {
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.active == nil {
+			return nil
+		}
+		err := p.active.Close()
+		p.active = nil
+		return err
+	})
+}
+
+// run периодически воспроизводит спул до вызова Close.
+func (p *spoolProvider) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.replay()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// replay передает внутреннему провайдеру записи из спула, начиная с самой старой,
+// до первой ошибки или до опустошения спула. Провайдеры, реализующие BatchWriter,
+// получают записи пакетами.
+func (p *spoolProvider) replay() {
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		path, records, err := p.readBatch()
+		if err != nil {
+			p.diagnostics.reportf(p.Name(), "read spool: %v", err)
+			return
+		}
+		if path == "" {
+			return
+		}
+
+		entries := make([]Entry, len(records))
+		for i, record := range records {
+			entries[i] = record.entry
+		}
+		written, err := WriteEntries(context.Background(), p.inner, entries)
+		if written > 0 {
+			p.advance(path, records[written-1].end)
+		}
+		if err != nil {
+			p.diagnostics.reportf(p.Name(), "replay failed, retrying in %s: %v", p.config.RetryInterval, err)
+			return
+		}
+	}
+}
+
+// readBatch читает очередную порцию записей из самого старого сегмента.
+// Полностью прочитанные сегменты удаляются; когда удален последний сегмент,
+// спул считается пустым и новые записи снова передаются напрямую.
+// Возвращает пустой путь, если спул пуст.
+func (p *spoolProvider) readBatch() (string, []spoolRecord, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.segments) > 0 {
+		segment := p.segments[0]
+		records, err := readSpoolRecords(segment.path, p.readOffset, spoolReplayBatch)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(records) > 0 {
+			return segment.path, records, nil
+		}
+		// Сегмент прочитан до конца, остаток не содержит корректных записей
+		p.dropSegmentLocked()
+	}
+	return "", nil, nil
+}
+
+// advance фиксирует успешное воспроизведение записи, если сегмент
+// не был удален за время передачи.
+func (p *spoolProvider) advance(path string, offset int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.segments) > 0 && p.segments[0].path == path {
+		p.readOffset = offset
+	}
+}
+
+// appendLocked добавляет запись в конец спула, при необходимости начиная новый
+// сегмент и удаляя самые старые сегменты для соблюдения MaxBytes.
+// Вызывается с захваченным мьютексом.
+func (p *spoolProvider) appendLocked(entry Entry) error {
+	entry.Fields = jsonSafeFields(entry.Fields, FloatFormat{})
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("sglogger: encode spool record: %w", err)
+	}
+	line = append(line, '\n')
+	size := int64(len(line))
+
+	for len(p.segments) > 0 && p.totalBytes+size > p.config.MaxBytes {
+		p.evictOldestLocked()
+	}
+
+	last := len(p.segments) - 1
+	if p.active == nil || p.segments[last].size+size > p.config.SegmentBytes {
+		if err := p.openSegmentLocked(); err != nil {
+			return err
+		}
+		last = len(p.segments) - 1
+	}
+
+	if _, err := p.active.Write(line); err != nil {
+		return fmt.Errorf("sglogger: write spool record: %w", err)
+	}
+	p.segments[last].size += size
+	p.totalBytes += size
+	return nil
+}
+
+// openSegmentLocked закрывает текущий сегмент и создает новый.
+func (p *spoolProvider) openSegmentLocked() error {
+	if p.active != nil {
+		p.active.Close()
+		p.active = nil
+	}
+
+	path := filepath.Join(p.config.Dir, fmt.Sprintf("%s%020d%s", spoolSegmentPrefix, p.nextSeq, spoolSegmentSuffix))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("sglogger: create spool segment: %w", err)
+	}
+
+	p.nextSeq++
+	p.active = file
+	p.segments = append(p.segments, spoolSegment{path: path})
+	return nil
+}
+
+// evictOldestLocked удаляет самый старый сегмент при переполнении спула
+// и сообщает о потерянных записях в диагностический канал.
+func (p *spoolProvider) evictOldestLocked() {
+	oldest := p.segments[0]
+	p.diagnostics.reportf(p.Name(), "spool is full, evicted segment %s (%d bytes)", filepath.Base(oldest.path), oldest.size)
+	p.dropSegmentLocked()
+}
+
+// dropSegmentLocked удаляет самый старый сегмент и начинает чтение
+// следующего с начала. Используется и для воспроизведенных сегментов,
+// поэтому ни о чем не сообщает.
+func (p *spoolProvider) dropSegmentLocked() {
+	oldest := p.segments[0]
+	if len(p.segments) == 1 && p.active != nil {
+		p.active.Close()
+		p.active = nil
+	}
+
+	os.Remove(oldest.path)
+	p.segments = p.segments[1:]
+	p.totalBytes -= oldest.size
+	p.readOffset = 0
+}
+
+// loadSegments находит сегменты, оставшиеся от предыдущего запуска,
+// и отбрасывает оборванную последнюю запись в самом новом из них.
+func (p *spoolProvider) loadSegments() error {
+	matches, err := filepath.Glob(filepath.Join(p.config.Dir, spoolSegmentPrefix+"*"+spoolSegmentSuffix))
+	if err != nil {
+		return fmt.Errorf("sglogger: list spool segments: %w", err)
+	}
+	sort.Strings(matches)
+
+	for i, path := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), spoolSegmentPrefix), spoolSegmentSuffix)
+		seq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if i == len(matches)-1 {
+			if err := truncatePartialRecord(path); err != nil {
+				return err
+			}
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("sglogger: stat spool segment: %w", err)
+		}
+		if info.Size() == 0 {
+			os.Remove(path)
+			continue
+		}
+
+		p.segments = append(p.segments, spoolSegment{path: path, size: info.Size()})
+		p.totalBytes += info.Size()
+		if seq >= p.nextSeq {
+			p.nextSeq = seq + 1
+		}
+	}
+	return nil
+}
+
+// truncatePartialRecord обрезает файл по последнему символу перевода строки,
+// отбрасывая запись, запись которой была прервана.
+func truncatePartialRecord(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("sglogger: read segment: %w", err)
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+
+	size := bytes.LastIndexByte(data, '\n') + 1
+	if err := os.Truncate(path, int64(size)); err != nil {
+		return fmt.Errorf("sglogger: truncate segment: %w", err)
+	}
+	return nil
+}
+
+// readSpoolRecords читает до limit записей из сегмента начиная со смещения offset.
+// Строки, которые не удается разобрать, пропускаются.
+func readSpoolRecords(path string, offset int64, limit int) ([]spoolRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("sglogger: open spool segment: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("sglogger: seek spool segment: %w", err)
+	}
+
+	var records []spoolRecord
+	reader := bufio.NewReader(file)
+	for len(records) < limit {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// Неполная строка еще не дописана или оборвана - читаем только целые записи
+			break
+		}
+		offset += int64(len(line))
+
+		var entry Entry
+		if json.Unmarshal(line, &entry) != nil {
+			continue
+		}
+		records = append(records, spoolRecord{entry: entry, end: offset})
+	}
+	return records, nil
+}
@@ -0,0 +1,248 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyProvider отклоняет записи, пока установлен failing, и запоминает
+// принятые записи вместе с временем записи из контекста.
+type flakyProvider struct {
+	failing int32
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (p *flakyProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if atomic.LoadInt32(&p.failing) != 0 {
+		return errors.New("collector unavailable")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = append(p.entries, Entry{Time: entryTime(ctx), Level: level, Message: message, Fields: fields})
+	return nil
+}
+
+func (p *flakyProvider) ShouldLog(ctx context.Context, level Level) bool { return true }
+
+func (p *flakyProvider) Close(ctx context.Context) error { return nil }
+
+func (p *flakyProvider) setFailing(failing bool) {
+	var v int32
+	if failing {
+		v = 1
+	}
+	atomic.StoreInt32(&p.failing, v)
+}
+
+// Entries возвращает копию принятых записей.
+func (p *flakyProvider) Entries() []Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Entry(nil), p.entries...)
+}
+
+// waitMessages ждет, пока внутренний провайдер примет count записей,
+// и возвращает их сообщения.
+func waitMessages(t *testing.T, p *flakyProvider, count int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(p.Entries()) < count && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	var got []string
+	for _, e := range p.Entries() {
+		got = append(got, e.Message)
+	}
+	return got
+}
+
+// spoolLine возвращает запись спула в формате NDJSON.
+func spoolLine(message string) string {
+	return fmt.Sprintf(`{"ts":"2024-05-01T10:00:00Z","level":%d,"msg":%q}`+"\n", LevelInfo, message)
+}
+
+func TestSpoolProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     string // содержимое сегмента, оставшегося от предыдущего запуска
+		maxBytes     int64
+		segmentBytes int64
+		outage       []string // записи во время недоступности внутреннего провайдера
+		after        []string // записи после восстановления
+		want         []string
+		wantDiag     string
+	}{
+		{
+			name:   "replays in order after outage",
+			outage: []string{"a", "b", "c"},
+			after:  []string{"d"},
+			want:   []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "drops truncated last record on startup",
+			existing: spoolLine("old-1") + spoolLine("old-2") + `{"ts":"2024-05-01T10:00:00Z","lev`,
+			after:    []string{"new"},
+			want:     []string{"old-1", "old-2", "new"},
+		},
+		{
+			name:         "evicts oldest segments at max bytes",
+			maxBytes:     600, // три сегмента по три записи
+			segmentBytes: 200,
+			outage:       []string{"m00", "m01", "m02", "m03", "m04", "m05", "m06", "m07", "m08", "m09", "m10", "m11"},
+			want:         []string{"m03", "m04", "m05", "m06", "m07", "m08", "m09", "m10", "m11"},
+			wantDiag:     "spool is full, evicted segment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.existing != "" {
+				path := filepath.Join(dir, fmt.Sprintf("%s%020d%s", spoolSegmentPrefix, 7, spoolSegmentSuffix))
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			inner := &flakyProvider{}
+			inner.setFailing(true)
+			diag := &syncBuffer{}
+			p, err := NewSpoolProvider(inner, SpoolConfig{
+				Dir:           dir,
+				MaxBytes:      tt.maxBytes,
+				SegmentBytes:  tt.segmentBytes,
+				RetryInterval: 10 * time.Millisecond,
+				Diagnostics:   &DiagnosticsConfig{Output: diag, Rate: 1000},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.Close(context.Background())
+
+			// Фиксированное время делает размер записей спула предсказуемым
+			ctx := ContextWithEntryTime(context.Background(), time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+			for _, message := range tt.outage {
+				if err := p.Write(ctx, LevelInfo, message, nil); err != nil {
+					t.Fatalf("Write(%q) = %v, want nil while spooling", message, err)
+				}
+			}
+			if got := inner.Entries(); len(got) != 0 {
+				t.Fatalf("inner received %d entries during outage", len(got))
+			}
+
+			inner.setFailing(false)
+			for _, message := range tt.after {
+				if err := p.Write(ctx, LevelInfo, message, nil); err != nil {
+					t.Fatalf("Write(%q) = %v", message, err)
+				}
+			}
+
+			if got := waitMessages(t, inner, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inner received %q, want %q", got, tt.want)
+			}
+			if tt.wantDiag != "" && !strings.Contains(diag.String(), tt.wantDiag) {
+				t.Errorf("diagnostics = %q, want %q", diag.String(), tt.wantDiag)
+			}
+			if tt.wantDiag == "" && strings.Contains(diag.String(), "evicted") {
+				t.Errorf("unexpected eviction report: %q", diag.String())
+			}
+		})
+	}
+}
+
+func TestSpoolProviderKeepsSegmentsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	inner := &flakyProvider{}
+	inner.setFailing(true)
+
+	config := SpoolConfig{Dir: dir, RetryInterval: 10 * time.Millisecond, Diagnostics: &DiagnosticsConfig{Disabled: true}}
+	p, err := NewSpoolProvider(inner, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range []string{"a", "b"} {
+		p.Write(context.Background(), LevelInfo, message, nil)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	inner.setFailing(false)
+	p, err = NewSpoolProvider(inner, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	want := []string{"a", "b"}
+	if got := waitMessages(t, inner, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("inner received %q after restart, want %q", got, want)
+	}
+}
+
+func TestSpoolProviderReplayKeepsEntryTime(t *testing.T) {
+	// Записи, сохраненные предыдущим запуском, и записи текущей недоступности
+	old := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	path := filepath.Join(dir, fmt.Sprintf("%s%020d%s", spoolSegmentPrefix, 1, spoolSegmentSuffix))
+	if err := os.WriteFile(path, []byte(spoolLine("old-1")+spoolLine("old-2")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := &flakyProvider{}
+	inner.setFailing(true)
+	p, err := NewSpoolProvider(inner, SpoolConfig{Dir: dir, RetryInterval: 10 * time.Millisecond, Diagnostics: &DiagnosticsConfig{Disabled: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	outage := time.Now().Add(-time.Hour).Truncate(time.Second)
+	p.Write(ContextWithEntryTime(context.Background(), outage), LevelInfo, "during-outage", nil)
+	time.Sleep(30 * time.Millisecond)
+	inner.setFailing(false)
+
+	want := map[string]time.Time{"old-1": old, "old-2": old, "during-outage": outage}
+	waitMessages(t, inner, len(want))
+	for _, e := range inner.Entries() {
+		if !e.Time.Equal(want[e.Message]) {
+			t.Errorf("%s replayed with time %v, want %v", e.Message, e.Time, want[e.Message])
+		}
+	}
+}
+
+func TestSpoolProviderReplayEmitsEntryTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, fmt.Sprintf("%s%020d%s", spoolSegmentPrefix, 1, spoolSegmentSuffix))
+	if err := os.WriteFile(path, []byte(spoolLine("old-1")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &syncBuffer{}
+	inner := NewJSONProvider(ProviderConfig{}, out)
+	p, err := NewSpoolProvider(inner, SpoolConfig{Dir: dir, RetryInterval: 10 * time.Millisecond, Diagnostics: &DiagnosticsConfig{Disabled: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "old-1") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if line := out.String(); !strings.Contains(line, `"ts":"2024-05-01T10:00:00Z"`) {
+		t.Errorf("replayed line = %q, want the original timestamp", line)
+	}
+}
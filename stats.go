@@ -0,0 +1,69 @@
+package sglogger
+
+import "sync/atomic"
+
+// LoggerStats содержит счетчики работы логгера.
+type LoggerStats struct {
+	Sampled            uint64                   // Количество сообщений, отброшенных семплированием
+	Fallback           uint64                   // Количество сообщений, выведенных в stderr после ошибок всех провайдеров
+	FallbackSuppressed uint64                   // Количество сообщений, не выведенных в stderr из-за ограничения частоты
+	SlowWrites         uint64                   // Количество сообщений, запись которых превысила SlowWriteBudget
+	Sanitized          uint64                   // Количество сообщений, исправленных SanitizeUTF8 или NormalizeUnicode
+	Providers          map[string]ProviderStats // Счетчики провайдеров по их именам
+}
+
+// ProviderStats содержит счетчики отдельного провайдера.
+type ProviderStats struct {
+	Written    uint64         // Количество успешно записанных сообщений
+	Errors     uint64         // Количество сообщений, запись которых завершилась ошибкой
+	Inactive   bool           // Провайдер неактивен (см. Gated)
+	SlowWrites uint64         // Количество медленных записей, в которых провайдер был самым медленным
+	Filtered   uint64         // Количество записей, отброшенных фильтром провайдера (см. Filterer); входят в Written
+	Panics     uint64         // Количество перехваченных паник в методах провайдера
+	Disabled   bool           // Провайдер отключен после MaxProviderPanics паник подряд
+	Sizes      *SizeHistogram // Размеры записанных записей (см. SizeRecorder), nil для остальных провайдеров
+	Order      *OrderStats    // Счетчики упорядочивания (см. OrderRecorder), nil для остальных провайдеров
+	Dropped    uint64         // Записи, принятые в очередь, но не доставленные (см. DropRecorder)
+}
+
+// loggerStats хранит счетчики логгера и обновляется атомарно.
+type loggerStats struct {
+	sampled            uint64
+	fallback           uint64
+	fallbackSuppressed uint64
+	slowWrites         uint64
+	sanitized          uint64
+}
+
+// providerStats хранит счетчики провайдера и обновляется атомарно.
+type providerStats struct {
+	written    uint64
+	errors     uint64
+	slowWrites uint64
+
+	panics            uint64
+	consecutivePanics int32
+	disabled          int32
+}
+
+// snapshot возвращает текущие значения счетчиков.
+func (s *loggerStats) snapshot() LoggerStats {
+	return LoggerStats{
+		Sampled:            atomic.LoadUint64(&s.sampled),
+		Fallback:           atomic.LoadUint64(&s.fallback),
+		FallbackSuppressed: atomic.LoadUint64(&s.fallbackSuppressed),
+		SlowWrites:         atomic.LoadUint64(&s.slowWrites),
+		Sanitized:          atomic.LoadUint64(&s.sanitized),
+	}
+}
+
+// snapshot возвращает текущие значения счетчиков.
+func (s *providerStats) snapshot() ProviderStats {
+	return ProviderStats{
+		Written:    atomic.LoadUint64(&s.written),
+		Errors:     atomic.LoadUint64(&s.errors),
+		SlowWrites: atomic.LoadUint64(&s.slowWrites),
+		Panics:     atomic.LoadUint64(&s.panics),
+		Disabled:   atomic.LoadInt32(&s.disabled) != 0,
+	}
+}
@@ -0,0 +1,161 @@
+// Package stdshim повторяет функции стандартного пакета log поверх логгера
+// по умолчанию sglogger (см. sglogger.Default и sglogger.SetDefault),
+// чтобы перевести файл на sglogger заменой импорта:
+//
+//	import log "github.com/SergeiKhanlarov/seri-go-logger/stdshim"
+//
+// Print*, Output и Writer пишут сообщения уровня Info. Fatal* пишут сообщение
+// через Logger.Fatal, который сбрасывает провайдеры и завершает процесс.
+// Panic* пишут сообщение уровня Error и вызывают panic с текстом сообщения,
+// как log.Panic. Префикс SetPrefix добавляется к началу сообщения; флаги
+// SetFlags сохраняются, но не влияют на вывод: время, уровень и место вызова
+// определяют провайдеры. SetOutput ничего не делает.
+package stdshim
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+)
+
+var (
+	mu     sync.RWMutex
+	prefix string
+	flags  = 3 // log.LstdFlags
+
+	setOutputWarning sync.Once
+)
+
+// Print пишет сообщение, сформированное как fmt.Sprint.
+func Print(v ...interface{}) {
+	info(fmt.Sprint(v...))
+}
+
+// Printf пишет сообщение, сформированное как fmt.Sprintf.
+func Printf(format string, v ...interface{}) {
+	sglogger.Default().Info(context.Background(), prefixedFormat(format), v...)
+}
+
+// Println пишет сообщение, сформированное как fmt.Sprintln, без завершающего перевода строки.
+func Println(v ...interface{}) {
+	info(sprintln(v...))
+}
+
+// Fatal пишет сообщение как Print через Logger.Fatal и завершает процесс.
+func Fatal(v ...interface{}) {
+	sglogger.Default().Fatal(context.Background(), "%s", Prefix()+fmt.Sprint(v...))
+}
+
+// Fatalf пишет сообщение как Printf через Logger.Fatal и завершает процесс.
+func Fatalf(format string, v ...interface{}) {
+	sglogger.Default().Fatal(context.Background(), prefixedFormat(format), v...)
+}
+
+// Fatalln пишет сообщение как Println через Logger.Fatal и завершает процесс.
+func Fatalln(v ...interface{}) {
+	sglogger.Default().Fatal(context.Background(), "%s", Prefix()+sprintln(v...))
+}
+
+// Panic пишет сообщение как Print уровнем Error и вызывает panic.
+func Panic(v ...interface{}) {
+	panicMessage(fmt.Sprint(v...))
+}
+
+// Panicf пишет сообщение как Printf уровнем Error и вызывает panic.
+func Panicf(format string, v ...interface{}) {
+	panicMessage(fmt.Sprintf(format, v...))
+}
+
+// Panicln пишет сообщение как Println уровнем Error и вызывает panic.
+func Panicln(v ...interface{}) {
+	panicMessage(sprintln(v...))
+}
+
+// Output пишет s уровнем Info. calldepth не используется.
+func Output(calldepth int, s string) error {
+	info(strings.TrimSuffix(s, "\n"))
+	return nil
+}
+
+// SetPrefix задает префикс сообщений.
+func SetPrefix(p string) {
+	mu.Lock()
+	prefix = p
+	mu.Unlock()
+}
+
+// Prefix возвращает префикс сообщений.
+func Prefix() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return prefix
+}
+
+// SetFlags сохраняет флаги для Flags; на вывод они не влияют.
+func SetFlags(f int) {
+	mu.Lock()
+	flags = f
+	mu.Unlock()
+}
+
+// Flags возвращает флаги, заданные SetFlags.
+func Flags() int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return flags
+}
+
+// SetOutput ничего не делает: вывод определяется провайдерами логгера
+// по умолчанию. При первом вызове в stderr выводится предупреждение.
+func SetOutput(w io.Writer) {
+	setOutputWarning.Do(func() {
+		fmt.Fprintf(os.Stderr, "%s sglogger diagnostics: stdshim: SetOutput is ignored, output goes to the default logger\n",
+			time.Now().Format(time.RFC3339))
+	})
+}
+
+// Writer возвращает writer, каждая запись в который пишется сообщением
+// уровня Info (например, для http.Server.ErrorLog через log.New(stdshim.Writer(), "", 0)).
+func Writer() io.Writer {
+	return writer{}
+}
+
+// writer пишет каждую запись сообщением уровня Info.
+type writer struct{}
+
+// Write пишет p без завершающего перевода строки.
+func (writer) Write(p []byte) (int, error) {
+	info(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// info пишет готовое сообщение с префиксом уровнем Info.
+func info(message string) {
+	sglogger.Default().Info(context.Background(), "%s", Prefix()+message)
+}
+
+// panicMessage пишет сообщение с префиксом уровнем Error и, как log.Panic,
+// вызывает panic с сообщением без префикса.
+func panicMessage(message string) {
+	sglogger.Default().Error(context.Background(), "%s", Prefix()+message)
+	panic(message)
+}
+
+// prefixedFormat добавляет префикс к формату, экранируя в нем символы %.
+func prefixedFormat(format string) string {
+	if p := Prefix(); p != "" {
+		return strings.ReplaceAll(p, "%", "%%") + format
+	}
+	return format
+}
+
+// sprintln форматирует как fmt.Sprintln без завершающего перевода строки.
+func sprintln(v ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
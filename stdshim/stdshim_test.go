@@ -0,0 +1,171 @@
+package stdshim
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	sglogger "github.com/SergeiKhanlarov/seri-go-logger"
+	"github.com/SergeiKhanlarov/seri-go-logger/sglogtest"
+)
+
+// fatalRecorder подменяет Fatal логгера, чтобы проверять Fatal* без
+// завершения процесса; остальные методы передаются логгеру.
+type fatalRecorder struct {
+	sglogger.Logger
+
+	fatals []string
+}
+
+func (l *fatalRecorder) Fatal(ctx context.Context, format string, args ...interface{}) {
+	l.fatals = append(l.fatals, fmt.Sprintf(format, args...))
+}
+
+// observe подменяет логгер по умолчанию логгером, пишущим в Observer,
+// и задает префикс на время теста.
+func observe(t *testing.T, prefix string) (*sglogtest.Observer, *fatalRecorder) {
+	t.Helper()
+	observer := sglogtest.NewObserver()
+	logger := &fatalRecorder{Logger: sglogger.NewLogger(sglogger.LoggerConfig{}, sglogger.NewFieldsHandler(), observer)}
+	sglogtest.WithDefault(t, logger)
+
+	old := Prefix()
+	SetPrefix(prefix)
+	t.Cleanup(func() { SetPrefix(old) })
+	return observer, logger
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		call   func()
+		want   string
+	}{
+		{name: "Print", call: func() { Print("a", 1, 2, "b") }, want: "a1 2b"},
+		{name: "Printf", call: func() { Printf("%d items in %s", 3, "cart") }, want: "3 items in cart"},
+		{name: "Println", call: func() { Println("a", 1, "b") }, want: "a 1 b"},
+		{name: "Output", call: func() { Output(2, "line\n") }, want: "line"},
+		{name: "Writer", call: func() { io.WriteString(Writer(), "from writer\n") }, want: "from writer"},
+		{name: "Print with prefix", prefix: "app: ", call: func() { Print("started") }, want: "app: started"},
+		{name: "Printf with percent in prefix", prefix: "100%: ", call: func() { Printf("%s", "done") }, want: "100%: done"},
+		{name: "Println with prefix", prefix: "[db] ", call: func() { Println("ready", true) }, want: "[db] ready true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := observe(t, tt.prefix)
+			tt.call()
+
+			entries := observer.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if entries[0].Level != sglogger.LevelInfo || entries[0].Message != tt.want {
+				t.Errorf("entry = %v %q, want info %q", entries[0].Level, entries[0].Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestPanic(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func()
+		want      string
+		wantPanic string
+	}{
+		{name: "Panic", call: func() { Panic("bad ", 42) }, want: "app: bad 42", wantPanic: "bad 42"},
+		{name: "Panicf", call: func() { Panicf("bad %d", 42) }, want: "app: bad 42", wantPanic: "bad 42"},
+		{name: "Panicln", call: func() { Panicln("bad", 42) }, want: "app: bad 42", wantPanic: "bad 42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := observe(t, "app: ")
+
+			func() {
+				defer func() {
+					// Как log.Panic, значение паники - сообщение без префикса
+					if r := recover(); r != tt.wantPanic {
+						t.Errorf("panic value = %v, want %q", r, tt.wantPanic)
+					}
+				}()
+				tt.call()
+			}()
+
+			entries := observer.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if entries[0].Level != sglogger.LevelError || entries[0].Message != tt.want {
+				t.Errorf("entry = %v %q, want error %q", entries[0].Level, entries[0].Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestFatal(t *testing.T) {
+	tests := []struct {
+		name string
+		call func()
+		want string
+	}{
+		{name: "Fatal", call: func() { Fatal("lost ", "connection") }, want: "app: lost connection"},
+		{name: "Fatalf", call: func() { Fatalf("lost %s after %d tries", "connection", 3) }, want: "app: lost connection after 3 tries"},
+		{name: "Fatalln", call: func() { Fatalln("lost", "connection") }, want: "app: lost connection"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, logger := observe(t, "app: ")
+			tt.call()
+
+			if len(logger.fatals) != 1 || logger.fatals[0] != tt.want {
+				t.Errorf("Fatal messages = %q, want [%q]", logger.fatals, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	old := Flags()
+	defer SetFlags(old)
+
+	if old != 3 {
+		t.Errorf("default Flags = %d, want log.LstdFlags", old)
+	}
+	SetFlags(0)
+	if got := Flags(); got != 0 {
+		t.Errorf("Flags = %d after SetFlags(0)", got)
+	}
+}
+
+func TestSetOutputWarnsOnce(t *testing.T) {
+	observer, _ := observe(t, "")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	SetOutput(io.Discard)
+	SetOutput(io.Discard)
+	os.Stderr = stderr
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	if got := strings.Count(string(out), "SetOutput is ignored"); got != 1 {
+		t.Errorf("stderr = %q, want one warning", out)
+	}
+
+	// Вывод по-прежнему идет в логгер по умолчанию
+	Print("still logged")
+	if entries := observer.Entries(); len(entries) != 1 || entries[0].Message != "still logged" {
+		t.Errorf("entries after SetOutput = %v", entries)
+	}
+}
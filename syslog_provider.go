@@ -0,0 +1,486 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSyslogTimeout задает тайм-аут подключения и записи по умолчанию.
+	defaultSyslogTimeout = 5 * time.Second
+
+	// syslogUDPMaxSize - размер датаграммы UDP по умолчанию: RFC 5424
+	// рекомендует получателям принимать сообщения до 2048 байт.
+	syslogUDPMaxSize = 2048
+
+	// syslogLocalMaxSize - размер датаграммы локального сокета по умолчанию.
+	syslogLocalMaxSize = 8 << 10
+
+	// syslogStructuredDataID - SD-ID элемента с полями записи. 32473 -
+	// номер предприятия IANA, зарезервированный для примеров и частного
+	// использования.
+	syslogStructuredDataID = "fields@32473"
+
+	// Ограничения длины имени параметра, APP-NAME и HOSTNAME по RFC 5424.
+	syslogParamNameMax = 32
+	syslogAppNameMax   = 48
+	syslogHostnameMax  = 255
+)
+
+// DefaultSyslogSeverities - соответствие уровней записей уровням важности
+// syslog по умолчанию. Изменение карты влияет на провайдеры, созданные после
+// него; для одного провайдера используйте SyslogConfig.Severities.
+var DefaultSyslogSeverities = map[Level]int{
+	LevelDebug: 7, // DEBUG
+	LevelInfo:  6, // INFO
+	LevelWarn:  4, // WARNING
+	LevelError: 3, // ERR
+	LevelFatal: 2, // CRIT
+}
+
+// syslogLocalSockets - пути сокета локального демона syslog на разных системах.
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogProvider записывает сообщения в демон syslog: локальный через
+// Unix-сокет или удаленный по UDP или TCP. Сообщения формируются без пакета
+// log/syslog, поэтому провайдер доступен на всех платформах.
+//
+// Поверх TCP сообщения RFC 5424 передаются с подсчетом октетов
+// ("<длина> <сообщение>", RFC 6587), остальные потоковые соединения
+// разделяют сообщения переводом строки. Датаграммы ограничены по размеру
+// согласно SyslogConfig.Oversize.
+type syslogProvider struct {
+	closedState
+
+	config    SyslogConfig
+	network   string
+	address   string
+	hostname  string
+	appName   string
+	pid       string
+	severity  [LevelFatal + 1]int
+	conn      net.Conn
+	mu        sync.Mutex
+	fitter    *datagramFitter
+	sizes     sizeHistogram
+	leakCheck *leakCheck
+}
+
+// NewSyslogProvider создает провайдер syslog и подключается к демону.
+// Уровни соответствуют уровням важности syslog из config.Severities,
+// по умолчанию - из DefaultSyslogSeverities: LevelDebug - DEBUG,
+// LevelInfo - INFO, LevelWarn - WARNING, LevelError - ERR, LevelFatal - CRIT.
+// Поля записываются как структурированные данные RFC 5424
+// ([fields@32473 key="value" ...]) или, в формате RFC 3164, добавляются
+// к сообщению как {key=value ...}.
+//
+// Если соединение разорвано, провайдер подключается заново и повторяет
+// отправку один раз; если и это не удалось, Write возвращает ошибку,
+// а следующая запись снова пытается подключиться. Как и в log/syslog,
+// сообщение, отправленное по TCP до того, как система обнаружила разрыв
+// соединения, может быть потеряно. Close закрывает соединение.
+// Возвращает ошибку, если параметры некорректны или подключиться не удалось.
+func NewSyslogProvider(config SyslogConfig) (LoggerProvider, error) {
+	switch config.Network {
+	case "":
+		if config.Address != "" {
+			return nil, fmt.Errorf("sglogger: syslog address %q requires a network", config.Address)
+		}
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+		if config.Address == "" {
+			return nil, fmt.Errorf("sglogger: syslog address is not set")
+		}
+	default:
+		return nil, fmt.Errorf("sglogger: unsupported syslog network %q", config.Network)
+	}
+	switch config.Format {
+	case SyslogRFC5424, SyslogRFC3164:
+	default:
+		return nil, fmt.Errorf("sglogger: invalid syslog format %d", config.Format)
+	}
+	if config.Facility == 0 {
+		config.Facility = SyslogUser
+	}
+	if config.Facility < 0 || config.Facility > SyslogLocal7 {
+		return nil, fmt.Errorf("sglogger: invalid syslog facility %d", config.Facility)
+	}
+	severity, err := syslogSeverities(config.Severities)
+	if err != nil {
+		return nil, err
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultSyslogTimeout
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultSyslogTimeout
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.Level = clampLevel(config.Level)
+
+	p := &syslogProvider{
+		config:   config,
+		network:  config.Network,
+		address:  config.Address,
+		hostname: config.Hostname,
+		appName:  config.AppName,
+		pid:      strconv.Itoa(os.Getpid()),
+		severity: severity,
+	}
+	if p.hostname == "" {
+		p.hostname, _ = os.Hostname()
+	}
+	if p.appName == "" {
+		p.appName = filepath.Base(os.Args[0])
+	}
+
+	if err := p.dialLocked(); err != nil {
+		return nil, err
+	}
+
+	maxSize := 0
+	switch p.network {
+	case "unixgram":
+		maxSize = syslogLocalMaxSize
+	case "udp", "udp4", "udp6":
+		maxSize = syslogUDPMaxSize
+	}
+	if maxSize > 0 {
+		fitter, err := newDatagramFitter(config.Oversize, maxSize, p.encode, nil)
+		if err != nil {
+			p.conn.Close()
+			return nil, err
+		}
+		p.fitter = fitter
+	}
+	if !config.LeakCheck {
+		return p, nil
+	}
+	// fitter ссылается на p, а финализатор объекта из цикла ссылок может
+	// не выполниться, поэтому он устанавливается на обертку
+	h := &syslogProviderHandle{p}
+	p.leakCheck = newLeakCheck(true, h, "syslog provider "+p.address, newDiagnostics(config.Diagnostics))
+	return h, nil
+}
+
+// syslogProviderHandle - провайдер, возвращаемый NewSyslogProvider при LeakCheck.
+type syslogProviderHandle struct{ *syslogProvider }
+
+// Write отправляет запись демону syslog.
+func (p *syslogProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+
+	e := Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	}
+	var packets [][]byte
+	if p.fitter != nil {
+		var err error
+		if packets, err = p.fitter.fit(e); err != nil {
+			return err
+		}
+	} else {
+		packets = [][]byte{p.frame(p.encode(e))}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	for _, packet := range packets {
+		if err := p.sendLocked(packet); err != nil {
+			return err
+		}
+		p.sizes.observe(len(packet))
+	}
+	return nil
+}
+
+// sendLocked отправляет сообщение, подключаясь заново, если соединения нет
+// или отправка не удалась. Вызывается с захваченным мьютексом.
+func (p *syslogProvider) sendLocked(packet []byte) error {
+	reconnected := false
+	if p.conn == nil {
+		if err := p.dialLocked(); err != nil {
+			return err
+		}
+		reconnected = true
+	}
+
+	err := p.writeLocked(packet)
+	if err == nil || reconnected {
+		return err
+	}
+	if err := p.dialLocked(); err != nil {
+		return err
+	}
+	return p.writeLocked(packet)
+}
+
+// writeLocked записывает сообщение в текущее соединение; при ошибке
+// соединение закрывается. Вызывается с захваченным мьютексом.
+func (p *syslogProvider) writeLocked(packet []byte) error {
+	p.conn.SetWriteDeadline(time.Now().Add(p.config.WriteTimeout))
+	if _, err := p.conn.Write(packet); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return fmt.Errorf("sglogger: write syslog: %w", err)
+	}
+	return nil
+}
+
+// dialLocked подключается к демону syslog. Для локального демона при первом
+// подключении перебираются известные пути сокета, сначала как датаграммного,
+// затем как потокового; повторные подключения используют найденный сокет.
+// Вызывается с захваченным мьютексом или при создании провайдера.
+func (p *syslogProvider) dialLocked() error {
+	if p.address != "" {
+		conn, err := net.DialTimeout(p.network, p.address, p.config.DialTimeout)
+		if err != nil {
+			return fmt.Errorf("sglogger: connect to syslog: %w", err)
+		}
+		p.conn = conn
+		return nil
+	}
+
+	var err error
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogLocalSockets {
+			var conn net.Conn
+			if conn, err = net.DialTimeout(network, path, p.config.DialTimeout); err == nil {
+				p.conn, p.network, p.address = conn, network, path
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("sglogger: connect to local syslog: %w", err)
+}
+
+// frame разделяет сообщения потокового соединения.
+func (p *syslogProvider) frame(message []byte) []byte {
+	switch p.network {
+	case "tcp", "tcp4", "tcp6":
+		if p.config.Format == SyslogRFC5424 {
+			framed := strconv.AppendInt(make([]byte, 0, len(message)+8), int64(len(message)), 10)
+			framed = append(framed, ' ')
+			return append(framed, message...)
+		}
+	case "unixgram", "udp", "udp4", "udp6":
+		return message
+	}
+	return append(message, '\n')
+}
+
+// encode формирует сообщение syslog в настроенном формате.
+func (p *syslogProvider) encode(e Entry) []byte {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(p.config.Facility)*8+int64(p.severity[clampLevel(e.Level)]), 10)
+	buf = append(buf, '>')
+
+	if p.config.Format == SyslogRFC3164 {
+		buf = e.Time.Local().AppendFormat(buf, time.Stamp)
+		buf = append(buf, ' ')
+		buf = appendSyslogHeader(buf, p.hostname, syslogHostnameMax)
+		buf = append(buf, ' ')
+		buf = appendSyslogHeader(buf, p.appName, syslogAppNameMax)
+		buf = append(buf, '[')
+		buf = append(buf, p.pid...)
+		buf = append(buf, "]: "...)
+		buf = append(buf, e.Message...)
+		if len(e.Fields) > 0 {
+			buf = append(buf, ' ')
+			buf = appendFields(buf, e.Fields, "", p.config.FloatFormat)
+		}
+		return buf
+	}
+
+	buf = append(buf, "1 "...)
+	buf = e.Time.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+	buf = append(buf, ' ')
+	buf = appendSyslogHeader(buf, p.hostname, syslogHostnameMax)
+	buf = append(buf, ' ')
+	buf = appendSyslogHeader(buf, p.appName, syslogAppNameMax)
+	buf = append(buf, ' ')
+	buf = append(buf, p.pid...)
+	buf = append(buf, " - "...)
+	buf = appendSyslogStructuredData(buf, e.Fields)
+	if e.Message != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, e.Message...)
+	}
+	return buf
+}
+
+// syslogSeverities возвращает уровни важности syslog для всех уровней
+// записей: из severities, а для отсутствующих в ней уровней - из
+// DefaultSyslogSeverities. Возвращает ошибку для неизвестного уровня
+// или уровня важности вне диапазона 0..7.
+func syslogSeverities(severities map[Level]int) ([LevelFatal + 1]int, error) {
+	var result [LevelFatal + 1]int
+	for level := LevelDebug; level <= LevelFatal; level++ {
+		severity, ok := severities[level]
+		if !ok {
+			severity = DefaultSyslogSeverities[level]
+		}
+		if severity < 0 || severity > 7 {
+			return result, fmt.Errorf("sglogger: invalid syslog severity %d for level %s", severity, level)
+		}
+		result[level] = severity
+	}
+	for level := range severities {
+		if !level.IsValid() {
+			return result, fmt.Errorf("sglogger: syslog severities contain invalid %s", level)
+		}
+	}
+	return result, nil
+}
+
+// appendSyslogHeader добавляет поле заголовка: печатаемые символы ASCII
+// без пробелов, не длиннее max; пустое значение заменяется на "-".
+func appendSyslogHeader(buf []byte, s string, max int) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' {
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+// appendSyslogStructuredData добавляет поля как элемент структурированных
+// данных RFC 5424, отсортированные по имени, или "-", если полей нет.
+// Недопустимые символы имен заменяются на "_", в значениях экранируются
+// '"', '\' и ']'.
+func appendSyslogStructuredData(buf []byte, fields Fields) []byte {
+	if len(fields) == 0 {
+		return append(buf, '-')
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf = append(buf, '[')
+	buf = append(buf, syslogStructuredDataID...)
+	for _, k := range keys {
+		buf = append(buf, ' ')
+		name := k
+		if len(name) > syslogParamNameMax {
+			name = name[:syslogParamNameMax]
+		}
+		if name == "" {
+			name = "_"
+		}
+		for i := 0; i < len(name); i++ {
+			c := name[i]
+			if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+				c = '_'
+			}
+			buf = append(buf, c)
+		}
+		buf = append(buf, '=', '"')
+		value := fieldString(fields[k])
+		for i := 0; i < len(value); i++ {
+			if c := value[i]; c == '"' || c == '\\' || c == ']' {
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, value[i])
+		}
+		buf = append(buf, '"')
+	}
+	return append(buf, ']')
+}
+
+// Name возвращает имя провайдера из конфигурации или "syslog" по умолчанию.
+func (p *syslogProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "syslog"
+}
+
+// Describe возвращает адрес, формат и уровень провайдера (см. Describer).
+func (p *syslogProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["network"] = p.network
+	description["address"] = p.address
+	description["facility"] = int(p.config.Facility)
+	description["format"] = "rfc5424"
+	if p.config.Format == SyslogRFC3164 {
+		description["format"] = "rfc3164"
+	}
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *syslogProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *syslogProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// EntrySizes возвращает гистограмму размеров отправленных сообщений.
+func (p *syslogProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// Close закрывает соединение с демоном syslog.
+func (p *syslogProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.markClosed() {
+		return nil
+	}
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	if err != nil {
+		return fmt.Errorf("sglogger: close syslog connection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package sglogger
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// tailBufferSize - количество записей, ожидающих отправки одному клиенту.
+	// При медленном клиенте записи сверх него отбрасываются.
+	tailBufferSize = 256
+
+	// tailHeartbeat - период отправки комментария, по которому обнаруживается
+	// отключение клиента, пока новых записей нет.
+	tailHeartbeat = 15 * time.Second
+
+	// tailWriteTimeout ограничивает отправку одного события.
+	tailWriteTimeout = 10 * time.Second
+)
+
+// TailHandler возвращает HTTP-обработчик, передающий записи ring в формате
+// Server-Sent Events: сначала сохраненные записи, затем новые по мере появления.
+// Каждое событие содержит одну запись в формате NewJSONFormatter.
+//
+// Параметры запроса:
+//   - level - минимальный уровень записей, например level=warning;
+//   - field=ключ:значение - точное совпадение значения поля, можно указать
+//     несколько раз, тогда должны совпасть все.
+//
+// Каждому клиенту выделяется очередь из 256 записей. Если клиент не успевает
+// их принимать, новые записи отбрасываются, а клиенту отправляется событие
+// "dropped" с количеством отброшенных записей ({"dropped":N}). Отправка
+// события ограничена 10 секундами; при ошибке записи или отключении клиента
+// обработчик завершается. После закрытия ring отправляется событие "end".
+// Аутентификация не выполняется: обработчик должен подключаться
+// к маршрутизатору, который ее проверяет.
+func TailHandler(ring *RingBufferProvider) http.Handler {
+	formatter := NewJSONFormatter(ProviderConfig{})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseTailFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rc := http.NewResponseController(w)
+
+		recent, sub := ring.subscribe(tailBufferSize)
+		defer ring.unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		var buf []byte
+		send := func(data []byte) bool {
+			rc.SetWriteDeadline(time.Now().Add(tailWriteTimeout))
+			if _, err := w.Write(data); err != nil {
+				return false
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return false
+			}
+			return true
+		}
+		event := func(e Entry) bool {
+			if !filter.matches(e) {
+				return true
+			}
+			buf = append(buf[:0], "data: "...)
+			buf = formatter.AppendFormat(buf, e)
+			buf = append(buf, '\n')
+			return send(buf)
+		}
+
+		for _, e := range recent {
+			if !event(e) {
+				return
+			}
+		}
+		if !send(nil) {
+			return
+		}
+
+		heartbeat := time.NewTicker(tailHeartbeat)
+		defer heartbeat.Stop()
+
+		var reported uint64
+		reportDropped := func() bool {
+			dropped := atomic.LoadUint64(&sub.dropped)
+			if dropped == reported {
+				return true
+			}
+			buf = append(buf[:0], "event: dropped\ndata: {\"dropped\":"...)
+			buf = strconv.AppendUint(buf, dropped-reported, 10)
+			buf = append(buf, "}\n\n"...)
+			reported = dropped
+			return send(buf)
+		}
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if !send([]byte(": ping\n\n")) {
+					return
+				}
+			case e, ok := <-sub.ch:
+				if !ok {
+					if reportDropped() {
+						send([]byte("event: end\ndata: {}\n\n"))
+					}
+					return
+				}
+				if !event(e) {
+					return
+				}
+				// Отброшенные записи новее записей, уже стоявших в очереди,
+				// поэтому уведомление отправляется после них.
+				if len(sub.ch) == 0 && !reportDropped() {
+					return
+				}
+			}
+		}
+	})
+}
+
+// tailFilter отбирает записи по параметрам запроса TailHandler.
+type tailFilter struct {
+	level  Level
+	fields map[string]string
+}
+
+// parseTailFilter разбирает параметры level и field.
+func parseTailFilter(r *http.Request) (tailFilter, error) {
+	query := r.URL.Query()
+	filter := tailFilter{level: LevelDebug}
+
+	if s := query.Get("level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return tailFilter{}, err
+		}
+		filter.level = level
+	}
+	for _, pair := range query["field"] {
+		key, value, ok := strings.Cut(pair, ":")
+		if !ok || key == "" {
+			return tailFilter{}, errors.New("sglogger: field filter must be key:value")
+		}
+		if filter.fields == nil {
+			filter.fields = make(map[string]string)
+		}
+		filter.fields[key] = value
+	}
+	return filter, nil
+}
+
+// matches сообщает, проходит ли запись фильтр.
+func (f tailFilter) matches(e Entry) bool {
+	if e.Level < f.level {
+		return false
+	}
+	for k, want := range f.fields {
+		v, ok := e.Fields[k]
+		if !ok || fieldString(v) != want {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// defaultTenantField - поле арендатора по умолчанию.
+const defaultTenantField = "tenant"
+
+// ContextWithTenant возвращает контекст, к которому привязан арендатор
+// под ключом TenantKey (см. WithTenantExtraction).
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, TenantKey, tenant)
+}
+
+// tenantFieldsHandler дополняет поля обработчика арендатором из контекста.
+type tenantFieldsHandler struct {
+	FieldsHandler
+	key   interface{}
+	field string
+}
+
+// WithTenantExtraction возвращает обработчик полей, который добавляет к полям
+// handler арендатора из контекста (строковое значение под config.ContextKey)
+// в поле config.Field. Если поле уже задано явно или через ContextWithFields,
+// оно не перезаписывается; пустой арендатор не добавляется. Ключ регистрируется
+// через RegisterContextKey, поэтому DetachFields переносит арендатора.
+func WithTenantExtraction(handler FieldsHandler, config TenantConfig) FieldsHandler {
+	if handler == nil {
+		handler = NewFieldsHandler()
+	}
+	if config.ContextKey == nil {
+		config.ContextKey = TenantKey
+	}
+	if config.Field == "" {
+		config.Field = defaultTenantField
+	}
+	RegisterContextKey(config.ContextKey)
+	return &tenantFieldsHandler{
+		FieldsHandler: handler,
+		key:           config.ContextKey,
+		field:         config.Field,
+	}
+}
+
+// ExtractFieldsFromContext извлекает поля обработчиком и добавляет арендатора.
+func (h *tenantFieldsHandler) ExtractFieldsFromContext(ctx context.Context, fields Fields) Fields {
+	result := h.FieldsHandler.ExtractFieldsFromContext(ctx, fields)
+	if ctx == nil {
+		return result
+	}
+
+	tenant, _ := ctx.Value(h.key).(string)
+	if tenant == "" {
+		return result
+	}
+	if _, ok := result[h.field]; ok {
+		return result
+	}
+	if result == nil {
+		result = make(Fields, 1)
+	}
+	result[h.field] = tenant
+	return result
+}
+
+// tenantRouter направляет записи провайдеру арендатора из поля записи.
+type tenantRouter struct {
+	closedState
+
+	config TenantRouterConfig
+}
+
+// NewTenantRouter создает провайдер, который передает каждую запись провайдеру
+// из config.Routes по значению поля config.Field. Записи неизвестных арендаторов
+// и записи без арендатора передаются config.Default, а если он не задан,
+// отбрасываются без ошибки. Один провайдер может обслуживать несколько
+// арендаторов: Flush и Close вызываются для него один раз.
+// Возвращает ошибку, если не задано ни одного маршрута и провайдера по умолчанию.
+func NewTenantRouter(config TenantRouterConfig) (LoggerProvider, error) {
+	if len(config.Routes) == 0 && config.Default == nil {
+		return nil, fmt.Errorf("sglogger: tenant router has no routes")
+	}
+	for tenant, provider := range config.Routes {
+		if provider == nil {
+			return nil, fmt.Errorf("sglogger: tenant %q has no provider", tenant)
+		}
+	}
+	if config.Field == "" {
+		config.Field = defaultTenantField
+	}
+	routes := make(map[string]LoggerProvider, len(config.Routes))
+	for tenant, provider := range config.Routes {
+		routes[tenant] = provider
+	}
+	config.Routes = routes
+	return &tenantRouter{config: config}, nil
+}
+
+// route возвращает провайдер для полей записи или nil.
+func (r *tenantRouter) route(fields Fields) LoggerProvider {
+	if provider, ok := r.config.Routes[fieldString(fields[r.config.Field])]; ok {
+		return provider
+	}
+	return r.config.Default
+}
+
+// Write передает запись провайдеру арендатора.
+func (r *tenantRouter) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if r.isClosed() {
+		return ErrProviderClosed
+	}
+
+	provider := r.route(fields)
+	if provider == nil || !providerActive(provider) || !provider.ShouldLog(ctx, level) {
+		return nil
+	}
+	return provider.Write(ctx, level, message, fields)
+}
+
+// ShouldLog сообщает, примет ли запись уровня level хотя бы один из провайдеров.
+func (r *tenantRouter) ShouldLog(ctx context.Context, level Level) bool {
+	if r.isClosed() {
+		return false
+	}
+	for _, provider := range r.providers() {
+		if providerActive(provider) && provider.ShouldLog(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name возвращает имя провайдера из конфигурации или "tenant-router" по умолчанию.
+func (r *tenantRouter) Name() string {
+	if r.config.Name != "" {
+		return r.config.Name
+	}
+	return "tenant-router"
+}
+
+// Flush сбрасывает буферизованный вывод всех провайдеров.
+func (r *tenantRouter) Flush(ctx context.Context) error {
+	var errs []error
+	for _, provider := range r.providers() {
+		if flusher, ok := provider.(Flusher); ok {
+			if err := flusher.Flush(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("sglogger: flush provider %q: %w", ProviderName(provider), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close закрывает все провайдеры, даже если предыдущие завершились ошибкой.
+// Повторный вызов возвращает nil.
+func (r *tenantRouter) Close(ctx context.Context) error {
+	if !r.markClosed() {
+		return nil
+	}
+	var errs []error
+	for _, provider := range r.providers() {
+		if err := provider.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("sglogger: close provider %q: %w", ProviderName(provider), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// providers возвращает провайдеры маршрутов и провайдер по умолчанию без повторов.
+func (r *tenantRouter) providers() []LoggerProvider {
+	seen := make(map[LoggerProvider]struct{}, len(r.config.Routes)+1)
+	result := make([]LoggerProvider, 0, len(r.config.Routes)+1)
+	add := func(provider LoggerProvider) {
+		if provider == nil {
+			return
+		}
+		if _, ok := seen[provider]; ok {
+			return
+		}
+		seen[provider] = struct{}{}
+		result = append(result, provider)
+	}
+	for _, provider := range r.config.Routes {
+		add(provider)
+	}
+	add(r.config.Default)
+	return result
+}
@@ -0,0 +1,140 @@
+package sglogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// textFormatter формирует строки вида
+// `[2006-01-02 15:04:05] <level> "<message>" {key1=value1 key2=value2}`.
+type textFormatter struct {
+	labels    levelLabels
+	align     AlignConfig
+	floats    FloatFormat
+	durations DurationPolicy
+	links     *hyperlinks
+}
+
+// NewTextFormatter создает текстовый формат, используемый fmtProvider по умолчанию.
+// Учитывает LevelFormat, Align, Hyperlinks, FloatFormat и Durations из конфигурации.
+// Ссылки выводятся независимо от того, куда записываются строки: проверку
+// терминала выполняет NewFmtProvider.
+// Возвращает ошибку, если подписи уровней в LevelFormat заданы не для всех уровней.
+func NewTextFormatter(config ProviderConfig) (Formatter, error) {
+	labels, err := newLevelLabels(config.LevelFormat)
+	if err != nil {
+		return nil, err
+	}
+	if config.Align.Enabled && config.LevelFormat.Width == 0 {
+		config.LevelFormat.Width = labels.maxWidth()
+		labels, _ = newLevelLabels(config.LevelFormat)
+	}
+	if config.Align.NameField == "" {
+		config.Align.NameField = defaultNameField
+	}
+
+	return &textFormatter{
+		labels:    labels,
+		align:     config.Align,
+		floats:    config.FloatFormat,
+		durations: config.Durations,
+		links:     newHyperlinks(config.Hyperlinks),
+	}, nil
+}
+
+// AppendFormat добавляет запись в текстовом формате к buf.
+func (f *textFormatter) AppendFormat(buf []byte, e Entry) []byte {
+	e.Fields = durationFields(e.Fields, f.durations)
+	if f.links != nil {
+		e.Fields = f.links.fields(e.Fields)
+	}
+
+	start := len(buf)
+	buf = append(buf, '[')
+	buf = e.Time.AppendFormat(buf, "2006-01-02 15:04:05")
+	buf = append(buf, "] "...)
+	buf = append(buf, f.labels.label(e.Level)...)
+
+	skip := ""
+	if f.align.Enabled && f.align.NameWidth > 0 {
+		skip = f.align.NameField
+		buf = append(buf, ' ')
+		buf = appendPadded(buf, fieldString(e.Fields[skip]), f.align.NameWidth)
+	}
+
+	buf = append(buf, " \""...)
+	buf = append(buf, e.Message...)
+	buf = append(buf, "\" "...)
+	if f.align.Enabled && countFields(e.Fields, skip) > 0 {
+		for width := displayWidth(buf[start:]); width < f.align.FieldsColumn; width++ {
+			buf = append(buf, ' ')
+		}
+	}
+	buf = appendFields(buf, e.Fields, skip, f.floats)
+	return append(buf, '\n')
+}
+
+// defaultNameField - поле, выводимое в колонке имени при выравнивании.
+const defaultNameField = "component"
+
+// fieldString возвращает строковое представление значения поля.
+func fieldString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.RawMessage:
+		return string(val)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// countFields возвращает количество полей без учета поля skip.
+func countFields(fields Fields, skip string) int {
+	n := len(fields)
+	if _, ok := fields[skip]; ok && skip != "" {
+		n--
+	}
+	return n
+}
+
+// appendFields добавляет поля в формате "{key1=value1 key2=value2}", пропуская поле skip.
+// Строковые значения заключаются в кавычки, числа с плавающей точкой выводятся
+// согласно format, остальные значения выводятся как есть.
+func appendFields(buf []byte, fields Fields, skip string, format FloatFormat) []byte {
+	if countFields(fields, skip) == 0 {
+		return buf
+	}
+
+	buf = append(buf, '{')
+	first := true
+	for k, v := range fields {
+		if k == skip && skip != "" {
+			continue
+		}
+		if !first {
+			buf = append(buf, ' ')
+		}
+		first = false
+
+		buf = append(buf, k...)
+		buf = append(buf, '=')
+		switch val := v.(type) {
+		case string:
+			buf = strconv.AppendQuote(buf, val)
+		case hyperlinkText:
+			buf = appendHyperlink(buf, val)
+		case json.RawMessage:
+			buf = appendQuotedPayload(buf, val)
+		case float64:
+			buf = appendFloat(buf, val, 64, format)
+		case float32:
+			buf = appendFloat(buf, float64(val), 32, format)
+		default:
+			buf = fmt.Appendf(buf, "%v", val)
+		}
+	}
+	return append(buf, '}')
+}
@@ -0,0 +1,97 @@
+package sglogger
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges перечисляет диапазоны символов, занимающих в терминале две колонки
+// (иероглифы, хангыль, полноширинные формы, эмодзи).
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe4f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1},
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1},
+	},
+}
+
+// runeWidth возвращает количество колонок терминала, занимаемых символом.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.IsControl(r):
+		return 0
+	case unicode.Is(wideRanges, r):
+		return 2
+	}
+	return 1
+}
+
+// ansiSequenceLen возвращает длину управляющей последовательности ANSI (CSI),
+// начинающейся с позиции i, или 0, если последовательности там нет.
+func ansiSequenceLen[T string | []byte](s T, i int) int {
+	if i+1 >= len(s) || s[i] != 0x1b || s[i+1] != '[' {
+		return 0
+	}
+	for j := i + 2; j < len(s); j++ {
+		if s[j] >= 0x40 && s[j] <= 0x7e {
+			return j - i + 1
+		}
+	}
+	return len(s) - i
+}
+
+// displayWidth возвращает ширину текста в колонках терминала.
+// Управляющие последовательности ANSI (цвета) не учитываются.
+func displayWidth(s []byte) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := ansiSequenceLen(s, i); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRune(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// appendPadded добавляет текст, обрезанный или дополненный пробелами до ширины width колонок.
+// Управляющие последовательности ANSI копируются без учета в ширине.
+func appendPadded(buf []byte, s string, width int) []byte {
+	used := 0
+	for i := 0; i < len(s); {
+		if n := ansiSequenceLen(s, i); n > 0 {
+			buf = append(buf, s[i:i+n]...)
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runeWidth(r)
+		if used+w > width {
+			break
+		}
+		buf = append(buf, s[i:i+size]...)
+		used += w
+		i += size
+	}
+
+	for ; used < width; used++ {
+		buf = append(buf, ' ')
+	}
+	return buf
+}
@@ -0,0 +1,206 @@
+package sglogger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// timeoutQueueSize ограничивает количество записей, ожидающих выполнения
+// во внутреннем провайдере. Вместе с единственным рабочим горутином это
+// гарантирует, что зависший провайдер не приведет к неограниченному росту горутин.
+const timeoutQueueSize = 256
+
+// timeoutProvider оборачивает LoggerProvider и ограничивает время выполнения Write.
+// Записи выполняются последовательно одним рабочим горутином; если запись
+// не завершилась за отведенное время, вызывающий получает ErrTimeout,
+// а сама запись продолжает выполняться в фоне.
+type timeoutProvider struct {
+	closedState
+
+	inner  LoggerProvider
+	worker *timeoutWorker
+}
+
+// NewTimeoutProvider создает обертку, ограничивающую каждый вызов Write
+// внутреннего провайдера заданным таймаутом.
+// Защищает логгер от зависания, например, при недоступном сетевом диске.
+// Возвращает ошибку, если внутренний провайдер не задан или таймаут не положителен.
+func NewTimeoutProvider(inner LoggerProvider, timeout time.Duration) (LoggerProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("sglogger: timeout provider requires an inner provider")
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("sglogger: invalid write timeout %s", timeout)
+	}
+
+	return &timeoutProvider{
+		inner:  inner,
+		worker: newTimeoutWorker(timeout),
+	}, nil
+}
+
+// Write передает сообщение внутреннему провайдеру и ожидает результат
+// не дольше заданного таймаута. При превышении таймаута возвращает ErrTimeout;
+// если очередь заполнена и не освободилась за таймаут, сообщение отбрасывается.
+func (p *timeoutProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	return p.worker.do(ctx, func() error {
+		return p.inner.Write(ctx, level, message, fields)
+	})
+}
+
+// Name возвращает имя внутреннего провайдера.
+func (p *timeoutProvider) Name() string {
+	return ProviderName(p.inner)
+}
+
+// Describe возвращает таймаут записи и описание внутреннего провайдера (см. Describer).
+func (p *timeoutProvider) Describe() map[string]interface{} {
+	return map[string]interface{}{
+		"timeout": p.worker.timeout.String(),
+		"inner":   describeProvider(p.inner),
+	}
+}
+
+// WriteTimeout возвращает таймаут записи, заданный при создании обертки.
+func (p *timeoutProvider) WriteTimeout() time.Duration {
+	return p.worker.timeout
+}
+
+// Active делегирует проверку активности внутреннему провайдеру.
+func (p *timeoutProvider) Active() bool {
+	return providerActive(p.inner)
+}
+
+// ShouldLog делегирует проверку уровня внутреннему провайдеру.
+func (p *timeoutProvider) ShouldLog(ctx context.Context, level Level) bool {
+	return !p.isClosed() && p.inner.ShouldLog(ctx, level)
+}
+
+// Close прекращает прием новых сообщений, дожидается выполнения уже
+// поставленных в очередь записей (в пределах ctx) и закрывает внутренний провайдер
+// (см. ChainClose).
+func (p *timeoutProvider) Close(ctx context.Context) error {
+	if !p.markClosed() {
+		return nil
+	}
+	return ChainClose(ctx, p.inner, p.worker.stop)
+}
+
+// timeoutWorker выполняет записи последовательно в одном горутине и
+// ограничивает ожидание их результата таймаутом. Запись, не завершившаяся
+// за таймаут, продолжает выполняться в фоне; очередь ограничена
+// timeoutQueueSize, поэтому зависшая запись не приводит к росту числа горутин.
+type timeoutWorker struct {
+	timeout  time.Duration
+	jobs     chan *timeoutJob
+	stopping chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// timeoutJob описывает одну отложенную запись.
+type timeoutJob struct {
+	run    func() error
+	result chan error
+}
+
+// newTimeoutWorker создает очередь записей и запускает рабочий горутин.
+func newTimeoutWorker(timeout time.Duration) *timeoutWorker {
+	w := &timeoutWorker{
+		timeout:  timeout,
+		jobs:     make(chan *timeoutJob, timeoutQueueSize),
+		stopping: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+// do ставит run в очередь и ожидает результат не дольше таймаута.
+// Возвращает ErrTimeout, если запись не поставлена в очередь или не завершилась
+// за таймаут, ошибку ctx при его отмене и ErrProviderClosed после stop.
+// Если do вернул ошибку до постановки в очередь, run не вызывается.
+func (w *timeoutWorker) do(ctx context.Context, run func() error) error {
+	select {
+	case <-w.stopping:
+		return ErrProviderClosed
+	default:
+	}
+
+	job := &timeoutJob{
+		run:    run,
+		result: make(chan error, 1),
+	}
+
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+
+	select {
+	case w.jobs <- job:
+	case <-w.stopping:
+		return ErrProviderClosed
+	case <-timer.C:
+		return ErrTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-job.result:
+		return err
+	case <-w.stopped:
+		// Запись, поставленная в очередь одновременно с stop, могла
+		// не выполниться: рабочий горутин уже завершился
+		select {
+		case err := <-job.result:
+			return err
+		default:
+			return ErrProviderClosed
+		}
+	case <-timer.C:
+		return ErrTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop прекращает прием записей и ждет выполнения поставленных в очередь
+// в пределах ctx. Не блокируется дольше ctx, даже если запись зависла.
+// Повторный вызов только ждет.
+func (w *timeoutWorker) stop(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stopping) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop последовательно выполняет записи из очереди, а после stop -
+// записи, оставшиеся в очереди.
+func (w *timeoutWorker) loop() {
+	defer close(w.stopped)
+
+	for {
+		select {
+		case job := <-w.jobs:
+			job.result <- job.run()
+		case <-w.stopping:
+			for {
+				select {
+				case job := <-w.jobs:
+					job.result <- job.run()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
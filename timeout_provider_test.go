@@ -0,0 +1,75 @@
+package sglogger
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimeoutWorkerStopWithHungWrite(t *testing.T) {
+	w := newTimeoutWorker(time.Hour)
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+	waiting := make(chan error, 1)
+	go func() {
+		waiting <- w.do(context.Background(), func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// Вызывающий Write все еще ждет результата, но stop ограничен ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := w.stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stop = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stop returned after %s, want about 50ms", elapsed)
+	}
+	if err := w.do(context.Background(), func() error { return nil }); err != ErrProviderClosed {
+		t.Errorf("do after stop = %v, want ErrProviderClosed", err)
+	}
+}
+
+func TestTimeoutWorkerStopRunsQueuedWrites(t *testing.T) {
+	w := newTimeoutWorker(5 * time.Second)
+	release := make(chan struct{})
+	var ran int32
+	job := func() error {
+		<-release
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}
+
+	results := make(chan error, 3)
+	for i := 0; i < cap(results); i++ {
+		go func() { results <- w.do(context.Background(), job) }()
+	}
+	// Первая запись выполняется, две ждут в очереди
+	for len(w.jobs) < cap(results)-1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- w.stop(context.Background()) }()
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Errorf("stop = %v", err)
+	}
+	for i := 0; i < cap(results); i++ {
+		if err := <-results; err != nil {
+			t.Errorf("do = %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&ran); got != 3 {
+		t.Errorf("%d queued writes ran, want 3", got)
+	}
+}
@@ -0,0 +1,120 @@
+package sglogger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultEscalationTTL - время, в течение которого трасса остается эскалированной.
+	defaultEscalationTTL = time.Minute
+
+	// defaultEscalationMaxTraces ограничивает число одновременно эскалированных трасс.
+	defaultEscalationMaxTraces = 1024
+)
+
+// escalatedTrace - трасса в очереди эскалации.
+type escalatedTrace struct {
+	traceID string
+	expires time.Time
+}
+
+// traceEscalation хранит трассы, в которых была записана ошибка. Для вызовов
+// с trace_id такой трассы минимальный уровень понижается до LevelDebug.
+// Срок эскалации отсчитывается от первой ошибки и не продлевается, поэтому
+// порядок очереди совпадает с порядком истечения сроков: истекшие и, при
+// переполнении, самые старые трассы удаляются из ее начала.
+type traceEscalation struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	maxTraces int
+	expires   map[string]time.Time
+	queue     []escalatedTrace
+}
+
+// newTraceEscalation создает набор эскалированных трасс. Возвращает nil, если эскалация не настроена.
+func newTraceEscalation(config *TraceEscalationConfig) *traceEscalation {
+	if config == nil {
+		return nil
+	}
+
+	e := &traceEscalation{
+		ttl:       config.TTL,
+		maxTraces: config.MaxTraces,
+		expires:   make(map[string]time.Time),
+	}
+	if e.ttl <= 0 {
+		e.ttl = defaultEscalationTTL
+	}
+	if e.maxTraces <= 0 {
+		e.maxTraces = defaultEscalationMaxTraces
+	}
+	return e
+}
+
+// context возвращает ctx с минимальным уровнем LevelDebug, если trace_id
+// контекста эскалирован. Явное переопределение уровнем LevelDebug не заменяется.
+func (e *traceEscalation) context(ctx context.Context) context.Context {
+	if e == nil || ctx == nil {
+		return ctx
+	}
+	traceID, ok := ctx.Value(TraceIDKey).(string)
+	if !ok || traceID == "" {
+		return ctx
+	}
+	if level, ok := MinLevelFromContext(ctx); ok && level <= LevelDebug {
+		return ctx
+	}
+
+	e.mu.Lock()
+	expires, escalated := e.expires[traceID]
+	e.mu.Unlock()
+
+	if !escalated || !time.Now().Before(expires) {
+		return ctx
+	}
+	return ContextWithMinLevel(ctx, LevelDebug)
+}
+
+// observe эскалирует трассу ctx, если запись имеет уровень LevelError или выше.
+func (e *traceEscalation) observe(ctx context.Context, level Level) {
+	if e == nil || level < LevelError || ctx == nil {
+		return
+	}
+	traceID, ok := ctx.Value(TraceIDKey).(string)
+	if !ok || traceID == "" {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.expireLocked(now)
+	if _, escalated := e.expires[traceID]; escalated {
+		return
+	}
+	if len(e.queue) >= e.maxTraces {
+		oldest := e.queue[0]
+		e.queue = e.queue[1:]
+		delete(e.expires, oldest.traceID)
+	}
+	expires := now.Add(e.ttl)
+	e.expires[traceID] = expires
+	e.queue = append(e.queue, escalatedTrace{traceID: traceID, expires: expires})
+}
+
+// expireLocked удаляет трассы с истекшим сроком. Вызывается с захваченным мьютексом.
+func (e *traceEscalation) expireLocked(now time.Time) {
+	n := 0
+	for n < len(e.queue) && !now.Before(e.queue[n].expires) {
+		delete(e.expires, e.queue[n].traceID)
+		n++
+	}
+	if n > 0 {
+		// Копирование вместо сдвига среза не дает очереди удерживать
+		// растущий исходный массив.
+		e.queue = append(e.queue[:0], e.queue[n:]...)
+	}
+}
@@ -0,0 +1,80 @@
+package sglogger
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// sanitizer заменяет некорректные последовательности UTF-8 в сообщении,
+// ключах и строковых значениях полей и, если задано, нормализует эти строки.
+type sanitizer struct {
+	normalize func(string) string
+}
+
+// newSanitizer создает sanitizer по конфигурации логгера. Возвращает nil,
+// если ни проверка UTF-8, ни нормализация не включены.
+func newSanitizer(config LoggerConfig) *sanitizer {
+	if !config.SanitizeUTF8 && config.NormalizeUnicode == nil {
+		return nil
+	}
+	return &sanitizer{normalize: config.NormalizeUnicode}
+}
+
+// entry возвращает исправленные сообщение и поля и сообщает, изменилось ли
+// что-нибудь. Карта полей копируется только при изменении, поэтому поля
+// вызывающего и контекста не изменяются.
+func (s *sanitizer) entry(message string, fields Fields) (string, Fields, bool) {
+	message, changed := s.string(message)
+
+	copied := false
+	for k, v := range fields {
+		key, keyChanged := s.string(k)
+		value, valueChanged := v, false
+		if str, ok := v.(string); ok {
+			value, valueChanged = s.string(str)
+		}
+		if !keyChanged && !valueChanged {
+			continue
+		}
+		if !copied {
+			fields = cloneFields(fields)
+			copied = true
+		}
+		if keyChanged {
+			delete(fields, k)
+		}
+		fields[key] = value
+		changed = true
+	}
+	return message, fields, changed
+}
+
+// string заменяет некорректные байты строки на U+FFFD и применяет нормализацию.
+func (s *sanitizer) string(str string) (string, bool) {
+	result := str
+	if !utf8.ValidString(result) {
+		result = replaceInvalidUTF8(result)
+	}
+	if s.normalize != nil {
+		result = s.normalize(result)
+	}
+	return result, result != str
+}
+
+// replaceInvalidUTF8 заменяет каждый некорректный байт на U+FFFD, как это
+// делает кодировщик JSON, чтобы текстовый и JSON-вывод совпадали.
+// strings.ToValidUTF8 заменяет серию байтов одним символом и поэтому не подходит.
+func replaceInvalidUTF8(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
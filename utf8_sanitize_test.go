@@ -0,0 +1,98 @@
+package sglogger
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// composeAcute заменяет "e" с комбинируемым ударением на "é", как это
+// сделал бы norm.NFC.String для этой строки.
+func composeAcute(s string) string {
+	return strings.ReplaceAll(s, "e\u0301", "é")
+}
+
+func TestSanitizerString(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) string
+		in        string
+		want      string
+	}{
+		{name: "valid", in: "привет, мир", want: "привет, мир"},
+		{name: "invalid bytes", in: "a\xff\xfeb", want: "a��b"},
+		{name: "truncated rune", in: "price \xe2\x82", want: "price ��"},
+		{name: "overlong encoding", in: "\xc0\xaf", want: "��"},
+		{name: "surrogate half", in: "\xed\xa0\x80", want: "���"},
+		{name: "continuation without lead", in: "\x80abc", want: "�abc"},
+		{name: "normalized", normalize: composeAcute, in: "cafe\u0301", want: "café"},
+		{name: "sanitized then normalized", normalize: composeAcute, in: "cafe\u0301\xff", want: "café�"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &sanitizer{normalize: tt.normalize}
+			got, changed := s.string(tt.in)
+			if got != tt.want {
+				t.Errorf("string(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if changed != (tt.in != tt.want) {
+				t.Errorf("changed = %v, want %v", changed, tt.in != tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeUTF8Outputs(t *testing.T) {
+	recorder := &recordingProvider{}
+	logger := NewLogger(LoggerConfig{SanitizeUTF8: true, Diagnostics: &DiagnosticsConfig{Disabled: true}}, NewFieldsHandler(), recorder)
+
+	fields := Fields{"user\xff": "bob\xc0", "count": 3, "clean": "ok"}
+	logger.InfoWithFields(context.Background(), fields, "%s", "bad \xe2\x82 message")
+	logger.Info(context.Background(), "clean message")
+
+	if got := logger.Stats().Sanitized; got != 1 {
+		t.Errorf("Sanitized = %d, want 1", got)
+	}
+	if _, ok := fields["user\xff"]; !ok || fields["user\xff"] != "bob\xc0" {
+		t.Errorf("caller fields modified: %q", fields)
+	}
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	entry := entries[0]
+	entry.Time = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if entry.Message != "bad �� message" {
+		t.Errorf("message = %q", entry.Message)
+	}
+	wantFields := Fields{"user�": "bob�", "count": 3, "clean": "ok"}
+	if !reflect.DeepEqual(entry.Fields, wantFields) {
+		t.Errorf("fields = %q, want %q", entry.Fields, wantFields)
+	}
+
+	text, err := NewTextFormatter(ProviderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatters := map[string]Formatter{
+		"text":          text,
+		"json":          NewJSONFormatter(ProviderConfig{}),
+		"deterministic": NewJSONFormatter(ProviderConfig{JSON: JSONFormat{Deterministic: true}}),
+	}
+	for name, f := range formatters {
+		line := f.AppendFormat(nil, entry)
+		if !utf8.Valid(line) {
+			t.Errorf("%s output is not valid UTF-8: %q", name, line)
+		}
+		for _, want := range []string{"bad �� message", "user�", "bob�"} {
+			if !strings.Contains(string(line), want) {
+				t.Errorf("%s output %q does not contain %q", name, line, want)
+			}
+		}
+	}
+}
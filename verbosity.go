@@ -0,0 +1,112 @@
+package sglogger
+
+import (
+	"flag"
+	"strconv"
+)
+
+// VerbosityToLevel преобразует уровень подробности командной строки в уровень логирования:
+// 0 - LevelWarn, 1 (-v) - LevelInfo, 2 и более (-vv) - LevelDebug,
+// отрицательные значения (-q) - LevelError.
+func VerbosityToLevel(n int) Level {
+	switch {
+	case n < 0:
+		return LevelError
+	case n == 0:
+		return LevelWarn
+	case n == 1:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// BindVerbosity регистрирует в наборе флагов классические флаги подробности
+// и применяет их к уровню логгера по мере разбора командной строки:
+//   - -v увеличивает подробность на 1 (может повторяться: -v -v);
+//   - -vv увеличивает подробность на 2;
+//   - -q, -quiet оставляют только ошибки.
+//
+// Сразу после вызова уровень логгера устанавливается в VerbosityToLevel(0).
+// Для cobra/pflag набор флагов можно подключить через pflag.FlagSet.AddGoFlagSet.
+func BindVerbosity(fs *flag.FlagSet, l Logger) {
+	v := &verbosity{logger: l}
+
+	fs.Var(&verbosityFlag{v: v, step: 1}, "v", "increase log verbosity (repeatable)")
+	fs.Var(&verbosityFlag{v: v, step: 2}, "vv", "increase log verbosity by two")
+	fs.Var(&quietFlag{v: v}, "q", "log errors only")
+	fs.Var(&quietFlag{v: v}, "quiet", "log errors only")
+
+	v.apply()
+}
+
+// verbosity хранит состояние флагов подробности и применяет его к логгеру.
+type verbosity struct {
+	logger Logger
+	count  int
+	quiet  bool
+}
+
+// apply устанавливает уровень логгера в соответствии с текущими флагами.
+func (v *verbosity) apply() {
+	n := v.count
+	if v.quiet {
+		n = -1
+	}
+	v.logger.SetLevel(VerbosityToLevel(n))
+}
+
+// verbosityFlag реализует flag.Value для счетных флагов -v и -vv.
+type verbosityFlag struct {
+	v    *verbosity
+	step int
+}
+
+func (f *verbosityFlag) String() string {
+	if f == nil || f.v == nil {
+		return "0"
+	}
+	return strconv.Itoa(f.v.count)
+}
+
+func (f *verbosityFlag) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		f.v.count += f.step
+		f.v.apply()
+	}
+	return nil
+}
+
+func (f *verbosityFlag) IsBoolFlag() bool {
+	return true
+}
+
+// quietFlag реализует flag.Value для флагов -q и -quiet.
+type quietFlag struct {
+	v *verbosity
+}
+
+func (f *quietFlag) String() string {
+	if f == nil || f.v == nil {
+		return "false"
+	}
+	return strconv.FormatBool(f.v.quiet)
+}
+
+func (f *quietFlag) Set(value string) error {
+	quiet, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	f.v.quiet = quiet
+	f.v.apply()
+	return nil
+}
+
+func (f *quietFlag) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,246 @@
+package sglogger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// victoriaLogsProvider отправляет записи в VictoriaLogs через
+// /insert/jsonline: одна строка JSON на запись, сообщение в поле _msg,
+// время в поле _time.
+type victoriaLogsProvider struct {
+	closedState
+
+	config      VictoriaLogsConfig
+	client      *http.Client
+	url         string
+	reserved    []string
+	batcher     *httpBatcher
+	diagnostics *diagnostics
+	sizes       sizeHistogram
+	leakCheck   *leakCheck
+}
+
+// NewVictoriaLogsProvider создает провайдер VictoriaLogs.
+// Записи отправляются пакетами в фоне (см. HTTPBatchConfig) и при включенном
+// Gzip или Batch.Compression сжимаются. Каждая строка содержит _msg, _time, level, поля StreamFields
+// и поля записи; поля записи с именами служебных полей или полей потока
+// получают префикс "fields.". При заданном Batch.TenantHeaderFromField значение поля
+// передается в заголовке Batch.TenantHeader, по умолчанию AccountID.
+// Возвращает ошибку, если адрес не задан или некорректен.
+func NewVictoriaLogsProvider(config VictoriaLogsConfig) (LoggerProvider, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("sglogger: victorialogs endpoint is not set")
+	}
+	base, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("sglogger: invalid victorialogs endpoint: %w", err)
+	}
+	if err := config.ProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Batch.validate(); err != nil {
+		return nil, err
+	}
+	config.ProviderConfig = config.ProviderConfig.clone()
+	config.StreamFields = cloneStrings(config.StreamFields)
+	config.Level = clampLevel(config.Level)
+	if config.Batch.TenantHeader == "" {
+		config.Batch.TenantHeader = "AccountID"
+	}
+	if config.Gzip {
+		config.Batch.Compression = HTTPCompressionGzip
+	}
+
+	client, err := NewHTTPClient(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	streamKeys := make([]string, 0, len(config.StreamFields))
+	for k := range config.StreamFields {
+		streamKeys = append(streamKeys, k)
+	}
+	sort.Strings(streamKeys)
+
+	query := url.Values{}
+	query.Set("_msg_field", "_msg")
+	query.Set("_time_field", "_time")
+	if len(streamKeys) > 0 {
+		query.Set("_stream_fields", strings.Join(streamKeys, ","))
+	}
+	base.Path = strings.TrimRight(base.Path, "/") + "/insert/jsonline"
+	base.RawQuery = query.Encode()
+
+	p := &victoriaLogsProvider{
+		config:      config,
+		client:      client,
+		url:         base.String(),
+		reserved:    append([]string{"_msg", "_time", "level"}, streamKeys...),
+		diagnostics: newDiagnostics(config.Diagnostics),
+	}
+	p.batcher = newHTTPBatcher(config.Batch, p.send, nil, func(err error) {
+		p.diagnostics.reportf(p.Name(), "%v", err)
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(p.Name(), err)
+		}
+	})
+	if !config.LeakCheck {
+		return p, nil
+	}
+	// Фоновая отправка ссылается на p, поэтому финализатор устанавливается
+	// на обертку, которую удерживает только вызывающий
+	h := &victoriaLogsProviderHandle{p}
+	p.leakCheck = newLeakCheck(true, h, "VictoriaLogs provider "+p.url, p.diagnostics)
+	return h, nil
+}
+
+// victoriaLogsProviderHandle - провайдер, возвращаемый NewVictoriaLogsProvider при LeakCheck.
+type victoriaLogsProviderHandle struct{ *victoriaLogsProvider }
+
+// Write ставит запись в очередь отправки.
+func (p *victoriaLogsProvider) Write(ctx context.Context, level Level, message string, fields Fields) error {
+	if p.isClosed() {
+		return ErrProviderClosed
+	}
+	if !p.ShouldLog(ctx, level) {
+		return nil
+	}
+	return p.batcher.add(Entry{
+		Time:    entryTime(ctx),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+// WriteBatch отправляет записи синхронно, минуя очередь (см. BatchWriter).
+func (p *victoriaLogsProvider) WriteBatch(ctx context.Context, entries []Entry) error {
+	return p.batcher.writeBatch(ctx, entries)
+}
+
+// EntrySizes возвращает гистограмму размеров отправленных строк
+// (до сжатия, без учета повторов).
+func (p *victoriaLogsProvider) EntrySizes() SizeHistogram {
+	return p.sizes.snapshot()
+}
+
+// DroppedEntries возвращает количество записей, потерянных при отправке
+// (см. DropRecorder).
+func (p *victoriaLogsProvider) DroppedEntries() uint64 {
+	return p.batcher.droppedEntries()
+}
+
+// Name возвращает имя провайдера из конфигурации или "victorialogs" по умолчанию.
+func (p *victoriaLogsProvider) Name() string {
+	if p.config.Name != "" {
+		return p.config.Name
+	}
+	return "victorialogs"
+}
+
+// Describe возвращает уровень, адрес, арендатора и параметры пакетов (см. Describer).
+func (p *victoriaLogsProvider) Describe() map[string]interface{} {
+	description := describeProviderConfig(p.config.ProviderConfig)
+	description["format"] = "jsonline"
+	description["endpoint"] = p.config.Endpoint
+	description["account_id"] = p.config.AccountID
+	description["project_id"] = p.config.ProjectID
+	description["gzip"] = p.config.Batch.Compression == HTTPCompressionGzip
+	description["batch"] = describeHTTPBatch(p.batcher.config)
+	return description
+}
+
+// Active сообщает, активен ли провайдер согласно EnabledWhen из конфигурации.
+func (p *victoriaLogsProvider) Active() bool {
+	return p.config.EnabledWhen == nil || p.config.EnabledWhen()
+}
+
+// ShouldLog определяет, нужно ли логировать сообщение данного уровня.
+// Если включен HonorContextLevel, уровень из ContextWithMinLevel заменяет уровень провайдера.
+func (p *victoriaLogsProvider) ShouldLog(ctx context.Context, level Level) bool {
+	if p.isClosed() {
+		return false
+	}
+	if p.config.HonorContextLevel {
+		if minLevel, ok := MinLevelFromContext(ctx); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= p.config.Level
+}
+
+// Flush отправляет записи из очереди.
+func (p *victoriaLogsProvider) Flush(ctx context.Context) error {
+	return p.batcher.flush(ctx)
+}
+
+// Close отправляет оставшиеся записи и прекращает прием новых.
+func (p *victoriaLogsProvider) Close(ctx context.Context) error {
+	p.leakCheck.markClosed()
+
+	if !p.markClosed() {
+		return nil
+	}
+	return p.batcher.close(ctx)
+}
+
+// send отправляет пакет записей.
+func (p *victoriaLogsProvider) send(ctx context.Context, tenant string, entries []Entry) error {
+	var body []byte
+	for _, e := range entries {
+		start := len(body)
+		body = p.appendLine(body, e)
+		p.sizes.observe(len(body) - start)
+	}
+
+	body, encoding, err := compressHTTPBody(p.config.Batch, body)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, p.client, httpMaxRetries(p.config.Batch), p.diagnostics, p.Name(), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("sglogger: create victorialogs request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/stream+json")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		if p.config.AccountID != "" {
+			req.Header.Set("AccountID", p.config.AccountID)
+		}
+		if p.config.ProjectID != "" {
+			req.Header.Set("ProjectID", p.config.ProjectID)
+		}
+		if tenant != "" {
+			req.Header.Set(p.config.Batch.TenantHeader, tenant)
+		}
+		return req, nil
+	})
+}
+
+// appendLine добавляет строку JSON для одной записи.
+func (p *victoriaLogsProvider) appendLine(buf []byte, e Entry) []byte {
+	buf = append(buf, `{"_msg":`...)
+	buf = appendJSONString(buf, e.Message)
+	buf = append(buf, `,"_time":"`...)
+	buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, `","level":`...)
+	buf = appendJSONString(buf, e.Level.String())
+	for k, v := range p.config.StreamFields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, k)
+		buf = append(buf, ':')
+		buf = appendJSONString(buf, v)
+	}
+	buf = appendJSONFields(buf, durationFields(e.Fields, p.config.Durations), p.config.FloatFormat, p.reserved...)
+	return append(buf, "}\n"...)
+}